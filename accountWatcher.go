@@ -0,0 +1,258 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+)
+
+var (
+	ErrWatchlistTooLarge = errors.New("too many accounts to watch")
+)
+
+// AccountChangeKind identifies what happened to a watched account
+type AccountChangeKind int
+
+const (
+	AccountSnapshot      AccountChangeKind = iota // initial state reported when watching starts
+	AccountDeactivated                            // the owner deactivated the account
+	AccountTakenDown                              // the host or a moderation service took the account down
+	AccountSuspended                              // the account was temporarily suspended
+	AccountDeleted                                // the account was deleted
+	AccountStatusChanged                          // the account went inactive for a reason not listed above
+	AccountReactivated                            // an inactive account became active again
+	AccountHandleChanged                          // the account's handle changed
+)
+
+func (k AccountChangeKind) String() string {
+	switch k {
+	case AccountSnapshot:
+		return "Snapshot"
+	case AccountDeactivated:
+		return "Deactivated"
+	case AccountTakenDown:
+		return "Taken Down"
+	case AccountSuspended:
+		return "Suspended"
+	case AccountDeleted:
+		return "Deleted"
+	case AccountStatusChanged:
+		return "Status Changed"
+	case AccountReactivated:
+		return "Reactivated"
+	case AccountHandleChanged:
+		return "Handle Changed"
+	default:
+		return "Unknown"
+	}
+}
+
+// AccountState is the known status of a watched account
+type AccountState struct {
+	DID    string `json:"did"`
+	Handle string `json:"handle,omitempty"` // empty until known
+	Active bool   `json:"active"`
+	Status string `json:"status,omitempty"` // reason the account is inactive, e.g. "takendown"
+}
+
+// AccountChange reports a status change for a watched account
+type AccountChange struct {
+	Kind     AccountChangeKind `json:"kind"`
+	DID      string            `json:"did"`
+	Previous *AccountState     `json:"previous,omitempty"` // nil for snapshots
+	Current  *AccountState     `json:"current"`
+	Time     time.Time         `json:"time"`
+	Sequence int64             `json:"sequence,omitempty"` // firehose sequence; 0 for snapshots
+}
+
+func (c AccountChange) String() string {
+	return fmt.Sprintf("AccountChange{Kind: %s, DID: %s}", c.Kind, c.DID)
+}
+
+// WatchAccounts reports status changes for a watchlist of up to 10,000 DIDs. It first sends an AccountSnapshot
+// for every account whose status can be fetched with com.atproto.sync.getRepoStatus, then follows the
+// firehose's account and identity events and sends a typed change whenever an account is deactivated, taken
+// down, reactivated, or changes handle. Events that don't change the known state are skipped.
+//
+// The returned channel is closed when ctx is cancelled or the client is closed. Failed snapshot lookups are
+// sent to Events; those accounts are still watched and their first event is compared against an active state.
+//
+// Example:
+//
+//	changes, err := client.WatchAccounts(ctx, []string{"did:plc:abc123", "did:plc:xyz789"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for change := range changes {
+//	    fmt.Printf("%s: %s\n", change.DID, change.Kind)
+//	}
+func (f *Firefly) WatchAccounts(ctx context.Context, dids []string) (chan *AccountChange, error) {
+	if f.isClosed() {
+		return nil, ErrClientClosed
+	}
+	if len(dids) > maxWantedDids {
+		return nil, fmt.Errorf("%w: %d accounts, at most %d", ErrWatchlistTooLarge, len(dids), maxWantedDids)
+	}
+
+	states := make(map[string]*AccountState, len(dids))
+	for _, did := range dids {
+		states[did] = &AccountState{DID: did, Active: true}
+	}
+
+	ctx, cancel := f.bindLifetime(ctx)
+	// Connect before taking the snapshot so changes made while it runs are not missed
+	events, err := f.StreamEvents(ctx, &FirehoseOptions{Authors: dids, ExcludeCommits: true})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	changes := make(chan *AccountChange, 100)
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		defer cancel()
+		defer close(changes)
+
+		send := func(change *AccountChange) bool {
+			select {
+			case changes <- change:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, snapshot := range f.accountSnapshots(ctx, dids) {
+			states[snapshot.DID] = snapshot
+			current := *snapshot
+			if !send(&AccountChange{Kind: AccountSnapshot, DID: snapshot.DID, Current: &current, Time: time.Now()}) {
+				return
+			}
+		}
+
+		for event := range events {
+			state, ok := states[event.Repo]
+			if !ok {
+				continue
+			}
+			change := accountChange(state, event)
+			if change == nil {
+				continue
+			}
+			next := *change.Current
+			states[event.Repo] = &next
+			if !send(change) {
+				return
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+// accountSnapshots fetches the current status and handle of each account, in the order given
+func (f *Firefly) accountSnapshots(ctx context.Context, dids []string) []*AccountState {
+	handles := f.ResolveDIDsBulk(ctx, dids)
+	snapshots := make([]*AccountState, len(dids))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, bulkResolveConcurrency)
+	for i, did := range dids {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		}
+		wg.Add(1)
+		go func(i int, did string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			status, err := atproto.SyncGetRepoStatus(ctx, f.api, did)
+			if err != nil {
+				if ctx.Err() == nil {
+					f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("failed to get status of %s: %w", did, err))
+				}
+				return
+			}
+			snapshot := &AccountState{DID: did, Handle: handles[did], Active: status.Active}
+			if status.Status != nil {
+				snapshot.Status = *status.Status
+			}
+			snapshots[i] = snapshot
+		}(i, did)
+	}
+	wg.Wait()
+
+	found := snapshots[:0]
+	for _, snapshot := range snapshots {
+		if snapshot != nil {
+			found = append(found, snapshot)
+		}
+	}
+	return found
+}
+
+// accountChange compares an account or identity event with the known state, returning nil if nothing changed.
+// A handle seen for the first time is recorded in previous without reporting a change.
+func accountChange(previous *AccountState, event *FirehoseEvent) *AccountChange {
+	current := *previous
+	change := &AccountChange{DID: previous.DID, Time: event.Timestamp, Sequence: event.Sequence}
+
+	switch {
+	case event.AccountEvent != nil:
+		account := event.AccountEvent
+		current.Active = account.Active
+		current.Status = account.Status
+		if account.Active {
+			current.Status = ""
+		}
+		if current.Active == previous.Active && current.Status == previous.Status {
+			return nil
+		}
+		switch {
+		case account.Active:
+			change.Kind = AccountReactivated
+		case account.Status == "deactivated":
+			change.Kind = AccountDeactivated
+		case account.Status == "takendown":
+			change.Kind = AccountTakenDown
+		case account.Status == "suspended":
+			change.Kind = AccountSuspended
+		case account.Status == "deleted":
+			change.Kind = AccountDeleted
+		default:
+			change.Kind = AccountStatusChanged
+		}
+		if !account.Time.IsZero() {
+			change.Time = account.Time
+		}
+	case event.IdentityEvent != nil:
+		identity := event.IdentityEvent
+		// Identity events without a handle only signal that the DID document changed
+		if identity.Handle == "" || identity.Handle == previous.Handle {
+			return nil
+		}
+		// A handle learned for the first time isn't a change
+		if previous.Handle == "" {
+			previous.Handle = identity.Handle
+			return nil
+		}
+		current.Handle = identity.Handle
+		change.Kind = AccountHandleChanged
+		if !identity.Time.IsZero() {
+			change.Time = identity.Time
+		}
+	default:
+		return nil
+	}
+
+	previousCopy := *previous
+	change.Previous = &previousCopy
+	change.Current = &current
+	return change
+}
@@ -0,0 +1,296 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInviteCodeRequired = errors.New("server requires an invite code")
+	ErrFailedCreate       = errors.New("failed to create account")
+)
+
+// NewAccount describes an account for CreateAccount. Handle and Password are required on most servers;
+// Email is required on servers that send verification mail.
+type NewAccount struct {
+	Handle            string
+	Email             string
+	Password          string
+	InviteCode        string // required when the server's InviteCodeRequired is set
+	VerificationCode  string // only for servers that require phone verification
+	VerificationPhone string
+	RecoveryKey       string // optional PLC rotation key added to the new DID
+}
+
+// ServerInfo describes what a PDS requires to create an account
+type ServerInfo struct {
+	DID                       string   `json:"did"`
+	InviteCodeRequired        bool     `json:"inviteCodeRequired"`
+	PhoneVerificationRequired bool     `json:"phoneVerificationRequired"`
+	AvailableUserDomains      []string `json:"availableUserDomains"` // handle suffixes the server hands out, like ".bsky.social"
+}
+
+// DescribeServer returns the account requirements of the PDS the client points at. It works without logging in.
+func (f *Firefly) DescribeServer(ctx context.Context) (*ServerInfo, error) {
+	out, err := atproto.ServerDescribeServer(ctx, f.api)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadServer, err)
+	}
+	info := &ServerInfo{
+		DID:                  out.Did,
+		AvailableUserDomains: out.AvailableUserDomains,
+	}
+	if out.InviteCodeRequired != nil {
+		info.InviteCodeRequired = *out.InviteCodeRequired
+	}
+	if out.PhoneVerificationRequired != nil {
+		info.PhoneVerificationRequired = *out.PhoneVerificationRequired
+	}
+	return info, nil
+}
+
+// Session scopes carried by access tokens, telling full logins apart from app passwords
+const (
+	scopeAppPassword           = "com.atproto.appPass"
+	scopeAppPasswordPrivileged = "com.atproto.appPassPrivileged"
+)
+
+// SelfInfo describes the authenticated account and the services it lives on
+type SelfInfo struct {
+	DID             string        `json:"did"`
+	Handle          string        `json:"handle"`
+	PDS             string        `json:"pds"`  // the account's PDS endpoint from its DID document; the client host if the document has none
+	Host            string        `json:"host"` // the host the client sends requests to, which may be an entryway in front of the PDS
+	Email           string        `json:"email,omitempty"`
+	EmailConfirmed  bool          `json:"emailConfirmed"`
+	EmailAuthFactor bool          `json:"emailAuthFactor"` // sign-in requires a code sent by email
+	Active          bool          `json:"active"`
+	Status          string        `json:"status,omitempty"`       // why the account is inactive, such as "takendown" or "deactivated"
+	AppPassword     bool          `json:"appPassword"`            // the session was created with an app password
+	Privileged      bool          `json:"privileged"`             // the app password may access direct messages
+	AppPasswords    []AppPassword `json:"appPasswords,omitempty"` // nil if the session isn't allowed to list them
+	Server          *ServerInfo   `json:"server"`
+}
+
+func (s SelfInfo) String() string {
+	return fmt.Sprintf("SelfInfo{DID: %s, Handle: %s, PDS: %s, EmailConfirmed: %t, Active: %t, AppPassword: %t}",
+		s.DID, s.Handle, s.PDS, s.EmailConfirmed, s.Active, s.AppPassword)
+}
+
+// AppPassword is one of the account's app passwords; the password itself is never returned
+type AppPassword struct {
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Privileged bool      `json:"privileged"`
+}
+
+// DescribeSelf combines getSession, describeServer and the account's DID document into one report of the
+// authenticated account: where it is hosted, whether its email is confirmed, and whether the session uses an
+// app password. App passwords are listed when the session is allowed to, which usually needs a full login.
+//
+// Example:
+//
+//	self, err := client.DescribeSelf(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !self.EmailConfirmed {
+//	    fmt.Println("Confirm your email at", self.PDS)
+//	}
+func (f *Firefly) DescribeSelf(ctx context.Context) (*SelfInfo, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	session, err := atproto.ServerGetSession(ctx, f.api)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	server, err := f.DescribeServer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client := f.currentClient()
+	info := &SelfInfo{
+		DID:             session.Did,
+		Handle:          session.Handle,
+		PDS:             client.Host,
+		Host:            client.Host,
+		EmailConfirmed:  session.EmailConfirmed != nil && *session.EmailConfirmed,
+		EmailAuthFactor: session.EmailAuthFactor != nil && *session.EmailAuthFactor,
+		Active:          session.Active == nil || *session.Active,
+		Server:          server,
+	}
+	if session.Email != nil {
+		info.Email = *session.Email
+	}
+	if session.Status != nil {
+		info.Status = *session.Status
+	}
+	if session.DidDoc != nil {
+		if raw, err := json.Marshal(*session.DidDoc); err == nil {
+			var doc DIDDocument
+			if json.Unmarshal(raw, &doc) == nil {
+				if pds := doc.ServiceEndpoint("atproto_pds"); pds != "" {
+					info.PDS = pds
+				}
+			}
+		}
+	}
+	if client.Auth != nil {
+		switch sessionScope(client.Auth.AccessJwt) {
+		case scopeAppPassword:
+			info.AppPassword = true
+		case scopeAppPasswordPrivileged:
+			info.AppPassword = true
+			info.Privileged = true
+		}
+	}
+
+	if passwords, err := atproto.ServerListAppPasswords(ctx, f.api); err == nil {
+		info.AppPasswords = make([]AppPassword, 0, len(passwords.Passwords))
+		for _, password := range passwords.Passwords {
+			entry := AppPassword{Name: password.Name, Privileged: password.Privileged != nil && *password.Privileged}
+			entry.CreatedAt, _ = time.Parse(time.RFC3339, password.CreatedAt)
+			info.AppPasswords = append(info.AppPasswords, entry)
+		}
+	}
+	return info, nil
+}
+
+// sessionScope reads the scope claim of an access token without verifying it
+func sessionScope(accessJwt string) string {
+	token, _, err := jwt.NewParser().ParseUnverified(accessJwt, jwt.MapClaims{})
+	if token == nil || err != nil {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	scope, _ := claims["scope"].(string)
+	return scope
+}
+
+// CreateAccount registers a new account on the PDS the client points at and logs in as it, so the client is
+// ready to use just as after Login. If the server requires an invite code and account.InviteCode is empty,
+// ErrInviteCodeRequired is returned without contacting the server again.
+//
+// A brand new account has no profile yet, so Self only holds its DID and handle until the profile is
+// created and indexed.
+//
+// Example:
+//
+//	err := client.CreateAccount(ctx, &firefly.NewAccount{
+//	    Handle:     "newbot.pds.example.com",
+//	    Email:      "bot@example.com",
+//	    Password:   os.Getenv("BOT_PASSWORD"),
+//	    InviteCode: "pds-example-com-abcde-fghij",
+//	})
+func (f *Firefly) CreateAccount(ctx context.Context, account *NewAccount) error {
+	info, err := f.DescribeServer(ctx)
+	if err != nil {
+		return err
+	}
+	if info.InviteCodeRequired && account.InviteCode == "" {
+		return ErrInviteCodeRequired
+	}
+
+	out, err := atproto.ServerCreateAccount(ctx, f.api, &atproto.ServerCreateAccount_Input{
+		Email:             optionalString(account.Email),
+		Handle:            account.Handle,
+		InviteCode:        optionalString(account.InviteCode),
+		Password:          optionalString(account.Password),
+		RecoveryKey:       optionalString(account.RecoveryKey),
+		VerificationCode:  optionalString(account.VerificationCode),
+		VerificationPhone: optionalString(account.VerificationPhone),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedCreate, err)
+	}
+	if err := f.startSession(ctx, out.AccessJwt, out.RefreshJwt, out.Handle, out.Did); err != nil {
+		return err
+	}
+	if f.Self == nil {
+		f.Self = &User{Did: out.Did, Handle: out.Handle}
+	}
+	return nil
+}
+
+// InviteCode is an invite code owned by the authenticated user
+type InviteCode struct {
+	Code      string          `json:"code"`
+	Available int             `json:"available"` // total number of uses the code allows
+	Disabled  bool            `json:"disabled"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Uses      []InviteCodeUse `json:"uses"`
+}
+
+// InviteCodeUse records one account created with an invite code
+type InviteCodeUse struct {
+	UsedBy string    `json:"usedBy"` // DID of the new account
+	UsedAt time.Time `json:"usedAt"`
+}
+
+// Remaining returns how many more accounts can be created with the code
+func (c InviteCode) Remaining() int {
+	if c.Disabled {
+		return 0
+	}
+	return max(c.Available-len(c.Uses), 0)
+}
+
+func (c InviteCode) String() string {
+	return fmt.Sprintf("InviteCode{Code: %s, Remaining: %d, Disabled: %t}", c.Code, c.Remaining(), c.Disabled)
+}
+
+// GetAccountInviteCodes returns the authenticated user's invite codes. Set includeUsed to also return codes
+// that have no uses left, and createAvailable to let the server mint any codes the account has earned but
+// not yet been given.
+//
+// Example:
+//
+//	codes, err := client.GetAccountInviteCodes(ctx, false, true)
+//	for _, code := range codes {
+//	    if code.Remaining() > 0 {
+//	        fmt.Println(code.Code)
+//	    }
+//	}
+func (f *Firefly) GetAccountInviteCodes(ctx context.Context, includeUsed bool, createAvailable bool) ([]*InviteCode, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	out, err := atproto.ServerGetAccountInviteCodes(ctx, f.api, createAvailable, includeUsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	codes := make([]*InviteCode, 0, len(out.Codes))
+	for _, raw := range out.Codes {
+		code := &InviteCode{
+			Code:      raw.Code,
+			Available: int(raw.Available),
+			Disabled:  raw.Disabled,
+		}
+		code.CreatedAt, _ = time.Parse(time.RFC3339, raw.CreatedAt)
+		for _, use := range raw.Uses {
+			usedAt, _ := time.Parse(time.RFC3339, use.UsedAt)
+			code.Uses = append(code.Uses, InviteCodeUse{UsedBy: use.UsedBy, UsedAt: usedAt})
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// optionalString returns nil for an empty string, for omitempty lexicon fields
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
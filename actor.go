@@ -0,0 +1,113 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bluesky-social/indigo/atproto/syntax"
+)
+
+var (
+	ErrInvalidActor = errors.New("invalid actor")
+)
+
+// Actor identifies an account by handle, DID, or both. ParseActor accepts the forms users paste, so code
+// taking an account from config or chat doesn't need to check which kind it got. Methods that take an actor
+// string, such as GetProfile, Follow and the PostSearch author filters, accept String() or any input
+// ParseActor understands.
+type Actor struct {
+	Handle string `json:"handle,omitempty"` // normalized to lower case; empty if only the DID is known
+	DID    string `json:"did,omitempty"`    // empty until resolved
+}
+
+// ParseActor normalizes a handle ("alice.bsky.social" or "@Alice.bsky.social"), a DID, an AT URI, or a
+// bsky.app profile or post link into an Actor. Nothing is resolved; use ResolveActor for the DID.
+//
+// Example:
+//
+//	actor, err := firefly.ParseActor("https://bsky.app/profile/alice.bsky.social")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	profile, err := client.GetProfile(ctx, actor.String())
+func ParseActor(input string) (Actor, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(input), "@")
+
+	switch {
+	case strings.HasPrefix(value, "at://"):
+		uri, err := syntax.ParseATURI(value)
+		if err != nil {
+			return Actor{}, fmt.Errorf("%w: %w", ErrInvalidActor, err)
+		}
+		value = uri.Authority().String()
+	case strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://"):
+		link, err := url.Parse(value)
+		if err != nil {
+			return Actor{}, fmt.Errorf("%w: %w", ErrInvalidActor, err)
+		}
+		segments := strings.Split(strings.Trim(link.Path, "/"), "/")
+		if len(segments) < 2 || segments[0] != "profile" {
+			return Actor{}, fmt.Errorf("%w: %s is not a profile link", ErrInvalidActor, input)
+		}
+		value, err = url.PathUnescape(segments[1])
+		if err != nil {
+			return Actor{}, fmt.Errorf("%w: %w", ErrInvalidActor, err)
+		}
+	}
+
+	if strings.HasPrefix(value, "did:") {
+		did, err := syntax.ParseDID(value)
+		if err != nil {
+			return Actor{}, fmt.Errorf("%w: %w", ErrInvalidActor, err)
+		}
+		return Actor{DID: did.String()}, nil
+	}
+	handle, err := syntax.ParseHandle(value)
+	if err != nil {
+		return Actor{}, fmt.Errorf("%w: %w", ErrInvalidActor, err)
+	}
+	return Actor{Handle: handle.Normalize().String()}, nil
+}
+
+// String returns the identifier to send to the API: the DID when known, otherwise the handle
+func (a Actor) String() string {
+	if a.DID != "" {
+		return a.DID
+	}
+	return a.Handle
+}
+
+// IsResolved reports whether the DID is known
+func (a Actor) IsResolved() bool {
+	return a.DID != ""
+}
+
+// ResolveActor returns actor with its DID filled in, resolving the handle if needed. Resolutions are
+// cached with the client's other handle lookups.
+func (f *Firefly) ResolveActor(ctx context.Context, actor Actor) (Actor, error) {
+	if actor.IsResolved() {
+		return actor, nil
+	}
+	if actor.Handle == "" {
+		return actor, ErrInvalidActor
+	}
+	did, err := f.ResolveHandleToDID(ctx, actor.Handle)
+	if err != nil {
+		return actor, err
+	}
+	actor.DID = did
+	return actor, nil
+}
+
+// normalizeActor rewrites any input ParseActor understands to a bare handle or DID. Input it can't parse is
+// returned trimmed, so the server can report the problem.
+func normalizeActor(input string) string {
+	actor, err := ParseActor(input)
+	if err != nil {
+		return strings.TrimPrefix(strings.TrimSpace(input), "@")
+	}
+	return actor.String()
+}
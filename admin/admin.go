@@ -0,0 +1,245 @@
+// Package admin wraps the com.atproto.admin endpoints so operators of a self-hosted PDS can manage their
+// instance from Go. Requests are authenticated with the PDS admin password (PDS_ADMIN_PASSWORD in the
+// reference PDS), so the firefly client only needs to point at the PDS; it doesn't need to be logged in.
+//
+// Example:
+//
+//	client, err := firefly.NewCustomInstance(ctx, "https://pds.example.com", nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	pds := admin.New(client, os.Getenv("PDS_ADMIN_PASSWORD"))
+//	codes, err := pds.CreateInviteCodes(ctx, 5, 1)
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/TheAlyxGreen/firefly"
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/lex/util"
+)
+
+var (
+	ErrFailedQuery  = errors.New("failed to query PDS admin API")
+	ErrFailedUpdate = errors.New("failed to update PDS")
+	ErrEmailNotSent = errors.New("email was not sent")
+)
+
+// Invite code sort orders for ListInviteCodes
+const (
+	SortRecent = "recent"
+	SortUsage  = "usage"
+)
+
+// Client sends admin requests to the PDS the firefly client points at
+type Client struct {
+	f   *firefly.Firefly
+	lex util.LexClient
+}
+
+// New creates an admin client for f's PDS, authenticated with the PDS admin password
+func New(f *firefly.Firefly, adminToken string) *Client {
+	return &Client{f: f, lex: f.AdminLexClient(adminToken)}
+}
+
+// LexClient returns the admin-authenticated client, for admin endpoints this package doesn't wrap
+func (c *Client) LexClient() util.LexClient {
+	return c.lex
+}
+
+// CreateInviteCodes creates count invite codes that can each be used uses times. With forAccounts, count
+// codes are created for each of those accounts (handles or DIDs); otherwise they belong to the PDS itself.
+// The new codes are returned in creation order.
+func (c *Client) CreateInviteCodes(ctx context.Context, count int, uses int, forAccounts ...string) ([]string, error) {
+	dids, err := c.resolveAll(ctx, forAccounts)
+	if err != nil {
+		return nil, err
+	}
+	result, err := atproto.ServerCreateInviteCodes(ctx, c.lex, &atproto.ServerCreateInviteCodes_Input{
+		CodeCount:   int64(count),
+		ForAccounts: dids,
+		UseCount:    int64(uses),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedUpdate, err)
+	}
+	var codes []string
+	for _, account := range result.Codes {
+		codes = append(codes, account.Codes...)
+	}
+	return codes, nil
+}
+
+// ListInviteCodes returns one page of the PDS's invite codes, sorted by SortRecent or SortUsage, along with
+// the cursor for the next page
+func (c *Client) ListInviteCodes(ctx context.Context, sort string, cursor string, limit int) ([]*atproto.ServerDefs_InviteCode, string, error) {
+	result, err := atproto.AdminGetInviteCodes(ctx, c.lex, cursor, int64(limit), sort)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedQuery, err)
+	}
+	nextCursor := ""
+	if result.Cursor != nil {
+		nextCursor = *result.Cursor
+	}
+	return result.Codes, nextCursor, nil
+}
+
+// InviteCodesPager pages through the PDS's invite codes
+func (c *Client) InviteCodesPager(sort string) *firefly.Pager[*atproto.ServerDefs_InviteCode] {
+	return firefly.NewPager(func(ctx context.Context, cursor string, limit int) ([]*atproto.ServerDefs_InviteCode, string, error) {
+		return c.ListInviteCodes(ctx, sort, cursor, limit)
+	})
+}
+
+// DisableInviteCodes disables the given codes so they can no longer be used
+func (c *Client) DisableInviteCodes(ctx context.Context, codes ...string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+	if err := atproto.AdminDisableInviteCodes(ctx, c.lex, &atproto.AdminDisableInviteCodes_Input{Codes: codes}); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedUpdate, err)
+	}
+	return nil
+}
+
+// DisableAccountInviteCodes disables every invite code belonging to the given accounts (handles or DIDs)
+func (c *Client) DisableAccountInviteCodes(ctx context.Context, accounts ...string) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+	dids, err := c.resolveAll(ctx, accounts)
+	if err != nil {
+		return err
+	}
+	if err := atproto.AdminDisableInviteCodes(ctx, c.lex, &atproto.AdminDisableInviteCodes_Input{Accounts: dids}); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedUpdate, err)
+	}
+	return nil
+}
+
+// GetAccount returns the admin view of an account, including its email and invite codes
+func (c *Client) GetAccount(ctx context.Context, account string) (*atproto.AdminDefs_AccountView, error) {
+	did, err := c.resolve(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	view, err := atproto.AdminGetAccountInfo(ctx, c.lex, did)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedQuery, err)
+	}
+	return view, nil
+}
+
+// SearchAccounts returns one page of accounts whose email matches email (empty for every account), along
+// with the cursor for the next page
+func (c *Client) SearchAccounts(ctx context.Context, email string, cursor string, limit int) ([]*atproto.AdminDefs_AccountView, string, error) {
+	result, err := atproto.AdminSearchAccounts(ctx, c.lex, cursor, email, int64(limit))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedQuery, err)
+	}
+	nextCursor := ""
+	if result.Cursor != nil {
+		nextCursor = *result.Cursor
+	}
+	return result.Accounts, nextCursor, nil
+}
+
+// AccountsPager pages through the accounts whose email matches email
+func (c *Client) AccountsPager(email string) *firefly.Pager[*atproto.AdminDefs_AccountView] {
+	return firefly.NewPager(func(ctx context.Context, cursor string, limit int) ([]*atproto.AdminDefs_AccountView, string, error) {
+		return c.SearchAccounts(ctx, email, cursor, limit)
+	})
+}
+
+// Email is a message sent to an account's email address with SendEmail
+type Email struct {
+	Recipient string // handle or DID of the account to email
+	Sender    string // DID recorded as the sender, usually the operator's own account
+	Subject   string // optional
+	Content   string
+	Comment   string // optional note for other operators; not included in the email
+}
+
+// SendEmail sends an email to an account through the PDS's mailer. It returns ErrEmailNotSent if the PDS
+// accepted the request but didn't send anything, for example because the account has no email address.
+func (c *Client) SendEmail(ctx context.Context, email *Email) error {
+	recipient, err := c.resolve(ctx, email.Recipient)
+	if err != nil {
+		return err
+	}
+	result, err := atproto.AdminSendEmail(ctx, c.lex, &atproto.AdminSendEmail_Input{
+		Comment:      optional(email.Comment),
+		Content:      email.Content,
+		RecipientDid: recipient,
+		SenderDid:    email.Sender,
+		Subject:      optional(email.Subject),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedUpdate, err)
+	}
+	if !result.Sent {
+		return ErrEmailNotSent
+	}
+	return nil
+}
+
+// UpdateHandle changes an account's handle. The PDS checks that the new handle is valid and available.
+func (c *Client) UpdateHandle(ctx context.Context, account string, handle string) error {
+	did, err := c.resolve(ctx, account)
+	if err != nil {
+		return err
+	}
+	err = atproto.AdminUpdateAccountHandle(ctx, c.lex, &atproto.AdminUpdateAccountHandle_Input{Did: did, Handle: handle})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedUpdate, err)
+	}
+	return nil
+}
+
+// UpdatePassword sets a new password for an account, for example after a recovery request
+func (c *Client) UpdatePassword(ctx context.Context, account string, password string) error {
+	did, err := c.resolve(ctx, account)
+	if err != nil {
+		return err
+	}
+	err = atproto.AdminUpdateAccountPassword(ctx, c.lex, &atproto.AdminUpdateAccountPassword_Input{Did: did, Password: password})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedUpdate, err)
+	}
+	return nil
+}
+
+// resolve turns a handle, DID or profile link into a DID
+func (c *Client) resolve(ctx context.Context, account string) (string, error) {
+	actor, err := firefly.ParseActor(account)
+	if err != nil {
+		return "", err
+	}
+	actor, err = c.f.ResolveActor(ctx, actor)
+	if err != nil {
+		return "", err
+	}
+	return actor.DID, nil
+}
+
+func (c *Client) resolveAll(ctx context.Context, accounts []string) ([]string, error) {
+	dids := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		did, err := c.resolve(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		dids = append(dids, did)
+	}
+	return dids, nil
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,219 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bluesky-social/indigo/lex/util"
+	"github.com/bluesky-social/indigo/xrpc"
+)
+
+// apiClient wraps the XRPC client with Firefly's request handling. It implements util.LexClient, so it can
+// be passed to any generated indigo API function in place of the raw XRPC client.
+type apiClient struct {
+	f          *Firefly
+	proxy      string            // service every request is forwarded to, overriding serviceProxies; empty for the default routing
+	adminToken *string           // PDS admin password sent as Basic auth on admin endpoints; nil for session auth
+	headers    map[string]string // extra headers sent with every request, overriding the client's own
+}
+
+// PublicAppView is Bluesky's public AppView, which answers app.bsky.* queries without authentication. Pass it
+// to WithHost to send reads there instead of through the PDS.
+const PublicAppView = "https://public.api.bsky.app"
+
+// hostKey is the context key for WithHost
+type hostKey struct{}
+
+// WithHost returns a context whose read requests go straight to host, such as PublicAppView or another PDS,
+// instead of the client's server. Requests sent there are anonymous, so they don't count against the
+// session's rate limits, but they also come back without viewer state such as whether you follow an account.
+// Writes and other procedures ignore it and always go to the logged-in PDS.
+//
+// Example:
+//
+//	// Hydrate a large batch of profiles without spending the account's rate limit
+//	users, err := client.GetProfiles(firefly.WithHost(ctx, firefly.PublicAppView), dids)
+func WithHost(ctx context.Context, host string) context.Context {
+	return context.WithValue(ctx, hostKey{}, strings.TrimSuffix(host, "/"))
+}
+
+// hostOverride returns the host set by WithHost, if any, for a request of the given method
+func hostOverride(ctx context.Context, method string) (string, bool) {
+	host, _ := ctx.Value(hostKey{}).(string)
+	return host, host != "" && method == util.Query
+}
+
+// sessionEndpoints manage the session themselves and must never trigger a reactive refresh
+var sessionEndpoints = map[string]bool{
+	"com.atproto.server.createSession":  true,
+	"com.atproto.server.refreshSession": true,
+	"com.atproto.server.deleteSession":  true,
+}
+
+// serviceProxies maps endpoint prefixes to the service the PDS should forward them to
+var serviceProxies = map[string]string{
+	"chat.bsky.": "did:web:api.bsky.chat#bsky_chat",
+}
+
+// withServiceProxy returns a copy of client with the atproto-proxy header set to proxy, or, when proxy is empty,
+// to the service the endpoint belongs to if the PDS proxies it, such as the chat service. A proxy header set by
+// the caller is left alone.
+func withServiceProxy(client *xrpc.Client, endpoint string, proxy string) *xrpc.Client {
+	if proxy == "" {
+		for prefix, service := range serviceProxies {
+			if strings.HasPrefix(endpoint, prefix) {
+				proxy = service
+				break
+			}
+		}
+	}
+	if proxy == "" {
+		return client
+	}
+	if _, ok := client.Headers["atproto-proxy"]; ok {
+		return client
+	}
+	headers := make(map[string]string, len(client.Headers)+1)
+	for key, value := range client.Headers {
+		headers[key] = value
+	}
+	headers["atproto-proxy"] = proxy
+	proxied := *client
+	proxied.Headers = headers
+	return &proxied
+}
+
+// LexDo performs an XRPC request, applying the default request timeout if ctx has no deadline. If the server reports that the access token has expired, the session
+// is refreshed once (shared between all concurrent callers) and the request is retried. Record writes wait
+// for their turn in the write queue, if it is enabled, after their records are checked by WithRecordValidation.
+// Queries go to the host set with WithHost, if any.
+func (c *apiClient) LexDo(ctx context.Context, method string, inputEncoding string, endpoint string, params map[string]any, bodyData any, out any) error {
+	if c.f.validateRecords && c.adminToken == nil {
+		if err := validateWrites(endpoint, bodyData); err != nil {
+			return err
+		}
+	}
+	if c.f.writes != nil && c.adminToken == nil {
+		if cost := writeCost(endpoint, bodyData); cost > 0 {
+			release, err := c.f.writes.acquire(ctx, cost)
+			if err != nil {
+				return err
+			}
+			defer release()
+		}
+	}
+
+	if c.f.requestTimeout > 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.f.requestTimeout)
+			defer cancel()
+		}
+	}
+
+	client := c.f.currentClient()
+	if host, ok := hostOverride(ctx, method); ok && c.adminToken == nil {
+		anonymous := *client
+		anonymous.Host = host
+		anonymous.Auth = nil
+		client = &anonymous
+	}
+	err := c.do(ctx, client, method, inputEncoding, endpoint, params, bodyData, out)
+	if err == nil || !isExpiredTokenError(err) || client.Auth == nil || c.adminToken != nil || sessionEndpoints[endpoint] {
+		return err
+	}
+
+	// Request bodies that were already consumed can only be replayed if they can be rewound
+	if reader, ok := bodyData.(io.Reader); ok {
+		seeker, ok := reader.(io.Seeker)
+		if !ok {
+			return err
+		}
+		if _, seekErr := seeker.Seek(0, io.SeekStart); seekErr != nil {
+			return err
+		}
+	}
+
+	if refreshErr := c.f.syncRefresh(ctx, client.Auth.AccessJwt); refreshErr != nil {
+		return errors.Join(err, refreshErr)
+	}
+	return c.do(ctx, c.f.currentClient(), method, inputEncoding, endpoint, params, bodyData, out)
+}
+
+// do sends a single request, through the circuit breaker if one is configured
+func (c *apiClient) do(ctx context.Context, client *xrpc.Client, method string, inputEncoding string, endpoint string, params map[string]any, bodyData any, out any) error {
+	// Overridden hosts are called directly, so there is no PDS to proxy through
+	if _, ok := hostOverride(ctx, method); !ok || c.adminToken != nil {
+		client = withServiceProxy(client, endpoint, c.proxy)
+	}
+	if len(c.headers) > 0 {
+		headers := make(map[string]string, len(client.Headers)+len(c.headers))
+		for key, value := range client.Headers {
+			headers[key] = value
+		}
+		for key, value := range c.headers {
+			headers[key] = value
+		}
+		withHeaders := *client
+		withHeaders.Headers = headers
+		client = &withHeaders
+	}
+	if c.adminToken != nil {
+		admin := *client
+		admin.AdminToken = c.adminToken
+		client = &admin
+	}
+	if c.f.breaker == nil {
+		return client.LexDo(ctx, method, inputEncoding, endpoint, params, bodyData, out)
+	}
+	return c.f.breaker.do(c.f, client.Host, func() error {
+		return client.LexDo(ctx, method, inputEncoding, endpoint, params, bodyData, out)
+	})
+}
+
+// isExpiredTokenError reports whether an XRPC error means the access token is no longer accepted
+func isExpiredTokenError(err error) bool {
+	var xrpcErr *xrpc.XRPCError
+	if errors.As(err, &xrpcErr) && xrpcErr.ErrStr == "ExpiredToken" {
+		return true
+	}
+	var httpErr *xrpc.Error
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized
+}
+
+// LexClient returns a client for calling any generated indigo API function through Firefly, with the
+// same session handling, headers, and timeouts as Firefly's own methods.
+//
+// Example:
+//
+//	out, err := atproto.ServerGetSession(ctx, client.LexClient())
+func (f *Firefly) LexClient() util.LexClient {
+	return f.api
+}
+
+// ProxiedLexClient is like LexClient, but asks the PDS to forward every request to the given service, written
+// as a DID and service ID such as "did:plc:abc123#atproto_labeler". Use it for services the PDS doesn't route
+// to on its own, like a labeler's Ozone instance.
+//
+// Example:
+//
+//	labeler := client.ProxiedLexClient("did:plc:abc123#atproto_labeler")
+//	out, err := ozone.ModerationGetRepo(ctx, labeler, "did:plc:xyz789")
+func (f *Firefly) ProxiedLexClient(proxy string) util.LexClient {
+	return &apiClient{f: f, proxy: proxy}
+}
+
+// AdminLexClient is like LexClient, but authenticates com.atproto.admin.* and invite code creation requests
+// with the PDS admin password instead of the session. Other requests still use the session, if there is one,
+// so the client doesn't need to be logged in to administer its PDS.
+//
+// Example:
+//
+//	admin := client.AdminLexClient(os.Getenv("PDS_ADMIN_PASSWORD"))
+//	account, err := atproto.AdminGetAccountInfo(ctx, admin, "did:plc:xyz789")
+func (f *Firefly) AdminLexClient(adminToken string) util.LexClient {
+	return &apiClient{f: f, adminToken: &adminToken}
+}
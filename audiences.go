@@ -0,0 +1,159 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+)
+
+// AudienceComparison is the overlap between two accounts' followers, as reported by CompareAudiences
+type AudienceComparison struct {
+	ActorA     string  `json:"actorA"` // DID of the first actor
+	ActorB     string  `json:"actorB"` // DID of the second actor
+	FollowersA int     `json:"followersA"`
+	FollowersB int     `json:"followersB"`
+	Shared     int     `json:"shared"` // accounts following both actors
+	OnlyA      int     `json:"onlyA"`  // accounts following ActorA but not ActorB
+	OnlyB      int     `json:"onlyB"`  // accounts following ActorB but not ActorA
+	Samples    []*User `json:"samples,omitempty"`
+}
+
+func (c AudienceComparison) String() string {
+	return fmt.Sprintf("AudienceComparison{A: %s (%d), B: %s (%d), Shared: %d}", c.ActorA, c.FollowersA, c.ActorB, c.FollowersB, c.Shared)
+}
+
+// Similarity returns the Jaccard index of the two audiences: shared followers divided by all distinct
+// followers, from 0 for no overlap to 1 for identical audiences
+func (c AudienceComparison) Similarity() float64 {
+	total := c.Shared + c.OnlyA + c.OnlyB
+	if total == 0 {
+		return 0
+	}
+	return float64(c.Shared) / float64(total)
+}
+
+// AudienceProgress reports how many followers of each actor CompareAudiences has read so far
+type AudienceProgress struct {
+	FetchedA  int `json:"fetchedA"`
+	FetchedB  int `json:"fetchedB"`
+	ExpectedA int `json:"expectedA"` // follower count from the profile; the final total may differ slightly
+	ExpectedB int `json:"expectedB"`
+}
+
+// AudienceOptions configures CompareAudiences
+type AudienceOptions struct {
+	SampleSize int                     // Shared followers to include in Samples (default 25, negative for none)
+	OnProgress func(*AudienceProgress) // Optional callback invoked after every page
+}
+
+// CompareAudiences reads every follower of actorA and actorB and reports how much the two audiences overlap,
+// with a sample of shared followers. Pass nil for options to use the defaults.
+//
+// Only the DIDs of the smaller audience are held in memory; the larger one is streamed past them, so
+// comparing against an account with millions of followers is slow but doesn't need millions of profiles in
+// memory. Large graphs take one request per 100 followers, so use OnProgress to report progress.
+//
+// Example:
+//
+//	result, err := client.CompareAudiences(ctx, "alice.bsky.social", "bob.bsky.social", &firefly.AudienceOptions{
+//	    OnProgress: func(p *firefly.AudienceProgress) {
+//	        log.Printf("read %d/%d and %d/%d followers", p.FetchedA, p.ExpectedA, p.FetchedB, p.ExpectedB)
+//	    },
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%d shared followers (%.1f%% similar)\n", result.Shared, result.Similarity()*100)
+func (f *Firefly) CompareAudiences(ctx context.Context, actorA string, actorB string, options *AudienceOptions) (*AudienceComparison, error) {
+	if options == nil {
+		options = &AudienceOptions{}
+	}
+	opts := *options
+	if opts.SampleSize == 0 {
+		opts.SampleSize = 25
+	}
+
+	profileA, err := f.GetProfile(ctx, actorA)
+	if err != nil {
+		return nil, err
+	}
+	profileB, err := f.GetProfile(ctx, actorB)
+	if err != nil {
+		return nil, err
+	}
+	result := &AudienceComparison{ActorA: profileA.Did, ActorB: profileB.Did}
+	progress := &AudienceProgress{}
+	if profileA.FollowersCount != nil {
+		progress.ExpectedA = *profileA.FollowersCount
+	}
+	if profileB.FollowersCount != nil {
+		progress.ExpectedB = *profileB.FollowersCount
+	}
+	report := func() {
+		if opts.OnProgress != nil {
+			snapshot := *progress
+			opts.OnProgress(&snapshot)
+		}
+	}
+
+	// Hold the smaller audience in memory and stream the larger one past it
+	small, large := result.ActorA, result.ActorB
+	smallFetched, largeFetched := &progress.FetchedA, &progress.FetchedB
+	if progress.ExpectedB < progress.ExpectedA {
+		small, large = large, small
+		smallFetched, largeFetched = largeFetched, smallFetched
+	}
+
+	known := make(map[string]struct{}, min(progress.ExpectedA, progress.ExpectedB))
+	pager := f.FollowersPager(small)
+	pager.PageSize = 100
+	for !pager.Done() {
+		page, err := pager.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range page {
+			known[user.Did] = struct{}{}
+		}
+		*smallFetched += len(page)
+		report()
+	}
+
+	largeTotal := 0
+	seen := make(map[string]struct{})
+	pager = f.FollowersPager(large)
+	pager.PageSize = 100
+	for !pager.Done() {
+		page, err := pager.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range page {
+			largeTotal++
+			if _, ok := known[user.Did]; !ok {
+				continue
+			}
+			// Only shared followers are remembered, so a follower repeated across pages isn't counted twice
+			if _, ok := seen[user.Did]; ok {
+				largeTotal--
+				continue
+			}
+			seen[user.Did] = struct{}{}
+			result.Shared++
+			if len(result.Samples) < opts.SampleSize {
+				result.Samples = append(result.Samples, user)
+			}
+		}
+		*largeFetched += len(page)
+		report()
+	}
+
+	smallTotal := len(known)
+	if small == result.ActorA {
+		result.FollowersA, result.FollowersB = smallTotal, largeTotal
+	} else {
+		result.FollowersA, result.FollowersB = largeTotal, smallTotal
+	}
+	result.OnlyA = result.FollowersA - result.Shared
+	result.OnlyB = result.FollowersB - result.Shared
+	return result, nil
+}
@@ -0,0 +1,431 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PostSyncEventType identifies whether a synced post was added or deleted
+type PostSyncEventType int
+
+const (
+	PostSyncAdded PostSyncEventType = iota
+	PostSyncDeleted
+)
+
+func (t PostSyncEventType) String() string {
+	switch t {
+	case PostSyncAdded:
+		return "Added"
+	case PostSyncDeleted:
+		return "Deleted"
+	default:
+		return "Unknown"
+	}
+}
+
+// PostSyncSource chooses how SyncAuthorPosts follows an author once their existing posts are stored
+type PostSyncSource int
+
+const (
+	PostSyncFirehose PostSyncSource = iota // watch the firehose for the author's post commits
+	PostSyncPolling                        // poll the author's feed
+)
+
+func (s PostSyncSource) String() string {
+	switch s {
+	case PostSyncFirehose:
+		return "Firehose"
+	case PostSyncPolling:
+		return "Polling"
+	default:
+		return "Unknown"
+	}
+}
+
+// PostSyncEvent reports a post added to or deleted from an author's synced posts
+type PostSyncEvent struct {
+	Type     PostSyncEventType `json:"type"`
+	URI      string            `json:"uri"`
+	Post     *FeedPost         `json:"post,omitempty"` // the post; for deletes, the stored copy
+	Backfill bool              `json:"backfill"`       // found by the first full walk of the author's feed
+	Time     time.Time         `json:"time"`
+}
+
+func (e PostSyncEvent) String() string {
+	return fmt.Sprintf("PostSyncEvent{Type: %s, URI: %s}", e.Type, e.URI)
+}
+
+// PostSyncState records how far SyncAuthorPosts has got with an author, so a restarted sync picks up where
+// the last one stopped
+type PostSyncState struct {
+	DID            string    `json:"did"`
+	Backfilled     bool      `json:"backfilled"`               // the first walk of the author's feed finished
+	FeedCursor     string    `json:"feedCursor,omitempty"`     // where an unfinished walk resumes
+	FirehoseCursor int64     `json:"firehoseCursor,omitempty"` // Unix microseconds of the last firehose event read
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func (s PostSyncState) String() string {
+	return fmt.Sprintf("PostSyncState{DID: %s, Backfilled: %t}", s.DID, s.Backfilled)
+}
+
+// AuthorPostStore keeps the local copy of authors' posts for SyncAuthorPosts. Implementations must be safe
+// for concurrent use.
+type AuthorPostStore interface {
+	// LoadPosts returns every stored post by did
+	LoadPosts(ctx context.Context, did string) ([]*FeedPost, error)
+	PutPost(ctx context.Context, did string, post *FeedPost) error
+	DeletePost(ctx context.Context, did string, uri string) error
+	// LoadSyncState returns the sync state for did, or nil and no error if it was never synced
+	LoadSyncState(ctx context.Context, did string) (*PostSyncState, error)
+	SaveSyncState(ctx context.Context, state *PostSyncState) error
+}
+
+// MemoryAuthorPostStore is an AuthorPostStore that keeps posts in memory, so every run starts with a full
+// backfill
+type MemoryAuthorPostStore struct {
+	mu     sync.Mutex
+	posts  map[string]map[string]*FeedPost
+	states map[string]PostSyncState
+}
+
+// NewMemoryAuthorPostStore creates an empty MemoryAuthorPostStore
+func NewMemoryAuthorPostStore() *MemoryAuthorPostStore {
+	return &MemoryAuthorPostStore{
+		posts:  make(map[string]map[string]*FeedPost),
+		states: make(map[string]PostSyncState),
+	}
+}
+
+func (s *MemoryAuthorPostStore) LoadPosts(ctx context.Context, did string) ([]*FeedPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := make([]*FeedPost, 0, len(s.posts[did]))
+	for _, post := range s.posts[did] {
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
+
+func (s *MemoryAuthorPostStore) PutPost(ctx context.Context, did string, post *FeedPost) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.posts[did] == nil {
+		s.posts[did] = make(map[string]*FeedPost)
+	}
+	s.posts[did][post.URI] = post
+	return nil
+}
+
+func (s *MemoryAuthorPostStore) DeletePost(ctx context.Context, did string, uri string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.posts[did], uri)
+	return nil
+}
+
+func (s *MemoryAuthorPostStore) LoadSyncState(ctx context.Context, did string) (*PostSyncState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.states[did]; ok {
+		return &state, nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryAuthorPostStore) SaveSyncState(ctx context.Context, state *PostSyncState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.DID] = *state
+	return nil
+}
+
+// PostSyncOptions configures SyncAuthorPosts
+type PostSyncOptions struct {
+	Source       PostSyncSource
+	PollInterval time.Duration // Time between feed polls with PostSyncPolling, and between retries of a failed backfill (default 5 minutes)
+	BufferSize   int           // Event channel buffer size (default 100)
+}
+
+// authorSync is the state of one SyncAuthorPosts call
+type authorSync struct {
+	f       *Firefly
+	did     string
+	store   AuthorPostStore
+	options PostSyncOptions
+	events  chan *PostSyncEvent
+
+	state *PostSyncState
+	known map[string]*FeedPost
+}
+
+// SyncAuthorPosts keeps store up to date with actor's posts, including replies, in the background until ctx
+// is cancelled or the client is closed. The first run walks the author's whole feed page by page, saving the
+// cursor as it goes so an interrupted walk resumes; after that, new and deleted posts are picked up from the
+// firehose or by polling the feed. Every change is sent on the returned channel, which must be read and is
+// closed when the sync stops. Pass nil for store to keep posts in memory and nil for options to use the
+// defaults.
+//
+// The firehose source resumes from the last event it read, so nothing is missed across restarts within
+// Jetstream's replay window. Polling only sees the newest page of the feed, so it notices deletions among
+// roughly the author's latest hundred posts.
+//
+// Example:
+//
+//	events, err := client.SyncAuthorPosts(ctx, "alice.bsky.social", store, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for event := range events {
+//	    switch event.Type {
+//	    case firefly.PostSyncAdded:
+//	        mirror(event.Post)
+//	    case firefly.PostSyncDeleted:
+//	        unmirror(event.URI)
+//	    }
+//	}
+func (f *Firefly) SyncAuthorPosts(ctx context.Context, actor string, store AuthorPostStore, options *PostSyncOptions) (chan *PostSyncEvent, error) {
+	if f.isClosed() {
+		return nil, ErrClientClosed
+	}
+	if store == nil {
+		store = NewMemoryAuthorPostStore()
+	}
+	if options == nil {
+		options = &PostSyncOptions{}
+	}
+	opts := *options
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Minute
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+
+	did, err := f.resolveActor(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	state, err := store.LoadSyncState(ctx, did)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sync state: %w", err)
+	}
+	if state == nil {
+		state = &PostSyncState{DID: did}
+	}
+	if state.FirehoseCursor == 0 {
+		// Posts made while the backfill runs are replayed from the firehose afterwards
+		state.FirehoseCursor = time.Now().UnixMicro()
+	}
+	posts, err := store.LoadPosts(ctx, did)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load posts: %w", err)
+	}
+	known := make(map[string]*FeedPost, len(posts))
+	for _, post := range posts {
+		known[post.URI] = post
+	}
+
+	s := &authorSync{
+		f:       f,
+		did:     did,
+		store:   store,
+		options: opts,
+		events:  make(chan *PostSyncEvent, opts.BufferSize),
+		state:   state,
+		known:   known,
+	}
+
+	ctx, cancel := f.bindLifetime(ctx)
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		defer cancel()
+		defer close(s.events)
+
+		for !s.state.Backfilled {
+			err := s.backfill(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				f.emit(SourceScheduler, SeverityError, fmt.Errorf("author sync %s: backfill: %w", did, err))
+				if !sleepUntil(ctx, time.Now().Add(opts.PollInterval)) {
+					return
+				}
+			}
+		}
+
+		if opts.Source == PostSyncPolling {
+			s.poll(ctx)
+		} else {
+			s.follow(ctx)
+		}
+	}()
+	return s.events, nil
+}
+
+// backfill walks the author's feed from the saved cursor, storing every post not already stored
+func (s *authorSync) backfill(ctx context.Context) error {
+	for {
+		items, next, err := s.f.GetAuthorFeed(ctx, s.did, AuthorFeedPostsWithReplies, false, s.state.FeedCursor, 100)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if item.IsRepost() || item.Post == nil || item.Post.Author == nil || item.Post.Author.Did != s.did {
+				continue
+			}
+			if _, ok := s.known[item.Post.URI]; ok {
+				continue
+			}
+			if err := s.add(ctx, item.Post, true); err != nil {
+				return err
+			}
+		}
+		s.state.FeedCursor = next
+		if next == "" || len(items) == 0 {
+			s.state.FeedCursor = ""
+			s.state.Backfilled = true
+		}
+		if err := s.saveState(ctx); err != nil {
+			return err
+		}
+		if s.state.Backfilled {
+			return nil
+		}
+	}
+}
+
+// follow applies the author's post commits from the firehose, resuming from the saved cursor
+func (s *authorSync) follow(ctx context.Context) {
+	cursor := s.state.FirehoseCursor
+	events, err := s.f.StreamEvents(ctx, &FirehoseOptions{
+		Collections: []string{CollectionPost},
+		Authors:     []string{s.did},
+		Cursor:      &cursor,
+	})
+	if err != nil {
+		s.f.emit(SourceScheduler, SeverityError, fmt.Errorf("author sync %s: %w", s.did, err))
+		return
+	}
+	for event := range events {
+		if event.Repo != s.did {
+			continue
+		}
+		var err error
+		switch {
+		case event.Type == EventTypePost && event.Post != nil:
+			if _, ok := s.known[event.Post.URI]; !ok {
+				err = s.add(ctx, event.Post, false)
+			}
+		case event.Type == EventTypeDelete && event.DeleteEvent != nil && event.DeleteEvent.Collection == CollectionPost:
+			err = s.remove(ctx, event.DeleteEvent.URI)
+		default:
+			continue
+		}
+		if err == nil {
+			s.state.FirehoseCursor = event.Timestamp.UnixMicro()
+			err = s.saveState(ctx)
+		}
+		if err != nil && ctx.Err() == nil {
+			s.f.emit(SourceScheduler, SeverityError, fmt.Errorf("author sync %s: %w", s.did, err))
+		}
+	}
+}
+
+// poll reads the newest page of the author's feed every poll interval, adding posts it hasn't stored and
+// removing stored posts that are missing from the page's time span
+func (s *authorSync) poll(ctx context.Context) {
+	ticker := time.NewTicker(s.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.pollOnce(ctx); err != nil && ctx.Err() == nil {
+			s.f.emit(SourceScheduler, SeverityError, fmt.Errorf("author sync %s: %w", s.did, err))
+		}
+	}
+}
+
+func (s *authorSync) pollOnce(ctx context.Context) error {
+	items, next, err := s.f.GetAuthorFeed(ctx, s.did, AuthorFeedPostsWithReplies, false, "", 100)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]struct{}, len(items))
+	var oldest *time.Time
+	for _, item := range items {
+		if item.IsRepost() || item.Post == nil || item.Post.Author == nil || item.Post.Author.Did != s.did {
+			continue
+		}
+		seen[item.Post.URI] = struct{}{}
+		if createdAt := item.Post.CreatedAt; createdAt != nil && (oldest == nil || createdAt.Before(*oldest)) {
+			oldest = createdAt
+		}
+		if _, ok := s.known[item.Post.URI]; !ok {
+			if err := s.add(ctx, item.Post, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	for uri, post := range s.known {
+		if _, ok := seen[uri]; ok || post.CreatedAt == nil {
+			continue
+		}
+		// Without a next page the feed is complete, so anything missing from it is gone
+		if next != "" && (oldest == nil || post.CreatedAt.Before(*oldest)) {
+			continue
+		}
+		if err := s.remove(ctx, uri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add stores a post and reports it
+func (s *authorSync) add(ctx context.Context, post *FeedPost, backfill bool) error {
+	if err := s.store.PutPost(ctx, s.did, post); err != nil {
+		return fmt.Errorf("failed to store %s: %w", post.URI, err)
+	}
+	s.known[post.URI] = post
+	return s.send(ctx, &PostSyncEvent{Type: PostSyncAdded, URI: post.URI, Post: post, Backfill: backfill, Time: time.Now()})
+}
+
+// remove deletes a stored post and reports it; posts that were never stored are ignored
+func (s *authorSync) remove(ctx context.Context, uri string) error {
+	post, ok := s.known[uri]
+	if !ok {
+		return nil
+	}
+	if err := s.store.DeletePost(ctx, s.did, uri); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", uri, err)
+	}
+	delete(s.known, uri)
+	return s.send(ctx, &PostSyncEvent{Type: PostSyncDeleted, URI: uri, Post: post, Time: time.Now()})
+}
+
+func (s *authorSync) send(ctx context.Context, event *PostSyncEvent) error {
+	select {
+	case s.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *authorSync) saveState(ctx context.Context) error {
+	s.state.UpdatedAt = time.Now()
+	if err := s.store.SaveSyncState(ctx, s.state); err != nil {
+		return fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package firefly
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventSource identifies the background subsystem that produced a BackgroundEvent
+type EventSource int
+
+const (
+	SourceUnknown EventSource = iota
+	SourceSessionRefresh
+	SourceFirehose
+	SourceScheduler
+	SourceCircuitBreaker
+	SourceWatchdog
+)
+
+func (s EventSource) String() string {
+	switch s {
+	case SourceSessionRefresh:
+		return "Session Refresh"
+	case SourceFirehose:
+		return "Firehose"
+	case SourceScheduler:
+		return "Scheduler"
+	case SourceCircuitBreaker:
+		return "Circuit Breaker"
+	case SourceWatchdog:
+		return "Watchdog"
+	default:
+		return "Unknown"
+	}
+}
+
+// EventSeverity indicates how serious a BackgroundEvent is
+type EventSeverity int
+
+const (
+	SeverityInfo EventSeverity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s EventSeverity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "Info"
+	case SeverityWarning:
+		return "Warning"
+	case SeverityError:
+		return "Error"
+	default:
+		return "Unknown"
+	}
+}
+
+// BackgroundEvent reports something that happened in a background operation such as session refresh,
+// a firehose connection, or a scheduled job. Warnings are recoverable (e.g. the firehose will reconnect),
+// while errors usually need attention (e.g. the session could not be refreshed).
+type BackgroundEvent struct {
+	Source    EventSource   `json:"source"`
+	Severity  EventSeverity `json:"severity"`
+	Timestamp time.Time     `json:"timestamp"`
+	Err       error         `json:"-"`
+}
+
+func (e BackgroundEvent) String() string {
+	return fmt.Sprintf("BackgroundEvent{Source: %s, Severity: %s, Err: %v}", e.Source, e.Severity, e.Err)
+}
+
+// Unwrap returns the wrapped error so BackgroundEvents work with errors.Is and errors.As
+func (e BackgroundEvent) Unwrap() error {
+	return e.Err
+}
+
+// DroppedEvents returns the number of background events that were discarded because Events was full
+func (f *Firefly) DroppedEvents() uint64 {
+	return f.droppedEvents.Load()
+}
+
+// emit reports a background event to OnEvent (if set) and Events without ever blocking the caller.
+// Events that don't fit in the channel buffer are counted in DroppedEvents. Events emitted after Close
+// are only passed to OnEvent.
+func (f *Firefly) emit(source EventSource, severity EventSeverity, err error) {
+	event := &BackgroundEvent{
+		Source:    source,
+		Severity:  severity,
+		Timestamp: time.Now(),
+		Err:       err,
+	}
+	if f.OnEvent != nil {
+		f.OnEvent(event)
+	}
+	f.eventsMu.RLock()
+	defer f.eventsMu.RUnlock()
+	if f.closed {
+		return
+	}
+	select {
+	case f.Events <- event:
+	default:
+		f.droppedEvents.Add(1)
+	}
+}
@@ -0,0 +1,115 @@
+package firefly
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// BackoffPolicy controls how long to wait between retries of a failing operation. Each retry waits Multiplier
+// times longer than the previous one, starting at Initial and capped at Max, with up to Jitter of the delay
+// added or removed at random so many clients don't retry in lockstep.
+//
+// The client's policy, set with WithBackoffPolicy, is used for firehose reconnects and NotificationRouter
+// polls; webhook sinks build theirs from WebhookSinkOptions. Zero fields use the defaults.
+type BackoffPolicy struct {
+	Initial    time.Duration // Delay before the first retry (default 1 second)
+	Max        time.Duration // Longest delay between retries (default 2 minutes)
+	Multiplier float64       // Growth factor per retry (default 2)
+	Jitter     float64       // Fraction of each delay to randomize, between 0 and 1 (default 0.1)
+	MaxRetries int           // Consecutive retries before giving up; 0 retries forever
+}
+
+// DefaultBackoffPolicy is used when a client has no policy of its own
+var DefaultBackoffPolicy = BackoffPolicy{
+	Initial:    time.Second,
+	Max:        2 * time.Minute,
+	Multiplier: 2,
+	Jitter:     0.1,
+}
+
+// WithBackoffPolicy sets the policy used between firehose reconnects and failed notification polls. Zero
+// fields fall back to DefaultBackoffPolicy.
+//
+// Example:
+//
+//	client, err := firefly.NewDefaultInstance(ctx, firefly.WithBackoffPolicy(firefly.BackoffPolicy{
+//	    Initial:    500 * time.Millisecond,
+//	    Max:        30 * time.Second,
+//	    MaxRetries: 20,
+//	}))
+func WithBackoffPolicy(policy BackoffPolicy) Option {
+	return func(f *Firefly) {
+		f.backoff = policy.withDefaults()
+	}
+}
+
+// withDefaults fills zero fields from DefaultBackoffPolicy and clamps the rest into range
+func (p BackoffPolicy) withDefaults() BackoffPolicy {
+	if p.Initial <= 0 {
+		p.Initial = DefaultBackoffPolicy.Initial
+	}
+	if p.Max <= 0 {
+		p.Max = max(DefaultBackoffPolicy.Max, p.Initial)
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultBackoffPolicy.Multiplier
+	}
+	if p.Jitter <= 0 {
+		p.Jitter = DefaultBackoffPolicy.Jitter
+	}
+	p.Jitter = min(p.Jitter, 1)
+	p.MaxRetries = max(p.MaxRetries, 0)
+	return p
+}
+
+// Delay returns how long to wait before retry number retry, counting from 0
+func (p BackoffPolicy) Delay(retry int) time.Duration {
+	p = p.withDefaults()
+	delay := float64(p.Initial) * math.Pow(p.Multiplier, float64(max(retry, 0)))
+	delay = min(delay, float64(p.Max))
+	delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	return time.Duration(delay)
+}
+
+// Exhausted reports whether retries consecutive retries have used up the policy
+func (p BackoffPolicy) Exhausted(retries int) bool {
+	return p.MaxRetries > 0 && retries >= p.MaxRetries
+}
+
+// backoffPolicy returns the client's policy, or the default if none was set
+func (f *Firefly) backoffPolicy() BackoffPolicy {
+	if f.backoff == (BackoffPolicy{}) {
+		return DefaultBackoffPolicy
+	}
+	return f.backoff
+}
+
+// backoff tracks consecutive failures of one operation under a policy
+type backoff struct {
+	policy  BackoffPolicy
+	retries int
+}
+
+// wait sleeps for the next delay. It returns false without waiting if the policy is exhausted, or early if
+// ctx is cancelled.
+func (b *backoff) wait(ctx context.Context) bool {
+	if b.policy.Exhausted(b.retries) {
+		return false
+	}
+	timer := time.NewTimer(b.policy.Delay(b.retries))
+	defer timer.Stop()
+	b.retries++
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// reset starts the delays over after a success
+func (b *backoff) reset() {
+	b.retries = 0
+}
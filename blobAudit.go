@@ -0,0 +1,120 @@
+package firefly
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+)
+
+// BlobAudit compares the blobs a repository's records reference with the blobs its PDS stores
+type BlobAudit struct {
+	DID        string              `json:"did"`
+	Records    int                 `json:"records"`    // records scanned
+	Referenced map[string][]string `json:"referenced"` // blob CID -> URIs of the records that reference it
+	Stored     []string            `json:"stored"`     // blob CIDs reported by listBlobs
+	Orphaned   []string            `json:"orphaned"`   // stored but not referenced by any record
+	Missing    []string            `json:"missing"`    // referenced by a record but not stored
+}
+
+func (a BlobAudit) String() string {
+	return fmt.Sprintf("BlobAudit{DID: %s, Records: %d, Referenced: %d, Stored: %d, Orphaned: %d, Missing: %d}",
+		a.DID, a.Records, len(a.Referenced), len(a.Stored), len(a.Orphaned), len(a.Missing))
+}
+
+// AuditBlobs downloads an account's repository, collects every blob its records reference, and compares them
+// with the blobs its PDS lists for it. Orphaned blobs are usually left behind by deleted posts or abandoned
+// uploads and are safe to ignore; missing blobs mean images or videos that will fail to load, and that a
+// migration will fail to copy. did is a DID or anything ParseActor accepts.
+//
+// Example:
+//
+//	audit, err := client.AuditBlobs(ctx, "alice.bsky.social")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, blob := range audit.Missing {
+//	    fmt.Println(blob, "referenced by", audit.Referenced[blob])
+//	}
+func (f *Firefly) AuditBlobs(ctx context.Context, did string) (*BlobAudit, error) {
+	did, err := f.resolveActor(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	car, err := f.GetRepo(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	records, err := ReadRepoCAR(bytes.NewReader(car))
+	if err != nil {
+		return nil, err
+	}
+	stored, err := f.ListAllBlobs(ctx, did, "")
+	if err != nil {
+		return nil, err
+	}
+
+	audit := &BlobAudit{
+		DID:        did,
+		Records:    len(records),
+		Referenced: make(map[string][]string),
+		Stored:     stored,
+	}
+	for _, record := range records {
+		var value map[string]any
+		if err := cbor.DecodeInto(record.Data, &value); err != nil {
+			continue
+		}
+		uri := record.URI(did)
+		for _, blob := range blobRefs(value, nil) {
+			if !slices.Contains(audit.Referenced[blob], uri) {
+				audit.Referenced[blob] = append(audit.Referenced[blob], uri)
+			}
+		}
+	}
+
+	storedSet := make(map[string]bool, len(stored))
+	for _, blob := range stored {
+		storedSet[blob] = true
+		if _, ok := audit.Referenced[blob]; !ok {
+			audit.Orphaned = append(audit.Orphaned, blob)
+		}
+	}
+	for blob := range audit.Referenced {
+		if !storedSet[blob] {
+			audit.Missing = append(audit.Missing, blob)
+		}
+	}
+	slices.Sort(audit.Orphaned)
+	slices.Sort(audit.Missing)
+	return audit, nil
+}
+
+// blobRefs appends the CIDs of every blob inside a decoded record. Current blobs are {$type: "blob", ref: CID};
+// older records use {cid: "...", mimeType: "..."}.
+func blobRefs(value any, found []string) []string {
+	switch v := value.(type) {
+	case map[string]any:
+		if v["$type"] == "blob" {
+			if ref, ok := v["ref"].(cid.Cid); ok {
+				return append(found, ref.String())
+			}
+		}
+		if legacy, ok := v["cid"].(string); ok {
+			if _, isBlob := v["mimeType"]; isBlob {
+				return append(found, legacy)
+			}
+		}
+		for _, child := range v {
+			found = blobRefs(child, found)
+		}
+	case []any:
+		for _, child := range v {
+			found = blobRefs(child, found)
+		}
+	}
+	return found
+}
@@ -0,0 +1,227 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	ErrNoPublisher       = errors.New("broker publisher is required")
+	ErrUnsupportedFormat = errors.New("unsupported serialization format")
+)
+
+// SerializationFormat selects how events are encoded for a message broker
+type SerializationFormat int
+
+const (
+	FormatJSON SerializationFormat = iota
+	FormatCBOR
+)
+
+func (sf SerializationFormat) String() string {
+	switch sf {
+	case FormatJSON:
+		return "JSON"
+	case FormatCBOR:
+		return "CBOR"
+	default:
+		return "Unknown"
+	}
+}
+
+// contentType returns the MIME type sent in the content-type header
+func (sf SerializationFormat) contentType() string {
+	if sf == FormatCBOR {
+		return "application/cbor"
+	}
+	return "application/json"
+}
+
+// TopicMode controls how events are spread across topics or subjects
+type TopicMode int
+
+const (
+	TopicSingle       TopicMode = iota // Every event goes to Topic; the type is only in the headers
+	TopicPerEventType                  // Events go to Topic + "." + type name, e.g. "firehose.post"
+)
+
+func (tm TopicMode) String() string {
+	switch tm {
+	case TopicSingle:
+		return "Single Topic"
+	case TopicPerEventType:
+		return "Topic Per Event Type"
+	default:
+		return "Unknown"
+	}
+}
+
+// Header names set on every broker message
+const (
+	HeaderEventType   = "firefly-event-type"
+	HeaderContentType = "content-type"
+	HeaderRepo        = "firefly-repo"
+	HeaderSequence    = "firefly-sequence"
+)
+
+// BrokerMessage is a single encoded event ready to publish
+type BrokerMessage struct {
+	Topic   string            // Kafka topic or NATS subject
+	Key     []byte            // Partition key (Kafka only)
+	Headers map[string]string // Message headers
+	Value   []byte            // Encoded event
+}
+
+// BrokerPublisher sends a message to a broker. Implement it with the client library of your choice;
+// Firefly doesn't depend on any broker client.
+type BrokerPublisher interface {
+	Publish(ctx context.Context, message *BrokerMessage) error
+}
+
+// BrokerPublisherFunc adapts a function to the BrokerPublisher interface
+type BrokerPublisherFunc func(ctx context.Context, message *BrokerMessage) error
+
+func (fn BrokerPublisherFunc) Publish(ctx context.Context, message *BrokerMessage) error {
+	return fn(ctx, message)
+}
+
+// BrokerSinkOptions configures a BrokerSink
+type BrokerSinkOptions struct {
+	Topic  string                    // Topic or subject, or prefix when using TopicPerEventType (default "firehose")
+	Mode   TopicMode                 // How events are assigned to topics
+	Format SerializationFormat       // Encoding for message values (default JSON)
+	Filter func(*FirehoseEvent) bool // Only events for which Filter returns true are published; nil publishes all
+}
+
+// BrokerSink is an EventSink that publishes firehose events to a message broker such as Kafka or NATS JetStream.
+// Create one with NewKafkaSink or NewNATSSink.
+//
+// Delivery guarantees: Write returns only after the publisher returns, so a publisher that waits for broker
+// acknowledgement gives at-least-once delivery for every event the firehose delivered. Jetstream itself may
+// replay events after a reconnect with a cursor, so consumers should de-duplicate on repo and sequence
+// (or, with NATS, rely on the Nats-Msg-Id header set by NewNATSSink). Events are published in firehose order,
+// but ordering across partitions is only preserved per repo when the Kafka key is used for partitioning.
+type BrokerSink struct {
+	publisher BrokerPublisher
+	options   BrokerSinkOptions
+	decorate  func(*BrokerMessage, *FirehoseEvent)
+}
+
+func newBrokerSink(publisher BrokerPublisher, options *BrokerSinkOptions, decorate func(*BrokerMessage, *FirehoseEvent)) (*BrokerSink, error) {
+	if publisher == nil {
+		return nil, ErrNoPublisher
+	}
+	var opts BrokerSinkOptions
+	if options != nil {
+		opts = *options
+	}
+	if opts.Topic == "" {
+		opts.Topic = "firehose"
+	}
+	if opts.Format != FormatJSON && opts.Format != FormatCBOR {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, opts.Format)
+	}
+	return &BrokerSink{publisher: publisher, options: opts, decorate: decorate}, nil
+}
+
+// NewKafkaSink creates a BrokerSink for Kafka. Messages are keyed by the event's repo DID, so all events
+// from one account land on the same partition and stay in order.
+//
+// Example (using github.com/segmentio/kafka-go):
+//
+//	writer := &kafka.Writer{Addr: kafka.TCP("localhost:9092"), RequiredAcks: kafka.RequireAll}
+//	sink, _ := firefly.NewKafkaSink(firefly.BrokerPublisherFunc(func(ctx context.Context, m *firefly.BrokerMessage) error {
+//	    msg := kafka.Message{Topic: m.Topic, Key: m.Key, Value: m.Value}
+//	    for k, v := range m.Headers {
+//	        msg.Headers = append(msg.Headers, kafka.Header{Key: k, Value: []byte(v)})
+//	    }
+//	    return writer.WriteMessages(ctx, msg)
+//	}), &firefly.BrokerSinkOptions{Mode: firefly.TopicPerEventType})
+//	events, _ := client.StreamEvents(ctx, &firefly.FirehoseOptions{Sinks: []firefly.EventSink{sink}})
+func NewKafkaSink(publisher BrokerPublisher, options *BrokerSinkOptions) (*BrokerSink, error) {
+	return newBrokerSink(publisher, options, func(message *BrokerMessage, event *FirehoseEvent) {
+		message.Key = []byte(event.Repo)
+	})
+}
+
+// NewNATSSink creates a BrokerSink for NATS JetStream. Each message carries a Nats-Msg-Id header built from
+// the event's repo, sequence and record, so JetStream's duplicate window discards replays after a reconnect.
+//
+// Example (using github.com/nats-io/nats.go/jetstream):
+//
+//	js, _ := jetstream.New(nc)
+//	sink, _ := firefly.NewNATSSink(firefly.BrokerPublisherFunc(func(ctx context.Context, m *firefly.BrokerMessage) error {
+//	    msg := nats.NewMsg(m.Topic)
+//	    msg.Data = m.Value
+//	    for k, v := range m.Headers {
+//	        msg.Header.Set(k, v)
+//	    }
+//	    _, err := js.PublishMsg(ctx, msg)
+//	    return err
+//	}), &firefly.BrokerSinkOptions{Topic: "bsky", Mode: firefly.TopicPerEventType})
+func NewNATSSink(publisher BrokerPublisher, options *BrokerSinkOptions) (*BrokerSink, error) {
+	return newBrokerSink(publisher, options, func(message *BrokerMessage, event *FirehoseEvent) {
+		id := event.Repo + ":" + strconv.FormatInt(event.Sequence, 10)
+		if _, rkey, _ := eventRecordLocation(event); rkey != "" {
+			id += ":" + rkey
+		}
+		message.Headers["Nats-Msg-Id"] = id
+	})
+}
+
+// Write encodes an event and publishes it, returning once the publisher does
+func (s *BrokerSink) Write(ctx context.Context, event *FirehoseEvent) error {
+	if event == nil || (s.options.Filter != nil && !s.options.Filter(event)) {
+		return nil
+	}
+	message, err := s.message(event)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSinkFailed, err)
+	}
+	if err := s.publisher.Publish(ctx, message); err != nil {
+		return fmt.Errorf("%w: publishing to %s: %w", ErrSinkFailed, message.Topic, err)
+	}
+	return nil
+}
+
+// message builds the broker message for an event
+func (s *BrokerSink) message(event *FirehoseEvent) (*BrokerMessage, error) {
+	value, err := encodeEvent(event, s.options.Format)
+	if err != nil {
+		return nil, err
+	}
+	typeName := string(firehoseEventTypeNames.text(event.Type))
+	topic := s.options.Topic
+	if s.options.Mode == TopicPerEventType {
+		topic += "." + typeName
+	}
+	message := &BrokerMessage{
+		Topic: topic,
+		Headers: map[string]string{
+			HeaderEventType:   typeName,
+			HeaderContentType: s.options.Format.contentType(),
+			HeaderRepo:        event.Repo,
+			HeaderSequence:    strconv.FormatInt(event.Sequence, 10),
+		},
+		Value: value,
+	}
+	if s.decorate != nil {
+		s.decorate(message, event)
+	}
+	return message, nil
+}
+
+// encodeEvent serializes an event in the requested format
+func encodeEvent(event *FirehoseEvent, format SerializationFormat) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.Marshal(event)
+	case FormatCBOR:
+		return marshalCBOR(event)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
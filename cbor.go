@@ -0,0 +1,591 @@
+package firefly
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+)
+
+var (
+	ErrCBORFailed  = errors.New("CBOR encoding failed")
+	ErrNoRawRecord = errors.New("post has no raw record")
+)
+
+// Firefly's own types are encoded field by field as DAG-CBOR maps keyed by their cborgen or JSON names, so
+// field names stay consistent between the two encodings. Enums and times are written as the same text as in
+// JSON, integers stay integers, and the Raw views of the original API types are left out. Map keys are
+// written in canonical order, which makes the output deterministic.
+
+// MarshalCBOR writes the post as DAG-CBOR
+func (p *FeedPost) MarshalCBOR(w io.Writer) error { return writeCBOR(w, p) }
+
+// UnmarshalCBOR reads a post written by MarshalCBOR
+func (p *FeedPost) UnmarshalCBOR(r io.Reader) error { return readCBOR(r, p) }
+
+// MarshalCBOR writes the user as DAG-CBOR
+func (u *User) MarshalCBOR(w io.Writer) error { return writeCBOR(w, u) }
+
+// UnmarshalCBOR reads a user written by MarshalCBOR
+func (u *User) UnmarshalCBOR(r io.Reader) error { return readCBOR(r, u) }
+
+// MarshalCBOR writes the event as DAG-CBOR
+func (e *FirehoseEvent) MarshalCBOR(w io.Writer) error { return writeCBOR(w, e) }
+
+// UnmarshalCBOR reads an event written by MarshalCBOR
+func (e *FirehoseEvent) UnmarshalCBOR(r io.Reader) error { return readCBOR(r, e) }
+
+// MarshalCBOR writes the notification as DAG-CBOR
+func (n *Notification) MarshalCBOR(w io.Writer) error { return writeCBOR(w, n) }
+
+// UnmarshalCBOR reads a notification written by MarshalCBOR
+func (n *Notification) UnmarshalCBOR(r io.Reader) error { return readCBOR(r, n) }
+
+// RecordCID computes the CID of an atproto record, such as a *bsky.FeedPost, by encoding it as
+// canonical DAG-CBOR and hashing it with SHA-256 (CIDv1, dag-cbor codec).
+func RecordCID(record cbg.CBORMarshaler) (string, error) {
+	var buf bytes.Buffer
+	if err := record.MarshalCBOR(&buf); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrCBORFailed, err)
+	}
+	id, err := cid.NewPrefixV1(cid.DagCBOR, multihash.SHA2_256).Sum(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrCBORFailed, err)
+	}
+	return id.String(), nil
+}
+
+// VerifyCID re-encodes the post's raw record and reports whether it hashes to the post's CID.
+// A mismatch means the record was altered, or that it contains fields this version of the
+// lexicon doesn't know about and so can't be re-encoded exactly.
+func (p *FeedPost) VerifyCID() (bool, error) {
+	if p.Raw == nil {
+		return false, ErrNoRawRecord
+	}
+	computed, err := RecordCID(p.Raw)
+	if err != nil {
+		return false, err
+	}
+	return computed == p.CID, nil
+}
+
+// writeCBOR encodes a value to w as DAG-CBOR
+func writeCBOR(w io.Writer, v any) error {
+	data, err := marshalCBOR(v)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCBORFailed, err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// readCBOR decodes DAG-CBOR written by writeCBOR from r into v, which must be a pointer
+func readCBOR(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	decoder := cborDecoder{data: data}
+	if err := decoder.decode(reflect.ValueOf(v).Elem(), 0); err != nil {
+		return fmt.Errorf("%w: %w", ErrCBORFailed, err)
+	}
+	if decoder.pos != len(data) {
+		return fmt.Errorf("%w: %d bytes of trailing data", ErrCBORFailed, len(data)-decoder.pos)
+	}
+	return nil
+}
+
+// marshalCBOR encodes a value as DAG-CBOR
+func marshalCBOR(v any) ([]byte, error) {
+	var encoder cborEncoder
+	if err := encoder.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return encoder.out, nil
+}
+
+// CBOR simple values and the header of a 64-bit float
+const (
+	cborFalse   = 0xf4
+	cborTrue    = 0xf5
+	cborNull    = 0xf6
+	cborFloat64 = 0xfb
+)
+
+// maxCBORDepth bounds how deeply nested input readCBOR accepts
+const maxCBORDepth = 64
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// cborField is a struct field as it appears in the CBOR map
+type cborField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// cborFieldCache holds the []cborField of each struct type already seen
+var cborFieldCache sync.Map
+
+// cborFields lists the fields of a struct type that are encoded, keyed by their cborgen or json names in
+// canonical order. Fields without a name, like the Raw views, are left out.
+func cborFields(t reflect.Type) []cborField {
+	if cached, ok := cborFieldCache.Load(t); ok {
+		return cached.([]cborField)
+	}
+	var fields []cborField
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("cborgen")
+		if tag == "" {
+			tag = field.Tag.Get("json")
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, cborField{name: name, index: i, omitEmpty: strings.Contains(options, "omitempty")})
+	}
+	slices.SortFunc(fields, func(a, b cborField) int { return compareCBORKeys(a.name, b.name) })
+	cborFieldCache.Store(t, fields)
+	return fields
+}
+
+// compareCBORKeys orders map keys the way DAG-CBOR requires: shorter keys first, then bytewise
+func compareCBORKeys(a, b string) int {
+	if c := cmp.Compare(len(a), len(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// isEmptyCBOR reports whether an omitempty field is left out, using the same rules as encoding/json
+func isEmptyCBOR(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+// cborEncoder writes Go values as DAG-CBOR. Integers stay integers, floats are always 64-bit, and types
+// with a text form, such as enums and times, are written as text strings.
+type cborEncoder struct {
+	out []byte
+}
+
+// header writes a major type and its argument in the shortest form
+func (e *cborEncoder) header(major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		e.out = append(e.out, major|byte(n))
+	case n <= math.MaxUint8:
+		e.out = append(e.out, major|24, byte(n))
+	case n <= math.MaxUint16:
+		e.out = binary.BigEndian.AppendUint16(append(e.out, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		e.out = binary.BigEndian.AppendUint32(append(e.out, major|26), uint32(n))
+	default:
+		e.out = binary.BigEndian.AppendUint64(append(e.out, major|27), n)
+	}
+}
+
+func (e *cborEncoder) text(s string) {
+	e.header(cbg.MajTextString, uint64(len(s)))
+	e.out = append(e.out, s...)
+}
+
+func (e *cborEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.out = append(e.out, cborNull)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.out = append(e.out, cborNull)
+			return nil
+		}
+		return e.encode(v.Elem())
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.text(string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.out = append(e.out, cborTrue)
+		} else {
+			e.out = append(e.out, cborFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int(); n >= 0 {
+			e.header(cbg.MajUnsignedInt, uint64(n))
+		} else {
+			e.header(cbg.MajNegativeInt, uint64(-1-n))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.header(cbg.MajUnsignedInt, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.out = binary.BigEndian.AppendUint64(append(e.out, cborFloat64), math.Float64bits(v.Float()))
+	case reflect.String:
+		e.text(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.out = append(e.out, cborNull)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.header(cbg.MajByteString, uint64(v.Len()))
+			e.out = append(e.out, v.Bytes()...)
+			return nil
+		}
+		e.header(cbg.MajArray, uint64(v.Len()))
+		for i := range v.Len() {
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+		if v.IsNil() {
+			e.out = append(e.out, cborNull)
+			return nil
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return compareCBORKeys(a.String(), b.String()) })
+		e.header(cbg.MajMap, uint64(len(keys)))
+		for _, key := range keys {
+			e.text(key.String())
+			if err := e.encode(v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := cborFields(v.Type())
+		count := 0
+		for _, field := range fields {
+			if !field.omitEmpty || !isEmptyCBOR(v.Field(field.index)) {
+				count++
+			}
+		}
+		e.header(cbg.MajMap, uint64(count))
+		for _, field := range fields {
+			value := v.Field(field.index)
+			if field.omitEmpty && isEmptyCBOR(value) {
+				continue
+			}
+			e.text(field.name)
+			if err := e.encode(value); err != nil {
+				return fmt.Errorf("%s: %w", field.name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// cborDecoder reads DAG-CBOR written by cborEncoder into Go values. Map keys that don't match a field are
+// skipped, so data from newer versions and the Raw fields of older ones still decode.
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// header reads a major type and its argument. For major type 7 the argument is the simple value, or the
+// bits of a float.
+func (d *cborDecoder) header() (byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	first := d.data[d.pos]
+	d.pos++
+	major, low := first>>5, uint64(first&0x1f)
+	size := 0
+	switch {
+	case low < 24:
+		return major, low, nil
+	case low == 24:
+		size = 1
+	case low == 25:
+		size = 2
+	case low == 26:
+		size = 4
+	case low == 27:
+		size = 8
+	default:
+		return 0, 0, fmt.Errorf("unsupported CBOR header 0x%x", first)
+	}
+	if len(d.data)-d.pos < size {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	var n uint64
+	for _, b := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(b)
+	}
+	d.pos += size
+	if major == cbg.MajOther && (size == 2 || size == 4) {
+		return 0, 0, errors.New("DAG-CBOR floats must be 64-bit")
+	}
+	return major, n, nil
+}
+
+// bytes reads the n bytes of a string
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// text reads a text string
+func (d *cborDecoder) text() (string, error) {
+	major, n, err := d.header()
+	if err != nil {
+		return "", err
+	}
+	if major != cbg.MajTextString {
+		return "", fmt.Errorf("expected text string, got major type %d", major)
+	}
+	b, err := d.bytes(n)
+	return string(b), err
+}
+
+// length reads the header of an array or map, checking that the input could hold that many items
+func (d *cborDecoder) length(want byte) (int, error) {
+	major, n, err := d.header()
+	if err != nil {
+		return 0, err
+	}
+	if major != want {
+		return 0, fmt.Errorf("expected major type %d, got %d", want, major)
+	}
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return int(n), nil
+}
+
+func (d *cborDecoder) decode(v reflect.Value, depth int) error {
+	if depth > maxCBORDepth {
+		return errors.New("CBOR nested too deeply")
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == cborNull {
+		d.pos++
+		v.SetZero()
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(v.Elem(), depth)
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		text, err := d.text()
+		if err != nil {
+			return err
+		}
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		major, n, err := d.header()
+		if err != nil {
+			return err
+		}
+		if major != cbg.MajOther || (n != cborFalse&0x1f && n != cborTrue&0x1f) {
+			return fmt.Errorf("expected bool, got major type %d", major)
+		}
+		v.SetBool(n == cborTrue&0x1f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		major, n, err := d.header()
+		if err != nil {
+			return err
+		}
+		var value int64
+		switch {
+		case major == cbg.MajUnsignedInt && n <= math.MaxInt64:
+			value = int64(n)
+		case major == cbg.MajNegativeInt && n <= math.MaxInt64:
+			value = -1 - int64(n)
+		default:
+			return fmt.Errorf("expected %s, got major type %d", v.Type(), major)
+		}
+		if v.OverflowInt(value) {
+			return fmt.Errorf("%d overflows %s", value, v.Type())
+		}
+		v.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		major, n, err := d.header()
+		if err != nil {
+			return err
+		}
+		if major != cbg.MajUnsignedInt {
+			return fmt.Errorf("expected %s, got major type %d", v.Type(), major)
+		}
+		if v.OverflowUint(n) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		major, n, err := d.header()
+		if err != nil {
+			return err
+		}
+		switch major {
+		case cbg.MajOther:
+			v.SetFloat(math.Float64frombits(n))
+		case cbg.MajUnsignedInt:
+			v.SetFloat(float64(n))
+		case cbg.MajNegativeInt:
+			v.SetFloat(-1 - float64(n))
+		default:
+			return fmt.Errorf("expected %s, got major type %d", v.Type(), major)
+		}
+	case reflect.String:
+		text, err := d.text()
+		if err != nil {
+			return err
+		}
+		v.SetString(text)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			major, n, err := d.header()
+			if err != nil {
+				return err
+			}
+			if major != cbg.MajByteString {
+				return fmt.Errorf("expected byte string, got major type %d", major)
+			}
+			b, err := d.bytes(n)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(bytes.Clone(b))
+			return nil
+		}
+		n, err := d.length(cbg.MajArray)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := range n {
+			if err := d.decode(v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+		n, err := d.length(cbg.MajMap)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+		for range n {
+			key, err := d.text()
+			if err != nil {
+				return err
+			}
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(value, depth+1); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), value)
+		}
+	case reflect.Struct:
+		n, err := d.length(cbg.MajMap)
+		if err != nil {
+			return err
+		}
+		fields := cborFields(v.Type())
+		for range n {
+			key, err := d.text()
+			if err != nil {
+				return err
+			}
+			i := slices.IndexFunc(fields, func(field cborField) bool { return field.name == key })
+			if i < 0 {
+				err = d.skip(depth + 1)
+			} else {
+				err = d.decode(v.Field(fields[i].index), depth+1)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// skip reads past one value of any type
+func (d *cborDecoder) skip(depth int) error {
+	if depth > maxCBORDepth {
+		return errors.New("CBOR nested too deeply")
+	}
+	major, n, err := d.header()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case cbg.MajByteString, cbg.MajTextString:
+		_, err = d.bytes(n)
+		return err
+	case cbg.MajArray, cbg.MajMap:
+		if n > uint64(len(d.data)-d.pos) {
+			return io.ErrUnexpectedEOF
+		}
+		items := n
+		if major == cbg.MajMap {
+			items *= 2
+		}
+		for range items {
+			if err := d.skip(depth + 1); err != nil {
+				return err
+			}
+		}
+	case cbg.MajTag:
+		return d.skip(depth + 1)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/api/chat"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+)
+
+var (
+	ErrInvalidChatPolicy = errors.New("invalid chat policy")
+)
+
+// ChatPolicy controls who may start a direct message conversation with an account
+type ChatPolicy string
+
+const (
+	// ChatPolicyAll allows anyone to start a conversation
+	ChatPolicyAll ChatPolicy = "all"
+	// ChatPolicyFollowing allows only accounts the user follows to start a conversation (the default)
+	ChatPolicyFollowing ChatPolicy = "following"
+	// ChatPolicyNone disallows all new conversations
+	ChatPolicyNone ChatPolicy = "none"
+)
+
+// IsValid reports whether the policy is one of the values understood by the chat service
+func (p ChatPolicy) IsValid() bool {
+	return p == ChatPolicyAll || p == ChatPolicyFollowing || p == ChatPolicyNone
+}
+
+// CanMessage reports whether the authenticated user is allowed to start a direct message conversation with
+// actor, based on the target's chat declaration and the relationship between the two accounts.
+// The actor parameter can be either a handle or a DID.
+//
+// Existing conversations may still be usable when this returns false.
+func (f *Firefly) CanMessage(ctx context.Context, actor string) (bool, error) {
+	if f.Self == nil {
+		return false, ErrNotLoggedIn
+	}
+	profile, err := bsky.ActorGetProfile(ctx, f.api, actor)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	if profile.Did == f.Self.Did {
+		return false, nil
+	}
+
+	viewer := profile.Viewer
+	if viewer != nil {
+		if viewer.Blocking != nil || viewer.BlockingByList != nil || (viewer.BlockedBy != nil && *viewer.BlockedBy) {
+			return false, nil
+		}
+	}
+
+	// Accounts without a declaration only accept messages from accounts they follow
+	policy := ChatPolicyFollowing
+	if profile.Associated != nil && profile.Associated.Chat != nil {
+		policy = ChatPolicy(profile.Associated.Chat.AllowIncoming)
+	}
+
+	switch policy {
+	case ChatPolicyAll:
+		return true, nil
+	case ChatPolicyFollowing:
+		return viewer != nil && viewer.FollowedBy != nil, nil
+	default:
+		return false, nil
+	}
+}
+
+// SetIncomingChatPolicy writes the authenticated user's chat.bsky.actor.declaration record, which controls
+// who may start direct message conversations with them.
+func (f *Firefly) SetIncomingChatPolicy(ctx context.Context, policy ChatPolicy) error {
+	if !policy.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidChatPolicy, policy)
+	}
+	if f.Self == nil {
+		return ErrNotLoggedIn
+	}
+
+	_, err := atproto.RepoPutRecord(ctx, f.api, &atproto.RepoPutRecord_Input{
+		Collection: "chat.bsky.actor.declaration",
+		Repo:       f.Self.Did,
+		Rkey:       "self",
+		Record: &lexutil.LexiconTypeDecoder{
+			Val: &chat.ActorDeclaration{
+				LexiconTypeID: "chat.bsky.actor.declaration",
+				AllowIncoming: string(policy),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update chat declaration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bluesky-social/indigo/xrpc"
+)
+
+var (
+	ErrCircuitOpen = errors.New("circuit breaker open")
+)
+
+// CircuitState is the state of the circuit breaker for a single host
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // Requests flow normally
+	CircuitOpen                         // Requests fail fast with ErrCircuitOpen
+	CircuitHalfOpen                     // A single probe request is allowed through
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "Closed"
+	case CircuitOpen:
+		return "Open"
+	case CircuitHalfOpen:
+		return "Half Open"
+	default:
+		return "Unknown"
+	}
+}
+
+// circuitBreaker tracks consecutive failures per host and short-circuits requests to hosts that keep failing
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostCircuit
+}
+
+// hostCircuit is the breaker state for one host
+type hostCircuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// WithCircuitBreaker enables a circuit breaker around API calls. After threshold consecutive failures
+// (network errors, 5xx responses, or rate limiting) to a host, calls to that host fail immediately with
+// ErrCircuitOpen for the cooldown period. A single probe request is then let through; success closes the
+// circuit again, failure re-opens it. State changes are reported on Events.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(f *Firefly) {
+		if threshold <= 0 || cooldown <= 0 {
+			return
+		}
+		f.breaker = &circuitBreaker{
+			threshold: threshold,
+			cooldown:  cooldown,
+			hosts:     make(map[string]*hostCircuit),
+		}
+	}
+}
+
+// CircuitState returns the circuit breaker state for a host, or for the client's own host if host is empty.
+// It always reports CircuitClosed if the circuit breaker is not enabled.
+func (f *Firefly) CircuitState(host string) CircuitState {
+	if f.breaker == nil {
+		return CircuitClosed
+	}
+	if host == "" {
+		host = f.client.Host
+	}
+	f.breaker.mu.Lock()
+	defer f.breaker.mu.Unlock()
+	circuit, ok := f.breaker.hosts[host]
+	if !ok {
+		return CircuitClosed
+	}
+	if circuit.state == CircuitOpen && time.Since(circuit.openedAt) >= f.breaker.cooldown {
+		return CircuitHalfOpen
+	}
+	return circuit.state
+}
+
+// allow reports whether a request to host may proceed, moving an open circuit to half-open once its
+// cooldown has passed
+func (b *circuitBreaker) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	circuit, ok := b.hosts[host]
+	if !ok {
+		return nil
+	}
+	switch circuit.state {
+	case CircuitOpen:
+		if time.Since(circuit.openedAt) < b.cooldown {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+		circuit.state = CircuitHalfOpen
+		circuit.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if circuit.probing {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+		circuit.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record updates the circuit for host with the outcome of a request and returns the new state and
+// whether it changed
+func (b *circuitBreaker) record(host string, err error) (CircuitState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	circuit, ok := b.hosts[host]
+	if !ok {
+		circuit = &hostCircuit{}
+		b.hosts[host] = circuit
+	}
+	previous := circuit.state
+	circuit.probing = false
+
+	if !isCircuitFailure(err) {
+		circuit.failures = 0
+		circuit.state = CircuitClosed
+		return circuit.state, previous != circuit.state
+	}
+
+	circuit.failures++
+	if circuit.state == CircuitHalfOpen || circuit.failures >= b.threshold {
+		circuit.state = CircuitOpen
+		circuit.openedAt = time.Now()
+	}
+	return circuit.state, previous != circuit.state
+}
+
+// do runs a request through the circuit breaker, reporting state changes on Events
+func (b *circuitBreaker) do(f *Firefly, host string, request func() error) error {
+	if err := b.allow(host); err != nil {
+		return err
+	}
+	err := request()
+	state, changed := b.record(host, err)
+	if changed {
+		switch state {
+		case CircuitOpen:
+			f.emit(SourceCircuitBreaker, SeverityWarning, fmt.Errorf("%w: %s: %w", ErrCircuitOpen, host, err))
+		case CircuitClosed:
+			f.emit(SourceCircuitBreaker, SeverityInfo, fmt.Errorf("circuit breaker closed: %s", host))
+		}
+	}
+	return err
+}
+
+// isCircuitFailure reports whether an error indicates the host is unhealthy rather than a bad request
+func isCircuitFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr *xrpc.Error
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/atproto/syntax"
+)
+
+var (
+	ErrFailedDelete = errors.New("failed to delete records")
+)
+
+// maxApplyWrites is the most writes the PDS accepts in a single com.atproto.repo.applyWrites call
+const maxApplyWrites = 200
+
+// CleanupOptions holds the settings for the cleanup functions; set them with CleanupOption functions
+type CleanupOptions struct {
+	DryRun    bool // Find matching records without deleting them
+	BatchSize int  // Deletes per applyWrites call (default and max 200)
+}
+
+// CleanupOption configures UnlikeAll, RemoveAllReposts and DeletePostsMatching
+type CleanupOption func(*CleanupOptions)
+
+// CleanupDryRun reports which records would be deleted without deleting them
+func CleanupDryRun() CleanupOption {
+	return func(o *CleanupOptions) { o.DryRun = true }
+}
+
+// CleanupBatchSize sets how many records are deleted per applyWrites call (1-200)
+func CleanupBatchSize(size int) CleanupOption {
+	return func(o *CleanupOptions) { o.BatchSize = size }
+}
+
+// CleanupResult summarizes a cleanup run
+type CleanupResult struct {
+	DryRun  bool     `json:"dryRun"`
+	Matched []string `json:"matched"` // URIs of every matching record
+	Deleted int      `json:"deleted"` // zero for dry runs
+}
+
+func (r CleanupResult) String() string {
+	return fmt.Sprintf("CleanupResult{Matched: %d, Deleted: %d, DryRun: %t}", len(r.Matched), r.Deleted, r.DryRun)
+}
+
+// UnlikeAll deletes every like the authenticated user created before olderThan.
+// Pass a zero time to remove all likes.
+//
+// Example:
+//
+//	result, err := client.UnlikeAll(ctx, time.Now().AddDate(-1, 0, 0), firefly.CleanupDryRun())
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("would remove %d likes\n", len(result.Matched))
+func (f *Firefly) UnlikeAll(ctx context.Context, olderThan time.Time, options ...CleanupOption) (*CleanupResult, error) {
+	return f.cleanupCollection(ctx, CollectionLike, func(record *atproto.RepoListRecords_Record) bool {
+		like, ok := record.Value.Val.(*bsky.FeedLike)
+		return ok && createdBefore(like.CreatedAt, olderThan)
+	}, options)
+}
+
+// RemoveAllReposts deletes every repost the authenticated user created before olderThan.
+// Pass a zero time to remove all reposts.
+func (f *Firefly) RemoveAllReposts(ctx context.Context, olderThan time.Time, options ...CleanupOption) (*CleanupResult, error) {
+	return f.cleanupCollection(ctx, CollectionRepost, func(record *atproto.RepoListRecords_Record) bool {
+		repost, ok := record.Value.Val.(*bsky.FeedRepost)
+		return ok && createdBefore(repost.CreatedAt, olderThan)
+	}, options)
+}
+
+// DeletePostsMatching deletes every post by the authenticated user for which predicate returns true.
+// Posts are read from the repository rather than the AppView, so URI, CID and record fields are populated
+// but engagement counts and Author are not.
+//
+// Example:
+//
+//	// Remove replies older than six months
+//	cutoff := time.Now().AddDate(0, -6, 0)
+//	result, err := client.DeletePostsMatching(ctx, func(post *firefly.FeedPost) bool {
+//	    return post.CreatedAt != nil && post.CreatedAt.Before(cutoff) && post.ReplyInfo != nil
+//	})
+func (f *Firefly) DeletePostsMatching(ctx context.Context, predicate func(*FeedPost) bool, options ...CleanupOption) (*CleanupResult, error) {
+	return f.cleanupCollection(ctx, CollectionPost, func(record *atproto.RepoListRecords_Record) bool {
+		raw, ok := record.Value.Val.(*bsky.FeedPost)
+		if !ok {
+			return false
+		}
+		post, err := f.OldToNewPost(raw, f.Self.Did)
+		if err != nil {
+			return false
+		}
+		post.URI = record.Uri
+		post.CID = record.Cid
+		return predicate(post)
+	}, options)
+}
+
+// cleanupCollection lists every record in one of the authenticated user's collections and deletes the matches
+// in batches. All matches are found before anything is deleted so the listing isn't disturbed.
+func (f *Firefly) cleanupCollection(ctx context.Context, collection string, match func(*atproto.RepoListRecords_Record) bool, options []CleanupOption) (*CleanupResult, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	opts := CleanupOptions{BatchSize: maxApplyWrites}
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.BatchSize <= 0 || opts.BatchSize > maxApplyWrites {
+		opts.BatchSize = maxApplyWrites
+	}
+
+	result := &CleanupResult{DryRun: opts.DryRun}
+	var rkeys []string
+	cursor := ""
+	for {
+		page, err := atproto.RepoListRecords(ctx, f.api, collection, cursor, 100, f.Self.Did, false)
+		if err != nil {
+			return result, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+		}
+		for _, record := range page.Records {
+			if record == nil || record.Value == nil || !match(record) {
+				continue
+			}
+			uri, err := syntax.ParseATURI(record.Uri)
+			if err != nil {
+				continue
+			}
+			result.Matched = append(result.Matched, record.Uri)
+			rkeys = append(rkeys, uri.RecordKey().String())
+		}
+		if page.Cursor == nil || *page.Cursor == "" || len(page.Records) == 0 {
+			break
+		}
+		cursor = *page.Cursor
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	for start := 0; start < len(rkeys); start += opts.BatchSize {
+		end := min(start+opts.BatchSize, len(rkeys))
+		writes := make([]*atproto.RepoApplyWrites_Input_Writes_Elem, 0, end-start)
+		for _, rkey := range rkeys[start:end] {
+			writes = append(writes, &atproto.RepoApplyWrites_Input_Writes_Elem{
+				RepoApplyWrites_Delete: &atproto.RepoApplyWrites_Delete{
+					Collection: collection,
+					Rkey:       rkey,
+				},
+			})
+		}
+		if _, err := atproto.RepoApplyWrites(ctx, f.api, &atproto.RepoApplyWrites_Input{
+			Repo:   f.Self.Did,
+			Writes: writes,
+		}); err != nil {
+			return result, fmt.Errorf("%w: %w", ErrFailedDelete, err)
+		}
+		result.Deleted += end - start
+	}
+	return result, nil
+}
+
+// createdBefore reports whether an RFC 3339 createdAt timestamp is before cutoff. A zero cutoff matches everything.
+func createdBefore(createdAt string, cutoff time.Time) bool {
+	if cutoff.IsZero() {
+		return true
+	}
+	created, err := syntax.ParseDatetimeLenient(createdAt)
+	if err != nil {
+		return false
+	}
+	return created.Time().Before(cutoff)
+}
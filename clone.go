@@ -0,0 +1,223 @@
+package firefly
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+)
+
+// Equal reports whether two references point at the same record version. Nil references are only equal to each other.
+func (ref *PostRef) Equal(other *PostRef) bool {
+	if ref == nil || other == nil {
+		return ref == other
+	}
+	return ref.URI == other.URI && ref.CID == other.CID
+}
+
+// Equal reports whether two replies share the same parent and root
+func (info *ReplyInfo) Equal(other *ReplyInfo) bool {
+	if info == nil || other == nil {
+		return info == other
+	}
+	return info.ReplyTarget.Equal(other.ReplyTarget) && info.ReplyRoot.Equal(other.ReplyRoot)
+}
+
+// Clone returns a copy of the reference
+func (ref *PostRef) Clone() *PostRef {
+	if ref == nil {
+		return nil
+	}
+	clone := *ref
+	return &clone
+}
+
+// Clone returns a deep copy of the reply info
+func (info *ReplyInfo) Clone() *ReplyInfo {
+	if info == nil {
+		return nil
+	}
+	return &ReplyInfo{
+		ReplyTarget: info.ReplyTarget.Clone(),
+		ReplyRoot:   info.ReplyRoot.Clone(),
+	}
+}
+
+// Clone returns a deep copy of the post that shares no mutable state with the original, so it can be
+// cached or handed to another goroutine. Raw and RawDetailed are duplicated by re-encoding them; if that
+// fails they are left nil rather than shared.
+func (p *FeedPost) Clone() *FeedPost {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	clone.Author = p.Author.Clone()
+	clone.CreatedAt = cloneValue(p.CreatedAt)
+	clone.IndexedAt = cloneValue(p.IndexedAt)
+	clone.Facets = slices.Clone(p.Facets)
+	clone.Tags = slices.Clone(p.Tags)
+	clone.Languages = slices.Clone(p.Languages)
+	clone.ReplyInfo = p.ReplyInfo.Clone()
+	clone.LikeCount = cloneValue(p.LikeCount)
+	clone.QuoteCount = cloneValue(p.QuoteCount)
+	clone.ReplyCount = cloneValue(p.ReplyCount)
+	clone.RepostCount = cloneValue(p.RepostCount)
+	clone.Labels = slices.Clone(p.Labels)
+	clone.Embed = p.Embed.Clone()
+	clone.Viewer = cloneValue(p.Viewer)
+	clone.Raw = cloneRaw(p.Raw)
+	clone.RawDetailed = cloneRaw(p.RawDetailed)
+	return &clone
+}
+
+// Clone returns a deep copy of the embed
+func (e *Embed) Clone() *Embed {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.Images = slices.Clone(e.Images)
+	clone.External = cloneValue(e.External)
+	clone.Record = e.Record.Clone()
+	clone.Video = cloneValue(e.Video)
+	clone.Raw = cloneRaw(e.Raw)
+	return &clone
+}
+
+// Clone returns a deep copy of the user. Raw profile views are duplicated by re-encoding them; if that
+// fails they are left nil rather than shared.
+func (u *User) Clone() *User {
+	if u == nil {
+		return nil
+	}
+	clone := *u
+	clone.Avatar = cloneValue(u.Avatar)
+	clone.Banner = cloneValue(u.Banner)
+	clone.Description = cloneValue(u.Description)
+	clone.DisplayName = cloneValue(u.DisplayName)
+	clone.IndexedAt = cloneValue(u.IndexedAt)
+	clone.FollowersCount = cloneValue(u.FollowersCount)
+	clone.FollowsCount = cloneValue(u.FollowsCount)
+	clone.PinnedPost = u.PinnedPost.Clone()
+	clone.PostsCount = cloneValue(u.PostsCount)
+	clone.Labels = slices.Clone(u.Labels)
+	clone.Viewer = cloneValue(u.Viewer)
+	clone.RawBasic = cloneRaw(u.RawBasic)
+	clone.Raw = cloneRaw(u.Raw)
+	clone.RawDetailed = cloneRaw(u.RawDetailed)
+	return &clone
+}
+
+// Clone returns a deep copy of the draft, including any embed media
+func (d *DraftPost) Clone() *DraftPost {
+	if d == nil {
+		return nil
+	}
+	clone := *d
+	clone.Fragments = make([]PostFragment, len(d.Fragments))
+	for i, fragment := range d.Fragments {
+		fragment.UserDID = cloneValue(fragment.UserDID)
+		fragment.URL = cloneValue(fragment.URL)
+		fragment.Tag = cloneValue(fragment.Tag)
+		clone.Fragments[i] = fragment
+	}
+	clone.Languages = slices.Clone(d.Languages)
+	clone.Labels = slices.Clone(d.Labels)
+	clone.CustomLabels = slices.Clone(d.CustomLabels)
+	clone.ReplyInfo = d.ReplyInfo.Clone()
+	if d.ReplyGate != nil {
+		gate := *d.ReplyGate
+		gate.Lists = slices.Clone(d.ReplyGate.Lists)
+		clone.ReplyGate = &gate
+	}
+	clone.Embed = d.Embed.Clone()
+	return &clone
+}
+
+// Clone returns a deep copy of the builder, including its media bytes
+func (b *EmbedBuilder) Clone() *EmbedBuilder {
+	if b == nil {
+		return nil
+	}
+	clone := *b
+	clone.images = make([]embedMedia, len(b.images))
+	for i, image := range b.images {
+		clone.images[i] = embedMedia{Data: bytes.Clone(image.Data), AltText: image.AltText}
+	}
+	if b.video != nil {
+		clone.video = &embedMedia{Data: bytes.Clone(b.video.Data), AltText: b.video.AltText}
+	}
+	clone.external = cloneValue(b.external)
+	clone.thumb = bytes.Clone(b.thumb)
+	clone.record = b.record.Clone()
+	clone.process = cloneValue(b.process)
+	return &clone
+}
+
+// Clone returns a deep copy of the event that shares no mutable state with the original
+func (e *FirehoseEvent) Clone() *FirehoseEvent {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	clone.Post = e.Post.Clone()
+	clone.User = e.User.Clone()
+	clone.DeleteEvent = cloneValue(e.DeleteEvent)
+	if e.LikeEvent != nil {
+		clone.LikeEvent = &FirehoseLike{Subject: e.LikeEvent.Subject.Clone(), URI: e.LikeEvent.URI}
+	}
+	if e.RepostEvent != nil {
+		clone.RepostEvent = &FirehoseRepost{Subject: e.RepostEvent.Subject.Clone(), URI: e.RepostEvent.URI}
+	}
+	clone.IdentityEvent = cloneValue(e.IdentityEvent)
+	clone.AccountEvent = cloneValue(e.AccountEvent)
+	clone.Classification = e.Classification.Clone()
+	if e.RawCommit != nil {
+		raw := *e.RawCommit
+		if e.RawCommit.Commit != nil {
+			commit := *e.RawCommit.Commit
+			commit.Record = bytes.Clone(e.RawCommit.Commit.Record)
+			raw.Commit = &commit
+		}
+		raw.Account = cloneRaw(e.RawCommit.Account)
+		raw.Identity = cloneRaw(e.RawCommit.Identity)
+		clone.RawCommit = &raw
+	}
+	return &clone
+}
+
+// Clone returns a deep copy of the classification
+func (c *Classification) Clone() *Classification {
+	if c == nil {
+		return nil
+	}
+	return &Classification{
+		Labels: slices.Clone(c.Labels),
+		Scores: maps.Clone(c.Scores),
+	}
+}
+
+// cloneValue copies the value behind a pointer. It is only a deep copy for types without nested pointers or slices.
+func cloneValue[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	clone := *v
+	return &clone
+}
+
+// cloneRaw duplicates an API type by encoding and decoding it, returning nil if the round trip fails
+func cloneRaw[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	clone := new(T)
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil
+	}
+	return clone
+}
@@ -0,0 +1,150 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+
+	"github.com/bluesky-social/indigo/atproto/syntax"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+)
+
+// CollectionRecord is one record listed by ListCollection
+type CollectionRecord struct {
+	URI  string `json:"uri"`
+	RKey string `json:"rkey"`
+	CID  string `json:"cid"`
+
+	// Value is the decoded record: the indigo type for lexicons it knows, such as *bsky.FeedPost or
+	// *bsky.GraphFollow, and map[string]any for records from other apps
+	Value any             `json:"value"`
+	Raw   json.RawMessage `json:"-"` // the record as the server sent it
+}
+
+func (r CollectionRecord) String() string {
+	return fmt.Sprintf("CollectionRecord{URI: %s, CID: %s}", r.URI, r.CID)
+}
+
+// Decode unmarshals the raw record into v, for record types that Value doesn't decode
+func (r *CollectionRecord) Decode(v any) error {
+	return json.Unmarshal(r.Raw, v)
+}
+
+// ListRecordsQuery holds the settings for ListCollection; set them with ListRecordsOption functions
+type ListRecordsQuery struct {
+	Reverse  bool   // List oldest records first instead of newest first
+	PageSize int    // Records requested per page (1-100, default 100)
+	Cursor   string // Resume after a record key returned by an earlier listing
+}
+
+// ListRecordsOption configures a ListCollection call
+type ListRecordsOption func(*ListRecordsQuery)
+
+// ListRecordsReverse lists the oldest records first
+func ListRecordsReverse() ListRecordsOption {
+	return func(q *ListRecordsQuery) { q.Reverse = true }
+}
+
+// ListRecordsPageSize sets how many records are fetched per request (1-100)
+func ListRecordsPageSize(size int) ListRecordsOption {
+	return func(q *ListRecordsQuery) { q.PageSize = size }
+}
+
+// ListRecordsCursor resumes a listing after the given record key
+func ListRecordsCursor(cursor string) ListRecordsOption {
+	return func(q *ListRecordsQuery) { q.Cursor = cursor }
+}
+
+// listRecordsOutput is com.atproto.repo.listRecords with the records left undecoded, so collections indigo
+// doesn't know about don't fail the whole page
+type listRecordsOutput struct {
+	Cursor  *string `json:"cursor"`
+	Records []struct {
+		URI   string          `json:"uri"`
+		CID   string          `json:"cid"`
+		Value json.RawMessage `json:"value"`
+	} `json:"records"`
+}
+
+// ListCollection iterates over every record in one collection of a repository, newest first, fetching pages
+// as needed. repo is a DID or anything ParseActor accepts; pass an empty repo for Self. It works for any
+// collection, including ones from other atproto apps. Iteration stops after yielding the first error.
+//
+// Example:
+//
+//	for record, err := range client.ListCollection(ctx, "alice.bsky.social", firefly.CollectionFollow) {
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    if follow, ok := record.Value.(*bsky.GraphFollow); ok {
+//	        fmt.Println(record.RKey, "follows", follow.Subject)
+//	    }
+//	}
+func (f *Firefly) ListCollection(ctx context.Context, repo string, collection string, options ...ListRecordsOption) iter.Seq2[*CollectionRecord, error] {
+	query := ListRecordsQuery{PageSize: 100}
+	for _, option := range options {
+		option(&query)
+	}
+	if query.PageSize <= 0 || query.PageSize > 100 {
+		query.PageSize = 100
+	}
+
+	return func(yield func(*CollectionRecord, error) bool) {
+		did := repo
+		if did == "" {
+			if f.Self == nil {
+				yield(nil, ErrNotLoggedIn)
+				return
+			}
+			did = f.Self.Did
+		} else {
+			var err error
+			if did, err = f.resolveActor(ctx, repo); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+
+		cursor := query.Cursor
+		for {
+			params := map[string]any{
+				"repo":       did,
+				"collection": collection,
+				"limit":      query.PageSize,
+				"reverse":    query.Reverse,
+			}
+			if cursor != "" {
+				params["cursor"] = cursor
+			}
+			var page listRecordsOutput
+			if err := f.api.LexDo(ctx, lexutil.Query, "", "com.atproto.repo.listRecords", params, nil, &page); err != nil {
+				yield(nil, fmt.Errorf("%w: %w", ErrFailedFetch, err))
+				return
+			}
+			for _, raw := range page.Records {
+				record := &CollectionRecord{URI: raw.URI, CID: raw.CID, Raw: raw.Value}
+				if uri, err := syntax.ParseATURI(raw.URI); err == nil {
+					record.RKey = uri.RecordKey().String()
+				}
+				if value, err := lexutil.JsonDecodeValue(raw.Value); err == nil {
+					record.Value = value
+				} else {
+					var generic map[string]any
+					if err := json.Unmarshal(raw.Value, &generic); err != nil {
+						yield(nil, fmt.Errorf("%w: %s: %w", ErrBadResponse, raw.URI, err))
+						return
+					}
+					record.Value = generic
+				}
+				if !yield(record, nil) {
+					return
+				}
+			}
+			if page.Cursor == nil || *page.Cursor == "" || *page.Cursor == cursor || len(page.Records) == 0 {
+				return
+			}
+			cursor = *page.Cursor
+		}
+	}
+}
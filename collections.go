@@ -0,0 +1,159 @@
+package firefly
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bluesky-social/indigo/atproto/syntax"
+)
+
+var (
+	ErrInvalidCollection = errors.New("invalid collection")
+)
+
+// Collection NSIDs for the record types Bluesky uses, for FirehoseOptions.Collections and repo operations
+const (
+	CollectionPost                    = "app.bsky.feed.post"
+	CollectionLike                    = "app.bsky.feed.like"
+	CollectionRepost                  = "app.bsky.feed.repost"
+	CollectionThreadgate              = "app.bsky.feed.threadgate"
+	CollectionPostgate                = "app.bsky.feed.postgate"
+	CollectionFeedGenerator           = "app.bsky.feed.generator"
+	CollectionFollow                  = "app.bsky.graph.follow"
+	CollectionBlock                   = "app.bsky.graph.block"
+	CollectionList                    = "app.bsky.graph.list"
+	CollectionListItem                = "app.bsky.graph.listitem"
+	CollectionListBlock               = "app.bsky.graph.listblock"
+	CollectionStarterPack             = "app.bsky.graph.starterpack"
+	CollectionVerification            = "app.bsky.graph.verification"
+	CollectionProfile                 = "app.bsky.actor.profile"
+	CollectionStatus                  = "app.bsky.actor.status"
+	CollectionLabelerService          = "app.bsky.labeler.service"
+	CollectionNotificationDeclaration = "app.bsky.notification.declaration"
+	CollectionChatDeclaration         = "chat.bsky.actor.declaration"
+)
+
+// CollectionAll in FirehoseOptions.Collections or WithDefaultCollections subscribes to every collection,
+// including ones from other atproto apps
+const CollectionAll = "*"
+
+// defaultFirehoseCollections are used when neither FirehoseOptions.Collections nor WithDefaultCollections is set.
+// They cover the main content types so a plain stream isn't flooded with every record type.
+var defaultFirehoseCollections = []string{
+	CollectionPost,
+	CollectionLike,
+	CollectionRepost,
+	CollectionFollow,
+}
+
+// WithDefaultCollections sets the collections a firehose stream subscribes to when FirehoseOptions.Collections is
+// empty, replacing the built-in set of posts, likes, reposts and follows. Pass CollectionAll to receive every
+// collection by default.
+//
+// Example:
+//
+//	client, err := firefly.NewDefaultInstance(ctx,
+//	    firefly.WithDefaultCollections(firefly.CollectionPost, firefly.CollectionBlock))
+func WithDefaultCollections(collections ...string) Option {
+	return func(f *Firefly) {
+		f.defaultCollections = slices.Clone(collections)
+	}
+}
+
+// wantedCollections returns the collection filter to send to Jetstream, or nil to receive everything
+func wantedCollections(collections []string) []string {
+	if slices.Contains(collections, CollectionAll) {
+		return nil
+	}
+	return truncate(collections, maxWantedCollections)
+}
+
+// knownCollections lists the collection constants, in declaration order, for ExpandCollections
+var knownCollections = []string{
+	CollectionPost, CollectionLike, CollectionRepost, CollectionThreadgate, CollectionPostgate,
+	CollectionFeedGenerator, CollectionFollow, CollectionBlock, CollectionList, CollectionListItem,
+	CollectionListBlock, CollectionStarterPack, CollectionVerification, CollectionProfile, CollectionStatus,
+	CollectionLabelerService, CollectionNotificationDeclaration, CollectionChatDeclaration,
+}
+
+// ValidateCollections checks a FirehoseOptions.Collections list. Each entry must be a collection NSID, a
+// prefix wildcard such as "app.bsky.graph.*" that Jetstream expands on the server, or CollectionAll.
+func ValidateCollections(collections []string) error {
+	if len(collections) > maxWantedCollections {
+		return fmt.Errorf("%w: %d collections given, Jetstream accepts at most %d", ErrInvalidCollection, len(collections), maxWantedCollections)
+	}
+	for _, collection := range collections {
+		if collection == CollectionAll {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(collection, ".*"); ok {
+			// A prefix needs at least two segments, like an NSID authority
+			segments := strings.Split(prefix, ".")
+			if len(segments) < 2 || slices.ContainsFunc(segments, func(s string) bool { return !validNSIDSegment(s) }) {
+				return fmt.Errorf("%w: %q", ErrInvalidCollection, collection)
+			}
+			continue
+		}
+		if _, err := syntax.ParseNSID(collection); err != nil {
+			return fmt.Errorf("%w: %q: %w", ErrInvalidCollection, collection, err)
+		}
+	}
+	return nil
+}
+
+// CollectionMatches reports whether collection is selected by pattern, which may be an exact NSID, a prefix
+// wildcard like "app.bsky.graph.*", or CollectionAll
+func CollectionMatches(pattern string, collection string) bool {
+	if pattern == CollectionAll {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(collection, prefix)
+	}
+	return pattern == collection
+}
+
+// ExpandCollections replaces wildcards with the known collections they match, for code that needs the
+// concrete list, like switching on event collections or logging a subscription. Exact NSIDs are kept even
+// if unknown, and duplicates are removed. Collections from other apps can't be listed, so a wildcard that
+// matches none of the constants expands to nothing.
+//
+// Example:
+//
+//	firefly.ExpandCollections("app.bsky.graph.*")
+//	// [app.bsky.graph.follow app.bsky.graph.block app.bsky.graph.list ...]
+func ExpandCollections(patterns ...string) []string {
+	var expanded []string
+	add := func(collection string) {
+		if !slices.Contains(expanded, collection) {
+			expanded = append(expanded, collection)
+		}
+	}
+	for _, pattern := range patterns {
+		if pattern != CollectionAll && !strings.HasSuffix(pattern, "*") {
+			add(pattern)
+			continue
+		}
+		for _, collection := range knownCollections {
+			if CollectionMatches(pattern, collection) {
+				add(collection)
+			}
+		}
+	}
+	return expanded
+}
+
+// validNSIDSegment reports whether s can be one dot-separated part of an NSID
+func validNSIDSegment(s string) bool {
+	if s == "" || len(s) > 63 {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
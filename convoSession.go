@@ -0,0 +1,436 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/chat"
+)
+
+var (
+	ErrNoConvoHandler = errors.New("no handler for conversation step")
+)
+
+// ConvoState is what a ConvoSession remembers about one conversation between messages
+type ConvoState struct {
+	ConvoID   string            `json:"convoId"`
+	Step      string            `json:"step"`           // Where the conversation is in the bot's flow; "" for a new conversation
+	Data      map[string]string `json:"data,omitempty"` // Anything else the handlers want to keep
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+func (s ConvoState) String() string {
+	return fmt.Sprintf("ConvoState{ConvoID: %s, Step: %s}", s.ConvoID, s.Step)
+}
+
+// clone copies a state so a store's copy can't be changed by handlers
+func (s *ConvoState) clone() *ConvoState {
+	copied := *s
+	copied.Data = maps.Clone(s.Data)
+	return &copied
+}
+
+// ConvoStore keeps the state of conversations between messages, and across restarts if it is persistent.
+// Implementations must be safe for concurrent use.
+type ConvoStore interface {
+	// LoadConvo returns the state of a conversation, or nil and no error if it has none
+	LoadConvo(ctx context.Context, convoID string) (*ConvoState, error)
+	SaveConvo(ctx context.Context, state *ConvoState) error
+	DeleteConvo(ctx context.Context, convoID string) error
+}
+
+// MemoryConvoStore is a ConvoStore that keeps state in memory, so conversations start over when the program
+// restarts
+type MemoryConvoStore struct {
+	mu     sync.Mutex
+	states map[string]*ConvoState
+}
+
+// NewMemoryConvoStore creates an empty MemoryConvoStore
+func NewMemoryConvoStore() *MemoryConvoStore {
+	return &MemoryConvoStore{states: make(map[string]*ConvoState)}
+}
+
+func (s *MemoryConvoStore) LoadConvo(ctx context.Context, convoID string) (*ConvoState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.states[convoID]; ok {
+		return state.clone(), nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryConvoStore) SaveConvo(ctx context.Context, state *ConvoState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state.ConvoID] = state.clone()
+	return nil
+}
+
+func (s *MemoryConvoStore) DeleteConvo(ctx context.Context, convoID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, convoID)
+	return nil
+}
+
+// ConvoTurn is one incoming message together with its conversation's state. Handlers change State, usually
+// with Goto, and the session saves it once the handlers succeed.
+type ConvoTurn struct {
+	Message *ChatMessage
+	State   *ConvoState
+
+	session *ConvoSession
+	ended   bool
+}
+
+func (t ConvoTurn) String() string {
+	return fmt.Sprintf("ConvoTurn{ConvoID: %s, Step: %s, Message: %s}", t.State.ConvoID, t.State.Step, t.Message.ID)
+}
+
+// Reply sends a plain text message to the conversation
+func (t *ConvoTurn) Reply(ctx context.Context, text string) (*ChatMessage, error) {
+	return t.session.f.SendMessage(ctx, t.Message.ConvoID, text)
+}
+
+// Goto moves the conversation to step; the next message is handled by that step's handler
+func (t *ConvoTurn) Goto(step string) {
+	t.State.Step = step
+}
+
+// End forgets the conversation once the turn succeeds, so the next message starts it over at step ""
+func (t *ConvoTurn) End() {
+	t.ended = true
+}
+
+// ConvoHandler handles one message of a conversation. Returning an error leaves the conversation's state as
+// it was and the message unread, and the poll stops there, so the message is handled again by the next poll
+// before any that came after it.
+type ConvoHandler func(ctx context.Context, turn *ConvoTurn) error
+
+// ConvoMiddleware wraps a handler, to run code before or after it or to stop a message from reaching it
+type ConvoMiddleware func(next ConvoHandler) ConvoHandler
+
+// ConvoSessionOptions configures a ConvoSession
+type ConvoSessionOptions struct {
+	Store        ConvoStore    // Where conversation state is kept (default a new MemoryConvoStore)
+	PollInterval time.Duration // Time between chat log polls (default 5 seconds)
+	LeaveUnread  bool          // Don't mark handled messages as read
+
+	// Cursor resumes reading the chat log where an earlier session stopped (see ConvoSession.Cursor). By
+	// default the session starts at the end of the log, so only messages sent after its first poll are handled.
+	Cursor string
+}
+
+// ConvoSession runs a bot over direct messages as a state machine: each conversation is at a step, every
+// incoming message is passed to that step's handler, and handlers move the conversation to its next step.
+// State is kept in a pluggable ConvoStore, and messages are marked read once they have been handled.
+//
+// Messages are handled one at a time, in the order they were sent. Messages sent by the authenticated user
+// are skipped.
+type ConvoSession struct {
+	f       *Firefly
+	options ConvoSessionOptions
+
+	mu          sync.Mutex
+	cursor      string
+	started     bool
+	steps       map[string]ConvoHandler
+	fallback    ConvoHandler
+	middlewares []ConvoMiddleware
+
+	// turns serializes handling, so a conversation's state is never loaded while a handler is changing it
+	turns sync.Mutex
+}
+
+// NewConvoSession creates a session for the authenticated user's conversations. Pass nil for options to use
+// the defaults.
+//
+// Example:
+//
+//	session := client.NewConvoSession(nil)
+//	session.On("", func(ctx context.Context, turn *firefly.ConvoTurn) error {
+//	    turn.Goto("handle")
+//	    _, err := turn.Reply(ctx, "Hi! What's the handle you'd like verified?")
+//	    return err
+//	})
+//	session.On("handle", func(ctx context.Context, turn *firefly.ConvoTurn) error {
+//	    turn.State.Data["handle"] = strings.TrimSpace(turn.Message.Text)
+//	    turn.End()
+//	    _, err := turn.Reply(ctx, "Thanks, a moderator will be in touch.")
+//	    return err
+//	})
+//	if err := session.Start(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (f *Firefly) NewConvoSession(options *ConvoSessionOptions) *ConvoSession {
+	if options == nil {
+		options = &ConvoSessionOptions{}
+	}
+	opts := *options
+	if opts.Store == nil {
+		opts.Store = NewMemoryConvoStore()
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	return &ConvoSession{
+		f:       f,
+		options: opts,
+		cursor:  opts.Cursor,
+		started: opts.Cursor != "",
+		steps:   make(map[string]ConvoHandler),
+	}
+}
+
+// On registers the handler for messages in conversations at step, replacing any earlier one. Step "" is
+// where new conversations start.
+func (s *ConvoSession) On(step string, handler ConvoHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps[step] = handler
+}
+
+// Otherwise registers the handler for conversations at a step with no handler of its own
+func (s *ConvoSession) Otherwise(handler ConvoHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = handler
+}
+
+// Use adds middleware around every handler. Middleware runs in the order it was added, the first outermost.
+//
+// Example:
+//
+//	session.Use(func(next firefly.ConvoHandler) firefly.ConvoHandler {
+//	    return func(ctx context.Context, turn *firefly.ConvoTurn) error {
+//	        if strings.EqualFold(strings.TrimSpace(turn.Message.Text), "cancel") {
+//	            turn.End()
+//	            _, err := turn.Reply(ctx, "Cancelled.")
+//	            return err
+//	        }
+//	        return next(ctx, turn)
+//	    }
+//	})
+func (s *ConvoSession) Use(middleware ...ConvoMiddleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, middleware...)
+}
+
+// Cursor returns the chat log position the session has read up to. Save it and pass it as
+// ConvoSessionOptions.Cursor to pick up where the session left off.
+func (s *ConvoSession) Cursor() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor
+}
+
+// Handle runs one message through the middleware and its conversation's step handler, then saves the
+// conversation's state and marks the message read. Messages sent by the authenticated user are skipped.
+func (s *ConvoSession) Handle(ctx context.Context, message *ChatMessage) error {
+	if message == nil {
+		return ErrNilMessage
+	}
+	if s.f.Self != nil && message.SenderDID == s.f.Self.Did {
+		return nil
+	}
+
+	s.turns.Lock()
+	defer s.turns.Unlock()
+
+	state, err := s.options.Store.LoadConvo(ctx, message.ConvoID)
+	if err != nil {
+		return fmt.Errorf("conversation %s: failed to load state: %w", message.ConvoID, err)
+	}
+	if state == nil {
+		state = &ConvoState{ConvoID: message.ConvoID}
+	}
+	if state.Data == nil {
+		state.Data = make(map[string]string)
+	}
+	turn := &ConvoTurn{Message: message, State: state, session: s}
+
+	s.mu.Lock()
+	handler := s.handler(state.Step)
+	middlewares := slices.Clone(s.middlewares)
+	s.mu.Unlock()
+	for _, middleware := range slices.Backward(middlewares) {
+		handler = middleware(handler)
+	}
+	if err := handler(ctx, turn); err != nil {
+		return fmt.Errorf("conversation %s: %w", message.ConvoID, err)
+	}
+
+	if turn.ended {
+		err = s.options.Store.DeleteConvo(ctx, message.ConvoID)
+	} else {
+		state.ConvoID = message.ConvoID
+		state.UpdatedAt = time.Now()
+		err = s.options.Store.SaveConvo(ctx, state)
+	}
+	if err != nil {
+		return fmt.Errorf("conversation %s: failed to save state: %w", message.ConvoID, err)
+	}
+
+	if !s.options.LeaveUnread {
+		_, err := chat.ConvoUpdateRead(ctx, s.f.api, &chat.ConvoUpdateRead_Input{
+			ConvoId:   message.ConvoID,
+			MessageId: &message.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("conversation %s: failed to mark read: %w: %w", message.ConvoID, ErrFailedFetch, err)
+		}
+	}
+	return nil
+}
+
+// handler returns the handler for a step, which fails with ErrNoConvoHandler if none is registered.
+// s.mu must be held.
+func (s *ConvoSession) handler(step string) ConvoHandler {
+	if handler, ok := s.steps[step]; ok {
+		return handler
+	}
+	if s.fallback != nil {
+		return s.fallback
+	}
+	return func(ctx context.Context, turn *ConvoTurn) error {
+		return fmt.Errorf("%w %q", ErrNoConvoHandler, step)
+	}
+}
+
+// Poll reads the chat log since the last poll and handles the new messages in order. The first poll of a
+// session without a Cursor only finds the end of the log. If a handler fails, the poll stops and the cursor
+// is left just before that message, so it is the first one handled next time.
+func (s *ConvoSession) Poll(ctx context.Context) error {
+	_, err := s.poll(ctx)
+	return err
+}
+
+// poll is Poll, also reporting whether the log could be read, so Start only backs off when the server is
+// failing rather than a handler
+func (s *ConvoSession) poll(ctx context.Context) (bool, error) {
+	if s.f.Self == nil {
+		return false, ErrNotLoggedIn
+	}
+	s.mu.Lock()
+	cursor, started := s.cursor, s.started
+	s.mu.Unlock()
+
+	var errs []error
+	for {
+		out, err := chat.ConvoGetLog(ctx, s.f.api, cursor)
+		if err != nil {
+			return false, errors.Join(append(errs, fmt.Errorf("%w: %w", ErrFailedFetch, err))...)
+		}
+		if started {
+			handled := cursor // the log position just before the next message to handle
+			for _, log := range out.Logs {
+				created := log.ConvoDefs_LogCreateMessage
+				if created == nil || created.Message == nil || created.Message.ConvoDefs_MessageView == nil {
+					continue
+				}
+				message, err := OldToNewChatMessage(created.ConvoId, created.Message.ConvoDefs_MessageView)
+				if err != nil {
+					// A message that can't be read never will be, so it is skipped
+					errs = append(errs, err)
+				} else if err := s.Handle(ctx, message); err != nil {
+					// Stop before the failed message so the next poll handles it again
+					s.mu.Lock()
+					s.cursor = handled
+					s.mu.Unlock()
+					return true, errors.Join(append(errs, err)...)
+				}
+				handled = created.Rev
+			}
+		}
+
+		next := cursor
+		if out.Cursor != nil {
+			next = *out.Cursor
+		}
+		s.mu.Lock()
+		s.cursor, s.started = next, true
+		s.mu.Unlock()
+		if len(out.Logs) == 0 || next == cursor {
+			break
+		}
+		cursor, started = next, true
+	}
+	return true, errors.Join(errs...)
+}
+
+// Start polls in the background until ctx is cancelled or the client is closed. Errors from polls and
+// handlers are sent to Events. After a failed poll, the next one waits for the poll interval or the client's
+// BackoffPolicy delay, whichever is longer; the session stops if the policy runs out of retries. Handler
+// errors don't count as failed polls.
+func (s *ConvoSession) Start(ctx context.Context) error {
+	if s.f.Self == nil {
+		return ErrNotLoggedIn
+	}
+	if s.f.isClosed() {
+		return ErrClientClosed
+	}
+
+	ctx, cancel := s.f.bindLifetime(ctx)
+	s.f.background.Add(1)
+	go func() {
+		defer s.f.background.Done()
+		defer cancel()
+		if err := s.run(ctx); err != nil {
+			s.f.emit(SourceScheduler, SeverityError, err)
+		}
+	}()
+	return nil
+}
+
+// Run polls like Start, but blocks until the session stops. It returns nil once ctx is cancelled or the client
+// is closed, and an error if the BackoffPolicy runs out of retries, so a Watchdog can restart it.
+func (s *ConvoSession) Run(ctx context.Context) error {
+	if s.f.Self == nil {
+		return ErrNotLoggedIn
+	}
+	if s.f.isClosed() {
+		return ErrClientClosed
+	}
+
+	ctx, cancel := s.f.bindLifetime(ctx)
+	defer cancel()
+	s.f.background.Add(1)
+	defer s.f.background.Done()
+	return s.run(ctx)
+}
+
+// run is the poll loop behind Start and Run
+func (s *ConvoSession) run(ctx context.Context) error {
+	policy := s.f.backoffPolicy()
+	failures := 0
+	for {
+		wait := s.options.PollInterval
+		fetched, err := s.poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.f.emit(SourceScheduler, SeverityError, fmt.Errorf("convo session: %w", err))
+		}
+		if !fetched && ctx.Err() == nil {
+			if policy.Exhausted(failures) {
+				return fmt.Errorf("convo session: giving up after %d failed polls", failures+1)
+			}
+			wait = max(wait, policy.Delay(failures))
+			failures++
+		} else {
+			failures = 0
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
@@ -46,6 +46,44 @@ func (ft FragmentType) String() string {
 	}
 }
 
+// fragmentTypeNames are the stable names used when serializing a FragmentType
+var fragmentTypeNames = enumNames[FragmentType]{
+	FragmentText:    "text",
+	FragmentMention: "mention",
+	FragmentLink:    "link",
+	FragmentHashtag: "hashtag",
+}
+
+// MarshalText encodes the fragment type as its stable name
+func (ft FragmentType) MarshalText() ([]byte, error) {
+	return fragmentTypeNames.text(ft), nil
+}
+
+// UnmarshalText decodes a fragment type from its name or integer value
+func (ft *FragmentType) UnmarshalText(data []byte) error {
+	value, err := fragmentTypeNames.parseText(data, "fragment type")
+	if err != nil {
+		return err
+	}
+	*ft = value
+	return nil
+}
+
+// MarshalJSON encodes the fragment type as a JSON string
+func (ft FragmentType) MarshalJSON() ([]byte, error) {
+	return fragmentTypeNames.json(ft)
+}
+
+// UnmarshalJSON decodes a fragment type from its name, or from the integer value used by older versions
+func (ft *FragmentType) UnmarshalJSON(data []byte) error {
+	value, err := fragmentTypeNames.parseJSON(data, "fragment type")
+	if err != nil {
+		return err
+	}
+	*ft = value
+	return nil
+}
+
 // PostFragment represents a composable piece of a post
 type PostFragment struct {
 	Text string       `json:"text"`
@@ -62,9 +100,16 @@ type DraftPost struct {
 	Fragments []PostFragment `json:"fragments"`
 
 	// Optional post metadata
-	Languages []string   `json:"languages,omitempty"` // Max 3 language codes
-	Labels    []string   `json:"labels,omitempty"`    // Content warning labels
-	ReplyInfo *ReplyInfo `json:"replyInfo,omitempty"` // Reply thread information
+	Languages    []string      `json:"languages,omitempty"`    // Max 3 language codes
+	Labels       []SelfLabel   `json:"labels,omitempty"`       // Content warning labels
+	CustomLabels []string      `json:"customLabels,omitempty"` // Labeler-defined self-labels, not checked against the known set
+	ReplyInfo    *ReplyInfo    `json:"replyInfo,omitempty"`    // Reply thread information
+	ReplyGate    *ReplyGate    `json:"replyGate,omitempty"`    // Who may reply; nil allows everyone
+	Embed        *EmbedBuilder `json:"embed,omitempty"`        // Images, video, link card or quoted record
+
+	// Repo is the DID or handle of the repository to publish to; empty for the authenticated user's. Writing to
+	// another repo needs a session the PDS grants authority over it, such as a service acting for its users.
+	Repo string `json:"repo,omitempty"`
 }
 
 // NewText creates a plain text fragment
@@ -147,19 +192,33 @@ func (d *DraftPost) SetLanguages(languages ...string) *DraftPost {
 	return d
 }
 
-// SetLabels sets content warning labels for the post
-// Common values: "porn", "sexual", "nudity", "graphic-media"
-func (d *DraftPost) SetLabels(labels ...string) *DraftPost {
+// SetLabels sets content warning labels for the post, replacing any set before.
+// Use the Label constants, e.g. LabelNudity or LabelGraphicMedia.
+func (d *DraftPost) SetLabels(labels ...SelfLabel) *DraftPost {
 	d.Labels = labels
 	return d
 }
 
 // AddLabel adds a content warning label to the post
-func (d *DraftPost) AddLabel(label string) *DraftPost {
+func (d *DraftPost) AddLabel(label SelfLabel) *DraftPost {
 	d.Labels = append(d.Labels, label)
 	return d
 }
 
+// AddCustomLabel adds a self-label value that isn't one of the predefined constants, such as one defined
+// by a third-party labeler. Custom values are only checked for length.
+func (d *DraftPost) AddCustomLabel(label string) *DraftPost {
+	d.CustomLabels = append(d.CustomLabels, label)
+	return d
+}
+
+// SetEmbed attaches images, a video, a link card or a quoted record built with an EmbedBuilder.
+// Media is uploaded when the post is published.
+func (d *DraftPost) SetEmbed(embed *EmbedBuilder) *DraftPost {
+	d.Embed = embed
+	return d
+}
+
 // SetReplyInfo sets up a reply to another post
 // For simple replies (replying directly to original post), pass the same PostRef for both parent and root
 // For thread replies, pass the immediate parent and the thread root separately
@@ -225,6 +284,22 @@ func (d *DraftPost) IsValid() error {
 		return ErrPostTooLong
 	}
 
+	if err := validateSelfLabels(d.Labels, d.CustomLabels); err != nil {
+		return err
+	}
+
+	if d.Embed != nil {
+		if err := d.Embed.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if d.ReplyGate != nil {
+		if err := d.ReplyGate.validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -338,18 +413,28 @@ func (f *Firefly) DraftToBskyPost(ctx context.Context, draft *DraftPost) (*bsky.
 		post.Facets = facets
 	}
 
+	// Upload media and attach the embed if specified
+	if draft.Embed != nil {
+		embed, err := f.BuildEmbed(ctx, draft.Embed)
+		if err != nil {
+			return nil, err
+		}
+		post.Embed = embed
+	}
+
 	// Add languages if specified
 	if len(draft.Languages) > 0 {
 		post.Langs = draft.Languages
 	}
 
 	// Add labels (content warnings) if specified
-	if len(draft.Labels) > 0 {
-		selfLabels := make([]*comatprototypes.LabelDefs_SelfLabel, len(draft.Labels))
-		for i, label := range draft.Labels {
-			selfLabels[i] = &comatprototypes.LabelDefs_SelfLabel{
-				Val: label,
-			}
+	if len(draft.Labels) > 0 || len(draft.CustomLabels) > 0 {
+		selfLabels := make([]*comatprototypes.LabelDefs_SelfLabel, 0, len(draft.Labels)+len(draft.CustomLabels))
+		for _, label := range draft.Labels {
+			selfLabels = append(selfLabels, &comatprototypes.LabelDefs_SelfLabel{Val: string(label)})
+		}
+		for _, label := range draft.CustomLabels {
+			selfLabels = append(selfLabels, &comatprototypes.LabelDefs_SelfLabel{Val: label})
 		}
 		post.Labels = &bsky.FeedPost_Labels{
 			LabelDefs_SelfLabels: &comatprototypes.LabelDefs_SelfLabels{
@@ -378,19 +463,81 @@ func (f *Firefly) DraftToBskyPost(ctx context.Context, draft *DraftPost) (*bsky.
 
 // PublishDraftPost publishes a draft post to BlueSky.
 //
+// If the draft has a ReplyGate, the post and its threadgate are written in a single atomic commit.
+// If the draft has a Repo, the post is written there instead of to Self's repository.
+// With WithDuplicateGuard, a post identical to a recent one fails with ErrDuplicatePost.
+//
 // Note: This method performs network requests to resolve user handles to DIDs if mentions
 // are present in the draft (via DraftToBskyPost).
 func (f *Firefly) PublishDraftPost(ctx context.Context, draft *DraftPost) (*PostRef, error) {
+	repo, err := f.draftRepo(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for duplicates before converting, so a refused post doesn't upload its media
+	claim, err := f.duplicates.claim(ctx, repo, draft)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert to BlueSky format with automatic facet generation
 	bskyPost, err := f.DraftToBskyPost(ctx, draft)
 	if err != nil {
+		claim.done(ctx, nil, err)
 		return nil, fmt.Errorf("failed to convert draft post: %w", err)
 	}
 
+	ref, err := f.publishPost(ctx, repo, bskyPost, draft.ReplyGate)
+	claim.done(ctx, ref, err)
+	return ref, err
+}
+
+// PublishThread publishes drafts as a thread, each one a reply to the one before. The first draft may itself
+// be a reply, in which case the thread continues the conversation it belongs to. It returns the posts
+// published so far along with the error if one fails partway through. The drafts themselves aren't changed.
+//
+// Example:
+//
+//	refs, err := client.PublishThread(ctx,
+//	    firefly.NewDraftPost().AddText("A thread about threads 🧵"),
+//	    firefly.NewDraftPost().AddText("Each post replies to the one before."),
+//	)
+func (f *Firefly) PublishThread(ctx context.Context, drafts ...*DraftPost) ([]*PostRef, error) {
+	var refs []*PostRef
+	var root *PostRef
+	for i, draft := range drafts {
+		if draft == nil {
+			return refs, fmt.Errorf("thread post %d of %d: %w", i+1, len(drafts), ErrNilPost)
+		}
+		if i > 0 {
+			// Thread a copy so the caller's draft keeps its own reply info
+			draft = draft.Clone().SetReplyInfo(refs[i-1], root)
+		} else if draft.ReplyInfo != nil && draft.ReplyInfo.ReplyRoot != nil {
+			root = draft.ReplyInfo.ReplyRoot
+		}
+		ref, err := f.PublishDraftPost(ctx, draft)
+		if err != nil {
+			return refs, fmt.Errorf("thread post %d of %d: %w", i+1, len(drafts), err)
+		}
+		if root == nil {
+			root = ref
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// publishPost writes a converted post to repo, with its threadgate if gate is set
+func (f *Firefly) publishPost(ctx context.Context, repo string, bskyPost *bsky.FeedPost, gate *ReplyGate) (*PostRef, error) {
+	if gate != nil {
+		return f.publishGatedPost(ctx, repo, bskyPost, gate)
+	}
+
 	// Create the post using BlueSky's API
-	resp, err := atproto.RepoCreateRecord(ctx, f.client, &atproto.RepoCreateRecord_Input{
-		Collection: "app.bsky.feed.post",
-		Repo:       f.Self.Did, // Use authenticated user's DID
+	resp, err := atproto.RepoCreateRecord(ctx, f.api, &atproto.RepoCreateRecord_Input{
+		Collection: CollectionPost,
+		Repo:       repo,
 		Record: &lexutil.LexiconTypeDecoder{
 			Val: bskyPost,
 		},
@@ -405,3 +552,55 @@ func (f *Firefly) PublishDraftPost(ctx context.Context, draft *DraftPost) (*Post
 		CID: resp.Cid,
 	}, nil
 }
+
+// draftRepo returns the DID of the repository a draft is published to
+func (f *Firefly) draftRepo(ctx context.Context, draft *DraftPost) (string, error) {
+	if draft.Repo != "" {
+		return f.resolveActor(ctx, draft.Repo)
+	}
+	if f.Self == nil {
+		return "", ErrNotLoggedIn
+	}
+	return f.Self.Did, nil
+}
+
+// publishGatedPost creates a post and its threadgate together in repo. Both records must share a record key,
+// so the key is generated locally instead of by the server.
+func (f *Firefly) publishGatedPost(ctx context.Context, repo string, bskyPost *bsky.FeedPost, gate *ReplyGate) (*PostRef, error) {
+	rkey := recordKeyClock.Next().String()
+	postURI := fmt.Sprintf("at://%s/app.bsky.feed.post/%s", repo, rkey)
+
+	resp, err := atproto.RepoApplyWrites(ctx, f.api, &atproto.RepoApplyWrites_Input{
+		Repo: repo,
+		Writes: []*atproto.RepoApplyWrites_Input_Writes_Elem{
+			{
+				RepoApplyWrites_Create: &atproto.RepoApplyWrites_Create{
+					LexiconTypeID: "com.atproto.repo.applyWrites#create",
+					Collection:    CollectionPost,
+					Rkey:          &rkey,
+					Value:         &lexutil.LexiconTypeDecoder{Val: bskyPost},
+				},
+			},
+			{
+				RepoApplyWrites_Create: &atproto.RepoApplyWrites_Create{
+					LexiconTypeID: "com.atproto.repo.applyWrites#create",
+					Collection:    CollectionThreadgate,
+					Rkey:          &rkey,
+					Value:         &lexutil.LexiconTypeDecoder{Val: gate.toThreadgate(postURI)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+	if len(resp.Results) == 0 || resp.Results[0].RepoApplyWrites_CreateResult == nil {
+		return nil, fmt.Errorf("%w: missing create result", ErrBadResponse)
+	}
+
+	created := resp.Results[0].RepoApplyWrites_CreateResult
+	return &PostRef{
+		URI: created.Uri,
+		CID: created.Cid,
+	}, nil
+}
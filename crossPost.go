@@ -0,0 +1,213 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// CrossPoster publishes Bluesky posts somewhere else, such as another network, a blog or an RSS file.
+// A Bridge calls Publish once for every post it forwards.
+type CrossPoster interface {
+	Publish(ctx context.Context, post *FeedPost) error
+}
+
+// CrossPosterFunc adapts a function to the CrossPoster interface
+type CrossPosterFunc func(ctx context.Context, post *FeedPost) error
+
+func (fn CrossPosterFunc) Publish(ctx context.Context, post *FeedPost) error {
+	return fn(ctx, post)
+}
+
+// BridgeSource is where a Bridge finds the posts it forwards. Create one with BridgeFromFeed or
+// BridgeFromFirehose.
+type BridgeSource struct {
+	name  string
+	posts func(ctx context.Context, f *Firefly, pollInterval time.Duration) (<-chan *FeedPost, error)
+}
+
+func (s BridgeSource) String() string {
+	return fmt.Sprintf("BridgeSource{%s}", s.name)
+}
+
+// BridgeFromFeed polls the first page of a feed, such as an AuthorFeedPager, FeedPager or ListFeedPager, and
+// forwards posts created after the bridge starts. Reposts are skipped.
+func BridgeFromFeed(pager *Pager[*FeedItem]) BridgeSource {
+	return BridgeSource{name: "feed", posts: func(ctx context.Context, f *Firefly, pollInterval time.Duration) (<-chan *FeedPost, error) {
+		posts := make(chan *FeedPost, 100)
+		f.background.Add(1)
+		go func() {
+			defer f.background.Done()
+			defer close(posts)
+			pollFeedPosts(ctx, f, pager, pollInterval, posts)
+		}()
+		return posts, nil
+	}}
+}
+
+// pollFeedPosts sends the new posts found on the first page of pager every interval, oldest first
+func pollFeedPosts(ctx context.Context, f *Firefly, pager *Pager[*FeedItem], interval time.Duration, posts chan<- *FeedPost) {
+	since := time.Now()
+	sent := make(map[string]struct{})
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pager.Reset()
+		items, err := pager.Next(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				f.emit(SourceScheduler, SeverityError, fmt.Errorf("bridge: %w", err))
+			}
+			continue
+		}
+		for _, item := range slices.Backward(items) {
+			post := item.Post
+			if item.IsRepost() || post == nil || post.CreatedAt == nil || !post.CreatedAt.After(since) {
+				continue
+			}
+			if _, ok := sent[post.URI]; ok {
+				continue
+			}
+			sent[post.URI] = struct{}{}
+			select {
+			case posts <- post:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// BridgeFromFirehose forwards the new posts on the firehose, filtered by options, such as the posts of a few
+// authors. Collections defaults to posts. Pass nil for options to forward every post on the network.
+func BridgeFromFirehose(options *FirehoseOptions) BridgeSource {
+	filters := FirehoseOptions{}
+	if options != nil {
+		filters = *options
+	}
+	if len(filters.Collections) == 0 {
+		filters.Collections = []string{CollectionPost}
+	}
+	return BridgeSource{name: "firehose", posts: func(ctx context.Context, f *Firefly, pollInterval time.Duration) (<-chan *FeedPost, error) {
+		stream := filters
+		events, err := f.StreamEvents(ctx, &stream)
+		if err != nil {
+			return nil, err
+		}
+		posts := make(chan *FeedPost, 100)
+		f.background.Add(1)
+		go func() {
+			defer f.background.Done()
+			defer close(posts)
+			for event := range events {
+				if event.Type != EventTypePost || event.Post == nil {
+					continue
+				}
+				select {
+				case posts <- event.Post:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return posts, nil
+	}}
+}
+
+// BridgeOptions configures a Bridge
+type BridgeOptions struct {
+	PollInterval time.Duration // Time between polls of a BridgeFromFeed source (default 1 minute)
+
+	// Filter, if set, is asked about every post from the source; only posts it returns true for are forwarded
+	Filter func(post *FeedPost) bool
+}
+
+// Bridge forwards posts from a source to one or more CrossPosters, mirroring Bluesky elsewhere. Posts are
+// forwarded one at a time, in the order the source finds them, to every target in turn.
+type Bridge struct {
+	f       *Firefly
+	source  BridgeSource
+	targets []CrossPoster
+	options BridgeOptions
+}
+
+// NewBridge creates a Bridge from source to targets. Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	mastodon := firefly.CrossPosterFunc(func(ctx context.Context, post *firefly.FeedPost) error {
+//	    return tootClient.Post(ctx, post.Text)
+//	})
+//	bridge := client.NewBridge(
+//	    firefly.BridgeFromFirehose(&firefly.FirehoseOptions{Authors: []string{client.Self.Did}}),
+//	    &firefly.BridgeOptions{Filter: func(post *firefly.FeedPost) bool { return post.ReplyInfo == nil }},
+//	    mastodon,
+//	)
+//	if err := bridge.Start(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (f *Firefly) NewBridge(source BridgeSource, options *BridgeOptions, targets ...CrossPoster) *Bridge {
+	if options == nil {
+		options = &BridgeOptions{}
+	}
+	opts := *options
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Minute
+	}
+	return &Bridge{f: f, source: source, targets: slices.Clone(targets), options: opts}
+}
+
+// Forward publishes one post to every target, skipping it if the filter rejects it. A failing target doesn't
+// stop the others; their errors are joined.
+func (b *Bridge) Forward(ctx context.Context, post *FeedPost) error {
+	if post == nil {
+		return ErrNilPost
+	}
+	if b.options.Filter != nil && !b.options.Filter(post) {
+		return nil
+	}
+	var errs []error
+	for _, target := range b.targets {
+		if err := target.Publish(ctx, post); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", target, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("forwarding %s: %w", post.URI, err)
+	}
+	return nil
+}
+
+// Start forwards posts in the background until ctx is cancelled or the client is closed. Errors from the
+// source and the targets are sent to Events; a post that fails is not retried.
+func (b *Bridge) Start(ctx context.Context) error {
+	if b.f.isClosed() {
+		return ErrClientClosed
+	}
+
+	ctx, cancel := b.f.bindLifetime(ctx)
+	posts, err := b.source.posts(ctx, b.f, b.options.PollInterval)
+	if err != nil {
+		cancel()
+		return err
+	}
+	b.f.background.Add(1)
+	go func() {
+		defer b.f.background.Done()
+		defer cancel()
+		for post := range posts {
+			if err := b.Forward(ctx, post); err != nil && ctx.Err() == nil {
+				b.f.emit(SourceScheduler, SeverityError, fmt.Errorf("bridge: %w", err))
+			}
+		}
+	}()
+	return nil
+}
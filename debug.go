@@ -0,0 +1,245 @@
+package firefly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	debugLogBodyLimit     = 1024    // bytes of each body written to the debug log
+	debugCaptureBodyLimit = 1 << 20 // bytes of each body written to the capture file
+)
+
+// debugSecrets matches JSON fields whose values must never reach a log, like session tokens and passwords
+var debugSecrets = regexp.MustCompile(`"(accessJwt|refreshJwt|password|token|authFactorToken)"(\s*):(\s*)"(?:[^"\\]|\\.)*"`)
+
+// WithDebug writes a line for every HTTP request and firehose frame to w: the method, endpoint and
+// parameters, status, duration, and the start of each JSON body. Session tokens and passwords are redacted.
+// Meant for development; it slows every request down.
+//
+// Example:
+//
+//	client, err := firefly.NewDefaultInstance(ctx, firefly.WithDebug(os.Stderr))
+func WithDebug(w io.Writer) Option {
+	return func(f *Firefly) {
+		f.debugger().log = w
+	}
+}
+
+// WithDebugCapture writes every HTTP exchange and firehose frame to w as JSON Lines, with full bodies
+// (up to 1 MiB each) and the same redaction as WithDebug. Attach the file to a bug report to show exactly
+// what the server sent.
+//
+// Example:
+//
+//	capture, _ := os.Create("firefly-capture.jsonl")
+//	defer capture.Close()
+//	client, err := firefly.NewDefaultInstance(ctx, firefly.WithDebugCapture(capture))
+func WithDebugCapture(w io.Writer) Option {
+	return func(f *Firefly) {
+		f.debugger().capture = w
+	}
+}
+
+// debugger returns the client's debug logger, creating it on first use
+func (f *Firefly) debugger() *debugLogger {
+	if f.debug == nil {
+		f.debug = &debugLogger{}
+	}
+	return f.debug
+}
+
+// debugRecord is one line of a debug capture file
+type debugRecord struct {
+	Time         time.Time         `json:"time"`
+	Kind         string            `json:"kind"` // "http", "firehose-connect" or "firehose-frame"
+	Method       string            `json:"method,omitempty"`
+	URL          string            `json:"url,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	RequestBody  string            `json:"requestBody,omitempty"`
+	Status       int               `json:"status,omitempty"`
+	ResponseBody string            `json:"responseBody,omitempty"`
+	DurationMS   int64             `json:"durationMs,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Frame        string            `json:"frame,omitempty"`
+}
+
+// debugLogger writes sanitized traffic for WithDebug and WithDebugCapture. A nil logger does nothing.
+type debugLogger struct {
+	mu      sync.Mutex
+	log     io.Writer
+	capture io.Writer
+}
+
+// wrap returns a copy of client whose requests are logged
+func (d *debugLogger) wrap(client *http.Client) *http.Client {
+	if client == nil {
+		client = new(http.Client)
+	}
+	wrapped := *client
+	base := wrapped.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped.Transport = &debugTransport{base: base, debug: d}
+	return &wrapped
+}
+
+// firehoseConnect records a new firehose connection
+func (d *debugLogger) firehoseConnect(url string) {
+	if d == nil {
+		return
+	}
+	d.write(&debugRecord{Time: time.Now(), Kind: "firehose-connect", URL: url})
+}
+
+// firehoseFrame records a message read from the firehose
+func (d *debugLogger) firehoseFrame(frame []byte) {
+	if d == nil {
+		return
+	}
+	d.write(&debugRecord{Time: time.Now(), Kind: "firehose-frame", Frame: string(frame)})
+}
+
+// write sends record to the log and the capture file. Write errors are ignored so debugging never breaks requests.
+func (d *debugLogger) write(record *debugRecord) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.log != nil {
+		var line bytes.Buffer
+		fmt.Fprintf(&line, "%s ", record.Time.Format("15:04:05.000"))
+		switch record.Kind {
+		case "http":
+			fmt.Fprintf(&line, "%s %s", record.Method, record.URL)
+			if record.Error != "" {
+				fmt.Fprintf(&line, " -> error: %s", record.Error)
+			} else {
+				fmt.Fprintf(&line, " -> %d", record.Status)
+			}
+			fmt.Fprintf(&line, " (%dms)\n", record.DurationMS)
+			if record.RequestBody != "" {
+				fmt.Fprintf(&line, "  request:  %s\n", truncateDebugBody(record.RequestBody, debugLogBodyLimit))
+			}
+			if record.ResponseBody != "" {
+				fmt.Fprintf(&line, "  response: %s\n", truncateDebugBody(record.ResponseBody, debugLogBodyLimit))
+			}
+		case "firehose-connect":
+			fmt.Fprintf(&line, "firehose connect %s\n", record.URL)
+		default:
+			fmt.Fprintf(&line, "firehose frame: %s\n", truncateDebugBody(record.Frame, debugLogBodyLimit))
+		}
+		d.log.Write(line.Bytes())
+	}
+
+	if d.capture != nil {
+		captured := *record
+		captured.RequestBody = truncateDebugBody(captured.RequestBody, debugCaptureBodyLimit)
+		captured.ResponseBody = truncateDebugBody(captured.ResponseBody, debugCaptureBodyLimit)
+		captured.Frame = truncateDebugBody(captured.Frame, debugCaptureBodyLimit)
+		if line, err := json.Marshal(&captured); err == nil {
+			d.capture.Write(append(line, '\n'))
+		}
+	}
+}
+
+// debugTransport logs each request passing through it
+type debugTransport struct {
+	base  http.RoundTripper
+	debug *debugLogger
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	record := &debugRecord{
+		Time:    start,
+		Kind:    "http",
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: redactHeaders(req.Header),
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if isJSONContent(req.Header.Get("Content-Type")) {
+			body, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			// RoundTrippers must not modify the caller's request
+			req = req.Clone(req.Context())
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			record.RequestBody = redactBody(body)
+		} else {
+			record.RequestBody = fmt.Sprintf("<%d bytes of %s>", req.ContentLength, req.Header.Get("Content-Type"))
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	record.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+		t.debug.write(record)
+		return nil, err
+	}
+	record.Status = resp.StatusCode
+
+	if isJSONContent(resp.Header.Get("Content-Type")) {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			record.Error = err.Error()
+			t.debug.write(record)
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		record.ResponseBody = redactBody(body)
+	} else if resp.ContentLength > 0 {
+		record.ResponseBody = fmt.Sprintf("<%d bytes of %s>", resp.ContentLength, resp.Header.Get("Content-Type"))
+	}
+	t.debug.write(record)
+	return resp, nil
+}
+
+// redactHeaders copies headers, hiding credentials
+func redactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for key, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+		switch http.CanonicalHeaderKey(key) {
+		case "Authorization", "Cookie", "Dpop":
+			redacted[key] = "[redacted]"
+		default:
+			redacted[key] = values[0]
+		}
+	}
+	return redacted
+}
+
+// redactBody returns body as a string with token and password fields hidden
+func redactBody(body []byte) string {
+	return string(debugSecrets.ReplaceAll(body, []byte(`"$1"$2:$3"[redacted]"`)))
+}
+
+// truncateDebugBody shortens body to at most limit bytes
+func truncateDebugBody(body string, limit int) string {
+	if len(body) <= limit {
+		return body
+	}
+	return fmt.Sprintf("%s... (%d bytes total)", body[:limit], len(body))
+}
+
+// isJSONContent reports whether a Content-Type header describes JSON
+func isJSONContent(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
@@ -2,9 +2,14 @@ package firefly
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bluesky-social/indigo/api/atproto"
 	"github.com/bluesky-social/indigo/atproto/syntax"
@@ -14,8 +19,144 @@ var (
 	ErrEmptyUri   = errors.New("empty URI")
 	ErrInvalidUri = errors.New("invalid URI")
 	ErrNoDid      = errors.New("URI uses a handle, not a DID")
+
+	ErrUnverifiedHandle = errors.New("handle does not resolve back to DID")
+	ErrUnsupportedDid   = errors.New("unsupported DID method")
+)
+
+const (
+	identityCacheTTL       = time.Hour
+	bulkResolveConcurrency = 8
+	plcDirectoryURL        = "https://plc.directory"
 )
 
+// DIDDocument is the subset of a DID document that atproto clients need
+type DIDDocument struct {
+	ID          string       `json:"id"`
+	AlsoKnownAs []string     `json:"alsoKnownAs,omitempty"`
+	Service     []DIDService `json:"service,omitempty"`
+}
+
+// DIDService is a service entry in a DID document, such as the account's PDS or a feed generator
+type DIDService struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+// ServiceEndpoint returns the endpoint URL of the service with the given ID (e.g. "atproto_pds" or
+// "bsky_fg", without the leading #), or an empty string if the document doesn't declare it
+func (d *DIDDocument) ServiceEndpoint(id string) string {
+	for _, service := range d.Service {
+		if service.ID == "#"+id || service.ID == d.ID+"#"+id {
+			return service.ServiceEndpoint
+		}
+	}
+	return ""
+}
+
+// Handle returns the handle the document claims, the first at:// entry in alsoKnownAs, or "" if there is
+// none. The claim is only trustworthy once the handle resolves back to the document's DID.
+func (d *DIDDocument) Handle() string {
+	for _, aka := range d.AlsoKnownAs {
+		if handle, ok := strings.CutPrefix(aka, "at://"); ok && handle != "" {
+			return handle
+		}
+	}
+	return ""
+}
+
+// ResolveDIDDocument fetches the DID document for a did:plc (from the PLC directory) or did:web
+// (from the domain's /.well-known/did.json) identifier
+func (f *Firefly) ResolveDIDDocument(ctx context.Context, did string) (*DIDDocument, error) {
+	var docURL string
+	switch {
+	case strings.HasPrefix(did, "did:plc:"):
+		docURL = plcDirectoryURL + "/" + did
+	case strings.HasPrefix(did, "did:web:"):
+		docURL = "https://" + strings.TrimPrefix(did, "did:web:") + "/.well-known/did.json"
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDid, did)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, docURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve DID document: %w", err)
+	}
+	req.Header.Set("Accept", "application/did+ld+json, application/json")
+	httpClient := f.client.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve DID document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to resolve DID document: %s returned %s", docURL, resp.Status)
+	}
+
+	var doc DIDDocument
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadResponse, err)
+	}
+	if doc.ID != did {
+		return nil, fmt.Errorf("%w: DID document is for %s, not %s", ErrBadResponse, doc.ID, did)
+	}
+	return &doc, nil
+}
+
+// identityCache remembers recent handle/DID resolutions in both directions
+type identityCache struct {
+	mu          sync.Mutex
+	handleToDid map[string]cachedIdentity
+	didToHandle map[string]cachedIdentity
+	ttl         time.Duration
+}
+
+// cachedIdentity is a single cached resolution
+type cachedIdentity struct {
+	value   string
+	expires time.Time
+}
+
+func newIdentityCache(ttl time.Duration) *identityCache {
+	return &identityCache{
+		handleToDid: make(map[string]cachedIdentity),
+		didToHandle: make(map[string]cachedIdentity),
+		ttl:         ttl,
+	}
+}
+
+// lookup returns a cached value from the given direction if it hasn't expired
+func (c *identityCache) lookup(table map[string]cachedIdentity, key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := table[key]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.value, true
+}
+
+// store records a verified handle/DID pair in both directions
+func (c *identityCache) store(handle string, did string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := time.Now().Add(c.ttl)
+	c.handleToDid[strings.ToLower(handle)] = cachedIdentity{value: did, expires: expires}
+	c.didToHandle[did] = cachedIdentity{value: handle, expires: expires}
+}
+
+// storeHandle records what a handle resolves to. The DID's document may not claim the handle back, so
+// nothing is cached in the other direction until ResolveDIDToHandle verifies it.
+func (c *identityCache) storeHandle(handle string, did string, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handleToDid[strings.ToLower(handle)] = cachedIdentity{value: did, expires: expires}
+}
+
 // ExtractDidFromUri extracts the DID from an AT URI format: at://did:plc:xyz123/collection/record
 // if URI is like at://an.example.handle/app.bsky.feed.post/, returns handle and ErrNoDid
 func ExtractDidFromUri(uri string) (string, error) {
@@ -51,15 +192,106 @@ func ExtractDidFromUri(uri string) (string, error) {
 	return userID, ErrNoDid
 }
 
-// ResolveHandleToDID resolves a BlueSky handle to its corresponding DID using the XRPC API
+// ResolveHandleToDID resolves a BlueSky handle to its corresponding DID using the XRPC API.
+// Results are cached for an hour, and concurrent lookups of the same handle share one request.
 func (f *Firefly) ResolveHandleToDID(ctx context.Context, handle string) (string, error) {
-	output, err := atproto.IdentityResolveHandle(ctx, f.client, handle)
+	handle = strings.TrimPrefix(handle, "@")
+	if did, ok := f.identities.lookup(f.identities.handleToDid, strings.ToLower(handle)); ok {
+		return did, nil
+	}
+	output, err := f.resolveHandle(ctx, handle)
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve handle to DID: %w", err)
 	}
+	f.identities.storeHandle(handle, output.Did, time.Now().Add(f.identities.ttl))
 	return output.Did, nil
 }
 
+// ResolveDIDToHandle finds the handle an account claims in its DID document's alsoKnownAs, which works
+// wherever the account is hosted, and verifies that the handle resolves back to the same DID. Results are
+// cached for an hour, and concurrent lookups of the same DID share one request.
+// If verification fails, the claimed handle is still returned, together with ErrUnverifiedHandle.
+func (f *Firefly) ResolveDIDToHandle(ctx context.Context, did string) (string, error) {
+	if handle, ok := f.identities.lookup(f.identities.didToHandle, did); ok {
+		return handle, nil
+	}
+	doc, err := f.flights.didDocs.do(ctx, did, func(ctx context.Context) (*DIDDocument, error) {
+		return f.ResolveDIDDocument(ctx, did)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve DID to handle: %w", err)
+	}
+	handle := doc.Handle()
+	if handle == "" {
+		return "", fmt.Errorf("failed to resolve DID to handle: %s claims no handle", did)
+	}
+
+	// Check the bidirectional link ourselves rather than trusting HandleIsCorrect alone
+	resolvedDid, err := f.resolveHandle(ctx, handle)
+	if err != nil || resolvedDid.Did != did {
+		return handle, ErrUnverifiedHandle
+	}
+	f.identities.store(handle, did)
+	return handle, nil
+}
+
+// resolveHandle calls com.atproto.identity.resolveHandle, sharing the request with concurrent lookups of the
+// same handle
+func (f *Firefly) resolveHandle(ctx context.Context, handle string) (*atproto.IdentityResolveHandle_Output, error) {
+	return f.flights.handles.do(ctx, strings.ToLower(handle), func(ctx context.Context) (*atproto.IdentityResolveHandle_Output, error) {
+		return atproto.IdentityResolveHandle(ctx, f.api, handle)
+	})
+}
+
+// ResolveHandlesBulk resolves many handles to DIDs concurrently, using the cache where possible.
+// The result maps each successfully resolved handle to its DID; handles that fail to resolve are omitted.
+func (f *Firefly) ResolveHandlesBulk(ctx context.Context, handles []string) map[string]string {
+	return f.resolveBulk(ctx, handles, f.ResolveHandleToDID)
+}
+
+// ResolveDIDsBulk resolves many DIDs to verified handles concurrently, using the cache where possible.
+// The result maps each successfully resolved DID to its handle; DIDs whose handle can't be verified are omitted.
+func (f *Firefly) ResolveDIDsBulk(ctx context.Context, dids []string) map[string]string {
+	return f.resolveBulk(ctx, dids, f.ResolveDIDToHandle)
+}
+
+// resolveBulk fans resolve out over the inputs with bounded concurrency
+func (f *Firefly) resolveBulk(ctx context.Context, inputs []string, resolve func(context.Context, string) (string, error)) map[string]string {
+	results := make(map[string]string, len(inputs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, bulkResolveConcurrency)
+	launched := make(map[string]struct{}, len(inputs))
+
+	for _, input := range inputs {
+		if _, ok := launched[input]; ok {
+			continue
+		}
+		launched[input] = struct{}{}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return results
+		}
+		wg.Add(1)
+		go func(input string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			resolved, err := resolve(ctx, input)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			results[input] = resolved
+			mu.Unlock()
+		}(input)
+	}
+	wg.Wait()
+	return results
+}
+
 // ExtractOrResolveDidFromUri extracts a DID from an AT URI, resolving handles to DIDs when necessary
 func (f *Firefly) ExtractOrResolveDidFromUri(ctx context.Context, uri string) (string, error) {
 	userID, err := ExtractDidFromUri(uri)
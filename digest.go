@@ -0,0 +1,293 @@
+package firefly
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+)
+
+var (
+	ErrEmptyDigest = errors.New("no posts for digest")
+)
+
+// DigestRanking chooses how a Digest picks its posts
+type DigestRanking int
+
+const (
+	DigestByEngagement DigestRanking = iota // most likes, reposts, quotes and replies first
+	DigestByRecency                         // newest first
+)
+
+func (r DigestRanking) String() string {
+	switch r {
+	case DigestByEngagement:
+		return "Engagement"
+	case DigestByRecency:
+		return "Recency"
+	default:
+		return "Unknown"
+	}
+}
+
+// DigestSource is where a Digest collects its posts from. Create one with DigestFromFeed, DigestFromList,
+// DigestFromSearch or DigestFromTimeline.
+type DigestSource struct {
+	name  string
+	posts func(f *Firefly, ctx context.Context, since time.Time) iter.Seq2[*FeedPost, error]
+}
+
+func (s DigestSource) String() string {
+	return fmt.Sprintf("DigestSource{%s}", s.name)
+}
+
+// DigestFromFeed collects posts from the custom feed at feedURI
+func DigestFromFeed(feedURI string) DigestSource {
+	return DigestSource{name: "feed " + feedURI, posts: func(f *Firefly, ctx context.Context, since time.Time) iter.Seq2[*FeedPost, error] {
+		return feedItemPosts(f.FeedPager(feedURI).All(ctx))
+	}}
+}
+
+// DigestFromList collects posts by the members of the list at listURI
+func DigestFromList(listURI string) DigestSource {
+	return DigestSource{name: "list " + listURI, posts: func(f *Firefly, ctx context.Context, since time.Time) iter.Seq2[*FeedPost, error] {
+		return feedItemPosts(f.ListFeedPager(listURI).All(ctx))
+	}}
+}
+
+// DigestFromTimeline collects posts from the authenticated user's home timeline
+func DigestFromTimeline() DigestSource {
+	return DigestSource{name: "timeline", posts: func(f *Firefly, ctx context.Context, since time.Time) iter.Seq2[*FeedPost, error] {
+		return feedItemPosts(f.TimelinePager().All(ctx))
+	}}
+}
+
+// DigestFromSearch collects posts matching a search query. Pass nil for options to search without filters;
+// From defaults to the start of the digest's period.
+func DigestFromSearch(query string, options *PostSearch) DigestSource {
+	filters := PostSearch{}
+	if options != nil {
+		filters = *options
+	}
+	return DigestSource{name: "search " + query, posts: func(f *Firefly, ctx context.Context, since time.Time) iter.Seq2[*FeedPost, error] {
+		search := filters
+		if search.From == nil {
+			search.From = &since
+		}
+		return f.SearchPostsPager(query, &search).All(ctx)
+	}}
+}
+
+// feedItemPosts turns feed items into their posts
+func feedItemPosts(items iter.Seq2[*FeedItem, error]) iter.Seq2[*FeedPost, error] {
+	return func(yield func(*FeedPost, error) bool) {
+		for item, err := range items {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if item.Post != nil && !yield(item.Post, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DigestOptions configures a Digest
+type DigestOptions struct {
+	Period  time.Duration // How far back posts are collected (default 24 hours)
+	Count   int           // Most posts in the digest (default 5)
+	Ranking DigestRanking // How posts are picked (default DigestByEngagement)
+	MaxScan int           // Most posts read from each source per digest (default 500)
+
+	// Title is the text of the thread's first post (default "Top posts from the last 24 hours", with the
+	// period filled in)
+	Title string
+
+	// Format renders one ranked post, numbered from 1, as a post of the thread. The default gives the rank
+	// and the author's handle and quotes the post.
+	Format func(rank int, post *FeedPost) *DraftPost
+}
+
+// Digest collects the best posts from its sources over a period and turns them into a summary thread: a
+// title post followed by one post per pick. Build it once and publish it by hand or on a schedule with Start.
+type Digest struct {
+	f       *Firefly
+	sources []DigestSource
+	options DigestOptions
+}
+
+// NewDigest creates a Digest over one or more sources; a post found in several is counted once. Pass nil for
+// options to use the defaults.
+//
+// Example:
+//
+//	digest := client.NewDigest(&firefly.DigestOptions{Count: 10},
+//	    firefly.DigestFromList(friendsListURI),
+//	    firefly.DigestFromSearch("#golang", nil),
+//	)
+//	thread, err := digest.Publish(ctx)
+func (f *Firefly) NewDigest(options *DigestOptions, sources ...DigestSource) *Digest {
+	if options == nil {
+		options = &DigestOptions{}
+	}
+	opts := *options
+	if opts.Period <= 0 {
+		opts.Period = 24 * time.Hour
+	}
+	if opts.Count <= 0 {
+		opts.Count = 5
+	}
+	if opts.MaxScan <= 0 {
+		opts.MaxScan = 500
+	}
+	if opts.Title == "" {
+		opts.Title = "Top posts from the last " + digestPeriod(opts.Period)
+	}
+	if opts.Format == nil {
+		opts.Format = defaultDigestFormat
+	}
+	return &Digest{f: f, sources: slices.Clone(sources), options: opts}
+}
+
+// Collect reads posts created within the period from every source and returns the top Count, ranked. Reposts
+// in feeds count as the original post, and replies are left out. It returns ErrEmptyDigest if no post
+// qualifies.
+func (d *Digest) Collect(ctx context.Context) ([]*FeedPost, error) {
+	since := time.Now().Add(-d.options.Period)
+	seen := make(map[string]struct{})
+	var posts []*FeedPost
+	for _, source := range d.sources {
+		scanned := 0
+		for post, err := range source.posts(d.f, ctx, since) {
+			if err != nil {
+				return nil, fmt.Errorf("digest %s: %w", source.name, err)
+			}
+			if scanned++; scanned > d.options.MaxScan {
+				break
+			}
+			if post.ReplyInfo != nil || post.CreatedAt == nil || post.CreatedAt.Before(since) {
+				continue
+			}
+			if _, ok := seen[post.URI]; ok {
+				continue
+			}
+			seen[post.URI] = struct{}{}
+			posts = append(posts, post)
+		}
+	}
+	if len(posts) == 0 {
+		return nil, ErrEmptyDigest
+	}
+
+	slices.SortStableFunc(posts, func(a, b *FeedPost) int {
+		if d.options.Ranking == DigestByEngagement {
+			if byScore := cmp.Compare(engagementScore(b), engagementScore(a)); byScore != 0 {
+				return byScore
+			}
+		}
+		return b.CreatedAt.Compare(*a.CreatedAt)
+	})
+	return posts[:min(len(posts), d.options.Count)], nil
+}
+
+// Render turns ranked posts into the drafts of a digest thread: the title post, then one post per pick
+func (d *Digest) Render(posts []*FeedPost) []*DraftPost {
+	drafts := []*DraftPost{NewDraftPost().AddText(d.options.Title)}
+	for i, post := range posts {
+		drafts = append(drafts, d.options.Format(i+1, post))
+	}
+	return drafts
+}
+
+// Publish collects, ranks and renders the digest and publishes it as a thread, returning the thread's posts.
+// If publishing fails partway through, the posts published so far are returned with the error.
+func (d *Digest) Publish(ctx context.Context) ([]*PostRef, error) {
+	posts, err := d.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.f.PublishThread(ctx, d.Render(posts)...)
+}
+
+// Start publishes the digest every interval in the background until ctx is cancelled or the client is closed,
+// the first time one interval from now. Periods with no posts are skipped and reported to Events as
+// SourceScheduler info; other failures are reported as errors.
+func (d *Digest) Start(ctx context.Context, interval time.Duration) error {
+	if d.f.Self == nil {
+		return ErrNotLoggedIn
+	}
+	if d.f.isClosed() {
+		return ErrClientClosed
+	}
+	if interval <= 0 {
+		interval = d.options.Period
+	}
+
+	ctx, cancel := d.f.bindLifetime(ctx)
+	d.f.background.Add(1)
+	go func() {
+		defer d.f.background.Done()
+		defer cancel()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			_, err := d.Publish(ctx)
+			switch {
+			case err == nil || ctx.Err() != nil:
+			case errors.Is(err, ErrEmptyDigest):
+				d.f.emit(SourceScheduler, SeverityInfo, fmt.Errorf("digest: %w", err))
+			default:
+				d.f.emit(SourceScheduler, SeverityError, fmt.Errorf("digest: %w", err))
+			}
+		}
+	}()
+	return nil
+}
+
+// engagementScore weighs a post's engagement, counting shares above likes and replies
+func engagementScore(post *FeedPost) int {
+	count := func(n *int) int {
+		if n == nil {
+			return 0
+		}
+		return *n
+	}
+	return count(post.LikeCount) + 2*count(post.RepostCount) + 2*count(post.QuoteCount) + count(post.ReplyCount)
+}
+
+// defaultDigestFormat renders a pick as its rank and author, quoting the post
+func defaultDigestFormat(rank int, post *FeedPost) *DraftPost {
+	text := fmt.Sprintf("%d.", rank)
+	if post.Author != nil && post.Author.Handle != "" {
+		text += " @" + post.Author.Handle
+	}
+	return NewDraftPost().
+		AddText(text).
+		SetEmbed(NewEmbedBuilder().SetRecord(&PostRef{URI: post.URI, CID: post.CID}))
+}
+
+// digestPeriod describes a period in whole days or hours for a digest title
+func digestPeriod(period time.Duration) string {
+	switch {
+	case period == 24*time.Hour:
+		return "24 hours"
+	case period%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d days", period/(24*time.Hour))
+	case period == time.Hour:
+		return "hour"
+	case period%time.Hour == 0:
+		return fmt.Sprintf("%d hours", period/time.Hour)
+	default:
+		return period.String()
+	}
+}
@@ -0,0 +1,165 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/api/chat"
+)
+
+var (
+	ErrEmptyMessage  = errors.New("message has no text or embed")
+	ErrEmptyReaction = errors.New("reaction is empty")
+	ErrNilMessage    = errors.New("nil message")
+)
+
+// ChatReaction is an emoji reaction to a direct message
+type ChatReaction struct {
+	Value     string    `json:"value"`     // The emoji
+	SenderDID string    `json:"senderDid"` // Who reacted
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ChatMessage is a direct message in a conversation
+type ChatMessage struct {
+	ID        string         `json:"id"`
+	ConvoID   string         `json:"convoId"`
+	SenderDID string         `json:"senderDid"`
+	Text      string         `json:"text"`
+	SentAt    time.Time      `json:"sentAt"`
+	Embed     *PostRef       `json:"embed,omitempty"` // Shared record, if any
+	Reactions []ChatReaction `json:"reactions,omitempty"`
+	Raw       *chat.ConvoDefs_MessageView
+}
+
+func (m ChatMessage) String() string {
+	return fmt.Sprintf("ChatMessage{ID: %s, Sender: %s, Text: '%s'}", m.ID, m.SenderDID, m.Text)
+}
+
+// OldToNewChatMessage converts a chat message view to a Firefly ChatMessage
+func OldToNewChatMessage(convoID string, view *chat.ConvoDefs_MessageView) (*ChatMessage, error) {
+	if view == nil {
+		return nil, ErrNilMessage
+	}
+	message := &ChatMessage{
+		ID:      view.Id,
+		ConvoID: convoID,
+		Text:    view.Text,
+		Raw:     view,
+	}
+	if view.Sender != nil {
+		message.SenderDID = view.Sender.Did
+	}
+	if sentAt, err := time.Parse(time.RFC3339, view.SentAt); err == nil {
+		message.SentAt = sentAt
+	}
+	if view.Embed != nil && view.Embed.EmbedRecord_View != nil && view.Embed.EmbedRecord_View.Record != nil {
+		record := view.Embed.EmbedRecord_View.Record
+		if record.EmbedRecord_ViewRecord != nil {
+			message.Embed = &PostRef{URI: record.EmbedRecord_ViewRecord.Uri, CID: record.EmbedRecord_ViewRecord.Cid}
+		}
+	}
+	for _, reaction := range view.Reactions {
+		converted := ChatReaction{Value: reaction.Value}
+		if reaction.Sender != nil {
+			converted.SenderDID = reaction.Sender.Did
+		}
+		if createdAt, err := time.Parse(time.RFC3339, reaction.CreatedAt); err == nil {
+			converted.CreatedAt = createdAt
+		}
+		message.Reactions = append(message.Reactions, converted)
+	}
+	return message, nil
+}
+
+// GetConvoForMembers returns the ID of the conversation between the authenticated user and the given
+// members (DIDs), creating it if it doesn't exist yet
+func (f *Firefly) GetConvoForMembers(ctx context.Context, members ...string) (string, error) {
+	out, err := chat.ConvoGetConvoForMembers(ctx, f.api, members)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	if out.Convo == nil {
+		return "", ErrBadResponse
+	}
+	return out.Convo.Id, nil
+}
+
+// SendMessage sends a plain text direct message to a conversation
+func (f *Firefly) SendMessage(ctx context.Context, convoID string, text string) (*ChatMessage, error) {
+	return f.sendMessage(ctx, convoID, &chat.ConvoDefs_MessageInput{Text: text})
+}
+
+// SharePost sends a direct message that embeds a record, usually a post, with optional accompanying text.
+//
+// Example:
+//
+//	convo, err := client.GetConvoForMembers(ctx, friendDID)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	_, err = client.SharePost(ctx, convo, &firefly.PostRef{URI: post.URI, CID: post.CID}, "Thought you'd like this")
+func (f *Firefly) SharePost(ctx context.Context, convoID string, ref *PostRef, text string) (*ChatMessage, error) {
+	if ref == nil || !ref.IsValid() {
+		return nil, fmt.Errorf("%w: shared record", ErrInvalidUri)
+	}
+	return f.sendMessage(ctx, convoID, &chat.ConvoDefs_MessageInput{
+		Text: text,
+		Embed: &chat.ConvoDefs_MessageInput_Embed{
+			EmbedRecord: &bsky.EmbedRecord{
+				LexiconTypeID: "app.bsky.embed.record",
+				Record:        &atproto.RepoStrongRef{Uri: ref.URI, Cid: ref.CID},
+			},
+		},
+	})
+}
+
+func (f *Firefly) sendMessage(ctx context.Context, convoID string, input *chat.ConvoDefs_MessageInput) (*ChatMessage, error) {
+	if input.Text == "" && input.Embed == nil {
+		return nil, ErrEmptyMessage
+	}
+	view, err := chat.ConvoSendMessage(ctx, f.api, &chat.ConvoSendMessage_Input{
+		ConvoId: convoID,
+		Message: input,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	return OldToNewChatMessage(convoID, view)
+}
+
+// AddReaction reacts to a message with an emoji and returns the updated message
+func (f *Firefly) AddReaction(ctx context.Context, convoID string, messageID string, emoji string) (*ChatMessage, error) {
+	if emoji == "" {
+		return nil, ErrEmptyReaction
+	}
+	out, err := chat.ConvoAddReaction(ctx, f.api, &chat.ConvoAddReaction_Input{
+		ConvoId:   convoID,
+		MessageId: messageID,
+		Value:     emoji,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	return OldToNewChatMessage(convoID, out.Message)
+}
+
+// RemoveReaction removes the authenticated user's emoji reaction from a message and returns the updated message
+func (f *Firefly) RemoveReaction(ctx context.Context, convoID string, messageID string, emoji string) (*ChatMessage, error) {
+	if emoji == "" {
+		return nil, ErrEmptyReaction
+	}
+	out, err := chat.ConvoRemoveReaction(ctx, f.api, &chat.ConvoRemoveReaction_Input{
+		ConvoId:   convoID,
+		MessageId: messageID,
+		Value:     emoji,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	return OldToNewChatMessage(convoID, out.Message)
+}
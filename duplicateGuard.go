@@ -0,0 +1,246 @@
+package firefly
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDuplicatePost = errors.New("identical post was published recently")
+)
+
+// PublishedPost is what the duplicate guard remembers about a published post
+type PublishedPost struct {
+	Hash        string    `json:"hash"` // hash of the repository, reply parent, normalized text and embed
+	Post        *PostRef  `json:"post"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+func (p PublishedPost) String() string {
+	return fmt.Sprintf("PublishedPost{Hash: %s, URI: %s}", p.Hash, p.Post.URI)
+}
+
+// DuplicateStore remembers recently published posts for the duplicate guard. Implement it to share the
+// history between processes or keep it across restarts.
+type DuplicateStore interface {
+	// LookupPost returns the most recent post published with hash, or nil if there is none
+	LookupPost(ctx context.Context, hash string) (*PublishedPost, error)
+	// RememberPost records a published post
+	RememberPost(ctx context.Context, post *PublishedPost) error
+}
+
+// MemoryDuplicateStore is a DuplicateStore that keeps the history in memory.
+// The zero value is ready to use.
+type MemoryDuplicateStore struct {
+	Retention time.Duration // Entries older than this are dropped (default 24 hours)
+
+	mu    sync.Mutex
+	posts map[string]*PublishedPost
+}
+
+func (s *MemoryDuplicateStore) LookupPost(ctx context.Context, hash string) (*PublishedPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post, ok := s.posts[hash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *post
+	return &copied, nil
+}
+
+func (s *MemoryDuplicateStore) RememberPost(ctx context.Context, post *PublishedPost) error {
+	retention := s.Retention
+	if retention <= 0 {
+		retention = 24 * time.Hour
+	}
+	cutoff := time.Now().Add(-retention)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.posts == nil {
+		s.posts = make(map[string]*PublishedPost)
+	}
+	for hash, old := range s.posts {
+		if old.PublishedAt.Before(cutoff) {
+			delete(s.posts, hash)
+		}
+	}
+	copied := *post
+	s.posts[post.Hash] = &copied
+	return nil
+}
+
+// DuplicateGuardOptions configures the duplicate guard
+type DuplicateGuardOptions struct {
+	Window   time.Duration  // How long a published text blocks an identical post (default 24 hours)
+	Store    DuplicateStore // History storage (default in-memory)
+	FlagOnly bool           // Publish duplicates anyway, reporting each one as a warning on Events
+}
+
+// WithDuplicateGuard makes PublishDraftPost refuse to publish a post identical to one published within the
+// window, returning ErrDuplicatePost instead, so a scheduled post that fires twice only goes out once. Posts
+// count as identical when they go to the same repository, reply to the same post, have the same text after
+// collapsing whitespace, and embed the same images, video, link and quoted post. Drafts are checked before
+// their media is uploaded. Use AllowDuplicate to let a single post through.
+//
+// Example:
+//
+//	client, err := firefly.NewDefaultInstance(ctx, firefly.WithDuplicateGuard(&firefly.DuplicateGuardOptions{
+//	    Window: 6 * time.Hour,
+//	}))
+func WithDuplicateGuard(options *DuplicateGuardOptions) Option {
+	return func(f *Firefly) {
+		if options == nil {
+			options = &DuplicateGuardOptions{}
+		}
+		opts := *options
+		if opts.Window <= 0 {
+			opts.Window = 24 * time.Hour
+		}
+		if opts.Store == nil {
+			opts.Store = &MemoryDuplicateStore{Retention: opts.Window}
+		}
+		f.duplicates = &duplicateGuard{f: f, options: opts, pending: make(map[string]struct{})}
+	}
+}
+
+// allowDuplicateKey is the context key for AllowDuplicate
+type allowDuplicateKey struct{}
+
+// AllowDuplicate returns a context whose posts skip the duplicate guard, for repeats that are intended
+func AllowDuplicate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowDuplicateKey{}, true)
+}
+
+// duplicateGuard checks drafts against recently published posts
+type duplicateGuard struct {
+	f       *Firefly
+	options DuplicateGuardOptions
+
+	mu      sync.Mutex
+	pending map[string]struct{} // hashes being published right now, so concurrent duplicates are caught too
+}
+
+// duplicateClaim holds a post's hash while it is being published
+type duplicateClaim struct {
+	guard *duplicateGuard
+	hash  string
+}
+
+// claim checks a post against the history and reserves its hash. It returns a nil claim when the guard is
+// off or skipped, and ErrDuplicatePost when the post must not be published.
+func (g *duplicateGuard) claim(ctx context.Context, repo string, draft *DraftPost) (*duplicateClaim, error) {
+	if g == nil {
+		return nil, nil
+	}
+	if allow, _ := ctx.Value(allowDuplicateKey{}).(bool); allow {
+		return nil, nil
+	}
+	hash := postHash(repo, draft)
+
+	g.mu.Lock()
+	_, inFlight := g.pending[hash]
+	if !inFlight {
+		g.pending[hash] = struct{}{}
+	}
+	g.mu.Unlock()
+
+	var duplicateErr error
+	if inFlight {
+		duplicateErr = fmt.Errorf("%w: an identical post is being published", ErrDuplicatePost)
+	} else {
+		previous, err := g.options.Store.LookupPost(ctx, hash)
+		if err != nil {
+			g.release(hash)
+			return nil, fmt.Errorf("failed to check for duplicate posts: %w", err)
+		}
+		if previous != nil && time.Since(previous.PublishedAt) < g.options.Window {
+			g.release(hash)
+			duplicateErr = fmt.Errorf("%w: published as %s at %s", ErrDuplicatePost, previous.Post.URI, previous.PublishedAt.Format(time.RFC3339))
+		}
+	}
+
+	if duplicateErr == nil {
+		return &duplicateClaim{guard: g, hash: hash}, nil
+	}
+	if !g.options.FlagOnly {
+		return nil, duplicateErr
+	}
+	g.f.emit(SourceScheduler, SeverityWarning, duplicateErr)
+	if inFlight {
+		return nil, nil
+	}
+	g.mu.Lock()
+	g.pending[hash] = struct{}{}
+	g.mu.Unlock()
+	return &duplicateClaim{guard: g, hash: hash}, nil
+}
+
+// release forgets an in-flight hash
+func (g *duplicateGuard) release(hash string) {
+	g.mu.Lock()
+	delete(g.pending, hash)
+	g.mu.Unlock()
+}
+
+// done records the outcome of the publish and releases the hash. A failed publish is forgotten so it can be
+// retried.
+func (c *duplicateClaim) done(ctx context.Context, ref *PostRef, err error) {
+	if c == nil {
+		return
+	}
+	defer c.guard.release(c.hash)
+	if err != nil || ref == nil {
+		return
+	}
+	published := &PublishedPost{Hash: c.hash, Post: ref, PublishedAt: time.Now()}
+	if err := c.guard.options.Store.RememberPost(context.WithoutCancel(ctx), published); err != nil {
+		c.guard.f.emit(SourceScheduler, SeverityWarning, fmt.Errorf("failed to remember published post: %w", err))
+	}
+}
+
+// postHash identifies a draft by its repository, reply parent, text with whitespace collapsed, and embed
+func postHash(repo string, draft *DraftPost) string {
+	parent := ""
+	if draft.ReplyInfo != nil && draft.ReplyInfo.ReplyTarget != nil {
+		parent = draft.ReplyInfo.ReplyTarget.URI
+	}
+	var text strings.Builder
+	for _, fragment := range draft.Fragments {
+		text.WriteString(fragment.Text)
+	}
+	key := repo + "\n" + parent + "\n" + strings.Join(strings.Fields(text.String()), " ")
+	// Posts without an embed keep the hash they had before embeds were counted
+	if draft.Embed != nil {
+		key += "\n" + embedIdentity(draft.Embed)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// embedIdentity describes what an embed shows: a hash of each image and the video, the link and the quoted post
+func embedIdentity(b *EmbedBuilder) string {
+	var parts []string
+	for _, image := range b.images {
+		sum := sha256.Sum256(image.Data)
+		parts = append(parts, "image:"+hex.EncodeToString(sum[:]))
+	}
+	if b.video != nil {
+		sum := sha256.Sum256(b.video.Data)
+		parts = append(parts, "video:"+hex.EncodeToString(sum[:]))
+	}
+	if b.external != nil {
+		parts = append(parts, "external:"+b.external.URL)
+	}
+	if b.record != nil {
+		parts = append(parts, "record:"+b.record.URI)
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,369 @@
+package firefly
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+)
+
+var (
+	ErrEmptyEmbed        = errors.New("embed has no content")
+	ErrTooManyImages     = errors.New("too many images")
+	ErrMediaTooLarge     = errors.New("media exceeds size limit")
+	ErrConflictingEmbeds = errors.New("conflicting embeds")
+	ErrFailedUpload      = errors.New("failed to upload blob")
+)
+
+// Embed limits from the app.bsky.embed lexicons
+const (
+	MaxEmbedImages = 4
+	MaxImageBytes  = 1000000
+	MaxVideoBytes  = 100000000
+)
+
+// embedMedia is a blob waiting to be uploaded when the embed is built
+type embedMedia struct {
+	Data    []byte `json:"data"`
+	AltText string `json:"altText,omitempty"`
+}
+
+// EmbedBuilder assembles a post embed. Posts can carry either images, a video or an external link card,
+// optionally combined with a quoted record. The builder checks those combinations and the lexicon size
+// limits, uploads media when built, and attaches aspect ratios read from the media itself.
+type EmbedBuilder struct {
+	images   []embedMedia
+	video    *embedMedia
+	external *EmbedLink
+	thumb    []byte
+	record   *PostRef
+	process  *ImageOptions
+}
+
+// NewEmbedBuilder creates an empty EmbedBuilder
+//
+// Example:
+//
+//	embed := firefly.NewEmbedBuilder().
+//	    AddImage(photo, "A cat asleep on a keyboard").
+//	    SetRecord(quotedPost)
+//	draft := firefly.NewDraftPost().AddText("Look at this").SetEmbed(embed)
+//	uri, err := client.PublishDraftPost(ctx, draft)
+func NewEmbedBuilder() *EmbedBuilder {
+	return &EmbedBuilder{}
+}
+
+// embedBuilderJSON is how an EmbedBuilder is stored, so drafts saved by a ScheduleStore keep their media
+type embedBuilderJSON struct {
+	Images   []embedMedia  `json:"images,omitempty"`
+	Video    *embedMedia   `json:"video,omitempty"`
+	External *EmbedLink    `json:"external,omitempty"`
+	Thumb    []byte        `json:"thumb,omitempty"`
+	Record   *PostRef      `json:"record,omitempty"`
+	Process  *ImageOptions `json:"process,omitempty"`
+}
+
+// MarshalJSON encodes the builder's contents, with media bytes base64-encoded
+func (b *EmbedBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(embedBuilderJSON{
+		Images:   b.images,
+		Video:    b.video,
+		External: b.external,
+		Thumb:    b.thumb,
+		Record:   b.record,
+		Process:  b.process,
+	})
+}
+
+// UnmarshalJSON decodes contents written by MarshalJSON
+func (b *EmbedBuilder) UnmarshalJSON(data []byte) error {
+	var stored embedBuilderJSON
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	*b = EmbedBuilder{
+		images:   stored.Images,
+		video:    stored.Video,
+		external: stored.External,
+		thumb:    stored.Thumb,
+		record:   stored.Record,
+		process:  stored.Process,
+	}
+	return nil
+}
+
+// AddImage adds an image (JPEG, PNG, GIF or WebP) with its alt text
+func (b *EmbedBuilder) AddImage(data []byte, altText string) *EmbedBuilder {
+	b.images = append(b.images, embedMedia{Data: data, AltText: altText})
+	return b
+}
+
+// SetVideo sets a video (MP4) with its alt text
+func (b *EmbedBuilder) SetVideo(data []byte, altText string) *EmbedBuilder {
+	b.video = &embedMedia{Data: data, AltText: altText}
+	return b
+}
+
+// SetExternal sets a link card. thumb is an optional preview image; pass nil for none.
+func (b *EmbedBuilder) SetExternal(url string, title string, description string, thumb []byte) *EmbedBuilder {
+	b.external = &EmbedLink{URL: url, Title: title, Description: description}
+	b.thumb = thumb
+	return b
+}
+
+// SetRecord sets a record to quote, such as a post
+func (b *EmbedBuilder) SetRecord(ref *PostRef) *EmbedBuilder {
+	b.record = ref
+	return b
+}
+
+// ProcessImages prepares images and the link thumbnail with PrepareImage before they are uploaded,
+// e.g. to shrink photos over the size limit or strip their location metadata
+func (b *EmbedBuilder) ProcessImages(options *ImageOptions) *EmbedBuilder {
+	if options == nil {
+		options = &ImageOptions{}
+	}
+	b.process = options
+	return b
+}
+
+// resizes reports whether oversized images will be shrunk when the embed is built
+func (b *EmbedBuilder) resizes() bool {
+	return b.process != nil && b.process.Resize
+}
+
+// Validate checks the embed against the lexicon rules without uploading anything
+func (b *EmbedBuilder) Validate() error {
+	mediaKinds := 0
+	if len(b.images) > 0 {
+		mediaKinds++
+	}
+	if b.video != nil {
+		mediaKinds++
+	}
+	if b.external != nil {
+		mediaKinds++
+	}
+	if mediaKinds == 0 && b.record == nil {
+		return ErrEmptyEmbed
+	}
+	if mediaKinds > 1 {
+		return fmt.Errorf("%w: only one of images, video or an external link can be embedded", ErrConflictingEmbeds)
+	}
+
+	if len(b.images) > MaxEmbedImages {
+		return fmt.Errorf("%w: %d given, maximum is %d", ErrTooManyImages, len(b.images), MaxEmbedImages)
+	}
+	for i, img := range b.images {
+		if len(img.Data) == 0 {
+			return fmt.Errorf("%w: image %d is empty", ErrEmptyEmbed, i+1)
+		}
+		if len(img.Data) > MaxImageBytes && !b.resizes() {
+			return fmt.Errorf("%w: image %d is %d bytes, maximum is %d", ErrMediaTooLarge, i+1, len(img.Data), MaxImageBytes)
+		}
+	}
+	if b.video != nil {
+		if len(b.video.Data) == 0 {
+			return fmt.Errorf("%w: video is empty", ErrEmptyEmbed)
+		}
+		if len(b.video.Data) > MaxVideoBytes {
+			return fmt.Errorf("%w: video is %d bytes, maximum is %d", ErrMediaTooLarge, len(b.video.Data), MaxVideoBytes)
+		}
+	}
+	if b.external != nil {
+		if b.external.URL == "" {
+			return fmt.Errorf("%w: missing URL", ErrInvalidLink)
+		}
+		if len(b.thumb) > MaxImageBytes && !b.resizes() {
+			return fmt.Errorf("%w: thumbnail is %d bytes, maximum is %d", ErrMediaTooLarge, len(b.thumb), MaxImageBytes)
+		}
+	}
+	if b.record != nil && !b.record.IsValid() {
+		return fmt.Errorf("%w: quoted record", ErrInvalidUri)
+	}
+	return nil
+}
+
+// BuildEmbed validates the builder, uploads its media and returns the embed union for a post record
+func (f *Firefly) BuildEmbed(ctx context.Context, b *EmbedBuilder) (*bsky.FeedPost_Embed, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	var record *bsky.EmbedRecord
+	if b.record != nil {
+		record = &bsky.EmbedRecord{
+			LexiconTypeID: "app.bsky.embed.record",
+			Record:        &atproto.RepoStrongRef{Uri: b.record.URI, Cid: b.record.CID},
+		}
+	}
+
+	var images *bsky.EmbedImages
+	if len(b.images) > 0 {
+		images = &bsky.EmbedImages{LexiconTypeID: "app.bsky.embed.images"}
+		for _, img := range b.images {
+			data, err := b.prepare(img.Data)
+			if err != nil {
+				return nil, err
+			}
+			blob, err := f.UploadBlob(ctx, data)
+			if err != nil {
+				return nil, err
+			}
+			images.Images = append(images.Images, &bsky.EmbedImages_Image{
+				Alt:         img.AltText,
+				AspectRatio: imageAspectRatio(data),
+				Image:       blob,
+			})
+		}
+	}
+
+	var video *bsky.EmbedVideo
+	if b.video != nil {
+		blob, err := f.UploadBlob(ctx, b.video.Data)
+		if err != nil {
+			return nil, err
+		}
+		video = &bsky.EmbedVideo{
+			LexiconTypeID: "app.bsky.embed.video",
+			AspectRatio:   videoAspectRatio(b.video.Data),
+			Video:         blob,
+		}
+		if b.video.AltText != "" {
+			video.Alt = &b.video.AltText
+		}
+	}
+
+	var external *bsky.EmbedExternal
+	if b.external != nil {
+		external = &bsky.EmbedExternal{
+			LexiconTypeID: "app.bsky.embed.external",
+			External: &bsky.EmbedExternal_External{
+				Uri:         b.external.URL,
+				Title:       b.external.Title,
+				Description: b.external.Description,
+			},
+		}
+		if len(b.thumb) > 0 {
+			data, err := b.prepare(b.thumb)
+			if err != nil {
+				return nil, err
+			}
+			thumb, err := f.UploadBlob(ctx, data)
+			if err != nil {
+				return nil, err
+			}
+			external.External.Thumb = thumb
+		}
+	}
+
+	hasMedia := images != nil || video != nil || external != nil
+	switch {
+	case record != nil && hasMedia:
+		return &bsky.FeedPost_Embed{
+			EmbedRecordWithMedia: &bsky.EmbedRecordWithMedia{
+				LexiconTypeID: "app.bsky.embed.recordWithMedia",
+				Record:        record,
+				Media: &bsky.EmbedRecordWithMedia_Media{
+					EmbedImages:   images,
+					EmbedVideo:    video,
+					EmbedExternal: external,
+				},
+			},
+		}, nil
+	case record != nil:
+		return &bsky.FeedPost_Embed{EmbedRecord: record}, nil
+	default:
+		return &bsky.FeedPost_Embed{EmbedImages: images, EmbedVideo: video, EmbedExternal: external}, nil
+	}
+}
+
+// prepare applies the builder's image options, if any
+func (b *EmbedBuilder) prepare(data []byte) ([]byte, error) {
+	if b.process == nil {
+		return data, nil
+	}
+	return PrepareImage(data, b.process)
+}
+
+// UploadBlob uploads media to the authenticated user's PDS, returning the blob reference to embed in a record
+func (f *Firefly) UploadBlob(ctx context.Context, data []byte) (*lexutil.LexBlob, error) {
+	out, err := atproto.RepoUploadBlob(ctx, f.api, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedUpload, err)
+	}
+	if out.Blob == nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedUpload, ErrBadResponse)
+	}
+	return out.Blob, nil
+}
+
+// imageAspectRatio reads an image's dimensions, or returns nil if the format isn't recognized
+func imageAspectRatio(data []byte) *bsky.EmbedDefs_AspectRatio {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width == 0 || config.Height == 0 {
+		return nil
+	}
+	return &bsky.EmbedDefs_AspectRatio{Width: int64(config.Width), Height: int64(config.Height)}
+}
+
+// videoAspectRatio reads an MP4's display dimensions from the first visual track header,
+// or returns nil if they can't be found
+func videoAspectRatio(data []byte) *bsky.EmbedDefs_AspectRatio {
+	width, height := mp4Dimensions(data)
+	if width == 0 || height == 0 {
+		return nil
+	}
+	return &bsky.EmbedDefs_AspectRatio{Width: int64(width), Height: int64(height)}
+}
+
+// mp4Dimensions walks the moov/trak boxes looking for a tkhd box with a non-zero size.
+// Track width and height are 16.16 fixed-point values in the last 8 bytes of tkhd.
+func mp4Dimensions(data []byte) (uint32, uint32) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		kind := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return 0, 0
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return 0, 0
+		}
+		body := data[header:size]
+
+		switch kind {
+		case "moov", "trak":
+			if width, height := mp4Dimensions(body); width != 0 && height != 0 {
+				return width, height
+			}
+		case "tkhd":
+			if len(body) >= 8 {
+				width := binary.BigEndian.Uint32(body[len(body)-8:]) >> 16
+				height := binary.BigEndian.Uint32(body[len(body)-4:]) >> 16
+				if width != 0 && height != 0 {
+					return width, height
+				}
+			}
+		}
+		data = data[size:]
+	}
+	return 0, 0
+}
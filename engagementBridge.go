@@ -0,0 +1,310 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EngagementType identifies how someone engaged with one of the authenticated user's posts
+type EngagementType int
+
+const (
+	EngagementLike EngagementType = iota
+	EngagementRepost
+	EngagementReply
+	EngagementQuote
+)
+
+func (et EngagementType) String() string {
+	switch et {
+	case EngagementLike:
+		return "Like"
+	case EngagementRepost:
+		return "Repost"
+	case EngagementReply:
+		return "Reply"
+	case EngagementQuote:
+		return "Quote"
+	default:
+		return "Unknown"
+	}
+}
+
+// Engagement is a like, repost, reply or quote of one of the authenticated user's posts, seen on the firehose
+type Engagement struct {
+	Type       EngagementType `json:"type"`
+	Actor      string         `json:"actor"`          // DID of the account that engaged
+	Subject    *PostRef       `json:"subject"`        // The authenticated user's post
+	URI        string         `json:"uri"`            // The like, repost, reply or quote record
+	Post       *FeedPost      `json:"post,omitempty"` // The reply or quote post; nil for likes and reposts
+	DetectedAt time.Time      `json:"detectedAt"`
+	Event      *FirehoseEvent `json:"-"` // The firehose event the engagement came from
+}
+
+func (e Engagement) String() string {
+	return fmt.Sprintf("Engagement{Type: %s, Actor: %s, Subject: %s}", e.Type, e.Actor, e.Subject.URI)
+}
+
+// EngagementBridgeOptions configures an EngagementBridge
+type EngagementBridgeOptions struct {
+	RecentPosts     int               // Number of the user's most recent posts to watch (default 100)
+	RefreshInterval time.Duration     // Time between reloads of the recent posts from the author feed (default 15 minutes)
+	IncludeSelf     bool              // Also report the user's own likes, reposts, replies and quotes of their posts
+	Sink            EventSink         // Optional sink, such as a WebhookSink, that receives the firehose event behind every engagement
+	BufferSize      int               // Channel buffer size (default 100)
+	OnEngagement    func(*Engagement) // Optional callback invoked for every engagement before it is sent on the channel
+}
+
+// EngagementBridge watches the firehose for likes, reposts, replies and quotes of the authenticated user's
+// recent posts and forwards them as they happen, without the delay of polling notifications. It keeps a set
+// of recent post URIs, loaded from the author feed and topped up as new posts appear on the firehose.
+//
+// Engagement from accounts whose notifications would be filtered, such as muted accounts, is still reported.
+type EngagementBridge struct {
+	f       *Firefly
+	options EngagementBridgeOptions
+
+	mu    sync.Mutex
+	posts map[string]*PostRef // Watched posts by URI
+	order []string            // Watched post URIs, oldest first
+}
+
+// NewEngagementBridge creates an EngagementBridge for the authenticated user.
+// Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	sink, _ := firefly.NewWebhookSink(&firefly.WebhookSinkOptions{URL: "https://example.com/hooks/engagement"})
+//	defer sink.Close(context.Background())
+//	bridge := client.NewEngagementBridge(&firefly.EngagementBridgeOptions{Sink: sink})
+//	engagements, err := bridge.Start(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for engagement := range engagements {
+//	    fmt.Printf("%s: %s on %s\n", engagement.Type, engagement.Actor, engagement.Subject.URI)
+//	}
+func (f *Firefly) NewEngagementBridge(options *EngagementBridgeOptions) *EngagementBridge {
+	if options == nil {
+		options = &EngagementBridgeOptions{}
+	}
+	opts := *options
+	if opts.RecentPosts <= 0 {
+		opts.RecentPosts = 100
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = 15 * time.Minute
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	return &EngagementBridge{
+		f:       f,
+		options: opts,
+		posts:   make(map[string]*PostRef),
+	}
+}
+
+// Watch adds a post to the watched set, such as one published by another client. Posts the authenticated user
+// publishes are added automatically when they appear on the firehose.
+func (b *EngagementBridge) Watch(post *PostRef) {
+	if post == nil || post.URI == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.watchLocked(post)
+}
+
+// Refresh reloads the watched set from the user's author feed. Posts already watched are kept until newer
+// posts push them out.
+func (b *EngagementBridge) Refresh(ctx context.Context) error {
+	if b.f.Self == nil {
+		return ErrNotLoggedIn
+	}
+	var posts []*PostRef
+	cursor := ""
+	for len(posts) < b.options.RecentPosts {
+		items, next, err := b.f.GetAuthorFeed(ctx, b.f.Self.Did, AuthorFeedPostsWithReplies, false, cursor, min(b.options.RecentPosts-len(posts), 100))
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if item.IsRepost() || item.Post == nil || item.Post.Author == nil || item.Post.Author.Did != b.f.Self.Did {
+				continue
+			}
+			posts = append(posts, &PostRef{URI: item.Post.URI, CID: item.Post.CID})
+		}
+		if next == "" || len(items) == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// The feed is newest first; add oldest first so the newest posts survive trimming
+	for i := len(posts) - 1; i >= 0; i-- {
+		b.watchLocked(posts[i])
+	}
+	return nil
+}
+
+// Start loads the user's recent posts and watches the firehose until ctx is cancelled. Engagements are passed
+// to OnEngagement (if set) and Sink (if set), then sent on the returned channel, which is closed when the
+// bridge stops or the client is closed. With OnEngagement or Sink set, engagements that find the channel full
+// are left off it, so a caller that never reads the channel doesn't hold them up; without either, the bridge
+// waits for the channel. Refresh and sink errors are sent to Events.
+func (b *EngagementBridge) Start(ctx context.Context) (chan *Engagement, error) {
+	if b.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	if b.f.isClosed() {
+		return nil, ErrClientClosed
+	}
+	if err := b.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	engagements := make(chan *Engagement, b.options.BufferSize)
+
+	ctx, cancel := b.f.bindLifetime(ctx)
+	events, err := b.f.StreamEvents(ctx, &FirehoseOptions{
+		Collections: []string{CollectionPost, CollectionLike, CollectionRepost},
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	b.f.background.Add(1)
+	go func() {
+		defer b.f.background.Done()
+		defer cancel()
+		defer close(engagements)
+
+		ticker := time.NewTicker(b.options.RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := b.Refresh(ctx); err != nil && ctx.Err() == nil {
+					b.f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("failed to refresh watched posts: %w", err))
+				}
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if engagement := b.handleEvent(event); engagement != nil {
+					b.deliver(ctx, engagements, engagement)
+				}
+			}
+		}
+	}()
+
+	return engagements, nil
+}
+
+// handleEvent returns the engagement an event represents, or nil if it doesn't touch a watched post. New
+// posts by Self are added to the watched set.
+func (b *EngagementBridge) handleEvent(event *FirehoseEvent) *Engagement {
+	if event == nil || b.f.Self == nil {
+		return nil
+	}
+	self := event.Repo == b.f.Self.Did
+	if self && event.Type == EventTypePost && event.Post != nil {
+		b.Watch(&PostRef{URI: event.Post.URI, CID: event.Post.CID})
+	}
+	if self && !b.options.IncludeSelf {
+		return nil
+	}
+
+	engagement := &Engagement{Actor: event.Repo, DetectedAt: time.Now(), Event: event}
+	var subject string
+	switch {
+	case event.Type == EventTypeLike && event.LikeEvent != nil && event.LikeEvent.Subject != nil:
+		engagement.Type = EngagementLike
+		engagement.URI = event.LikeEvent.URI
+		subject = event.LikeEvent.Subject.URI
+	case event.Type == EventTypeRepost && event.RepostEvent != nil && event.RepostEvent.Subject != nil:
+		engagement.Type = EngagementRepost
+		engagement.URI = event.RepostEvent.URI
+		subject = event.RepostEvent.Subject.URI
+	case event.Type == EventTypePost && event.Post != nil:
+		engagement.URI = event.Post.URI
+		engagement.Post = event.Post
+		// A quote of a watched post counts as a quote even when it is also a reply
+		if event.Post.Embed != nil && event.Post.Embed.Record != nil && b.watching(event.Post.Embed.Record.URI) {
+			engagement.Type = EngagementQuote
+			subject = event.Post.Embed.Record.URI
+		} else if event.Post.ReplyInfo != nil && event.Post.ReplyInfo.ReplyTarget != nil {
+			engagement.Type = EngagementReply
+			subject = event.Post.ReplyInfo.ReplyTarget.URI
+		}
+	}
+	if subject == "" {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	post, ok := b.posts[subject]
+	if !ok {
+		return nil
+	}
+	engagement.Subject = post
+	return engagement
+}
+
+// deliver passes an engagement to the callback, the sink and the channel. With a callback or sink the channel
+// is only a convenience, so a full one drops the engagement rather than stalling them; otherwise it waits.
+func (b *EngagementBridge) deliver(ctx context.Context, engagements chan<- *Engagement, engagement *Engagement) {
+	if b.options.OnEngagement != nil {
+		b.options.OnEngagement(engagement)
+	}
+	if b.options.Sink != nil {
+		if err := b.options.Sink.Write(ctx, engagement.Event); err != nil && ctx.Err() == nil {
+			b.f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("engagement sink failed: %w", err))
+		}
+	}
+	if b.options.OnEngagement != nil || b.options.Sink != nil {
+		select {
+		case engagements <- engagement:
+		default:
+		}
+		return
+	}
+	select {
+	case engagements <- engagement:
+	case <-ctx.Done():
+	}
+}
+
+// watching reports whether uri is in the watched set
+func (b *EngagementBridge) watching(uri string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.posts[uri]
+	return ok
+}
+
+// watchLocked adds a post as the newest watched post, dropping the oldest beyond RecentPosts. Callers must
+// hold b.mu.
+func (b *EngagementBridge) watchLocked(post *PostRef) {
+	if _, ok := b.posts[post.URI]; ok {
+		return
+	}
+	b.posts[post.URI] = post
+	b.order = append(b.order, post.URI)
+	if excess := len(b.order) - b.options.RecentPosts; excess > 0 {
+		for _, uri := range b.order[:excess] {
+			delete(b.posts, uri)
+		}
+		b.order = append([]string(nil), b.order[excess:]...)
+	}
+}
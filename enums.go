@@ -0,0 +1,56 @@
+package firefly
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// enumNames maps the values of an int enum to the stable names used when serializing it.
+// Names never change once published; new values get new names.
+type enumNames[T ~int] map[T]string
+
+// text returns the name of v, or its decimal value if it has no name
+func (names enumNames[T]) text(v T) []byte {
+	if name, ok := names[v]; ok {
+		return []byte(name)
+	}
+	return []byte(strconv.Itoa(int(v)))
+}
+
+// parseText decodes a name, or a decimal value for compatibility with data written as integers. Enums whose
+// zero value is named "unknown" decode names they don't recognize, such as ones added by newer versions, as
+// that value; the others reject them.
+func (names enumNames[T]) parseText(data []byte, kind string) (T, error) {
+	text := string(data)
+	for value, name := range names {
+		if name == text {
+			return value, nil
+		}
+	}
+	if number, err := strconv.Atoi(text); err == nil {
+		return T(number), nil
+	}
+	if names[0] == "unknown" {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unknown %s: %q", kind, text)
+}
+
+// json encodes v as a JSON string
+func (names enumNames[T]) json(v T) ([]byte, error) {
+	return json.Marshal(string(names.text(v)))
+}
+
+// parseJSON decodes a JSON string name or a JSON number written by older versions
+func (names enumNames[T]) parseJSON(data []byte, kind string) (T, error) {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		return T(number), nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", kind, data)
+	}
+	return names.parseText([]byte(name), kind)
+}
@@ -0,0 +1,200 @@
+package firefly
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+)
+
+var (
+	ErrInvalidEnvelope     = errors.New("invalid event envelope")
+	ErrUnsupportedEnvelope = errors.New("unsupported event envelope version")
+)
+
+// EventEnvelopeVersion is the envelope schema version written by WriteEvents and EventWriter. Readers accept
+// every version up to this one.
+const EventEnvelopeVersion = 1
+
+// maxEnvelopeSize bounds a single line read by ReadEvents
+const maxEnvelopeSize = 16 << 20
+
+// EventEnvelope is the stable on-disk form of a FirehoseEvent: one JSON object per line holding the schema
+// version, the event type's name, and the event itself. The type is repeated outside the payload so tools can
+// filter a capture without decoding every event.
+type EventEnvelope struct {
+	Version int             `json:"v"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// NewEventEnvelope wraps event in the current envelope version
+func NewEventEnvelope(event *FirehoseEvent) (*EventEnvelope, error) {
+	if event == nil {
+		return nil, fmt.Errorf("%w: nil event", ErrInvalidEnvelope)
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	return &EventEnvelope{
+		Version: EventEnvelopeVersion,
+		Type:    string(firehoseEventTypeNames.text(event.Type)),
+		Payload: payload,
+	}, nil
+}
+
+// Event decodes the wrapped event
+func (e *EventEnvelope) Event() (*FirehoseEvent, error) {
+	if e.Version < 1 || e.Version > EventEnvelopeVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedEnvelope, e.Version)
+	}
+	if len(e.Payload) == 0 {
+		return nil, fmt.Errorf("%w: missing payload", ErrInvalidEnvelope)
+	}
+	var event FirehoseEvent
+	if err := json.Unmarshal(e.Payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	return &event, nil
+}
+
+// EventWriter writes events to w as envelope JSON Lines. It implements EventSink, so it can be added to
+// FirehoseOptions.Sinks to record a stream while it is being consumed. It is safe for concurrent use.
+//
+// Example:
+//
+//	capture, _ := os.Create("firehose.jsonl")
+//	defer capture.Close()
+//	events, err := client.StreamEvents(ctx, &firefly.FirehoseOptions{
+//	    Sinks: []firefly.EventSink{firefly.NewEventWriter(capture)},
+//	})
+type EventWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewEventWriter creates an EventWriter that writes to w
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{w: w}
+}
+
+// Write appends one event as a single line
+func (ew *EventWriter) Write(_ context.Context, event *FirehoseEvent) error {
+	envelope, err := NewEventEnvelope(event)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+	if _, err := ew.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("%w: %w", ErrSinkFailed, err)
+	}
+	return nil
+}
+
+// WriteEvents writes every event received from events to w until the channel is closed or ctx is cancelled,
+// and returns how many were written.
+func WriteEvents(ctx context.Context, w io.Writer, events <-chan *FirehoseEvent) (int, error) {
+	writer := NewEventWriter(w)
+	written := 0
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return written, nil
+			}
+			if err := writer.Write(ctx, event); err != nil {
+				return written, err
+			}
+			written++
+		case <-ctx.Done():
+			return written, ctx.Err()
+		}
+	}
+}
+
+// ReadEvents iterates over the events in an envelope JSON Lines stream written by WriteEvents or EventWriter.
+// Blank lines are skipped. Iteration stops after yielding the first error.
+//
+// Example:
+//
+//	capture, _ := os.Open("firehose.jsonl")
+//	defer capture.Close()
+//	for event, err := range firefly.ReadEvents(capture) {
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    score(event)
+//	}
+func ReadEvents(r io.Reader) iter.Seq2[*FirehoseEvent, error] {
+	return func(yield func(*FirehoseEvent, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxEnvelopeSize)
+		line := 0
+		for scanner.Scan() {
+			line++
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			var envelope EventEnvelope
+			if err := json.Unmarshal(scanner.Bytes(), &envelope); err != nil {
+				yield(nil, fmt.Errorf("%w: line %d: %w", ErrInvalidEnvelope, line, err))
+				return
+			}
+			event, err := envelope.Event()
+			if err != nil {
+				yield(nil, fmt.Errorf("line %d: %w", line, err))
+				return
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err))
+		}
+	}
+}
+
+// HandleRecordedEvents runs handler over the events in a recording made with WriteEvents or EventWriter, with
+// the same per-repo ordering and concurrency as HandleEvents, so a feed algorithm or bot can be backtested
+// against captured data. It returns once every event has been handled, with the first read error, or with
+// ctx's error if it was cancelled first.
+//
+// Example:
+//
+//	capture, _ := os.Open("firehose.jsonl")
+//	defer capture.Close()
+//	err := client.HandleRecordedEvents(ctx, capture, scorePost, &firefly.HandlerOptions{Concurrency: 8})
+func (f *Firefly) HandleRecordedEvents(ctx context.Context, r io.Reader, handler EventHandler, handlerOptions *HandlerOptions) error {
+	events := make(chan *FirehoseEvent)
+	var readErr error
+	go func() {
+		defer close(events)
+		for event, err := range ReadEvents(r) {
+			if err != nil {
+				readErr = err
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	f.dispatchEvents(ctx, events, handler, handlerOptions)
+	if readErr != nil {
+		return readErr
+	}
+	return ctx.Err()
+}
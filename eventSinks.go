@@ -0,0 +1,242 @@
+package firefly
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSinkFailed       = errors.New("event sink write failed")
+	ErrInvalidTableName = errors.New("invalid table name")
+)
+
+// EventSink receives every firehose event delivered by StreamEvents, before it is sent on the channel.
+// Set FirehoseOptions.Sinks to persist or forward events without writing a consumer loop.
+// WebhookSink and SQLEventSink both implement EventSink.
+type EventSink interface {
+	Write(ctx context.Context, event *FirehoseEvent) error
+}
+
+// SQLDialect selects the SQL flavour used by SQLEventSink
+type SQLDialect int
+
+const (
+	SQLDialectSQLite SQLDialect = iota
+	SQLDialectPostgres
+)
+
+func (d SQLDialect) String() string {
+	switch d {
+	case SQLDialectSQLite:
+		return "SQLite"
+	case SQLDialectPostgres:
+		return "PostgreSQL"
+	default:
+		return "Unknown"
+	}
+}
+
+// SQLSinkOptions configures an SQLEventSink
+type SQLSinkOptions struct {
+	Table         string        // Table to write to (default "firehose_events")
+	BatchSize     int           // Rows per insert (default 100, which stays under SQLite's older 999-parameter limit)
+	FlushInterval time.Duration // Maximum age of a partial batch before the next Write flushes it (default 5 seconds)
+	CreateTable   bool          // Create the table and indexes if they don't exist
+}
+
+// SQLEventSink stores firehose events in an SQLite or PostgreSQL table using batched inserts.
+// The caller opens the *sql.DB with the driver of their choice, so Firefly doesn't depend on one.
+//
+// Each row holds the event's type, sequence, repo, timestamp, the collection, record key and URI
+// when the event is a commit, and the full event as JSON in the data column.
+type SQLEventSink struct {
+	db      *sql.DB
+	dialect SQLDialect
+	options SQLSinkOptions
+
+	mu        sync.Mutex
+	pending   []*FirehoseEvent
+	lastFlush time.Time
+}
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// NewSQLEventSink creates a sink that writes to db, creating the table first if options.CreateTable is set.
+// Pass nil for default options.
+//
+// Example:
+//
+//	db, _ := sql.Open("sqlite", "events.db")
+//	sink, err := firefly.NewSQLEventSink(ctx, db, firefly.SQLDialectSQLite, &firefly.SQLSinkOptions{CreateTable: true})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer sink.Close(ctx)
+//	events, _ := client.StreamEvents(ctx, &firefly.FirehoseOptions{Sinks: []firefly.EventSink{sink}})
+func NewSQLEventSink(ctx context.Context, db *sql.DB, dialect SQLDialect, options *SQLSinkOptions) (*SQLEventSink, error) {
+	var opts SQLSinkOptions
+	if options != nil {
+		opts = *options
+	}
+	if opts.Table == "" {
+		opts.Table = "firehose_events"
+	}
+	if !sqlIdentifier.MatchString(opts.Table) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTableName, opts.Table)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+
+	sink := &SQLEventSink{db: db, dialect: dialect, options: opts, lastFlush: time.Now()}
+	if opts.CreateTable {
+		if err := sink.createTable(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return sink, nil
+}
+
+// Schema returns the statements used to create the sink's table and indexes
+func (s *SQLEventSink) Schema() []string {
+	id := "id INTEGER PRIMARY KEY AUTOINCREMENT"
+	data := "TEXT"
+	if s.dialect == SQLDialectPostgres {
+		id = "id BIGSERIAL PRIMARY KEY"
+		data = "JSONB"
+	}
+	table := s.options.Table
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	%s,
+	type TEXT NOT NULL,
+	sequence BIGINT NOT NULL,
+	repo TEXT NOT NULL,
+	collection TEXT,
+	rkey TEXT,
+	uri TEXT,
+	timestamp TIMESTAMP NOT NULL,
+	data %s NOT NULL
+)`, table, id, data),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_repo_idx ON %s (repo)", table, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_type_time_idx ON %s (type, timestamp)", table, table),
+	}
+}
+
+func (s *SQLEventSink) createTable(ctx context.Context) error {
+	for _, statement := range s.Schema() {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("%w: creating table: %w", ErrSinkFailed, err)
+		}
+	}
+	return nil
+}
+
+// Write queues an event, inserting the batch once it is full or FlushInterval has passed
+func (s *SQLEventSink) Write(ctx context.Context, event *FirehoseEvent) error {
+	if event == nil {
+		return nil
+	}
+	s.mu.Lock()
+	s.pending = append(s.pending, event)
+	due := len(s.pending) >= s.options.BatchSize || time.Since(s.lastFlush) >= s.options.FlushInterval
+	s.mu.Unlock()
+
+	if due {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush inserts all queued events in a single transaction
+func (s *SQLEventSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.lastFlush = time.Now()
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := s.insert(ctx, batch); err != nil {
+		return fmt.Errorf("%w: %w", ErrSinkFailed, err)
+	}
+	return nil
+}
+
+// Close flushes any queued events. It does not close the underlying database.
+func (s *SQLEventSink) Close(ctx context.Context) error {
+	return s.Flush(ctx)
+}
+
+const sqlSinkColumns = 8
+
+func (s *SQLEventSink) insert(ctx context.Context, batch []*FirehoseEvent) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var query strings.Builder
+	fmt.Fprintf(&query, "INSERT INTO %s (type, sequence, repo, collection, rkey, uri, timestamp, data) VALUES ", s.options.Table)
+	args := make([]any, 0, len(batch)*sqlSinkColumns)
+	for i, event := range batch {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		collection, rkey, uri := eventRecordLocation(event)
+
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for column := 0; column < sqlSinkColumns; column++ {
+			if column > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(s.placeholder(len(args) + column + 1))
+		}
+		query.WriteString(")")
+		args = append(args, string(firehoseEventTypeNames.text(event.Type)), event.Sequence, event.Repo,
+			nullString(collection), nullString(rkey), nullString(uri), event.Timestamp.UTC(), string(data))
+	}
+
+	if _, err := tx.ExecContext(ctx, query.String(), args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLEventSink) placeholder(n int) string {
+	if s.dialect == SQLDialectPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// eventRecordLocation returns the collection, record key and AT URI of a commit event
+func eventRecordLocation(event *FirehoseEvent) (string, string, string) {
+	if event.RawCommit == nil || event.RawCommit.Commit == nil {
+		return "", "", ""
+	}
+	commit := event.RawCommit.Commit
+	uri := fmt.Sprintf("at://%s/%s/%s", event.Repo, commit.Collection, commit.RKey)
+	return commit.Collection, commit.RKey, uri
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,391 @@
+package firefly
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+)
+
+var (
+	ErrExportFailed = errors.New("export failed")
+)
+
+// ExportFormat selects the file format written by an Exporter
+type ExportFormat int
+
+const (
+	ExportJSONLines ExportFormat = iota
+	ExportCSV
+	ExportMarkdown
+)
+
+func (ef ExportFormat) String() string {
+	switch ef {
+	case ExportJSONLines:
+		return "JSON Lines"
+	case ExportCSV:
+		return "CSV"
+	case ExportMarkdown:
+		return "Markdown"
+	default:
+		return "Unknown"
+	}
+}
+
+// ExportedPost is a single archived post as written by an Exporter
+type ExportedPost struct {
+	URI         string    `json:"uri"`
+	CID         string    `json:"cid"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Text        string    `json:"text"`
+	ReplyTo     string    `json:"replyTo,omitempty"` // URI of the parent post
+	Quote       string    `json:"quote,omitempty"`   // URI of the quoted post
+	Link        string    `json:"link,omitempty"`    // URL of an external link card
+	Media       []string  `json:"media,omitempty"`   // Blob URLs of attached images and video
+	Languages   []string  `json:"languages,omitempty"`
+	LikeCount   int       `json:"likeCount"` // counts are zero when exporting from a CAR file
+	RepostCount int       `json:"repostCount"`
+	ReplyCount  int       `json:"replyCount"`
+	QuoteCount  int       `json:"quoteCount"`
+}
+
+// ExportProgress records how far an export has got so it can be resumed
+type ExportProgress struct {
+	Cursor    string    `json:"cursor,omitempty"` // Author feed cursor, or the last exported record key for CAR exports
+	Exported  int       `json:"exported"`
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ExporterOptions configures an Exporter
+type ExporterOptions struct {
+	Format         ExportFormat          // Output format (default JSON Lines)
+	Actor          string                // Handle or DID whose posts are exported (default the authenticated user)
+	IncludeReplies bool                  // Export replies as well as top-level posts
+	ProgressPath   string                // Optional file used to save progress and resume an interrupted export
+	OnProgress     func(*ExportProgress) // Optional callback invoked each time progress is saved
+}
+
+// Exporter archives an account's posts to JSON Lines, CSV or Markdown. Progress is saved after every page, and an
+// export started with the same ProgressPath picks up where the previous one stopped. When resuming, open the
+// output file in append mode so earlier rows are kept; headers are only written when nothing has been exported yet.
+type Exporter struct {
+	f        *Firefly
+	w        io.Writer
+	csv      *csv.Writer
+	options  ExporterOptions
+	progress ExportProgress
+}
+
+// NewExporter creates an Exporter that writes to w. Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	out, err := os.OpenFile("posts.jsonl", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer out.Close()
+//
+//	exporter := client.NewExporter(out, &firefly.ExporterOptions{ProgressPath: "posts.progress.json"})
+//	if err := exporter.Export(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (f *Firefly) NewExporter(w io.Writer, options *ExporterOptions) *Exporter {
+	if options == nil {
+		options = &ExporterOptions{}
+	}
+	e := &Exporter{
+		f:       f,
+		w:       w,
+		options: *options,
+	}
+	if e.options.Format == ExportCSV {
+		e.csv = csv.NewWriter(w)
+	}
+	return e
+}
+
+// Progress returns the current export progress
+func (e *Exporter) Progress() ExportProgress {
+	return e.progress
+}
+
+// Export walks the actor's author feed from newest to oldest and writes every post. Reposts are skipped.
+func (e *Exporter) Export(ctx context.Context) error {
+	actor := e.options.Actor
+	if actor == "" {
+		if e.f.Self == nil {
+			return ErrNotLoggedIn
+		}
+		actor = e.f.Self.Did
+	}
+	if err := e.start(); err != nil || e.progress.Done {
+		return err
+	}
+
+	filter := "posts_no_replies"
+	if e.options.IncludeReplies {
+		filter = "posts_with_replies"
+	}
+	for {
+		feed, err := bsky.FeedGetAuthorFeed(ctx, e.f.api, actor, e.progress.Cursor, filter, false, 100)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedFetch, err)
+		}
+		for _, item := range feed.Feed {
+			if item == nil || item.Post == nil || item.Reason != nil {
+				continue
+			}
+			post, err := e.f.OldToNewPostView(item.Post)
+			if err != nil {
+				continue
+			}
+			if err := e.write(e.f.exportedPost(post, item.Post.Author.Did)); err != nil {
+				return err
+			}
+		}
+
+		e.progress.Cursor = ""
+		if feed.Cursor != nil {
+			e.progress.Cursor = *feed.Cursor
+		}
+		e.progress.Done = e.progress.Cursor == "" || len(feed.Feed) == 0
+		if err := e.saveProgress(); err != nil {
+			return err
+		}
+		if e.progress.Done {
+			return nil
+		}
+	}
+}
+
+// ExportCAR writes the posts stored in a repository CAR file, such as one returned by GetRepo, in record key
+// (oldest first) order. It needs no network access, so it can archive accounts whose author feed is unavailable.
+// Engagement counts aren't part of the repository and are left at zero.
+func (e *Exporter) ExportCAR(ctx context.Context, car io.Reader, did string) error {
+	if err := e.start(); err != nil || e.progress.Done {
+		return err
+	}
+	records, err := ReadRepoCAR(car)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if record.Collection != CollectionPost || record.RKey <= e.progress.Cursor {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		raw := &bsky.FeedPost{}
+		if err := raw.UnmarshalCBOR(bytes.NewReader(record.Data)); err != nil {
+			continue
+		}
+		if raw.Reply != nil && !e.options.IncludeReplies {
+			continue
+		}
+		post, err := e.f.OldToNewPost(raw, did)
+		if err != nil {
+			continue
+		}
+		post.URI = record.URI(did)
+		post.CID = record.CID
+		if err := e.write(e.f.exportedPost(post, did)); err != nil {
+			return err
+		}
+		e.progress.Cursor = record.RKey
+		if e.progress.Exported%100 == 0 {
+			if err := e.saveProgress(); err != nil {
+				return err
+			}
+		}
+	}
+	e.progress.Done = true
+	return e.saveProgress()
+}
+
+// start loads saved progress and writes the format's header for a fresh export
+func (e *Exporter) start() error {
+	if e.options.ProgressPath != "" {
+		data, err := os.ReadFile(e.options.ProgressPath)
+		if err == nil {
+			if err := json.Unmarshal(data, &e.progress); err != nil {
+				return fmt.Errorf("%w: reading progress: %w", ErrExportFailed, err)
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: reading progress: %w", ErrExportFailed, err)
+		}
+	}
+	if e.progress.Exported > 0 || e.progress.Done {
+		return nil
+	}
+
+	var err error
+	switch e.options.Format {
+	case ExportCSV:
+		err = e.csv.Write([]string{"uri", "cid", "created_at", "text", "reply_to", "quote", "link", "media",
+			"languages", "likes", "reposts", "replies", "quotes"})
+	case ExportMarkdown:
+		title := e.options.Actor
+		if title == "" && e.f.Self != nil {
+			title = e.f.Self.Handle
+		}
+		_, err = fmt.Fprintf(e.w, "# Posts by %s\n\n", title)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrExportFailed, err)
+	}
+	return nil
+}
+
+// write encodes a single post in the configured format
+func (e *Exporter) write(post *ExportedPost) error {
+	var err error
+	switch e.options.Format {
+	case ExportCSV:
+		err = e.csv.Write([]string{
+			post.URI, post.CID, post.CreatedAt.Format(time.RFC3339), post.Text, post.ReplyTo, post.Quote, post.Link,
+			strings.Join(post.Media, " "), strings.Join(post.Languages, ","), strconv.Itoa(post.LikeCount),
+			strconv.Itoa(post.RepostCount), strconv.Itoa(post.ReplyCount), strconv.Itoa(post.QuoteCount),
+		})
+	case ExportMarkdown:
+		err = writeMarkdownPost(e.w, post)
+	default:
+		var line []byte
+		line, err = json.Marshal(post)
+		if err == nil {
+			_, err = e.w.Write(append(line, '\n'))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrExportFailed, err)
+	}
+	e.progress.Exported++
+	return nil
+}
+
+func writeMarkdownPost(w io.Writer, post *ExportedPost) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", post.CreatedAt.UTC().Format("02 Jan 2006 15:04 MST"))
+	if post.Text != "" {
+		fmt.Fprintf(&b, "%s\n\n", post.Text)
+	}
+	if post.ReplyTo != "" {
+		fmt.Fprintf(&b, "- Reply to: %s\n", post.ReplyTo)
+	}
+	if post.Quote != "" {
+		fmt.Fprintf(&b, "- Quoting: %s\n", post.Quote)
+	}
+	if post.Link != "" {
+		fmt.Fprintf(&b, "- Link: <%s>\n", post.Link)
+	}
+	for _, media := range post.Media {
+		fmt.Fprintf(&b, "- Media: <%s>\n", media)
+	}
+	fmt.Fprintf(&b, "- %d likes, %d reposts, %d replies, %d quotes\n", post.LikeCount, post.RepostCount,
+		post.ReplyCount, post.QuoteCount)
+	fmt.Fprintf(&b, "- `%s`\n\n", post.URI)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// saveProgress flushes buffered output, then atomically replaces the progress file
+func (e *Exporter) saveProgress() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return fmt.Errorf("%w: %w", ErrExportFailed, err)
+		}
+	}
+	e.progress.UpdatedAt = time.Now()
+	if e.options.ProgressPath != "" {
+		data, err := json.Marshal(e.progress)
+		if err != nil {
+			return fmt.Errorf("%w: saving progress: %w", ErrExportFailed, err)
+		}
+		tmp := e.options.ProgressPath + ".tmp"
+		if err := os.WriteFile(tmp, data, 0o644); err != nil {
+			return fmt.Errorf("%w: saving progress: %w", ErrExportFailed, err)
+		}
+		if err := os.Rename(tmp, e.options.ProgressPath); err != nil {
+			return fmt.Errorf("%w: saving progress: %w", ErrExportFailed, err)
+		}
+	}
+	if e.options.OnProgress != nil {
+		progress := e.progress
+		e.options.OnProgress(&progress)
+	}
+	return nil
+}
+
+// exportedPost flattens a post and its media references for archiving
+func (f *Firefly) exportedPost(post *FeedPost, did string) *ExportedPost {
+	exported := &ExportedPost{
+		URI:       post.URI,
+		CID:       post.CID,
+		Text:      post.Text,
+		Languages: post.Languages,
+	}
+	if post.CreatedAt != nil {
+		exported.CreatedAt = *post.CreatedAt
+	}
+	if post.ReplyInfo != nil && post.ReplyInfo.ReplyTarget != nil {
+		exported.ReplyTo = post.ReplyInfo.ReplyTarget.URI
+	}
+	if post.LikeCount != nil {
+		exported.LikeCount = *post.LikeCount
+	}
+	if post.RepostCount != nil {
+		exported.RepostCount = *post.RepostCount
+	}
+	if post.ReplyCount != nil {
+		exported.ReplyCount = *post.ReplyCount
+	}
+	if post.QuoteCount != nil {
+		exported.QuoteCount = *post.QuoteCount
+	}
+
+	embed := post.Embed
+	if embed == nil {
+		return exported
+	}
+	if embed.Record != nil {
+		exported.Quote = embed.Record.URI
+	}
+	// Quotes with media only expose the media through the raw embed
+	if embed.Raw != nil && embed.Raw.EmbedRecordWithMedia != nil && embed.Raw.EmbedRecordWithMedia.Media != nil {
+		media := embed.Raw.EmbedRecordWithMedia.Media
+		embed, _ = f.OldToNewEmbed(&bsky.FeedPost_Embed{
+			EmbedImages:   media.EmbedImages,
+			EmbedVideo:    media.EmbedVideo,
+			EmbedExternal: media.EmbedExternal,
+		}, did)
+		if embed == nil {
+			return exported
+		}
+	}
+	for _, image := range embed.Images {
+		if image.URL != "" {
+			exported.Media = append(exported.Media, image.URL)
+		}
+	}
+	if embed.Video != nil && embed.Video.URL != "" {
+		exported.Media = append(exported.Media, embed.Video.URL)
+	}
+	if embed.External != nil {
+		exported.Link = embed.External.URL
+	}
+	return exported
+}
@@ -0,0 +1,139 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/xrpc"
+)
+
+// FeedGenerator describes a custom feed and the health of the service that generates it
+type FeedGenerator struct {
+	URI         string     `json:"uri" cborgen:"uri"`
+	CID         string     `json:"cid" cborgen:"cid"`
+	ServiceDID  string     `json:"serviceDid" cborgen:"serviceDid"` // DID of the feed generator service
+	Creator     *User      `json:"creator" cborgen:"creator"`
+	DisplayName string     `json:"displayName" cborgen:"displayName"`
+	Description *string    `json:"description,omitempty" cborgen:"description,omitempty"`
+	Avatar      *string    `json:"avatar,omitempty" cborgen:"avatar,omitempty"`
+	LikeCount   *int       `json:"likeCount,omitempty" cborgen:"likeCount,omitempty"`
+	IndexedAt   *time.Time `json:"indexedAt,omitempty" cborgen:"indexedAt,omitempty"`
+
+	// Health as reported by the AppView
+	IsOnline bool `json:"isOnline" cborgen:"isOnline"` // service has been online recently
+	IsValid  bool `json:"isValid" cborgen:"isValid"`   // service is compatible with the record declaration
+
+	// Health as reported by the service itself. Always set by GetFeedGenerator; if the service couldn't be
+	// located or described, Reachable is false and Error says why.
+	Service *FeedGeneratorService `json:"service,omitempty" cborgen:"service,omitempty"`
+
+	Raw *bsky.FeedDefs_GeneratorView
+}
+
+// FeedGeneratorService holds the result of calling describeFeedGenerator on the feed's own service
+type FeedGeneratorService struct {
+	Endpoint  string   `json:"endpoint" cborgen:"endpoint"`               // URL from the service's DID document
+	Reachable bool     `json:"reachable" cborgen:"reachable"`             // describeFeedGenerator succeeded
+	Error     string   `json:"error,omitempty" cborgen:"error,omitempty"` // why the service could not be described
+	DID       string   `json:"did,omitempty" cborgen:"did,omitempty"`     // DID the service reports for itself
+	Feeds     []string `json:"feeds,omitempty" cborgen:"feeds,omitempty"` // feed URIs the service advertises
+	Listed    bool     `json:"listed" cborgen:"listed"`                   // the service advertises this feed
+}
+
+func (g FeedGenerator) String() string {
+	return fmt.Sprintf("FeedGenerator{URI: %s, Online: %t, Valid: %t}", g.URI, g.IsOnline, g.IsValid)
+}
+
+// GetFeedGenerator fetches a feed generator's view and health flags from the AppView, then locates the
+// generator's service through its DID document and calls describeFeedGenerator on it directly.
+// Failures talking to the service itself are recorded in Service rather than returned, so dashboards
+// can still show the AppView's view of a broken deployment.
+//
+// Example:
+//
+//	feed, err := client.GetFeedGenerator(ctx, "at://did:plc:xyz/app.bsky.feed.generator/my-feed")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !feed.IsOnline || !feed.Service.Listed {
+//	    log.Printf("feed %s looks unhealthy: %s", feed.DisplayName, feed.Service.Error)
+//	}
+func (f *Firefly) GetFeedGenerator(ctx context.Context, feedURI string) (*FeedGenerator, error) {
+	output, err := bsky.FeedGetFeedGenerator(ctx, f.api, feedURI)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	if output.View == nil {
+		return nil, fmt.Errorf("%w: missing generator view", ErrBadResponse)
+	}
+
+	view := output.View
+	generator := &FeedGenerator{
+		URI:         view.Uri,
+		CID:         view.Cid,
+		ServiceDID:  view.Did,
+		DisplayName: view.DisplayName,
+		Description: view.Description,
+		Avatar:      view.Avatar,
+		IsOnline:    output.IsOnline,
+		IsValid:     output.IsValid,
+		Raw:         view,
+	}
+	if view.LikeCount != nil {
+		likes := int(*view.LikeCount)
+		generator.LikeCount = &likes
+	}
+	if indexedAt, err := time.Parse(time.RFC3339, view.IndexedAt); err == nil {
+		generator.IndexedAt = &indexedAt
+	}
+	if creator, err := OldToNewUser(view.Creator); err == nil {
+		generator.Creator = creator
+	}
+
+	generator.Service = f.describeFeedService(ctx, view.Did, view.Uri)
+	return generator, nil
+}
+
+// describeFeedService resolves a feed generator service's endpoint and asks it to describe itself
+func (f *Firefly) describeFeedService(ctx context.Context, serviceDID string, feedURI string) *FeedGeneratorService {
+	doc, err := f.ResolveDIDDocument(ctx, serviceDID)
+	if err != nil {
+		return &FeedGeneratorService{Error: err.Error()}
+	}
+
+	service := &FeedGeneratorService{
+		Endpoint: doc.ServiceEndpoint("bsky_fg"),
+	}
+	if service.Endpoint == "" {
+		service.Error = "DID document has no bsky_fg service endpoint"
+		return service
+	}
+
+	// The service is public, so don't send our session credentials to it
+	local := &xrpc.Client{
+		Client:    f.client.Client,
+		Host:      strings.TrimSuffix(service.Endpoint, "/"),
+		UserAgent: f.client.UserAgent,
+	}
+	description, err := bsky.FeedDescribeFeedGenerator(ctx, local)
+	if err != nil {
+		service.Error = err.Error()
+		return service
+	}
+
+	service.Reachable = true
+	service.DID = description.Did
+	for _, feed := range description.Feeds {
+		if feed == nil {
+			continue
+		}
+		service.Feeds = append(service.Feeds, feed.Uri)
+		if feed.Uri == feedURI {
+			service.Listed = true
+		}
+	}
+	return service
+}
@@ -0,0 +1,191 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+)
+
+// AuthorFeedFilter selects which of an account's posts GetAuthorFeed returns
+type AuthorFeedFilter string
+
+const (
+	// AuthorFeedPostsWithReplies returns posts, reposts and replies (the server default)
+	AuthorFeedPostsWithReplies AuthorFeedFilter = "posts_with_replies"
+	// AuthorFeedPostsNoReplies returns posts and reposts, without replies
+	AuthorFeedPostsNoReplies AuthorFeedFilter = "posts_no_replies"
+	// AuthorFeedPostsWithMedia returns only posts with images or video
+	AuthorFeedPostsWithMedia AuthorFeedFilter = "posts_with_media"
+	// AuthorFeedPostsAndAuthorThreads returns posts, reposts and the author's replies to their own threads
+	AuthorFeedPostsAndAuthorThreads AuthorFeedFilter = "posts_and_author_threads"
+	// AuthorFeedPostsWithVideo returns only posts with video
+	AuthorFeedPostsWithVideo AuthorFeedFilter = "posts_with_video"
+)
+
+// FeedItem is one entry of a timeline or author feed. Post is the post being shown; if the entry is there
+// because someone reposted it, RepostedBy is the account that did, so it can be rendered as "Reposted by X".
+type FeedItem struct {
+	Post       *FeedPost                   `json:"post"`
+	RepostedBy *User                       `json:"repostedBy,omitempty"` // nil unless the item is a repost
+	RepostedAt *time.Time                  `json:"repostedAt,omitempty"` // when the repost was indexed
+	Repost     *PostRef                    `json:"repost,omitempty"`     // the repost record, when the server includes it
+	Pinned     bool                        `json:"pinned"`               // the post is pinned to the top of an author feed
+	Raw        *bsky.FeedDefs_FeedViewPost `json:"-"`
+}
+
+// IsRepost reports whether the item appears in the feed because it was reposted
+func (i FeedItem) IsRepost() bool {
+	return i.RepostedBy != nil
+}
+
+func (i FeedItem) String() string {
+	uri := ""
+	if i.Post != nil {
+		uri = i.Post.URI
+	}
+	switch {
+	case i.RepostedBy != nil:
+		return fmt.Sprintf("FeedItem{Post: %s, RepostedBy: %s}", uri, i.RepostedBy.Handle)
+	case i.Pinned:
+		return fmt.Sprintf("FeedItem{Post: %s, Pinned}", uri)
+	default:
+		return fmt.Sprintf("FeedItem{Post: %s}", uri)
+	}
+}
+
+// OldToNewFeedItem converts a feed view post, keeping the repost or pin reason that the plain post view drops
+func (f *Firefly) OldToNewFeedItem(oldItem *bsky.FeedDefs_FeedViewPost) (*FeedItem, error) {
+	if oldItem == nil {
+		return nil, ErrNilPost
+	}
+	post, err := f.OldToNewPostView(oldItem.Post)
+	if err != nil {
+		return nil, err
+	}
+	item := &FeedItem{Post: post, Raw: oldItem}
+	if oldItem.Reason == nil {
+		return item, nil
+	}
+	if repost := oldItem.Reason.FeedDefs_ReasonRepost; repost != nil {
+		item.RepostedBy, err = OldToNewUserBasic(repost.By)
+		if err != nil {
+			return nil, err
+		}
+		if indexedAt, err := time.Parse(time.RFC3339, repost.IndexedAt); err == nil {
+			item.RepostedAt = &indexedAt
+		}
+		if repost.Uri != nil {
+			item.Repost = &PostRef{URI: *repost.Uri}
+			if repost.Cid != nil {
+				item.Repost.CID = *repost.Cid
+			}
+		}
+	}
+	if oldItem.Reason.FeedDefs_ReasonPin != nil {
+		item.Pinned = true
+	}
+	return item, nil
+}
+
+// GetTimeline returns one page of the authenticated user's home timeline, along with the cursor for the next
+// page. Reposted items carry the reposting account in RepostedBy. The returned cursor is empty when there are
+// no more pages.
+//
+// Example:
+//
+//	items, _, err := client.GetTimeline(ctx, "", 50)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, item := range items {
+//	    if item.IsRepost() {
+//	        fmt.Println("Reposted by", item.RepostedBy.Handle)
+//	    }
+//	    fmt.Println(item.Post.Text)
+//	}
+func (f *Firefly) GetTimeline(ctx context.Context, cursor string, limit int) ([]*FeedItem, string, error) {
+	if f.Self == nil {
+		return nil, "", ErrNotLoggedIn
+	}
+	result, err := bsky.FeedGetTimeline(ctx, f.api, "", cursor, int64(limit))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	next := ""
+	if result.Cursor != nil {
+		next = *result.Cursor
+	}
+	return f.feedItems(result.Feed), next, nil
+}
+
+// GetAuthorFeed returns one page of actor's posts and reposts, along with the cursor for the next page. Pass an
+// empty actor to use Self and an empty filter for the server default. With includePins, the pinned post comes
+// first on the first page and is marked Pinned. The returned cursor is empty when there are no more pages.
+//
+// Example:
+//
+//	items, cursor, err := client.GetAuthorFeed(ctx, "alice.bsky.social", firefly.AuthorFeedPostsNoReplies, true, "", 30)
+func (f *Firefly) GetAuthorFeed(ctx context.Context, actor string, filter AuthorFeedFilter, includePins bool, cursor string, limit int) ([]*FeedItem, string, error) {
+	if actor == "" {
+		if f.Self == nil {
+			return nil, "", ErrNotLoggedIn
+		}
+		actor = f.Self.Did
+	}
+	result, err := bsky.FeedGetAuthorFeed(ctx, f.api, actor, cursor, string(filter), includePins, int64(limit))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	next := ""
+	if result.Cursor != nil {
+		next = *result.Cursor
+	}
+	return f.feedItems(result.Feed), next, nil
+}
+
+// GetFeed returns one page of a custom feed, identified by its feed generator's at:// URI, along with the
+// cursor for the next page. The returned cursor is empty when there are no more pages.
+//
+// Example:
+//
+//	items, cursor, err := client.GetFeed(ctx, "at://did:plc:z72i7hdynmk6r22z27h6tvur/app.bsky.feed.generator/whats-hot", "", 30)
+func (f *Firefly) GetFeed(ctx context.Context, feedURI string, cursor string, limit int) ([]*FeedItem, string, error) {
+	result, err := bsky.FeedGetFeed(ctx, f.api, cursor, feedURI, int64(limit))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	next := ""
+	if result.Cursor != nil {
+		next = *result.Cursor
+	}
+	return f.feedItems(result.Feed), next, nil
+}
+
+// GetListFeed returns one page of recent posts by the members of the list at listURI, along with the cursor
+// for the next page. The returned cursor is empty when there are no more pages.
+func (f *Firefly) GetListFeed(ctx context.Context, listURI string, cursor string, limit int) ([]*FeedItem, string, error) {
+	result, err := bsky.FeedGetListFeed(ctx, f.api, cursor, int64(limit), listURI)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	next := ""
+	if result.Cursor != nil {
+		next = *result.Cursor
+	}
+	return f.feedItems(result.Feed), next, nil
+}
+
+// feedItems converts a page of feed view posts, skipping any that fail to convert
+func (f *Firefly) feedItems(feed []*bsky.FeedDefs_FeedViewPost) []*FeedItem {
+	items := make([]*FeedItem, 0, len(feed))
+	for _, view := range feed {
+		item, err := f.OldToNewFeedItem(view)
+		if err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,220 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// FeedSource is one feed mixed by a FeedMerger
+type FeedSource struct {
+	Name   string            // Reported on each MergedItem, e.g. "timeline" or "cats"
+	Pager  *Pager[*FeedItem] // Where the items come from, such as TimelinePager, FeedPager or ListFeedPager
+	Weight float64           // Share of the merged feed relative to the other sources (default 1)
+}
+
+// MergedItem is an item chosen by a FeedMerger, with the source it came from
+type MergedItem struct {
+	Item   *FeedItem `json:"item"`
+	Source string    `json:"source"`
+}
+
+func (m MergedItem) String() string {
+	return fmt.Sprintf("MergedItem{Source: %s, URI: %s}", m.Source, m.Item.Post.URI)
+}
+
+// FeedMergerOptions configures a FeedMerger
+type FeedMergerOptions struct {
+	// AuthorWindow keeps an author from appearing again within this many consecutive items, where other
+	// authors are available. Zero turns author diversity off.
+	AuthorWindow int
+}
+
+// FeedMerger interleaves several feeds into one, for building mixed "For You" style views on the client.
+// Sources are drawn from in proportion to their weights, posts already shown by any source are dropped, and
+// an optional author window spreads out prolific accounts. Pages are fetched from each source only as needed.
+//
+// A FeedMerger is not safe for concurrent use.
+type FeedMerger struct {
+	options FeedMergerOptions
+	sources []*mergeSource
+	seen    map[string]struct{} // post URIs already returned
+	recent  []string            // authors of the last AuthorWindow items, oldest first
+}
+
+// mergeSource is a FeedSource with its buffered items and scheduling credit
+type mergeSource struct {
+	FeedSource
+	buffer []*FeedItem
+	credit float64
+}
+
+// NewFeedMerger creates a FeedMerger over sources. Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	merger := firefly.NewFeedMerger(&firefly.FeedMergerOptions{AuthorWindow: 5},
+//	    &firefly.FeedSource{Name: "timeline", Pager: client.TimelinePager(), Weight: 3},
+//	    &firefly.FeedSource{Name: "hot", Pager: client.FeedPager(whatsHotURI)},
+//	)
+//	items, err := merger.Next(ctx, 30)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, merged := range items {
+//	    fmt.Printf("[%s] %s\n", merged.Source, merged.Item.Post.Text)
+//	}
+func NewFeedMerger(options *FeedMergerOptions, sources ...*FeedSource) *FeedMerger {
+	if options == nil {
+		options = &FeedMergerOptions{}
+	}
+	opts := *options
+	opts.AuthorWindow = max(opts.AuthorWindow, 0)
+
+	m := &FeedMerger{options: opts, seen: make(map[string]struct{})}
+	for _, source := range sources {
+		if source == nil || source.Pager == nil {
+			continue
+		}
+		merged := &mergeSource{FeedSource: *source}
+		if merged.Weight <= 0 {
+			merged.Weight = 1
+		}
+		m.sources = append(m.sources, merged)
+	}
+	return m
+}
+
+// Next returns up to n more items. It returns fewer once every source has run out, and an error as soon as a
+// source fails to fetch; the items chosen before the failure are returned with it.
+func (m *FeedMerger) Next(ctx context.Context, n int) ([]*MergedItem, error) {
+	var items []*MergedItem
+	for len(items) < n {
+		item, err := m.next(ctx)
+		if err != nil {
+			return items, err
+		}
+		if item == nil {
+			break
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// All iterates over the merged feed until every source runs out. Iteration stops after yielding the first
+// error.
+func (m *FeedMerger) All(ctx context.Context) iter.Seq2[*MergedItem, error] {
+	return func(yield func(*MergedItem, error) bool) {
+		for {
+			item, err := m.next(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if item == nil || !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// next chooses the next item, or returns nil when every source has run out
+func (m *FeedMerger) next(ctx context.Context) (*MergedItem, error) {
+	for {
+		active := make([]*mergeSource, 0, len(m.sources))
+		for _, source := range m.sources {
+			if err := m.fill(ctx, source); err != nil {
+				return nil, fmt.Errorf("feed source %q: %w", source.Name, err)
+			}
+			if len(source.buffer) > 0 {
+				active = append(active, source)
+			}
+		}
+		if len(active) == 0 {
+			return nil, nil
+		}
+
+		// Smooth weighted round robin: every source earns its weight, and the richest one is charged the total
+		total := 0.0
+		for _, source := range active {
+			source.credit += source.Weight
+			total += source.Weight
+		}
+		slices.SortStableFunc(active, func(a, b *mergeSource) int {
+			switch {
+			case a.credit > b.credit:
+				return -1
+			case a.credit < b.credit:
+				return 1
+			default:
+				return 0
+			}
+		})
+		chosen := active[0]
+		chosen.credit -= total
+
+		// Prefer the chosen source, then the others, and only repeat a recent author if nothing else is ready
+		for _, source := range active {
+			if item := m.take(source, true); item != nil {
+				return item, nil
+			}
+		}
+		if item := m.take(chosen, false); item != nil {
+			return item, nil
+		}
+	}
+}
+
+// fill drops already-seen posts from a source's buffer and fetches pages until an unseen post is buffered or
+// the source runs out
+func (m *FeedMerger) fill(ctx context.Context, source *mergeSource) error {
+	for {
+		source.buffer = slices.DeleteFunc(source.buffer, func(item *FeedItem) bool {
+			return item == nil || item.Post == nil || m.isSeen(item)
+		})
+		if len(source.buffer) > 0 || source.Pager.Done() {
+			return nil
+		}
+		page, err := source.Pager.Next(ctx)
+		if err != nil {
+			return err
+		}
+		source.buffer = append(source.buffer, page...)
+	}
+}
+
+// take removes and returns the first buffered item of a source, skipping authors inside the window when
+// diverse is set. It returns nil if no item qualifies.
+func (m *FeedMerger) take(source *mergeSource, diverse bool) *MergedItem {
+	for i, item := range source.buffer {
+		if diverse && slices.Contains(m.recent, itemAuthor(item)) {
+			continue
+		}
+		source.buffer = slices.Delete(source.buffer, i, i+1)
+		m.seen[item.Post.URI] = struct{}{}
+		if m.options.AuthorWindow > 0 {
+			m.recent = append(m.recent, itemAuthor(item))
+			if len(m.recent) > m.options.AuthorWindow {
+				m.recent = m.recent[1:]
+			}
+		}
+		return &MergedItem{Item: item, Source: source.Name}
+	}
+	return nil
+}
+
+// isSeen reports whether a post has already been returned
+func (m *FeedMerger) isSeen(item *FeedItem) bool {
+	_, ok := m.seen[item.Post.URI]
+	return ok
+}
+
+// itemAuthor returns the DID of the author of an item's post
+func itemAuthor(item *FeedItem) string {
+	if item.Post.Author == nil {
+		return ""
+	}
+	return item.Post.Author.Did
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bluesky-social/indigo/api/atproto"
@@ -13,15 +15,21 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-const defaultBskyServer = "https://bsky.social"
+const (
+	defaultBskyServer    = "https://bsky.social"
+	defaultRefreshMargin = time.Minute
+	defaultClockSkew     = time.Minute
+)
 
 var (
 	ErrBadLogin           = errors.New("bad login credentials")
 	ErrBadServer          = errors.New("could not verify server")
 	ErrFailedFetch        = errors.New("failed to fetch data")
 	ErrBadResponse        = errors.New("bad response from server")
-	ErrBadSessionDuration = errors.New("session duration is less than 60 seconds")
+	ErrBadSessionDuration = errors.New("session token is already expired")
 	ErrFailedRefresh      = errors.New("failed to refresh session")
+	ErrNotLoggedIn        = errors.New("not logged in")
+	ErrClientClosed       = errors.New("client closed")
 )
 
 // Firefly provides a simplified client for BlueSky/AtProto with automatic session management.
@@ -29,13 +37,48 @@ var (
 // for common BlueSky operations like searching posts and fetching notifications.
 type Firefly struct {
 	client            *xrpc.Client
+	api               *apiClient
+	authMu            sync.RWMutex // guards client.Auth, which is swapped on every refresh
+	refreshMu         sync.Mutex   // serializes refreshes and guards cancelRefresh
 	sessionExpiration time.Time
+	refreshMargin     time.Duration
+	clockSkew         time.Duration
+	requestTimeout    time.Duration
+	breaker           *circuitBreaker
+	writes            *writeQueue     // nil unless WithWriteQueue is used
+	duplicates        *duplicateGuard // nil unless WithDuplicateGuard is used
+	validateRecords   bool            // set by WithRecordValidation
+	backoff           BackoffPolicy
+	identities        *identityCache
+	profiles          *profileCache // nil unless WithProfileCache is used
+	flights           readFlights
+	debug             *debugLogger
 	cancelRefresh     context.CancelFunc
+	droppedEvents     atomic.Uint64
+	firehoseCursor    atomic.Int64 // time_us of the last firehose event read, for Snapshot
+
+	schedulersMu sync.Mutex
+	schedulers   []*PostScheduler // every scheduler made by NewPostScheduler, in order, for Snapshot
+
+	// defaultCollections are the firehose collections used when FirehoseOptions.Collections is empty
+	defaultCollections []string
 
-	// ErrorChan receives errors from background operations like token refresh.
+	// lifetime is cancelled by Close to stop every background goroutine started by this client
+	lifetime   context.Context
+	shutdown   context.CancelFunc
+	background sync.WaitGroup
+	closeOnce  sync.Once
+	eventsMu   sync.RWMutex
+	closed     bool
+
+	// Events receives structured reports from background operations like token refresh and the firehose.
 	// Users should monitor this channel to handle authentication failures.
-	// The channel is buffered to prevent blocking if errors are not immediately read.
-	ErrorChan chan error
+	// The channel is buffered; if it fills up, new events are dropped and counted in DroppedEvents.
+	Events chan *BackgroundEvent
+
+	// OnEvent is an optional callback invoked synchronously for every background event before it is
+	// sent on Events. It must not block.
+	OnEvent func(*BackgroundEvent)
 
 	// Self contains the authenticated user's profile information, populated after Login().
 	Self *User
@@ -50,34 +93,52 @@ type Firefly struct {
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
-func NewDefaultInstance(ctx context.Context) (*Firefly, error) {
-	return NewCustomInstance(ctx, defaultBskyServer, new(http.Client))
+func NewDefaultInstance(ctx context.Context, opts ...Option) (*Firefly, error) {
+	return NewCustomInstance(ctx, defaultBskyServer, new(http.Client), opts...)
 }
 
 // NewCustomInstance creates a new Firefly client with custom configuration.
 // This allows you to specify a different AtProto server, custom HTTP client, or context.
 // The server parameter should be a full URL (e.g., "https://bsky.social").
+// Optional settings such as WithUserAgent or WithRefreshMargin can be passed as trailing options.
 // Returns an error if the server cannot be reached or verified.
 //
 // Example:
 //
 //	ctx := context.WithTimeout(context.Background(), 30*time.Second)
 //	client := &http.Client{Timeout: 10 * time.Second}
-//	firefly, err := firefly.NewCustomInstance(ctx, "https://bsky.social", client)
-func NewCustomInstance(ctx context.Context, server string, client *http.Client) (*Firefly, error) {
+//	firefly, err := firefly.NewCustomInstance(ctx, "https://bsky.social", client,
+//	    firefly.WithUserAgent("my-bot/1.0 (+https://example.com/bot)"))
+func NewCustomInstance(ctx context.Context, server string, client *http.Client, opts ...Option) (*Firefly, error) {
 	local := &xrpc.Client{
 		Client: client,
 		Host:   server,
 	}
-	if _, err := atproto.ServerDescribeServer(ctx, local); err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrBadServer, err)
-	}
 
-	return &Firefly{
+	lifetime, shutdown := context.WithCancel(context.Background())
+	f := &Firefly{
 		client:        local,
-		ErrorChan:     make(chan error, 10), // Buffered to prevent blocking
+		Events:        make(chan *BackgroundEvent, 100), // Buffered to prevent blocking
 		cancelRefresh: nil,
-	}, nil
+		lifetime:      lifetime,
+		shutdown:      shutdown,
+		refreshMargin: defaultRefreshMargin,
+		clockSkew:     defaultClockSkew,
+		identities:    newIdentityCache(identityCacheTTL),
+	}
+	f.api = &apiClient{f: f}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.debug != nil {
+		f.client.Client = f.debug.wrap(f.client.Client)
+	}
+
+	if _, err := atproto.ServerDescribeServer(ctx, f.api); err != nil {
+		shutdown()
+		return nil, fmt.Errorf("%w: %w", ErrBadServer, err)
+	}
+	return f, nil
 }
 
 // Login authenticates with BlueSky using username (handle) and password.
@@ -92,42 +153,46 @@ func NewCustomInstance(ctx context.Context, server string, client *http.Client)
 //	}
 //	fmt.Printf("Logged in as: %s\n", client.Self.DisplayName)
 func (f *Firefly) Login(ctx context.Context, username string, password string) error {
-	if _, err := atproto.ServerDescribeServer(ctx, f.client); err != nil {
+	if _, err := atproto.ServerDescribeServer(ctx, f.api); err != nil {
 		return fmt.Errorf("%w: %w", ErrBadServer, err)
 	}
 	authInput := atproto.ServerCreateSession_Input{
 		Identifier: username,
 		Password:   password,
 	}
-	authOutput, err := atproto.ServerCreateSession(ctx, f.client, &authInput)
+	authOutput, err := atproto.ServerCreateSession(ctx, f.api, &authInput)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrBadLogin, err)
 	}
 
-	authToken, _, err := jwt.NewParser().ParseUnverified(authOutput.AccessJwt, jwt.MapClaims{})
-	if authToken == nil || (err != nil && !errors.Is(err, jwt.ErrTokenUnverifiable)) {
-		return fmt.Errorf("%w: %w", ErrBadResponse, err)
-	}
-	expDate, err := authToken.Claims.GetExpirationTime()
-	if expDate == nil || err != nil {
+	return f.startSession(ctx, authOutput.AccessJwt, authOutput.RefreshJwt, authOutput.Handle, authOutput.Did)
+}
+
+// startSession installs a newly created session, schedules its refresh and loads Self
+func (f *Firefly) startSession(ctx context.Context, accessJwt string, refreshJwt string, handle string, did string) error {
+	expiration, err := f.sessionExpiryFromToken(accessJwt)
+	if err != nil {
 		return fmt.Errorf("%w: %w", ErrBadResponse, err)
 	}
-
-	f.sessionExpiration = expDate.Time
-	if f.sessionExpiration.Sub(time.Now()).Seconds() < 60 {
+	if !time.Now().Before(expiration) {
 		return ErrBadSessionDuration
 	}
 
-	f.client.Auth = &xrpc.AuthInfo{
-		AccessJwt:  authOutput.AccessJwt,
-		RefreshJwt: authOutput.RefreshJwt,
-		Handle:     authOutput.Handle,
-		Did:        authOutput.Did,
+	f.refreshMu.Lock()
+	f.sessionExpiration = expiration
+	f.setAuth(&xrpc.AuthInfo{
+		AccessJwt:  accessJwt,
+		RefreshJwt: refreshJwt,
+		Handle:     handle,
+		Did:        did,
+	})
+	if f.cancelRefresh != nil {
+		f.cancelRefresh()
 	}
-
 	f.scheduleSessionRefresh()
+	f.refreshMu.Unlock()
 
-	profile, err := bsky.ActorGetProfile(ctx, f.client, authOutput.Handle)
+	profile, err := bsky.ActorGetProfile(ctx, f.api, handle)
 	if err == nil {
 		selfUser, err := OldToNewDetailedUser(profile)
 		if err == nil {
@@ -138,42 +203,102 @@ func (f *Firefly) Login(ctx context.Context, username string, password string) e
 	return nil
 }
 
+// sessionExpiryFromToken parses an access JWT and returns the local time at which it expires. If the token
+// was issued within clockSkew of the local clock, the expiry is derived from the token's own lifetime
+// (exp - iat) so that a slightly wrong local clock neither shortens nor extends the session.
+func (f *Firefly) sessionExpiryFromToken(accessJwt string) (time.Time, error) {
+	authToken, _, err := jwt.NewParser().ParseUnverified(accessJwt, jwt.MapClaims{})
+	if authToken == nil || (err != nil && !errors.Is(err, jwt.ErrTokenUnverifiable)) {
+		return time.Time{}, err
+	}
+	expDate, err := authToken.Claims.GetExpirationTime()
+	if expDate == nil || err != nil {
+		return time.Time{}, fmt.Errorf("missing expiration: %w", err)
+	}
+
+	now := time.Now()
+	issuedAt, err := authToken.Claims.GetIssuedAt()
+	if err == nil && issuedAt != nil {
+		skew := issuedAt.Sub(now)
+		if skew.Abs() <= f.clockSkew {
+			return now.Add(expDate.Sub(issuedAt.Time)), nil
+		}
+	}
+	return expDate.Time, nil
+}
+
+// refreshDelay returns how long to wait before the next scheduled refresh. Tokens that live for less than
+// twice the refresh margin are refreshed halfway through their lifetime instead.
+func (f *Firefly) refreshDelay() time.Duration {
+	remaining := time.Until(f.sessionExpiration)
+	if remaining < 2*f.refreshMargin {
+		return remaining / 2
+	}
+	return remaining - f.refreshMargin
+}
+
 // updateSession refreshes the session tokens, updates expiration time, and checks the session duration for validity.
 func (f *Firefly) updateSession(ctx context.Context) error {
-	authOutput, err := atproto.ServerRefreshSession(ctx, f.client)
+	authOutput, err := atproto.ServerRefreshSession(ctx, f.refreshClient())
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrFailedRefresh, err)
 	}
 
-	authToken, _, err := jwt.NewParser().ParseUnverified(authOutput.AccessJwt, jwt.MapClaims{})
-	if authToken == nil || (err != nil && !errors.Is(err, jwt.ErrTokenUnverifiable)) {
-		return fmt.Errorf("%w: %w", ErrFailedRefresh, err)
-	}
-	expDate, err := authToken.Claims.GetExpirationTime()
-	if expDate == nil || err != nil {
+	expiration, err := f.sessionExpiryFromToken(authOutput.AccessJwt)
+	if err != nil {
 		return fmt.Errorf("%w: %w", ErrFailedRefresh, err)
 	}
-
-	f.sessionExpiration = expDate.Time
-	if f.sessionExpiration.Sub(time.Now()).Seconds() < 60 {
+	if !time.Now().Before(expiration) {
 		return ErrBadSessionDuration
 	}
+	f.sessionExpiration = expiration
 
-	f.client.Auth = &xrpc.AuthInfo{
+	f.setAuth(&xrpc.AuthInfo{
 		AccessJwt:  authOutput.AccessJwt,
 		RefreshJwt: authOutput.RefreshJwt,
 		Handle:     authOutput.Handle,
 		Did:        authOutput.Did,
+	})
+
+	return nil
+}
+
+// syncRefresh refreshes the session unless another caller already did so. staleAccessJwt is the token the
+// caller saw rejected; if the current token differs, the refresh already happened and nothing is done.
+// Pass an empty string to force a refresh. On success, the next scheduled refresh is rearmed.
+func (f *Firefly) syncRefresh(ctx context.Context, staleAccessJwt string) error {
+	f.refreshMu.Lock()
+	defer f.refreshMu.Unlock()
+
+	auth := f.currentClient().Auth
+	if auth == nil {
+		return ErrNotLoggedIn
+	}
+	if staleAccessJwt != "" && auth.AccessJwt != staleAccessJwt {
+		return nil
 	}
 
+	if f.cancelRefresh != nil {
+		f.cancelRefresh()
+		f.cancelRefresh = nil
+	}
+	if err := f.updateSession(ctx); err != nil {
+		return err
+	}
+	f.scheduleSessionRefresh()
 	return nil
 }
 
-// scheduleSessionRefresh schedules Firefly to refresh the session token 1 minute before expiration
+// scheduleSessionRefresh schedules Firefly to refresh the session token shortly before expiration.
+// Callers must hold refreshMu.
 func (f *Firefly) scheduleSessionRefresh() {
-	refreshCtx, cancel := context.WithCancel(context.Background())
-	f.cancelRefresh = cancel
-	time.AfterFunc(f.sessionExpiration.Sub(time.Now().Add(time.Minute)), func() {
+	refreshCtx, cancel := context.WithCancel(f.lifetime)
+	var timer *time.Timer
+	f.cancelRefresh = func() {
+		timer.Stop()
+		cancel()
+	}
+	timer = time.AfterFunc(f.refreshDelay(), func() {
 		select {
 		case <-refreshCtx.Done():
 			return
@@ -182,17 +307,8 @@ func (f *Firefly) scheduleSessionRefresh() {
 			ctx, cancelOp := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancelOp()
 
-			err := f.updateSession(ctx)
-			if err != nil {
-				// Non-blocking send to ErrorChan
-				select {
-				case f.ErrorChan <- err:
-				default:
-					// Channel is full, error is dropped to prevent blocking the goroutine
-				}
-				f.cancelRefresh = nil
-			} else {
-				f.scheduleSessionRefresh()
+			if err := f.syncRefresh(ctx, ""); err != nil {
+				f.emit(SourceSessionRefresh, SeverityError, err)
 			}
 		}
 	})
@@ -200,25 +316,106 @@ func (f *Firefly) scheduleSessionRefresh() {
 
 // RefreshSession manually refreshes the authentication token before its scheduled expiration.
 // This cancels any existing refresh timer and schedules a new one.
-// Any errors during refresh are sent to ErrorChan rather than returned.
+// Any errors during refresh are sent to Events rather than returned.
 //
 // This is typically not needed as Firefly handles token refresh automatically,
 // but can be useful if you suspect the token is invalid or want to refresh proactively.
 func (f *Firefly) RefreshSession(ctx context.Context) {
+	if err := f.syncRefresh(ctx, ""); err != nil {
+		f.emit(SourceSessionRefresh, SeverityError, err)
+	}
+}
+
+// Logout invalidates the session on the server using com.atproto.server.deleteSession, stops automatic
+// token refresh, and clears the stored credentials. The client can be logged in again afterwards.
+func (f *Firefly) Logout(ctx context.Context) error {
+	if f.currentClient().Auth == nil {
+		return ErrNotLoggedIn
+	}
+	f.refreshMu.Lock()
+	defer f.refreshMu.Unlock()
 	if f.cancelRefresh != nil {
 		f.cancelRefresh()
+		f.cancelRefresh = nil
 	}
-	err := f.updateSession(ctx)
-	if err != nil {
-		// Non-blocking send to ErrorChan
-		select {
-		case f.ErrorChan <- err:
-		default:
-			// Channel is full, error is dropped
+
+	// deleteSession authenticates with the refresh token rather than the access token
+	if err := atproto.ServerDeleteSession(ctx, f.refreshClient()); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	f.setAuth(nil)
+	f.sessionExpiration = time.Time{}
+	f.Self = nil
+	return nil
+}
+
+// Close stops the session refresh timer and all firehose streams and background trackers started by this
+// client, waits for them to exit, and closes Events. It does not invalidate the session on the server;
+// call Logout first for that. Close is safe to call more than once.
+func (f *Firefly) Close() error {
+	f.closeOnce.Do(func() {
+		f.refreshMu.Lock()
+		if f.cancelRefresh != nil {
+			f.cancelRefresh()
+			f.cancelRefresh = nil
+		}
+		f.refreshMu.Unlock()
+		f.shutdown()
+		f.background.Wait()
+
+		f.eventsMu.Lock()
+		f.closed = true
+		close(f.Events)
+		f.eventsMu.Unlock()
+	})
+	return nil
+}
+
+// refreshClient returns a copy of the XRPC client that authenticates with the refresh token,
+// as required by refreshSession and deleteSession
+func (f *Firefly) refreshClient() *xrpc.Client {
+	local := f.currentClient()
+	if local.Auth != nil {
+		local.Auth = &xrpc.AuthInfo{
+			AccessJwt:  local.Auth.RefreshJwt,
+			RefreshJwt: local.Auth.RefreshJwt,
+			Handle:     local.Auth.Handle,
+			Did:        local.Auth.Did,
 		}
-		f.cancelRefresh = nil
-	} else {
-		f.scheduleSessionRefresh()
 	}
-	return
+	return local
+}
+
+// currentClient returns a snapshot of the XRPC client with the current credentials, so a request in flight
+// is not affected by a concurrent refresh
+func (f *Firefly) currentClient() *xrpc.Client {
+	f.authMu.RLock()
+	defer f.authMu.RUnlock()
+	local := *f.client
+	return &local
+}
+
+// setAuth replaces the session credentials
+func (f *Firefly) setAuth(auth *xrpc.AuthInfo) {
+	f.authMu.Lock()
+	defer f.authMu.Unlock()
+	f.client.Auth = auth
+}
+
+// bindLifetime derives a context that is also cancelled when the client is closed
+func (f *Firefly) bindLifetime(ctx context.Context) (context.Context, context.CancelFunc) {
+	bound, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(f.lifetime, cancel)
+	return bound, func() {
+		stop()
+		cancel()
+	}
+}
+
+// isClosed reports whether Close has been called
+func (f *Firefly) isClosed() bool {
+	f.eventsMu.RLock()
+	defer f.eventsMu.RUnlock()
+	return f.closed
 }
@@ -2,12 +2,14 @@ package firefly
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bluesky-social/jetstream/pkg/models"
@@ -18,6 +20,7 @@ var (
 	ErrFirehoseFailed     = errors.New("firehose connection failed")
 	ErrFirehoseDisconnect = errors.New("firehose disconnected")
 	ErrInvalidEvent       = errors.New("invalid firehose event")
+	ErrFirehoseKeepalive  = errors.New("firehose keepalive failed")
 )
 
 // FirehoseEventType identifies the type of activity in a firehose event
@@ -58,6 +61,49 @@ func (et FirehoseEventType) String() string {
 	}
 }
 
+// firehoseEventTypeNames are the stable names used when serializing a FirehoseEventType
+var firehoseEventTypeNames = enumNames[FirehoseEventType]{
+	EventTypeUnknown:  "unknown",
+	EventTypePost:     "post",
+	EventTypeLike:     "like",
+	EventTypeFollow:   "follow",
+	EventTypeProfile:  "profile",
+	EventTypeDelete:   "delete",
+	EventTypeRepost:   "repost",
+	EventTypeIdentity: "identity",
+	EventTypeAccount:  "account",
+}
+
+// MarshalText encodes the firehose event type as its stable name
+func (et FirehoseEventType) MarshalText() ([]byte, error) {
+	return firehoseEventTypeNames.text(et), nil
+}
+
+// UnmarshalText decodes a firehose event type from its name or integer value
+func (et *FirehoseEventType) UnmarshalText(data []byte) error {
+	value, err := firehoseEventTypeNames.parseText(data, "firehose event type")
+	if err != nil {
+		return err
+	}
+	*et = value
+	return nil
+}
+
+// MarshalJSON encodes the firehose event type as a JSON string
+func (et FirehoseEventType) MarshalJSON() ([]byte, error) {
+	return firehoseEventTypeNames.json(et)
+}
+
+// UnmarshalJSON decodes a firehose event type from its name, or from the integer value used by older versions
+func (et *FirehoseEventType) UnmarshalJSON(data []byte) error {
+	value, err := firehoseEventTypeNames.parseJSON(data, "firehose event type")
+	if err != nil {
+		return err
+	}
+	*et = value
+	return nil
+}
+
 // FirehoseEvent represents a simplified firehose event using existing Firefly types
 type FirehoseEvent struct {
 	Type      FirehoseEventType `json:"type"`
@@ -73,6 +119,9 @@ type FirehoseEvent struct {
 	RepostEvent   *FirehoseRepost   `json:"repostEvent,omitempty"` // For reposts
 	IdentityEvent *FirehoseIdentity `json:"identity,omitempty"`    // For identity updates
 	AccountEvent  *FirehoseAccount  `json:"account,omitempty"`     // For account status changes
+
+	// Classification holds the output of FirehoseOptions.Classifiers for post events; nil if none ran
+	Classification *Classification `json:"classification,omitempty"`
 	// Raw Jetstream data preservation
 	RawCommit *models.Event
 }
@@ -116,17 +165,169 @@ type FirehoseAccount struct {
 // FirehoseOptions configures Firehose filtering and behavior
 type FirehoseOptions struct {
 	URL          *string  `json:"URL,omitempty"`          // URL of Jetstream or nil for random
-	Collections  []string `json:"collections,omitempty"`  // Filter by collection types (max 100)
+	Collections  []string `json:"collections,omitempty"`  // Filter by collection NSIDs or prefixes like "app.bsky.graph.*" (max 100); CollectionAll for every collection
 	Authors      []string `json:"authors,omitempty"`      // Filter by author DIDs/handles (max 10,000)
 	Cursor       *int64   `json:"cursor,omitempty"`       // Resume from Unix microsecond timestamp
 	BufferSize   int      `json:"bufferSize,omitempty"`   // Channel buffer size (default 1000)
 	Compression  bool     `json:"compression,omitempty"`  // Enable zstd compression
 	RequireHello bool     `json:"requireHello,omitempty"` // Pause until initial config
+
+	// Jetstream always sends identity and account events alongside commits; these flags drop
+	// event kinds before they are processed. Set ExcludeCommits to receive only identity/account events.
+	ExcludeCommits  bool `json:"excludeCommits,omitempty"`  // Drop record commits (posts, likes, ...)
+	ExcludeIdentity bool `json:"excludeIdentity,omitempty"` // Drop identity (handle change) events
+	ExcludeAccount  bool `json:"excludeAccount,omitempty"`  // Drop account status events
+
+	// Connection tuning; zero values use the defaults
+	PingInterval     time.Duration `json:"pingInterval,omitempty"`     // Time between keepalive pings (default 1 minute)
+	PongTimeout      time.Duration `json:"pongTimeout,omitempty"`      // Reconnect if no pong arrives within this time (default 5 minutes)
+	HandshakeTimeout time.Duration `json:"handshakeTimeout,omitempty"` // WebSocket handshake timeout (default 10 seconds)
+	MaxMessageSize   int64         `json:"maxMessageSize,omitempty"`   // Maximum frame size in bytes (default unlimited)
+
+	// Sinks receive every event before it is sent on the channel, even if the channel is full.
+	// Write errors are reported as SourceFirehose warnings and don't stop the stream.
+	Sinks []EventSink `json:"-"`
+
+	// PostIndexer is called with the indexable fields of every new or updated post, decoded straight from the
+	// record without building a FeedPost, for feeding external search indexes. With IndexOnly, posts are only
+	// indexed: they aren't converted, written to Sinks or sent on the channel, while other events are delivered
+	// as usual.
+	PostIndexer PostIndexFunc `json:"-"`
+	IndexOnly   bool          `json:"indexOnly,omitempty"`
+
+	// ResolveHandles fills in the empty handles of profile, follow and identity events before they are
+	// delivered. Handles are fetched from the AppView 25 accounts a request and cached for an hour, but
+	// uncached DIDs still cost API calls, so leave it off for high-volume streams that don't need handles.
+	ResolveHandles bool `json:"resolveHandles,omitempty"`
+
+	// Classifiers run on every post event, in order, and their combined output is attached to
+	// FirehoseEvent.Classification before the event is written to Sinks or sent on the channel. They run on
+	// the connection's read loop, so slow classifiers hold up the stream.
+	Classifiers []Classifier `json:"-"`
+
+	// Transformers run on every event, in order, after Classifiers and before the event is written to Sinks or
+	// sent on the channel. Each one can modify, replace or drop the event. Like Classifiers, they run on the
+	// connection's read loop.
+	Transformers []EventTransformer `json:"-"`
+
+	// Sampling thins the stream before records are converted, for statistics jobs that only need a
+	// representative fraction of the firehose. Zero values keep every event.
+	SampleRate         float64 `json:"sampleRate,omitempty"`         // Fraction of events to keep, between 0 and 1
+	SampleByRepo       bool    `json:"sampleByRepo,omitempty"`       // Keep every event from a fixed subset of accounts (chosen by DID hash) instead of random events
+	MaxEventsPerSecond int     `json:"maxEventsPerSecond,omitempty"` // Drop events that arrive faster than this rate
+
+	// sampler applies SampleRate and MaxEventsPerSecond for the current stream
+	sampler *firehoseSampler
+
+	// replay makes the stream lossless for ReplayEvents: sends block instead of dropping events, and Cursor
+	// advances with each delivered event so a reconnect resumes where it left off
+	replay bool
+
+	// monitor tracks connection state and throughput for OpenFirehose; nil for plain streams
+	monitor *firehoseMonitor
+
+	// live holds a DID filter that can change while connected (used by StreamMyNetwork)
+	live *liveFilter
+
+	// capture receives every raw frame instead of it being processed (used by CaptureEvents)
+	capture func(frame []byte)
+}
+
+// liveFilter is a wantedDids list that is pushed to Jetstream with options_update messages when it changes
+type liveFilter struct {
+	mu      sync.Mutex
+	dids    []string
+	changed chan struct{}
+}
+
+func newLiveFilter(dids []string) *liveFilter {
+	return &liveFilter{dids: dids, changed: make(chan struct{}, 1)}
+}
+
+// set replaces the DID list and signals the active connection
+func (lf *liveFilter) set(dids []string) {
+	lf.mu.Lock()
+	lf.dids = dids
+	lf.mu.Unlock()
+	select {
+	case lf.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (lf *liveFilter) get() []string {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.dids
+}
+
+// changes returns the change signal, or nil (which blocks forever) when there is no live filter
+func (lf *liveFilter) changes() <-chan struct{} {
+	if lf == nil {
+		return nil
+	}
+	return lf.changed
+}
+
+// jetstreamOptionsUpdate is a subscriber-sourced message that replaces the connection's filters
+type jetstreamOptionsUpdate struct {
+	Type    string                        `json:"type"`
+	Payload jetstreamOptionsUpdatePayload `json:"payload"`
+}
+
+type jetstreamOptionsUpdatePayload struct {
+	WantedCollections   []string `json:"wantedCollections"`
+	WantedDids          []string `json:"wantedDids"`
+	MaxMessageSizeBytes int      `json:"maxMessageSizeBytes"`
+}
+
+// sendOptionsUpdate pushes the current live filter to Jetstream
+func sendOptionsUpdate(conn *websocket.Conn, options *FirehoseOptions) error {
+	update := jetstreamOptionsUpdate{
+		Type: "options_update",
+		Payload: jetstreamOptionsUpdatePayload{
+			WantedCollections:   wantedCollections(options.Collections),
+			WantedDids:          truncate(options.live.get(), maxWantedDids),
+			MaxMessageSizeBytes: int(options.MaxMessageSize),
+		},
+	}
+	conn.SetWriteDeadline(time.Now().Add(options.HandshakeTimeout))
+	return conn.WriteJSON(update)
+}
+
+// Jetstream's filter limits
+const (
+	maxWantedCollections = 100
+	maxWantedDids        = 10000
+)
+
+func truncate(values []string, limit int) []string {
+	if len(values) > limit {
+		return values[:limit]
+	}
+	return values
+}
+
+// wantsKind reports whether events of the given Jetstream kind should be delivered
+func (o *FirehoseOptions) wantsKind(kind string) bool {
+	switch kind {
+	case models.EventKindCommit:
+		return !o.ExcludeCommits
+	case models.EventKindIdentity:
+		return !o.ExcludeIdentity
+	case models.EventKindAccount:
+		return !o.ExcludeAccount
+	default:
+		return true
+	}
 }
 
 // StreamEvents opens a Firehose connection with advanced filtering options
 // Uses options struct for complex configuration following Firefly's API patterns
 func (f *Firefly) StreamEvents(ctx context.Context, options *FirehoseOptions) (chan *FirehoseEvent, error) {
+	if f.isClosed() {
+		return nil, ErrClientClosed
+	}
 	if options == nil {
 		options = &FirehoseOptions{}
 	}
@@ -135,34 +336,65 @@ func (f *Firefly) StreamEvents(ctx context.Context, options *FirehoseOptions) (c
 	if options.BufferSize <= 0 {
 		options.BufferSize = 1000
 	}
+	if options.PingInterval <= 0 {
+		options.PingInterval = time.Minute
+	}
+	if options.PongTimeout <= 0 {
+		options.PongTimeout = time.Minute * 5
+	}
+	if options.HandshakeTimeout <= 0 {
+		options.HandshakeTimeout = 10 * time.Second
+	}
 
-	// If no collections are specified, default to the main content types
-	// This prevents getting flooded with account/identity events
+	// If no collections are specified, default to the client's default set, which is the main content types
+	// unless WithDefaultCollections changed it. This prevents getting flooded with every record type.
 	if len(options.Collections) == 0 {
-		options.Collections = []string{
-			"app.bsky.feed.post",
-			"app.bsky.feed.like",
-			"app.bsky.feed.repost",
-			"app.bsky.graph.follow",
+		options.Collections = slices.Clone(f.defaultCollections)
+		if len(options.Collections) == 0 {
+			options.Collections = slices.Clone(defaultFirehoseCollections)
 		}
 	}
+	if err := ValidateCollections(options.Collections); err != nil {
+		return nil, err
+	}
+
+	options.sampler = newFirehoseSampler(options)
 
 	// Create buffered channel for events
 	events := make(chan *FirehoseEvent, options.BufferSize)
 
-	// Start background goroutine to manage connection
+	// With ResolveHandles, events pass through the enrichment stage on their way to the caller
+	source := events
+	if options.ResolveHandles {
+		source = make(chan *FirehoseEvent, options.BufferSize)
+	}
+
+	// Start background goroutine to manage connection; it also stops when the client is closed
+	ctx, cancel := f.bindLifetime(ctx)
+	f.background.Add(1)
 	go func() {
-		defer close(events)
-		f.maintainFirehoseConnection(ctx, options, events)
+		defer f.background.Done()
+		defer cancel()
+		defer close(source)
+		f.maintainFirehoseConnection(ctx, options, source)
 	}()
 
+	if options.ResolveHandles {
+		f.background.Add(1)
+		go func() {
+			defer f.background.Done()
+			defer close(events)
+			f.enrichHandles(ctx, source, events)
+		}()
+	}
+
 	return events, nil
 }
 
 // maintainFirehoseConnection handles connection lifecycle with reconnection logic
 func (f *Firefly) maintainFirehoseConnection(ctx context.Context, options *FirehoseOptions, events chan<- *FirehoseEvent) {
-	backoff := time.Second
-	maxBackoff := time.Minute * 2
+	retries := backoff{policy: f.backoffPolicy()}
+	defer options.monitor.closed()
 
 	for {
 		select {
@@ -170,27 +402,23 @@ func (f *Firefly) maintainFirehoseConnection(ctx context.Context, options *Fireh
 			return
 		default:
 			err := f.connectFirehose(ctx, options, events)
+			if ctx.Err() == nil {
+				options.monitor.disconnected(err)
+			}
 			if err != nil {
-				// Send error to ErrorChan following Firefly's error handling pattern
-				select {
-				case f.ErrorChan <- fmt.Errorf("%w: %w", ErrFirehoseFailed, err):
-				default:
-					// ErrorChan is full, error will be lost but we won't block
-				}
+				// Report as a warning since we'll keep reconnecting
+				f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("%w: %w", ErrFirehoseFailed, err))
 
-				// Exponential backoff
-				select {
-				case <-ctx.Done():
-					return
-				case <-time.After(backoff):
-					if backoff < maxBackoff {
-						backoff *= 2
+				if !retries.wait(ctx) {
+					if ctx.Err() == nil {
+						f.emit(SourceFirehose, SeverityError, fmt.Errorf("%w: giving up after %d reconnects", ErrFirehoseFailed, retries.retries))
 					}
+					return
 				}
 				continue
 			}
 			// Reset backoff on successful connection
-			backoff = time.Second
+			retries.reset()
 		}
 	}
 }
@@ -200,33 +428,61 @@ func (f *Firefly) connectFirehose(ctx context.Context, options *FirehoseOptions,
 	// Build Jetstream WebSocket URL
 	url := f.buildJetstreamURL(options)
 
-	// Setup WebSocket dialer
-	dialer := websocket.DefaultDialer
-	dialer.HandshakeTimeout = 10 * time.Second
+	// Setup WebSocket dialer (copied so the shared default isn't modified)
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = options.HandshakeTimeout
 
 	// Connect to WebSocket
-	conn, _, err := dialer.Dial(url, http.Header{})
+	conn, _, err := dialer.DialContext(ctx, url, http.Header{})
 	if err != nil {
 		return fmt.Errorf("websocket dial failed: %w", err)
 	}
 	defer conn.Close()
+	f.debug.firehoseConnect(url)
+	options.monitor.connected()
+	if options.MaxMessageSize > 0 {
+		conn.SetReadLimit(options.MaxMessageSize)
+	}
+
+	// Unblock ReadMessage as soon as the context is cancelled
+	stopClose := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stopClose()
+
+	// A live filter can be too large for the URL, so it is sent once the connection is open
+	if options.live != nil {
+		if err := sendOptionsUpdate(conn, options); err != nil {
+			return fmt.Errorf("failed to send filter: %w", err)
+		}
+	}
 
 	// Set read deadline for keep-alive
-	conn.SetReadDeadline(time.Now().Add(time.Minute * 5))
+	conn.SetReadDeadline(time.Now().Add(options.PongTimeout))
 	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(time.Minute * 5))
+		conn.SetReadDeadline(time.Now().Add(options.PongTimeout))
 		return nil
 	})
 
-	// Start ping routine for keep-alive
-	pingTicker := time.NewTicker(time.Minute)
+	// Start ping routine for keep-alive; it stops when this connection ends
+	pingTicker := time.NewTicker(options.PingInterval)
 	defer pingTicker.Stop()
+	connDone := make(chan struct{})
+	defer close(connDone)
 
 	go func() {
 		for {
 			select {
 			case <-pingTicker.C:
-				conn.WriteMessage(websocket.PingMessage, []byte{})
+				deadline := time.Now().Add(options.HandshakeTimeout)
+				if err := conn.WriteControl(websocket.PingMessage, []byte{}, deadline); err != nil {
+					f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("%w: ping failed: %w", ErrFirehoseKeepalive, err))
+					return
+				}
+			case <-options.live.changes():
+				if err := sendOptionsUpdate(conn, options); err != nil {
+					f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("%w: failed to update filter: %w", ErrFirehoseFailed, err))
+				}
+			case <-connDone:
+				return
 			case <-ctx.Done():
 				return
 			}
@@ -241,35 +497,91 @@ func (f *Firefly) connectFirehose(ctx context.Context, options *FirehoseOptions,
 		default:
 			_, message, err := conn.ReadMessage()
 			if err != nil {
+				if ctx.Err() != nil {
+					// Closed on purpose
+					return nil
+				}
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					return fmt.Errorf("%w: no pong within %s: %w", ErrFirehoseKeepalive, options.PongTimeout, err)
+				}
 				return fmt.Errorf("%w: %w", ErrFirehoseDisconnect, err)
 			}
+			f.debug.firehoseFrame(message)
+			if options.capture != nil {
+				options.capture(message)
+				continue
+			}
 
 			// Process the message
-			event, err := f.processFirehoseMessage(message)
+			event, err := f.processFirehoseMessage(message, options)
 			if err != nil {
-				// Log error but continue processing
-				select {
-				case f.ErrorChan <- fmt.Errorf("%w: %w", ErrInvalidEvent, err):
-				default:
-				}
+				// Report error but continue processing
+				f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("%w: %w", ErrInvalidEvent, err))
 				continue
 			}
-
 			if event != nil {
-				// Send event to channel (non-blocking)
-				select {
-				case events <- event:
-				case <-ctx.Done():
-					return nil
-				default:
-					// Channel is full, drop the event
-					// Could log this or increment a metric
-				}
+				f.firehoseCursor.Store(event.Sequence)
+			}
+			if event != nil && !f.deliverEvent(ctx, options, events, event) {
+				return nil
 			}
 		}
 	}
 }
 
+// deliverEvent runs a processed event through the classifiers, transformers and sinks and sends it on events.
+// It returns false if ctx was cancelled.
+func (f *Firefly) deliverEvent(ctx context.Context, options *FirehoseOptions, events chan<- *FirehoseEvent, event *FirehoseEvent) bool {
+	f.classifyEvent(ctx, options, event)
+	sequence := event.Sequence
+	if event = f.transformEvent(options, event); event == nil {
+		if options.replay {
+			// Dropped events still count as handled, so a reconnect doesn't replay them
+			resume := sequence + 1
+			options.Cursor = &resume
+		}
+		return true
+	}
+	f.writeToSinks(ctx, options.Sinks, event)
+
+	if options.replay {
+		select {
+		case events <- event:
+			options.monitor.received(event, false)
+			resume := sequence + 1
+			options.Cursor = &resume
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// Send event to channel (non-blocking)
+	select {
+	case events <- event:
+		options.monitor.received(event, false)
+	case <-ctx.Done():
+		return false
+	default:
+		// Channel is full, drop the event
+		options.monitor.received(event, true)
+	}
+	return true
+}
+
+// writeToSinks passes an event to each configured sink, reporting failures as warnings
+func (f *Firefly) writeToSinks(ctx context.Context, sinks []EventSink, event *FirehoseEvent) {
+	for _, sink := range sinks {
+		if err := sink.Write(ctx, event); err != nil {
+			if !errors.Is(err, ErrSinkFailed) {
+				err = fmt.Errorf("%w: %w", ErrSinkFailed, err)
+			}
+			f.emit(SourceFirehose, SeverityWarning, err)
+		}
+	}
+}
+
 // buildJetstreamURL constructs the Jetstream WebSocket URL with query parameters
 func (f *Firefly) buildJetstreamURL(options *FirehoseOptions) string {
 	baseURL := ""
@@ -288,23 +600,16 @@ func (f *Firefly) buildJetstreamURL(options *FirehoseOptions) string {
 
 	var params []string
 
-	if len(options.Collections) > 0 {
-		// Limit to max 100 collections as per Jetstream spec
-		collections := options.Collections
-		if len(collections) > 100 {
-			collections = collections[:100]
-		}
+	if collections := wantedCollections(options.Collections); len(collections) > 0 {
+		// wantedCollections limits the list to 100 collections as per Jetstream spec
 		collectionsString := strings.Join(collections, "&wantedCollections=")
 		collectionsString = strings.TrimSuffix(collectionsString, "&wantedCollections=")
 		params = append(params, "wantedCollections="+collectionsString)
 	}
 
-	if len(options.Authors) > 0 {
+	if len(options.Authors) > 0 && options.live == nil {
 		// Limit to max 10,000 DIDs as per Jetstream spec
-		authors := options.Authors
-		if len(authors) > 10000 {
-			authors = authors[:10000]
-		}
+		authors := truncate(options.Authors, maxWantedDids)
 		authorsString := strings.Join(authors, "&wantedDids=")
 		authorsString = strings.TrimSuffix(authorsString, "&wantedDids=")
 		params = append(params, "wantedDids="+authorsString)
@@ -318,7 +623,7 @@ func (f *Firefly) buildJetstreamURL(options *FirehoseOptions) string {
 		params = append(params, "compress=true")
 	}
 
-	if options.RequireHello {
+	if options.RequireHello || options.live != nil {
 		params = append(params, "requireHello=true")
 	}
 
@@ -329,12 +634,19 @@ func (f *Firefly) buildJetstreamURL(options *FirehoseOptions) string {
 	return baseURL
 }
 
-// processFirehoseMessage converts a raw Jetstream message to a FirehoseEvent
-func (f *Firefly) processFirehoseMessage(message []byte) (*FirehoseEvent, error) {
+// processFirehoseMessage converts a raw Jetstream message to a FirehoseEvent.
+// Returns nil without an error if the event kind is excluded or the event is sampled out by options.
+func (f *Firefly) processFirehoseMessage(message []byte, options *FirehoseOptions) (*FirehoseEvent, error) {
 	var rawCommit models.Event
-	if err := json.Unmarshal(message, &rawCommit); err != nil {
+	if err := decodeJetstreamEvent(message, &rawCommit); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal jetstream message: %w", err)
 	}
+	if !options.wantsKind(rawCommit.Kind) || !options.sampler.keep(rawCommit.Did) {
+		return nil, nil
+	}
+	if f.indexPost(&rawCommit, options) && options.IndexOnly {
+		return nil, nil
+	}
 
 	// Convert timestamp from microseconds to time.Time
 	timestamp := time.Unix(0, rawCommit.TimeUS*1000)
@@ -350,11 +662,11 @@ func (f *Firefly) processFirehoseMessage(message []byte) (*FirehoseEvent, error)
 
 	// Process based on event kind
 	switch rawCommit.Kind {
-	case "commit":
+	case models.EventKindCommit:
 		return f.processCommitEvent(event, &rawCommit)
-	case "identity":
+	case models.EventKindIdentity:
 		return f.processIdentityEvent(event, &rawCommit)
-	case "account":
+	case models.EventKindAccount:
 		return f.processAccountEvent(event, &rawCommit)
 	default:
 		// Unknown event type, return as-is
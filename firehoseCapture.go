@@ -0,0 +1,212 @@
+package firefly
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"time"
+)
+
+var (
+	ErrCaptureFailed  = errors.New("firehose capture failed")
+	ErrInvalidCapture = errors.New("invalid firehose capture")
+)
+
+// maxCaptureFrame is the largest frame ReplayFile accepts, guarding against reading a corrupt length
+const maxCaptureFrame = 16 << 20
+
+// CaptureEvents records the raw Jetstream frames of a firehose stream to w until ctx is cancelled or the
+// client is closed, for reproducing parsing bugs and load-testing handlers with ReplayFile. Each frame is
+// written as a 4-byte big-endian length followed by the frame exactly as it arrived. Frames are not decoded,
+// so capturing keeps up with the full firehose; options choose what is captured (Collections, Authors,
+// Cursor and the connection settings) and their processing settings are ignored. Compression is turned off
+// so the frames can be replayed. It returns nil when stopped, or an error wrapping ErrCaptureFailed if
+// writing to w fails.
+//
+// Example:
+//
+//	out, err := os.Create("firehose.capture")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer out.Close()
+//	buffered := bufio.NewWriter(out)
+//	defer buffered.Flush()
+//	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+//	defer cancel()
+//	err = client.CaptureEvents(ctx, buffered, &firefly.FirehoseOptions{
+//	    Collections: []string{firefly.CollectionPost},
+//	})
+func (f *Firefly) CaptureEvents(ctx context.Context, w io.Writer, options *FirehoseOptions) error {
+	if f.isClosed() {
+		return ErrClientClosed
+	}
+	opts := FirehoseOptions{}
+	if options != nil {
+		opts = *options
+	}
+	opts.Compression = false
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// capture runs on the connection's read loop, and writeErr is only read once the stream has closed
+	var writeErr error
+	opts.capture = func(frame []byte) {
+		if writeErr != nil {
+			return
+		}
+		if writeErr = writeFrame(w, frame); writeErr != nil {
+			cancel()
+		}
+	}
+
+	events, err := f.StreamEvents(ctx, &opts)
+	if err != nil {
+		return err
+	}
+	for range events {
+	}
+	if writeErr != nil {
+		return fmt.Errorf("%w: %w", ErrCaptureFailed, writeErr)
+	}
+	return nil
+}
+
+// ReplayFile reads a capture written by CaptureEvents and feeds its frames through the same processing as a
+// live stream: kind exclusions, sampling, post indexing, classifiers, transformers, sinks and handle
+// resolution all apply. The capture's own Collections and Authors filters were applied when it was recorded,
+// so those options are ignored. Events are delivered at the pace they were recorded, divided by speed: 1 is
+// real time, 10 is ten times faster, and 0 or less replays as fast as the consumer reads. Nothing is dropped
+// when the consumer falls behind. The channel is closed at the end of the capture; a corrupt capture is
+// reported to Events as a SourceFirehose error wrapping ErrInvalidCapture. Pass nil for options to use the
+// defaults.
+//
+// Example:
+//
+//	capture, err := os.Open("firehose.capture")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer capture.Close()
+//	events, err := client.ReplayFile(ctx, capture, 0, &firefly.FirehoseOptions{
+//	    Classifiers: []firefly.Classifier{spamModel},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for event := range events {
+//	    handle(event)
+//	}
+func (f *Firefly) ReplayFile(ctx context.Context, r io.Reader, speed float64, options *FirehoseOptions) (chan *FirehoseEvent, error) {
+	if f.isClosed() {
+		return nil, ErrClientClosed
+	}
+	opts := FirehoseOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1000
+	}
+	opts.replay = true
+	opts.sampler = newFirehoseSampler(&opts)
+
+	events := make(chan *FirehoseEvent, opts.BufferSize)
+	source := events
+	if opts.ResolveHandles {
+		source = make(chan *FirehoseEvent, opts.BufferSize)
+	}
+
+	ctx, cancel := f.bindLifetime(ctx)
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		defer cancel()
+		defer close(source)
+
+		var start time.Time
+		var first int64
+		for frame, err := range readFrames(r) {
+			if err != nil {
+				f.emit(SourceFirehose, SeverityError, fmt.Errorf("%w: %w", ErrInvalidCapture, err))
+				return
+			}
+			event, err := f.processFirehoseMessage(frame, &opts)
+			if err != nil {
+				f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("%w: %w", ErrInvalidEvent, err))
+				continue
+			}
+			if event == nil {
+				continue
+			}
+			if speed > 0 {
+				if start.IsZero() {
+					start, first = time.Now(), event.Sequence
+				}
+				offset := time.Duration(float64(event.Sequence-first) * float64(time.Microsecond) / speed)
+				if !sleepUntil(ctx, start.Add(offset)) {
+					return
+				}
+			}
+			if !f.deliverEvent(ctx, &opts, source, event) {
+				return
+			}
+		}
+	}()
+
+	if opts.ResolveHandles {
+		f.background.Add(1)
+		go func() {
+			defer f.background.Done()
+			defer close(events)
+			f.enrichHandles(ctx, source, events)
+		}()
+	}
+
+	return events, nil
+}
+
+// writeFrame writes one length-prefixed frame
+func writeFrame(w io.Writer, frame []byte) error {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(frame)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// readFrames iterates over the length-prefixed frames in r. Iteration stops after yielding the first error;
+// a capture that ends cleanly between frames yields none.
+func readFrames(r io.Reader) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		reader := bufio.NewReader(r)
+		var header [4]byte
+		for frames := 1; ; frames++ {
+			if _, err := io.ReadFull(reader, header[:]); err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, fmt.Errorf("frame %d: %w", frames, err))
+				}
+				return
+			}
+			size := binary.BigEndian.Uint32(header[:])
+			if size > maxCaptureFrame {
+				yield(nil, fmt.Errorf("frame %d: length %d is over %d", frames, size, maxCaptureFrame))
+				return
+			}
+			frame := make([]byte, size)
+			if _, err := io.ReadFull(reader, frame); err != nil {
+				yield(nil, fmt.Errorf("frame %d: %w", frames, err))
+				return
+			}
+			if !yield(frame, nil) {
+				return
+			}
+		}
+	}
+}
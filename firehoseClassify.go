@@ -0,0 +1,115 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Classification is what classifiers concluded about a post, such as its language, topics, or spam score
+type Classification struct {
+	Labels []string           `json:"labels,omitempty"` // labels assigned by any classifier, like "lang:en" or "spam"
+	Scores map[string]float64 `json:"scores,omitempty"` // named scores, like "spam" or "topic:sports"
+}
+
+func (c Classification) String() string {
+	return fmt.Sprintf("Classification{Labels: %v, Scores: %v}", c.Labels, c.Scores)
+}
+
+// HasLabel reports whether any classifier assigned label
+func (c *Classification) HasLabel(label string) bool {
+	return c != nil && slices.Contains(c.Labels, label)
+}
+
+// Score returns the named score and whether any classifier set it
+func (c *Classification) Score(name string) (float64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	score, ok := c.Scores[name]
+	return score, ok
+}
+
+// merge adds another classifier's output; later scores overwrite earlier ones with the same name
+func (c *Classification) merge(other Classification) {
+	for _, label := range other.Labels {
+		if !slices.Contains(c.Labels, label) {
+			c.Labels = append(c.Labels, label)
+		}
+	}
+	if len(other.Scores) > 0 {
+		if c.Scores == nil {
+			c.Scores = make(map[string]float64, len(other.Scores))
+		}
+		maps.Copy(c.Scores, other.Scores)
+	}
+}
+
+// Classifier inspects a post from the stream. Set FirehoseOptions.Classifiers to run classifiers on every post
+// event; their combined output is attached to FirehoseEvent.Classification before the event reaches sinks,
+// gates and handlers, so spam, language or topic models can be plugged in without touching the stream.
+type Classifier interface {
+	Classify(ctx context.Context, post *FeedPost) (Classification, error)
+}
+
+// ClassifierFunc adapts a function to a Classifier
+//
+// Example:
+//
+//	shouting := firefly.ClassifierFunc(func(ctx context.Context, post *firefly.FeedPost) (firefly.Classification, error) {
+//	    if post.Text != "" && post.Text == strings.ToUpper(post.Text) {
+//	        return firefly.Classification{Labels: []string{"shouting"}}, nil
+//	    }
+//	    return firefly.Classification{}, nil
+//	})
+type ClassifierFunc func(ctx context.Context, post *FeedPost) (Classification, error)
+
+func (fn ClassifierFunc) Classify(ctx context.Context, post *FeedPost) (Classification, error) {
+	return fn(ctx, post)
+}
+
+// classifyEvent runs options.Classifiers on a post event. Classifier errors are reported as SourceFirehose
+// warnings, and the other classifiers' output is still attached.
+func (f *Firefly) classifyEvent(ctx context.Context, options *FirehoseOptions, event *FirehoseEvent) {
+	if len(options.Classifiers) == 0 || event.Type != EventTypePost || event.Post == nil {
+		return
+	}
+	classification := &Classification{}
+	for _, classifier := range options.Classifiers {
+		result, err := classifier.Classify(ctx, event.Post)
+		if err != nil {
+			f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("classifier failed on %s: %w", event.Post.URI, err))
+			continue
+		}
+		classification.merge(result)
+	}
+	event.Classification = classification
+}
+
+// LabelGate only allows events a classifier gave at least one of labels
+//
+// Example:
+//
+//	for event := range firefly.GateEvents(ctx, events, firefly.LabelGate("lang:en")) {
+//	    index(event)
+//	}
+func LabelGate(labels ...string) EventGate {
+	return func(ctx context.Context, event *FirehoseEvent) bool {
+		for _, label := range labels {
+			if event.Classification.HasLabel(label) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ScoreGate only allows events whose named score is at or below max, such as a spam score. Events without the
+// score, including events that weren't classified, are allowed.
+func ScoreGate(name string, max float64) EventGate {
+	return func(ctx context.Context, event *FirehoseEvent) bool {
+		score, ok := event.Classification.Score(name)
+		return !ok || score <= max
+	}
+}
@@ -0,0 +1,120 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// invalidHandle is what the AppView reports for an account whose handle doesn't verify
+const invalidHandle = "handle.invalid"
+
+// Handle enrichment batching: events are collected for up to handleBatchWait, or until handleBatchSize are
+// waiting, and their DIDs are resolved together
+const (
+	handleBatchSize = 100
+	handleBatchWait = 50 * time.Millisecond
+)
+
+// enrichHandles copies events from in to out, filling in missing handles on the way. Event order is kept.
+func (f *Firefly) enrichHandles(ctx context.Context, in <-chan *FirehoseEvent, out chan<- *FirehoseEvent) {
+	for {
+		first, ok := <-in
+		if !ok {
+			return
+		}
+		batch := []*FirehoseEvent{first}
+		timer := time.NewTimer(handleBatchWait)
+	collect:
+		for len(batch) < handleBatchSize {
+			select {
+			case event, ok := <-in:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, event)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		f.resolveEventHandles(ctx, batch)
+		for _, event := range batch {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// resolveEventHandles fills in User.Handle for the profile, follow and identity events in batch that have a
+// user without one. Handles announced by identity events are cached first, so later events in the batch don't
+// resolve them again. The rest come from the identity cache or from app.bsky.actor.getProfiles, 25 accounts a
+// request; the AppView only reports handles it has verified.
+func (f *Firefly) resolveEventHandles(ctx context.Context, batch []*FirehoseEvent) {
+	handles := make(map[string]string)
+	var missing []string
+	for _, event := range batch {
+		if !wantsHandle(event) {
+			continue
+		}
+		if event.User.Handle != "" {
+			if event.Type == EventTypeIdentity {
+				// The relay verifies identity events, so their handles can be trusted
+				f.identities.store(event.User.Handle, event.User.Did)
+			}
+			continue
+		}
+		if _, ok := handles[event.User.Did]; ok || slices.Contains(missing, event.User.Did) {
+			continue
+		}
+		if handle, ok := f.identities.lookup(f.identities.didToHandle, event.User.Did); ok {
+			handles[event.User.Did] = handle
+		} else {
+			missing = append(missing, event.User.Did)
+		}
+	}
+
+	if len(missing) > 0 {
+		users, err := f.GetProfiles(ctx, missing)
+		if err != nil && ctx.Err() == nil {
+			f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("failed to resolve handles: %w", err))
+		}
+		for _, user := range users {
+			if user.Handle == "" || user.Handle == invalidHandle {
+				continue
+			}
+			f.identities.store(user.Handle, user.Did)
+			handles[user.Did] = user.Handle
+		}
+	}
+
+	for _, event := range batch {
+		if !wantsHandle(event) || event.User.Handle != "" {
+			continue
+		}
+		if handle, ok := handles[event.User.Did]; ok {
+			event.User.Handle = handle
+			if event.User.RawBasic != nil {
+				event.User.RawBasic.Handle = handle
+			}
+			if event.IdentityEvent != nil {
+				event.IdentityEvent.Handle = handle
+			}
+		}
+	}
+}
+
+// wantsHandle reports whether event is one whose user ResolveHandles fills in
+func wantsHandle(event *FirehoseEvent) bool {
+	switch event.Type {
+	case EventTypeProfile, EventTypeFollow, EventTypeIdentity:
+		return event.User != nil && event.User.Did != ""
+	default:
+		return false
+	}
+}
@@ -21,15 +21,15 @@ func (f *Firefly) processCommitEvent(event *FirehoseEvent, commit *models.Event)
 	// Determine event type based on collection
 	// Collections should be exact matches
 	switch collection {
-	case "app.bsky.feed.post":
+	case CollectionPost:
 		return f.processPostEvent(event, commitData)
-	case "app.bsky.feed.like":
+	case CollectionLike:
 		return f.processLikeEvent(event, commitData)
-	case "app.bsky.feed.repost":
+	case CollectionRepost:
 		return f.processRepostEvent(event, commitData)
-	case "app.bsky.graph.follow":
+	case CollectionFollow:
 		return f.processFollowEvent(event, commitData)
-	case "app.bsky.actor.profile":
+	case CollectionProfile:
 		return f.processProfileEvent(event, commitData)
 	default:
 		// Unknown collection type - this might help debug what collections we're actually getting
@@ -0,0 +1,175 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventGate decides whether an event's author is allowed to trigger the bot. Gates that need lookups fail
+// closed: if the lookup fails the event is rejected and the error is sent to Events.
+type EventGate func(ctx context.Context, event *FirehoseEvent) bool
+
+// AllGates combines gates so an event must pass every one of them. Gates are checked in order, so put
+// cheap ones first.
+func AllGates(gates ...EventGate) EventGate {
+	return func(ctx context.Context, event *FirehoseEvent) bool {
+		for _, gate := range gates {
+			if !gate(ctx, event) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyGate combines gates so an event passes if any one of them allows it
+func AnyGate(gates ...EventGate) EventGate {
+	return func(ctx context.Context, event *FirehoseEvent) bool {
+		for _, gate := range gates {
+			if gate(ctx, event) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// GateEvents forwards the events that pass every gate. The returned channel is closed when events is closed
+// or ctx is cancelled.
+//
+// Example:
+//
+//	gate := firefly.AllGates(
+//	    client.AccountAgeGate(7*24*time.Hour),
+//	    client.FollowerGate(10*time.Minute),
+//	)
+//	for event := range firefly.GateEvents(ctx, events, gate) {
+//	    reply(event)
+//	}
+func GateEvents(ctx context.Context, events <-chan *FirehoseEvent, gates ...EventGate) chan *FirehoseEvent {
+	gate := AllGates(gates...)
+	passed := make(chan *FirehoseEvent, cap(events))
+	go func() {
+		defer close(passed)
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if event == nil || !gate(ctx, event) {
+					continue
+				}
+				select {
+				case passed <- event:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return passed
+}
+
+// FollowerGate only allows events from accounts that follow the authenticated user. The follower set is
+// fetched on first use and refreshed when it is older than refresh (default 10 minutes). Fetches run in the
+// background, so events are checked against the previous set until the new one is ready, and if a refresh
+// fails the previous set keeps being used. Failed fetches are retried with the client's BackoffPolicy,
+// waiting at most refresh between attempts, and every event is rejected until the first fetch succeeds.
+func (f *Firefly) FollowerGate(refresh time.Duration) EventGate {
+	if refresh <= 0 {
+		refresh = 10 * time.Minute
+	}
+	var mu sync.Mutex
+	var followers map[string]struct{}
+	var next time.Time // when to fetch the set again
+	refreshing := false
+	failures := 0
+
+	// fetch crawls the follower list without holding mu, then swaps the new set in
+	fetch := func(ctx context.Context, did string) {
+		fresh := make(map[string]struct{})
+		pager := f.FollowersPager(did)
+		pager.PageSize = 100
+		var failed error
+		for user, err := range pager.All(ctx) {
+			if err != nil {
+				failed = err
+				break
+			}
+			fresh[user.Did] = struct{}{}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		refreshing = false
+		// Wait before trying again rather than hammering the API on every event
+		if failed != nil {
+			next = time.Now().Add(min(f.backoffPolicy().Delay(failures), refresh))
+			failures++
+			f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("follower gate: %w", failed))
+		} else {
+			next = time.Now().Add(refresh)
+			failures = 0
+			followers = fresh
+		}
+	}
+
+	return func(ctx context.Context, event *FirehoseEvent) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if !refreshing && !time.Now().Before(next) && !f.isClosed() {
+			if f.Self == nil {
+				f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("follower gate: %w", ErrNotLoggedIn))
+				return false
+			}
+			refreshing = true
+			// The fetch outlives this event, so it stops with the client rather than with ctx
+			fetchCtx, cancel := f.bindLifetime(context.WithoutCancel(ctx))
+			f.background.Add(1)
+			go func(did string) {
+				defer f.background.Done()
+				defer cancel()
+				fetch(fetchCtx, did)
+			}(f.Self.Did)
+		}
+		_, ok := followers[event.Repo]
+		return ok
+	}
+}
+
+// maxGateProfiles bounds the account age gate's cache; it is cleared when full
+const maxGateProfiles = 50000
+
+// AccountAgeGate only allows events from accounts created at least minAge ago. Creation times come from
+// profile lookups, which are cached for the life of the gate since they never change.
+func (f *Firefly) AccountAgeGate(minAge time.Duration) EventGate {
+	var mu sync.Mutex
+	created := make(map[string]time.Time)
+
+	return func(ctx context.Context, event *FirehoseEvent) bool {
+		mu.Lock()
+		createdAt, ok := created[event.Repo]
+		mu.Unlock()
+		if !ok {
+			profile, err := f.GetProfile(ctx, event.Repo)
+			if err != nil {
+				f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("account age gate: %w", err))
+				return false
+			}
+			createdAt = profile.CreatedAt
+			mu.Lock()
+			if len(created) >= maxGateProfiles {
+				clear(created)
+			}
+			created[event.Repo] = createdAt
+			mu.Unlock()
+		}
+		// Profiles without a creation time are treated as brand new
+		return !createdAt.IsZero() && time.Since(createdAt) >= minAge
+	}
+}
@@ -0,0 +1,105 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// EventHandler processes a single firehose event. Returned errors are reported as SourceFirehose warnings
+// and don't stop the stream.
+type EventHandler func(ctx context.Context, event *FirehoseEvent) error
+
+// HandlerOptions configures HandleEvents
+type HandlerOptions struct {
+	Concurrency int // Number of events handled at once (default 1)
+	QueueSize   int // Events buffered per worker before the dispatcher waits (default 100)
+}
+
+// HandleEvents streams events and calls handler for each one until ctx is cancelled or the client is closed,
+// then waits for in-flight handlers to finish before returning. Pass nil for either options to use the defaults.
+//
+// With Concurrency above 1, events are sharded across workers by a hash of the repo DID: events from the same
+// account are always handled one at a time and in the order they arrived, while different accounts are
+// handled in parallel. Per-user state (conversations, rate limits, counters) is therefore safe to keep without
+// extra locking per account. A slow handler holds up only the accounts that share its worker, until that
+// worker's queue fills; after that the stream's own buffer absorbs the backlog and drops events when full.
+//
+// Example:
+//
+//	err := client.HandleEvents(ctx, &firefly.FirehoseOptions{
+//	    Collections: []string{"app.bsky.feed.post"},
+//	}, func(ctx context.Context, event *firefly.FirehoseEvent) error {
+//	    return respond(ctx, event)
+//	}, &firefly.HandlerOptions{Concurrency: 8})
+func (f *Firefly) HandleEvents(ctx context.Context, options *FirehoseOptions, handler EventHandler, handlerOptions *HandlerOptions) error {
+	events, err := f.StreamEvents(ctx, options)
+	if err != nil {
+		return err
+	}
+	f.dispatchEvents(ctx, events, handler, handlerOptions)
+	return nil
+}
+
+// dispatchEvents shards events across handler workers until events is closed, then waits for the workers
+func (f *Firefly) dispatchEvents(ctx context.Context, events <-chan *FirehoseEvent, handler EventHandler, handlerOptions *HandlerOptions) {
+	if handlerOptions == nil {
+		handlerOptions = &HandlerOptions{}
+	}
+	opts := *handlerOptions
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+
+	queues := make([]chan *FirehoseEvent, opts.Concurrency)
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan *FirehoseEvent, opts.QueueSize)
+		workers.Add(1)
+		go func(queue <-chan *FirehoseEvent) {
+			defer workers.Done()
+			for event := range queue {
+				f.handleEvent(ctx, handler, event)
+			}
+		}(queues[i])
+	}
+
+	// The source closes its channel when ctx ends; closing the queues then lets workers finish what they have
+	for event := range events {
+		queue := queues[repoShard(event.Repo, len(queues))]
+		select {
+		case queue <- event:
+		case <-ctx.Done():
+		}
+	}
+	for _, queue := range queues {
+		close(queue)
+	}
+	workers.Wait()
+}
+
+// handleEvent runs the handler for one event, turning errors and panics into background events
+func (f *Firefly) handleEvent(ctx context.Context, handler EventHandler, event *FirehoseEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			f.emit(SourceFirehose, SeverityError, fmt.Errorf("event handler panicked on %s: %v", event.Repo, r))
+		}
+	}()
+	if err := handler(ctx, event); err != nil {
+		f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("event handler failed on %s: %w", event.Repo, err))
+	}
+}
+
+// repoShard maps a repo DID to one of n workers. The same DID always maps to the same worker.
+func repoShard(did string, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(did))
+	return int(hash.Sum32() % uint32(n))
+}
@@ -0,0 +1,257 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FirehoseState is the connection state of a firehose stream
+type FirehoseState int
+
+const (
+	FirehoseConnecting   FirehoseState = iota // dialing for the first time
+	FirehoseConnected                         // connected and reading events
+	FirehoseReconnecting                      // disconnected and waiting to retry
+	FirehoseClosed                            // stopped; the events channel is closed
+)
+
+func (s FirehoseState) String() string {
+	switch s {
+	case FirehoseConnecting:
+		return "Connecting"
+	case FirehoseConnected:
+		return "Connected"
+	case FirehoseReconnecting:
+		return "Reconnecting"
+	case FirehoseClosed:
+		return "Closed"
+	default:
+		return "Unknown"
+	}
+}
+
+// firehoseStateNames are the stable names used when serializing a FirehoseState
+var firehoseStateNames = enumNames[FirehoseState]{
+	FirehoseConnecting:   "connecting",
+	FirehoseConnected:    "connected",
+	FirehoseReconnecting: "reconnecting",
+	FirehoseClosed:       "closed",
+}
+
+// MarshalJSON encodes the state as a JSON string
+func (s FirehoseState) MarshalJSON() ([]byte, error) {
+	return firehoseStateNames.json(s)
+}
+
+// UnmarshalJSON decodes a state from its name
+func (s *FirehoseState) UnmarshalJSON(data []byte) error {
+	value, err := firehoseStateNames.parseJSON(data, "firehose state")
+	if err != nil {
+		return err
+	}
+	*s = value
+	return nil
+}
+
+// FirehoseHealth is a point-in-time report on a firehose stream
+type FirehoseHealth struct {
+	State        FirehoseState `json:"state"`
+	ConnectedAt  time.Time     `json:"connectedAt,omitzero"` // start of the current connection
+	Reconnects   int           `json:"reconnects"`           // connections lost since the stream opened
+	LastError    string        `json:"lastError,omitempty"`  // why the last connection ended
+	LastEventAt  time.Time     `json:"lastEventAt,omitzero"` // when the last event was received
+	LastEventAge time.Duration `json:"lastEventAge"`         // time since LastEventAt; 0 before the first event
+	Lag          time.Duration `json:"lag"`                  // how far the last event's timestamp is behind the clock
+	EventsPerSec float64       `json:"eventsPerSec"`         // delivered events per second over the last minute
+	Delivered    int64         `json:"delivered"`            // events sent on the channel
+	Dropped      int64         `json:"dropped"`              // events dropped because the channel was full
+	Buffered     int           `json:"buffered"`             // events waiting in the channel
+	BufferSize   int           `json:"bufferSize"`           // capacity of the channel
+}
+
+// Firehose is a running firehose stream. Events are read from Events; Health reports how the stream is doing.
+type Firehose struct {
+	Events chan *FirehoseEvent
+
+	monitor *firehoseMonitor
+}
+
+// OpenFirehose is StreamEvents with access to the stream's health, for services that need liveness probes or
+// metrics. The stream runs until ctx is cancelled or the client is closed.
+//
+// Example:
+//
+//	stream, err := client.OpenFirehose(ctx, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	http.Handle("/healthz", stream.HealthHandler(30*time.Second))
+//	for event := range stream.Events {
+//	    process(event)
+//	}
+func (f *Firefly) OpenFirehose(ctx context.Context, options *FirehoseOptions) (*Firehose, error) {
+	if options == nil {
+		options = &FirehoseOptions{}
+	}
+	options.monitor = newFirehoseMonitor()
+	events, err := f.StreamEvents(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	options.monitor.events = events
+	return &Firehose{Events: events, monitor: options.monitor}, nil
+}
+
+// Health reports the stream's current state
+func (fh *Firehose) Health() FirehoseHealth {
+	return fh.monitor.health(time.Now())
+}
+
+// HealthHandler serves Health as JSON. It responds 200 while the stream is connected and 503 otherwise. If
+// maxLag is positive, it also responds 503 when the last event is older than maxLag, whether by arrival time
+// or by timestamp, which catches a connection that is open but stalled.
+func (fh *Firehose) HealthHandler(maxLag time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := fh.Health()
+		status := http.StatusOK
+		if health.State != FirehoseConnected {
+			status = http.StatusServiceUnavailable
+		} else if maxLag > 0 && (health.LastEventAge > maxLag || health.Lag > maxLag) {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(health)
+	})
+}
+
+// healthWindow is the number of one-second buckets EventsPerSec is averaged over
+const healthWindow = 60
+
+// firehoseMonitor records a stream's connection state and throughput. A nil monitor ignores every call, so
+// streams opened without OpenFirehose don't pay for it.
+type firehoseMonitor struct {
+	events chan *FirehoseEvent
+
+	mu          sync.Mutex
+	opened      time.Time
+	state       FirehoseState
+	connectedAt time.Time
+	reconnects  int
+	lastError   string
+	lastEventAt time.Time
+	lastEventTS time.Time
+	delivered   int64
+	dropped     int64
+	buckets     [healthWindow]int64
+	bucketSec   [healthWindow]int64 // the Unix second each bucket counts
+}
+
+func newFirehoseMonitor() *firehoseMonitor {
+	return &firehoseMonitor{opened: time.Now()}
+}
+
+func (m *firehoseMonitor) connected() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = FirehoseConnected
+	m.connectedAt = time.Now()
+}
+
+func (m *firehoseMonitor) disconnected(err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state == FirehoseConnected {
+		m.reconnects++
+	}
+	m.state = FirehoseReconnecting
+	m.connectedAt = time.Time{}
+	if err != nil {
+		m.lastError = err.Error()
+	}
+}
+
+func (m *firehoseMonitor) closed() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = FirehoseClosed
+	m.connectedAt = time.Time{}
+}
+
+// received records an event sent on the channel, or dropped because it was full
+func (m *firehoseMonitor) received(event *FirehoseEvent, dropped bool) {
+	if m == nil {
+		return
+	}
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastEventAt = now
+	m.lastEventTS = event.Timestamp
+	if dropped {
+		m.dropped++
+		return
+	}
+	m.delivered++
+	sec := now.Unix()
+	i := sec % healthWindow
+	if m.bucketSec[i] != sec {
+		m.bucketSec[i] = sec
+		m.buckets[i] = 0
+	}
+	m.buckets[i]++
+}
+
+func (m *firehoseMonitor) health(now time.Time) FirehoseHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	health := FirehoseHealth{
+		State:       m.state,
+		ConnectedAt: m.connectedAt,
+		Reconnects:  m.reconnects,
+		LastError:   m.lastError,
+		LastEventAt: m.lastEventAt,
+		Delivered:   m.delivered,
+		Dropped:     m.dropped,
+	}
+	if !m.lastEventAt.IsZero() {
+		health.LastEventAge = now.Sub(m.lastEventAt)
+	}
+	if !m.lastEventTS.IsZero() {
+		health.Lag = max(now.Sub(m.lastEventTS), 0)
+	}
+
+	// Average over the full window, or over the stream's lifetime while it is younger than that
+	var count int64
+	cutoff := now.Unix() - healthWindow
+	for i, sec := range m.bucketSec {
+		if sec > cutoff {
+			count += m.buckets[i]
+		}
+	}
+	window := min(now.Sub(m.opened).Seconds(), healthWindow)
+	if window >= 1 {
+		health.EventsPerSec = float64(count) / window
+	} else {
+		health.EventsPerSec = float64(count)
+	}
+
+	if m.events != nil {
+		health.Buffered = len(m.events)
+		health.BufferSize = cap(m.events)
+	}
+	return health
+}
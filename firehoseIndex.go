@@ -0,0 +1,85 @@
+package firefly
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bluesky-social/jetstream/pkg/models"
+)
+
+// IndexedPost holds the fields of a new or updated post that a text search index needs
+type IndexedPost struct {
+	DID       string
+	URI       string
+	Text      string
+	Languages []string
+	Tags      []string // the record's tags plus hashtags from its facets, without the leading #
+	CreatedAt time.Time
+}
+
+// PostIndexFunc receives every post created or updated on a stream, before the post is converted to a
+// FeedPost. Errors are reported as SourceFirehose warnings and don't stop the stream.
+//
+// Example:
+//
+//	events, err := client.StreamEvents(ctx, &firefly.FirehoseOptions{
+//	    Collections: []string{"app.bsky.feed.post"},
+//	    IndexOnly:   true,
+//	    PostIndexer: func(post firefly.IndexedPost) error {
+//	        return index.Index(post.URI, post)
+//	    },
+//	})
+type PostIndexFunc func(post IndexedPost) error
+
+// indexRecord is the subset of app.bsky.feed.post decoded for indexing; embeds and reply refs are skipped
+type indexRecord struct {
+	Text      string   `json:"text"`
+	Langs     []string `json:"langs"`
+	Tags      []string `json:"tags"`
+	CreatedAt string   `json:"createdAt"`
+	Facets    []struct {
+		Features []struct {
+			Type string `json:"$type"`
+			Tag  string `json:"tag"`
+		} `json:"features"`
+	} `json:"facets"`
+}
+
+// indexPost passes a post commit to options.PostIndexer. It reports whether the event was a post that was
+// indexed, so IndexOnly streams can skip converting it.
+func (f *Firefly) indexPost(raw *models.Event, options *FirehoseOptions) bool {
+	if options.PostIndexer == nil || raw.Commit == nil || raw.Commit.Collection != CollectionPost {
+		return false
+	}
+	commit := raw.Commit
+	if commit.Operation == "delete" || commit.Record == nil {
+		return false
+	}
+
+	var record indexRecord
+	if err := json.Unmarshal(commit.Record, &record); err != nil {
+		f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("%w: failed to decode post for indexing: %w", ErrInvalidEvent, err))
+		return true
+	}
+	post := IndexedPost{
+		DID:       raw.Did,
+		URI:       "at://" + raw.Did + "/app.bsky.feed.post/" + commit.RKey,
+		Text:      record.Text,
+		Languages: record.Langs,
+		Tags:      record.Tags,
+	}
+	post.CreatedAt, _ = time.Parse(time.RFC3339, record.CreatedAt)
+	for _, facet := range record.Facets {
+		for _, feature := range facet.Features {
+			if feature.Type == "app.bsky.richtext.facet#tag" && feature.Tag != "" {
+				post.Tags = append(post.Tags, feature.Tag)
+			}
+		}
+	}
+
+	if err := options.PostIndexer(post); err != nil {
+		f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("post indexer failed on %s: %w", post.URI, err))
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package firefly
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// firehoseSampler thins a stream according to SampleRate and MaxEventsPerSecond. Events are checked after
+// the envelope is decoded but before records are converted, so dropped events cost almost nothing.
+type firehoseSampler struct {
+	rate      float64
+	byRepo    bool
+	threshold uint64 // repos whose DID hash is below this are kept when sampling by repo
+
+	mu     sync.Mutex
+	limit  float64 // events per second; 0 for no limit
+	tokens float64
+	last   time.Time
+}
+
+// newFirehoseSampler returns nil when the options don't ask for any thinning
+func newFirehoseSampler(options *FirehoseOptions) *firehoseSampler {
+	rate := options.SampleRate
+	if rate <= 0 || rate >= 1 {
+		rate = 1
+	}
+	if rate == 1 && options.MaxEventsPerSecond <= 0 {
+		return nil
+	}
+	s := &firehoseSampler{
+		rate:   rate,
+		byRepo: options.SampleByRepo,
+		limit:  float64(max(options.MaxEventsPerSecond, 0)),
+	}
+	s.threshold = uint64(rate * math.MaxUint64)
+	if rate == 1 {
+		s.threshold = math.MaxUint64
+	}
+	s.tokens = s.limit
+	return s
+}
+
+// keep reports whether an event from the given repo should be delivered
+func (s *firehoseSampler) keep(did string) bool {
+	if s == nil {
+		return true
+	}
+	if s.rate < 1 {
+		if s.byRepo {
+			hash := fnv.New64a()
+			hash.Write([]byte(did))
+			if hash.Sum64() >= s.threshold {
+				return false
+			}
+		} else if rand.Float64() >= s.rate {
+			return false
+		}
+	}
+	if s.limit == 0 {
+		return true
+	}
+
+	// Token bucket holding up to one second of events
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if !s.last.IsZero() {
+		s.tokens = min(s.limit, s.tokens+now.Sub(s.last).Seconds()*s.limit)
+	}
+	s.last = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
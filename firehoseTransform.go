@@ -0,0 +1,41 @@
+package firefly
+
+import (
+	"fmt"
+)
+
+// EventTransformer rewrites a firehose event before it is delivered. It may modify the event in place,
+// return a different event, or return nil to drop it. Returning an error drops the event and reports the
+// error as a SourceFirehose warning, so a failing anonymizer never lets the original event through.
+//
+// Example:
+//
+//	// Replace author DIDs with a keyed hash before events leave the process
+//	anonymize := func(event *firefly.FirehoseEvent) (*firefly.FirehoseEvent, error) {
+//	    mac := hmac.New(sha256.New, key)
+//	    mac.Write([]byte(event.Repo))
+//	    event.Repo = hex.EncodeToString(mac.Sum(nil))
+//	    event.RawCommit = nil
+//	    return event, nil
+//	}
+//	events, err := client.StreamEvents(ctx, &firefly.FirehoseOptions{
+//	    Transformers: []firefly.EventTransformer{anonymize},
+//	})
+type EventTransformer func(event *FirehoseEvent) (*FirehoseEvent, error)
+
+// transformEvent runs options.Transformers in order, returning nil if one of them drops the event
+func (f *Firefly) transformEvent(options *FirehoseOptions, event *FirehoseEvent) *FirehoseEvent {
+	for i, transform := range options.Transformers {
+		original := event
+		var err error
+		event, err = transform(event)
+		if err != nil {
+			f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("transformer %d failed on %s event from %s: %w", i, original.Type, original.Repo, err))
+			return nil
+		}
+		if event == nil {
+			return nil
+		}
+	}
+	return event
+}
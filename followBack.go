@@ -0,0 +1,278 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FollowBackSource chooses how AutoFollowBack learns about new followers
+type FollowBackSource int
+
+const (
+	FollowBackNotifications FollowBackSource = iota // poll follow notifications
+	FollowBackFirehose                              // watch the firehose for follow records targeting Self
+)
+
+func (s FollowBackSource) String() string {
+	switch s {
+	case FollowBackNotifications:
+		return "Notifications"
+	case FollowBackFirehose:
+		return "Firehose"
+	default:
+		return "Unknown"
+	}
+}
+
+// FollowBackPolicy decides which new followers AutoFollowBack follows back. Followers who are already
+// followed or blocked in either direction are always skipped.
+type FollowBackPolicy struct {
+	Source        FollowBackSource
+	MinAccountAge time.Duration // Skip followers whose account is younger than this, or of unknown age
+	MinFollowers  int           // Skip followers with fewer followers than this
+
+	// Filter, if set, is asked about every follower that passes the other checks. It returns why the follower
+	// should be skipped, or "" to follow them back.
+	Filter func(follower *User) string
+
+	Interval     time.Duration // Minimum time between follow-backs (default paces to the repo-write rate limit)
+	PollInterval time.Duration // Time between notification polls with FollowBackNotifications (default 1 minute)
+	BufferSize   int           // Decision channel buffer size (default 100)
+	DryRun       bool          // Make every decision without creating any follows
+	AuditLog     io.Writer     // Optional; every decision is also written to it as a line of JSON
+}
+
+// FollowBackDecision records what AutoFollowBack did about one new follower, and why
+type FollowBackDecision struct {
+	Follower  *User        `json:"-"`                // full profile; nil if it couldn't be fetched
+	DID       string       `json:"did"`              // the follower's DID
+	Handle    string       `json:"handle,omitempty"` // the follower's handle, if known
+	Status    FollowStatus `json:"status"`
+	Reason    string       `json:"reason,omitempty"` // why the follower was skipped, or what failed
+	Follow    *PostRef     `json:"follow,omitempty"` // the new follow record when Status is FollowCreated
+	DryRun    bool         `json:"dryRun,omitempty"`
+	Err       error        `json:"-"` // set when Status is FollowFailed
+	DecidedAt time.Time    `json:"decidedAt"`
+}
+
+func (d FollowBackDecision) String() string {
+	if d.Reason != "" {
+		return fmt.Sprintf("FollowBackDecision{DID: %s, Status: %s, Reason: %s}", d.DID, d.Status, d.Reason)
+	}
+	return fmt.Sprintf("FollowBackDecision{DID: %s, Status: %s}", d.DID, d.Status)
+}
+
+// AutoFollowBack follows back new followers of the authenticated user in the background, until ctx is
+// cancelled or the client is closed. Each follower is checked against policy with a fresh profile, and
+// follow-backs are paced like FollowAll. One decision per follower is sent on the returned channel, which
+// must be read, and written to policy.AuditLog if it is set; the channel is closed when AutoFollowBack stops.
+// Only follows made after it starts are considered, and each follower is decided at most once per call.
+// Errors from the follower source are sent to Events. Pass nil for policy to use the defaults.
+//
+// Example:
+//
+//	audit, err := os.OpenFile("follow-back.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	decisions, err := client.AutoFollowBack(ctx, &firefly.FollowBackPolicy{
+//	    MinAccountAge: 7 * 24 * time.Hour,
+//	    MinFollowers:  10,
+//	    AuditLog:      audit,
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for decision := range decisions {
+//	    fmt.Println(decision)
+//	}
+func (f *Firefly) AutoFollowBack(ctx context.Context, policy *FollowBackPolicy) (chan *FollowBackDecision, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	if f.isClosed() {
+		return nil, ErrClientClosed
+	}
+	if policy == nil {
+		policy = &FollowBackPolicy{}
+	}
+	opts := *policy
+	if opts.Interval <= 0 {
+		opts.Interval = createRecordInterval
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Minute
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+
+	ctx, cancel := f.bindLifetime(ctx)
+	followers := make(chan string, 100)
+	switch opts.Source {
+	case FollowBackFirehose:
+		events, err := f.StreamEvents(ctx, &FirehoseOptions{Collections: []string{CollectionFollow}})
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		f.background.Add(1)
+		go func() {
+			defer f.background.Done()
+			defer close(followers)
+			f.firehoseFollowers(ctx, events, followers)
+		}()
+	default:
+		f.background.Add(1)
+		go func() {
+			defer f.background.Done()
+			defer close(followers)
+			f.pollFollowers(ctx, opts.PollInterval, followers)
+		}()
+	}
+
+	decisions := make(chan *FollowBackDecision, opts.BufferSize)
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		defer cancel()
+		defer close(decisions)
+
+		decided := make(map[string]struct{})
+		var lastWrite time.Time
+		for did := range followers {
+			if _, ok := decided[did]; ok {
+				continue
+			}
+			decided[did] = struct{}{}
+
+			decision := f.decideFollowBack(ctx, did, &opts)
+			if decision.Status == FollowCreated && !opts.DryRun {
+				if !sleepUntil(ctx, lastWrite.Add(opts.Interval)) {
+					return
+				}
+				decision.Follow, decision.Err = f.followWithRetry(ctx, did)
+				lastWrite = time.Now()
+				if decision.Err != nil {
+					decision.Status = FollowFailed
+					decision.Reason = decision.Err.Error()
+				}
+				decision.DecidedAt = time.Now()
+			}
+
+			if opts.AuditLog != nil {
+				if err := json.NewEncoder(opts.AuditLog).Encode(decision); err != nil {
+					f.emit(SourceScheduler, SeverityWarning, fmt.Errorf("follow back: failed to write audit log: %w", err))
+				}
+			}
+			select {
+			case decisions <- decision:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return decisions, nil
+}
+
+// decideFollowBack checks a new follower against the policy. A decision with Status FollowCreated means the
+// follower should be followed back; the follow itself is left to the caller.
+func (f *Firefly) decideFollowBack(ctx context.Context, did string, policy *FollowBackPolicy) *FollowBackDecision {
+	decision := &FollowBackDecision{DID: did, Status: FollowSkipped, DryRun: policy.DryRun}
+	defer func() { decision.DecidedAt = time.Now() }()
+
+	if did == f.Self.Did {
+		decision.Reason = "is the authenticated user"
+		return decision
+	}
+	follower, err := f.GetProfile(ctx, did, ProfileBypassCache(), ProfileWithViewer())
+	if err != nil {
+		decision.Status = FollowFailed
+		decision.Reason = err.Error()
+		decision.Err = err
+		return decision
+	}
+	decision.Follower = follower
+	decision.Handle = follower.Handle
+
+	followers := 0
+	if follower.FollowersCount != nil {
+		followers = *follower.FollowersCount
+	}
+	switch {
+	case follower.IsFollowedByMe():
+		decision.Reason = "already followed"
+	case follower.IsBlocked():
+		decision.Reason = "blocked"
+	case follower.BlocksMe():
+		decision.Reason = "blocks the authenticated user"
+	case policy.MinAccountAge > 0 && follower.CreatedAt.IsZero():
+		decision.Reason = "account age unknown"
+	case policy.MinAccountAge > 0 && time.Since(follower.CreatedAt) < policy.MinAccountAge:
+		decision.Reason = fmt.Sprintf("account is %s old, under %s",
+			time.Since(follower.CreatedAt).Round(time.Minute), policy.MinAccountAge)
+	case followers < policy.MinFollowers:
+		decision.Reason = fmt.Sprintf("%d followers, under %d", followers, policy.MinFollowers)
+	case policy.Filter != nil:
+		decision.Reason = policy.Filter(follower)
+	}
+	if decision.Reason == "" {
+		decision.Status = FollowCreated
+	}
+	return decision
+}
+
+// firehoseFollowers sends the DID of every account that follows Self in events
+func (f *Firefly) firehoseFollowers(ctx context.Context, events <-chan *FirehoseEvent, followers chan<- string) {
+	for event := range events {
+		if event.Type != EventTypeFollow || event.User == nil || event.User.Did != f.Self.Did {
+			continue
+		}
+		select {
+		case followers <- event.Repo:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollFollowers polls follow notifications every interval until ctx is cancelled, sending the DID of each
+// new follower oldest first
+func (f *Firefly) pollFollowers(ctx context.Context, interval time.Duration, followers chan<- string) {
+	since := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pending, err := f.GetNotificationsSince(ctx, since, NotifReasons(NewFollow))
+		if err != nil {
+			// The whole window is retried next time, so no follower is skipped
+			if ctx.Err() == nil {
+				f.emit(SourceScheduler, SeverityError, fmt.Errorf("follow back: %w", err))
+			}
+			continue
+		}
+		for _, notif := range pending {
+			if notif.IndexedAt.After(since) {
+				since = notif.IndexedAt
+			}
+			if notif.LinkedUser == nil {
+				continue
+			}
+			select {
+			case followers <- notif.LinkedUser.Did:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FollowerChangeType identifies whether a follower was gained or lost
+type FollowerChangeType int
+
+const (
+	FollowerGained FollowerChangeType = iota
+	FollowerLost
+)
+
+func (ct FollowerChangeType) String() string {
+	switch ct {
+	case FollowerGained:
+		return "Follower Gained"
+	case FollowerLost:
+		return "Follower Lost"
+	default:
+		return "Unknown"
+	}
+}
+
+// FollowerChange reports a single account that started or stopped following the authenticated user
+type FollowerChange struct {
+	Type       FollowerChangeType `json:"type"`
+	User       *User              `json:"user"` // may only have the DID populated for live firehose changes
+	DetectedAt time.Time          `json:"detectedAt"`
+}
+
+func (c FollowerChange) String() string {
+	return fmt.Sprintf("FollowerChange{Type: %s, DID: %s}", c.Type, c.User.Did)
+}
+
+// FollowerStore persists follower snapshots between tracker runs, keyed by DID.
+// Implementations must be safe to call from the tracker's goroutines.
+type FollowerStore interface {
+	LoadFollowers(ctx context.Context) (map[string]*User, error)
+	SaveFollowers(ctx context.Context, followers map[string]*User) error
+}
+
+// MemoryFollowerStore is a FollowerStore that keeps the snapshot in memory.
+// Snapshots are lost when the process exits, so the first check after a restart is treated as a baseline.
+type MemoryFollowerStore struct {
+	mu        sync.Mutex
+	followers map[string]*User
+}
+
+// LoadFollowers returns a copy of the stored snapshot, or nil if nothing has been saved yet
+func (s *MemoryFollowerStore) LoadFollowers(ctx context.Context) (map[string]*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.followers == nil {
+		return nil, nil
+	}
+	followers := make(map[string]*User, len(s.followers))
+	for did, user := range s.followers {
+		followers[did] = user
+	}
+	return followers, nil
+}
+
+// SaveFollowers replaces the stored snapshot
+func (s *MemoryFollowerStore) SaveFollowers(ctx context.Context, followers map[string]*User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.followers = make(map[string]*User, len(followers))
+	for did, user := range followers {
+		s.followers[did] = user
+	}
+	return nil
+}
+
+// FollowerTrackerOptions configures a FollowerTracker
+type FollowerTrackerOptions struct {
+	Store        FollowerStore         // Snapshot storage (default in-memory)
+	PollInterval time.Duration         // Time between full snapshots (default 15 minutes)
+	LiveFollows  bool                  // Also watch the firehose for new follows targeting Self
+	BufferSize   int                   // Channel buffer size (default 100)
+	OnChange     func(*FollowerChange) // Optional callback invoked for every change before it is sent on the channel
+}
+
+// FollowerTracker detects new and lost followers of the authenticated user by diffing follower snapshots.
+//
+// Lost followers can only be detected by a full snapshot, since firehose unfollow events do not say who was
+// unfollowed. Live firehose tracking therefore only speeds up the detection of new followers.
+type FollowerTracker struct {
+	f       *Firefly
+	options FollowerTrackerOptions
+
+	mu     sync.Mutex
+	known  map[string]*User
+	loaded bool
+}
+
+// NewFollowerTracker creates a FollowerTracker for the authenticated user.
+// Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	tracker := client.NewFollowerTracker(&firefly.FollowerTrackerOptions{LiveFollows: true})
+//	changes, err := tracker.Start(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for change := range changes {
+//	    fmt.Println(change)
+//	}
+func (f *Firefly) NewFollowerTracker(options *FollowerTrackerOptions) *FollowerTracker {
+	if options == nil {
+		options = &FollowerTrackerOptions{}
+	}
+	opts := *options
+	if opts.Store == nil {
+		opts.Store = &MemoryFollowerStore{}
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 15 * time.Minute
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	return &FollowerTracker{
+		f:       f,
+		options: opts,
+	}
+}
+
+// Check takes a fresh snapshot of the authenticated user's followers, diffs it against the stored snapshot,
+// saves the new snapshot, and returns the changes. If the store has no previous snapshot, the first
+// snapshot is saved as a baseline and no changes are returned.
+func (t *FollowerTracker) Check(ctx context.Context) ([]*FollowerChange, error) {
+	if t.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+
+	current, err := t.fetchFollowers(ctx, t.f.Self.Did)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+
+	var changes []*FollowerChange
+	now := time.Now()
+	if t.known != nil {
+		for did, user := range current {
+			if _, ok := t.known[did]; !ok {
+				changes = append(changes, &FollowerChange{Type: FollowerGained, User: user, DetectedAt: now})
+			}
+		}
+		for did, user := range t.known {
+			if _, ok := current[did]; !ok {
+				changes = append(changes, &FollowerChange{Type: FollowerLost, User: user, DetectedAt: now})
+			}
+		}
+	}
+
+	if err := t.options.Store.SaveFollowers(ctx, current); err != nil {
+		return nil, fmt.Errorf("failed to save follower snapshot: %w", err)
+	}
+	t.known = current
+	return changes, nil
+}
+
+// Start runs the tracker in the background until ctx is cancelled, checking immediately and then every
+// PollInterval. Changes are passed to OnChange (if set) and sent on the returned channel, which is closed
+// when the tracker stops or the client is closed. Errors from background checks are sent to Events.
+func (t *FollowerTracker) Start(ctx context.Context) (chan *FollowerChange, error) {
+	if t.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+
+	if t.f.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	changes := make(chan *FollowerChange, t.options.BufferSize)
+
+	ctx, cancel := t.f.bindLifetime(ctx)
+	var live chan *FirehoseEvent
+	if t.options.LiveFollows {
+		var err error
+		live, err = t.f.StreamEvents(ctx, &FirehoseOptions{
+			Collections: []string{CollectionFollow},
+		})
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	t.f.background.Add(1)
+	go func() {
+		defer t.f.background.Done()
+		defer cancel()
+		defer close(changes)
+
+		ticker := time.NewTicker(t.options.PollInterval)
+		defer ticker.Stop()
+
+		t.runCheck(ctx, changes)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.runCheck(ctx, changes)
+			case event, ok := <-live:
+				if !ok {
+					live = nil
+					continue
+				}
+				if change := t.handleFollowEvent(ctx, event); change != nil {
+					t.deliver(ctx, changes, change)
+				}
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+// runCheck performs a single snapshot diff and delivers the results
+func (t *FollowerTracker) runCheck(ctx context.Context, changes chan<- *FollowerChange) {
+	found, err := t.Check(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			t.f.emit(SourceScheduler, SeverityError, err)
+		}
+		return
+	}
+	for _, change := range found {
+		t.deliver(ctx, changes, change)
+	}
+}
+
+// handleFollowEvent records a live follow of Self and returns the change, or nil if the event is unrelated
+func (t *FollowerTracker) handleFollowEvent(ctx context.Context, event *FirehoseEvent) *FollowerChange {
+	if event == nil || event.Type != EventTypeFollow || event.User == nil || t.f.Self == nil {
+		return nil
+	}
+	if event.User.Did != t.f.Self.Did {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.loadLocked(ctx); err != nil || t.known == nil {
+		// Without a baseline we can't tell whether this is new; the next snapshot will pick it up
+		return nil
+	}
+	if _, ok := t.known[event.Repo]; ok {
+		return nil
+	}
+
+	follower := &User{Did: event.Repo}
+	t.known[event.Repo] = follower
+	if err := t.options.Store.SaveFollowers(ctx, t.known); err != nil {
+		t.f.emit(SourceScheduler, SeverityWarning, fmt.Errorf("failed to save follower snapshot: %w", err))
+	}
+
+	return &FollowerChange{Type: FollowerGained, User: follower, DetectedAt: event.Timestamp}
+}
+
+// deliver passes a change to the callback and the channel
+func (t *FollowerTracker) deliver(ctx context.Context, changes chan<- *FollowerChange, change *FollowerChange) {
+	if t.options.OnChange != nil {
+		t.options.OnChange(change)
+	}
+	select {
+	case changes <- change:
+	case <-ctx.Done():
+	}
+}
+
+// loadLocked loads the stored snapshot on first use. Callers must hold t.mu.
+func (t *FollowerTracker) loadLocked(ctx context.Context) error {
+	if t.loaded {
+		return nil
+	}
+	stored, err := t.options.Store.LoadFollowers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load follower snapshot: %w", err)
+	}
+	t.known = stored
+	t.loaded = true
+	return nil
+}
+
+// fetchFollowers walks every page of an actor's followers
+func (t *FollowerTracker) fetchFollowers(ctx context.Context, actor string) (map[string]*User, error) {
+	followers := make(map[string]*User)
+	pager := t.f.FollowersPager(actor)
+	pager.PageSize = 100
+	for user, err := range pager.All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		followers[user.Did] = user
+	}
+	return followers, nil
+}
@@ -0,0 +1,281 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+	"github.com/bluesky-social/indigo/xrpc"
+)
+
+var (
+	ErrFollowSelf = errors.New("cannot follow yourself")
+)
+
+// createRecordInterval spaces record creations to stay within the PDS repo-write budget of 5,000 points an hour,
+// where each create costs 3 points
+const createRecordInterval = time.Hour * 3 / 5000
+
+// Follow creates an app.bsky.graph.follow record for the given account. actor may be a DID, a handle, or any
+// other form ParseActor accepts; handles are resolved first.
+func (f *Firefly) Follow(ctx context.Context, actor string) (*PostRef, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	did, err := f.resolveActor(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	if did == f.Self.Did {
+		return nil, ErrFollowSelf
+	}
+	resp, err := atproto.RepoCreateRecord(ctx, f.api, &atproto.RepoCreateRecord_Input{
+		Collection: CollectionFollow,
+		Repo:       f.Self.Did,
+		Record: &lexutil.LexiconTypeDecoder{
+			Val: &bsky.GraphFollow{
+				Subject:   did,
+				CreatedAt: time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create follow: %w", err)
+	}
+	return &PostRef{URI: resp.Uri, CID: resp.Cid}, nil
+}
+
+// FollowStatus is the outcome of following a single account in FollowAll
+type FollowStatus int
+
+const (
+	FollowCreated FollowStatus = iota
+	FollowSkipped              // already followed, listed twice, or the authenticated user
+	FollowFailed
+)
+
+func (s FollowStatus) String() string {
+	switch s {
+	case FollowCreated:
+		return "Created"
+	case FollowSkipped:
+		return "Skipped"
+	case FollowFailed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// followStatusNames are the stable names used when serializing a FollowStatus
+var followStatusNames = enumNames[FollowStatus]{
+	FollowCreated: "created",
+	FollowSkipped: "skipped",
+	FollowFailed:  "failed",
+}
+
+// MarshalText encodes the follow status as its stable name
+func (s FollowStatus) MarshalText() ([]byte, error) {
+	return followStatusNames.text(s), nil
+}
+
+// UnmarshalText decodes a follow status from its name or integer value
+func (s *FollowStatus) UnmarshalText(data []byte) error {
+	value, err := followStatusNames.parseText(data, "follow status")
+	if err != nil {
+		return err
+	}
+	*s = value
+	return nil
+}
+
+// FollowResult reports what happened to one of the accounts passed to FollowAll
+type FollowResult struct {
+	Actor  string       `json:"actor"`         // handle or DID as given
+	DID    string       `json:"did,omitempty"` // empty if the handle couldn't be resolved
+	Status FollowStatus `json:"status"`
+	Follow *PostRef     `json:"follow,omitempty"` // the new follow record when Status is FollowCreated
+	Err    error        `json:"-"`                // set when Status is FollowFailed
+}
+
+func (r FollowResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("FollowResult{Actor: %s, Status: %s, Err: %v}", r.Actor, r.Status, r.Err)
+	}
+	return fmt.Sprintf("FollowResult{Actor: %s, Status: %s}", r.Actor, r.Status)
+}
+
+// FollowAllOptions holds the settings for FollowAll; set them with FollowAllOption functions
+type FollowAllOptions struct {
+	Interval   time.Duration // Minimum time between follow records (default paces to the repo-write rate limit)
+	BufferSize int           // Result channel buffer size (default 100)
+	DryRun     bool          // Resolve and check accounts without creating any follows
+}
+
+// FollowAllOption configures a FollowAll call
+type FollowAllOption func(*FollowAllOptions)
+
+// FollowInterval sets the minimum time between follow records. Shorter intervals risk rate limiting on large imports.
+func FollowInterval(interval time.Duration) FollowAllOption {
+	return func(o *FollowAllOptions) { o.Interval = interval }
+}
+
+// FollowBufferSize sets the result channel's buffer size
+func FollowBufferSize(size int) FollowAllOption {
+	return func(o *FollowAllOptions) { o.BufferSize = size }
+}
+
+// FollowDryRun reports which accounts would be followed without creating any records
+func FollowDryRun() FollowAllOption {
+	return func(o *FollowAllOptions) { o.DryRun = true }
+}
+
+// FollowAll follows every account in actors (handles or DIDs) in the background, in order. Accounts that are
+// already followed are skipped, and follows are spaced out so a large import stays within the PDS write budget;
+// if the server rate limits anyway, FollowAll waits for the limit to reset and retries. One result per actor is
+// sent on the returned channel, which is closed when the import finishes or ctx is cancelled.
+//
+// Example:
+//
+//	results, err := client.FollowAll(ctx, []string{"alice.bsky.social", "did:plc:abc123"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for result := range results {
+//	    fmt.Println(result)
+//	}
+func (f *Firefly) FollowAll(ctx context.Context, actors []string, options ...FollowAllOption) (chan *FollowResult, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	if f.isClosed() {
+		return nil, ErrClientClosed
+	}
+	opts := FollowAllOptions{Interval: createRecordInterval, BufferSize: 100}
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+
+	// Load the current follows first so a failed listing is reported before anything is written
+	following, err := f.followedDIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan *FollowResult, opts.BufferSize)
+	ctx, cancel := f.bindLifetime(ctx)
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		defer cancel()
+		defer close(results)
+
+		var lastWrite time.Time
+		for _, actor := range actors {
+			result := &FollowResult{Actor: actor}
+			did, err := f.resolveActor(ctx, actor)
+			if err != nil {
+				result.Status = FollowFailed
+				result.Err = err
+			} else {
+				result.DID = did
+				if _, ok := following[did]; ok || did == f.Self.Did {
+					result.Status = FollowSkipped
+				} else if opts.DryRun {
+					result.Status = FollowCreated
+					following[did] = struct{}{}
+				} else {
+					if !sleepUntil(ctx, lastWrite.Add(opts.Interval)) {
+						return
+					}
+					result.Follow, result.Err = f.followWithRetry(ctx, did)
+					lastWrite = time.Now()
+					if result.Err != nil {
+						result.Status = FollowFailed
+					} else {
+						following[did] = struct{}{}
+					}
+				}
+			}
+
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results, nil
+}
+
+// followWithRetry creates a follow, waiting out a single rate limit response if the server sends one
+func (f *Firefly) followWithRetry(ctx context.Context, did string) (*PostRef, error) {
+	return f.withRateLimitRetry(ctx, func() (*PostRef, error) {
+		return f.Follow(ctx, did)
+	})
+}
+
+// withRateLimitRetry runs a record write, waiting out a single rate limit response if the server sends one
+// and then trying again
+func (f *Firefly) withRateLimitRetry(ctx context.Context, write func() (*PostRef, error)) (*PostRef, error) {
+	ref, err := write()
+	var xrpcErr *xrpc.Error
+	if errors.As(err, &xrpcErr) && xrpcErr.StatusCode == http.StatusTooManyRequests &&
+		xrpcErr.Ratelimit != nil && !xrpcErr.Ratelimit.Reset.IsZero() {
+		f.emit(SourceScheduler, SeverityWarning, err)
+		if !sleepUntil(ctx, xrpcErr.Ratelimit.Reset) {
+			return nil, ctx.Err()
+		}
+		ref, err = write()
+	}
+	return ref, err
+}
+
+// followedDIDs lists every account the authenticated user follows
+func (f *Firefly) followedDIDs(ctx context.Context) (map[string]struct{}, error) {
+	following := make(map[string]struct{})
+	pager := f.FollowsPager(f.Self.Did)
+	pager.PageSize = 100
+	for user, err := range pager.All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		following[user.Did] = struct{}{}
+	}
+	return following, nil
+}
+
+// resolveActor returns the DID for any actor input ParseActor understands
+func (f *Firefly) resolveActor(ctx context.Context, actor string) (string, error) {
+	actor = normalizeActor(actor)
+	if strings.HasPrefix(actor, "did:") {
+		return actor, nil
+	}
+	return f.ResolveHandleToDID(ctx, actor)
+}
+
+// sleepUntil waits until t, returning false if ctx is cancelled first
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
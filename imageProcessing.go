@@ -0,0 +1,328 @@
+package firefly
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	ErrImageProcessing  = errors.New("image processing failed")
+)
+
+// ImageOptions controls how images are prepared before upload
+type ImageOptions struct {
+	MaxBytes      int  // Target size in bytes (default MaxImageBytes)
+	MaxDimension  int  // Longest side in pixels; larger images are scaled down when re-encoding (default 2000)
+	Quality       int  // Starting JPEG quality when re-encoding (default 90)
+	MinQuality    int  // Lowest JPEG quality tried before scaling down further (default 50)
+	Resize        bool // Re-encode and scale images that are over MaxBytes or MaxDimension
+	StripMetadata bool // Remove EXIF/XMP/IPTC and text metadata such as camera details and GPS location
+}
+
+// Images below this size are never scaled further
+const minImageDimension = 200
+
+// PrepareImage applies ImageOptions to an image. With StripMetadata, JPEG and PNG metadata is removed
+// without re-encoding where possible; a JPEG whose EXIF orientation rotates it is re-encoded with the
+// rotation applied, so it still displays the right way up. With Resize, images over the limits are
+// re-encoded as JPEG, stepping the quality down and then scaling until they fit. WebP images have no
+// lossless metadata stripping, so StripMetadata re-encodes them as JPEG too. Transparent areas become white,
+// and animated GIFs keep only their first frame.
+//
+// JPEG, PNG, GIF and WebP can be decoded. Other formats are returned unchanged if they are already within
+// MaxBytes, and rejected with ErrUnsupportedImage otherwise.
+//
+// Example:
+//
+//	photo, _ := os.ReadFile("holiday.jpg")
+//	prepared, err := firefly.PrepareImage(photo, &firefly.ImageOptions{Resize: true, StripMetadata: true})
+func PrepareImage(data []byte, options *ImageOptions) ([]byte, error) {
+	opts := ImageOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = MaxImageBytes
+	}
+	if opts.MaxDimension <= 0 {
+		opts.MaxDimension = 2000
+	}
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		opts.Quality = 90
+	}
+	if opts.MinQuality <= 0 || opts.MinQuality > opts.Quality {
+		opts.MinQuality = min(50, opts.Quality)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if len(data) <= opts.MaxBytes {
+			return data, nil
+		}
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedImage, err)
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	reencode := false
+	if opts.StripMetadata {
+		switch {
+		case format == "jpeg" && orientation > 1:
+			reencode = true
+		case format == "jpeg":
+			data = stripJPEGMetadata(data)
+		case format == "png":
+			data = stripPNGMetadata(data)
+		case format == "webp":
+			reencode = true
+		}
+	}
+
+	oversized := len(data) > opts.MaxBytes || max(config.Width, config.Height) > opts.MaxDimension
+	if !opts.Resize {
+		if len(data) > opts.MaxBytes {
+			return nil, fmt.Errorf("%w: image is %d bytes, maximum is %d", ErrMediaTooLarge, len(data), opts.MaxBytes)
+		}
+		// Only re-encode to apply the orientation, keeping the original dimensions
+		opts.MaxDimension = max(config.Width, config.Height)
+		oversized = false
+	}
+	if !oversized && !reencode {
+		return data, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrImageProcessing, err)
+	}
+	img = applyOrientation(img, orientation)
+	return shrinkToFit(img, opts)
+}
+
+// shrinkToFit encodes an image as JPEG, lowering quality and then dimensions until it fits in MaxBytes
+func shrinkToFit(img image.Image, opts ImageOptions) ([]byte, error) {
+	img = scaleToFit(img, opts.MaxDimension)
+	for {
+		for quality := opts.Quality; quality >= opts.MinQuality; quality -= 10 {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrImageProcessing, err)
+			}
+			if buf.Len() <= opts.MaxBytes {
+				return buf.Bytes(), nil
+			}
+		}
+		longest := max(img.Bounds().Dx(), img.Bounds().Dy())
+		if longest <= minImageDimension {
+			return nil, fmt.Errorf("%w: could not fit image in %d bytes", ErrMediaTooLarge, opts.MaxBytes)
+		}
+		img = scaleToFit(img, longest*3/4)
+	}
+}
+
+// scaleToFit scales an image down so its longest side is at most limit, averaging the source pixels
+// covered by each destination pixel. Transparent pixels are composited onto white.
+func scaleToFit(src image.Image, limit int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > limit {
+		width = max(1, width*limit/longest)
+		height = max(1, height*limit/longest)
+	}
+
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, src, bounds.Min, draw.Over)
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return flat
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+			var r, g, b, count uint32
+			for sy := y0; sy < y1; sy++ {
+				offset := flat.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(flat.Pix[offset])
+					g += uint32(flat.Pix[offset+1])
+					b += uint32(flat.Pix[offset+2])
+					offset += 4
+					count++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / count), G: uint8(g / count), B: uint8(b / count), A: 255})
+		}
+	}
+	return dst
+}
+
+// applyOrientation rotates and mirrors an image according to its EXIF orientation (1-8)
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, src.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+// jpegSegments calls fn for each marker segment before the image data, stopping if fn returns false.
+// It returns the offset of the start-of-scan marker, or -1 if the data is malformed.
+func jpegSegments(data []byte, fn func(marker byte, segment []byte) bool) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return -1
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return -1
+		}
+		marker := data[pos+1]
+		if marker == 0xDA {
+			return pos
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return -1
+		}
+		if !fn(marker, data[pos:end]) {
+			return pos
+		}
+		pos = end
+	}
+	return -1
+}
+
+// stripJPEGMetadata removes APP1 (EXIF/XMP), APP13 (IPTC) and comment segments, keeping colour profiles
+func stripJPEGMetadata(data []byte) []byte {
+	var out bytes.Buffer
+	out.Write(data[:2])
+	scan := jpegSegments(data, func(marker byte, segment []byte) bool {
+		if marker != 0xE1 && marker != 0xED && marker != 0xFE {
+			out.Write(segment)
+		}
+		return true
+	})
+	if scan < 0 {
+		return data
+	}
+	out.Write(data[scan:])
+	return out.Bytes()
+}
+
+// jpegOrientation returns the EXIF orientation tag of a JPEG, or 1 if there isn't one
+func jpegOrientation(data []byte) int {
+	orientation := 1
+	jpegSegments(data, func(marker byte, segment []byte) bool {
+		if marker != 0xE1 || len(segment) < 10 || string(segment[4:10]) != "Exif\x00\x00" {
+			return true
+		}
+		tiff := segment[10:]
+		if len(tiff) < 8 {
+			return false
+		}
+		var order binary.ByteOrder
+		switch string(tiff[:2]) {
+		case "II":
+			order = binary.LittleEndian
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return false
+		}
+		ifd := int(order.Uint32(tiff[4:8]))
+		if ifd+2 > len(tiff) {
+			return false
+		}
+		entries := int(order.Uint16(tiff[ifd : ifd+2]))
+		for i := 0; i < entries; i++ {
+			entry := ifd + 2 + i*12
+			if entry+12 > len(tiff) {
+				break
+			}
+			if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+				orientation = int(order.Uint16(tiff[entry+8 : entry+10]))
+				break
+			}
+		}
+		return false
+	})
+	return orientation
+}
+
+// pngMetadataChunks are ancillary PNG chunks that carry text or EXIF metadata
+var pngMetadataChunks = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"eXIf": true,
+	"tIME": true,
+}
+
+// stripPNGMetadata removes text, EXIF and timestamp chunks from a PNG
+func stripPNGMetadata(data []byte) []byte {
+	const signatureLength = 8
+	if len(data) < signatureLength {
+		return data
+	}
+	var out bytes.Buffer
+	out.Write(data[:signatureLength])
+	pos := signatureLength
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return data
+		}
+		if !pngMetadataChunks[string(data[pos+4:pos+8])] {
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,260 @@
+package firefly
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/bluesky-social/jetstream/pkg/models"
+)
+
+var errMalformedJSON = errors.New("malformed JSON")
+
+// decodeJetstreamEvent decodes a Jetstream message envelope without reflection. Commit events dominate the
+// stream, so their fields are scanned directly and the record is kept as a sub-slice of data rather than
+// copied; data must not be reused while the event is alive. Account and identity payloads are rare and go
+// through encoding/json. Input the scanner doesn't understand is decoded entirely by encoding/json, so
+// behavior (and error messages) match a plain json.Unmarshal, except that a null record is left nil.
+func decodeJetstreamEvent(data []byte, event *models.Event) error {
+	if err := scanJetstreamEvent(data, event); err != nil {
+		*event = models.Event{}
+		return json.Unmarshal(data, event)
+	}
+	return nil
+}
+
+func scanJetstreamEvent(data []byte, event *models.Event) error {
+	s := &jsonScanner{data: data}
+	err := s.object(func(key []byte) error {
+		switch string(key) {
+		case "did":
+			value, err := s.string()
+			event.Did = value
+			return err
+		case "time_us":
+			value, err := s.int()
+			event.TimeUS = value
+			return err
+		case "kind":
+			value, err := s.string()
+			event.Kind = value
+			return err
+		case "commit":
+			if s.null() {
+				return nil
+			}
+			event.Commit = &models.Commit{}
+			return s.commit(event.Commit)
+		case "account":
+			raw, err := s.skip()
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal(raw, &event.Account)
+		case "identity":
+			raw, err := s.skip()
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal(raw, &event.Identity)
+		default:
+			_, err := s.skip()
+			return err
+		}
+	})
+	if err != nil {
+		return err
+	}
+	s.space()
+	if s.pos != len(s.data) {
+		return errMalformedJSON
+	}
+	return nil
+}
+
+// jsonScanner is a minimal forward-only JSON reader for the Jetstream envelope
+type jsonScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *jsonScanner) commit(commit *models.Commit) error {
+	return s.object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "rev":
+			commit.Rev, err = s.string()
+		case "operation":
+			commit.Operation, err = s.string()
+		case "collection":
+			commit.Collection, err = s.string()
+		case "rkey":
+			commit.RKey, err = s.string()
+		case "cid":
+			commit.CID, err = s.string()
+		case "record":
+			var raw []byte
+			raw, err = s.skip()
+			if err == nil && string(raw) != "null" {
+				commit.Record = raw
+			}
+		default:
+			_, err = s.skip()
+		}
+		return err
+	})
+}
+
+// object walks the members of an object, calling member with each key positioned at the value
+func (s *jsonScanner) object(member func(key []byte) error) error {
+	s.space()
+	if !s.consume('{') {
+		return errMalformedJSON
+	}
+	s.space()
+	if s.consume('}') {
+		return nil
+	}
+	for {
+		s.space()
+		key, escaped, err := s.rawString()
+		if err != nil || escaped {
+			return errMalformedJSON
+		}
+		s.space()
+		if !s.consume(':') {
+			return errMalformedJSON
+		}
+		s.space()
+		if err := member(key); err != nil {
+			return err
+		}
+		s.space()
+		if s.consume(',') {
+			continue
+		}
+		if s.consume('}') {
+			return nil
+		}
+		return errMalformedJSON
+	}
+}
+
+func (s *jsonScanner) space() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *jsonScanner) consume(c byte) bool {
+	if s.pos < len(s.data) && s.data[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+func (s *jsonScanner) null() bool {
+	if len(s.data)-s.pos >= 4 && string(s.data[s.pos:s.pos+4]) == "null" {
+		s.pos += 4
+		return true
+	}
+	return false
+}
+
+// rawString returns the contents of a string without its quotes, and whether it contains escapes
+func (s *jsonScanner) rawString() ([]byte, bool, error) {
+	if !s.consume('"') {
+		return nil, false, errMalformedJSON
+	}
+	start := s.pos
+	escaped := false
+	for s.pos < len(s.data) {
+		switch c := s.data[s.pos]; {
+		case c == '"':
+			s.pos++
+			return s.data[start : s.pos-1], escaped, nil
+		case c == '\\':
+			escaped = true
+			s.pos += 2
+		case c < 0x20:
+			return nil, false, errMalformedJSON
+		default:
+			s.pos++
+		}
+	}
+	return nil, false, errMalformedJSON
+}
+
+func (s *jsonScanner) string() (string, error) {
+	if s.null() {
+		return "", nil
+	}
+	start := s.pos
+	raw, escaped, err := s.rawString()
+	if err != nil {
+		return "", err
+	}
+	if !escaped {
+		return string(raw), nil
+	}
+	var value string
+	err = json.Unmarshal(s.data[start:s.pos], &value)
+	return value, err
+}
+
+func (s *jsonScanner) int() (int64, error) {
+	start := s.pos
+	for s.pos < len(s.data) && (s.data[s.pos] == '-' || (s.data[s.pos] >= '0' && s.data[s.pos] <= '9')) {
+		s.pos++
+	}
+	return strconv.ParseInt(string(s.data[start:s.pos]), 10, 64)
+}
+
+// skip moves past one value of any type and returns its raw bytes
+func (s *jsonScanner) skip() ([]byte, error) {
+	start := s.pos
+	if s.pos >= len(s.data) {
+		return nil, errMalformedJSON
+	}
+	switch s.data[s.pos] {
+	case '"':
+		if _, _, err := s.rawString(); err != nil {
+			return nil, err
+		}
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				if _, _, err := s.rawString(); err != nil {
+					return nil, err
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			s.pos++
+			if depth == 0 {
+				return s.data[start:s.pos], nil
+			}
+		}
+		return nil, errMalformedJSON
+	default:
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case ',', '}', ']', ' ', '\t', '\n', '\r':
+				return s.data[start:s.pos], nil
+			}
+			s.pos++
+		}
+	}
+	return s.data[start:s.pos], nil
+}
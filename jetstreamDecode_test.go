@@ -0,0 +1,154 @@
+package firefly
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bluesky-social/jetstream/pkg/models"
+)
+
+// Envelopes as Jetstream sends them
+var (
+	jetstreamCommit   = []byte(`{"did":"did:plc:eygmaihciaxprqvxpfvl6flk","time_us":1725911162329308,"kind":"commit","commit":{"rev":"3l3qo2vutsw2b","operation":"create","collection":"app.bsky.feed.post","rkey":"3l3qo2vuowo2b","record":{"$type":"app.bsky.feed.post","createdAt":"2024-09-09T19:46:02.102Z","langs":["en"],"text":"Hello \"world\" — from the firehose","facets":[{"index":{"byteStart":0,"byteEnd":5},"features":[{"$type":"app.bsky.richtext.facet#link","uri":"https://example.com"}]}]},"cid":"bafyreidwaivazkwu67xztlmuobx35hs2lnfh3kolmgfmucldvhd3sgzcqi"}}`)
+	jetstreamDelete   = []byte(`{"did":"did:plc:rfov6bpyztcnedeyyzgfq42k","time_us":1725516666833633,"kind":"commit","commit":{"rev":"3l3f6nzl3cv2s","operation":"delete","collection":"app.bsky.graph.follow","rkey":"3l3dn7tku762u"}}`)
+	jetstreamIdentity = []byte(`{"did":"did:plc:ufbl4k27gp6kzas5glhz7fim","time_us":1725516665234703,"kind":"identity","identity":{"did":"did:plc:ufbl4k27gp6kzas5glhz7fim","handle":"yohenrique.bsky.social","seq":1409752997,"time":"2024-09-05T06:11:04.870Z"}}`)
+	jetstreamAccount  = []byte(`{"did":"did:plc:ufbl4k27gp6kzas5glhz7fim","time_us":1725516665333808,"kind":"account","account":{"active":true,"did":"did:plc:ufbl4k27gp6kzas5glhz7fim","seq":1409753013,"time":"2024-09-05T06:11:04.870Z"}}`)
+)
+
+func TestDecodeJetstreamEventMatchesUnmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		fallback bool // whether the scanner gives up and encoding/json decodes it
+	}{
+		{"commit", jetstreamCommit, false},
+		{"delete", jetstreamDelete, false},
+		{"identity", jetstreamIdentity, false},
+		{"account", jetstreamAccount, false},
+		{"whitespace", []byte(" {\n\t\"did\" : \"did:plc:x\" ,\"time_us\": 7, \"kind\":\"commit\", \"commit\" : {\"operation\":\"create\",\"record\": {\"a\": [1, {\"b\": \"}\"}]} } }\n"), false},
+		{"null commit", []byte(`{"did":"did:plc:x","time_us":1,"kind":"commit","commit":null}`), false},
+		{"escaped value", []byte(`{"did":"did:plc:\u0078","time_us":1,"kind":"com\"mit\n"}`), false},
+		{"unknown fields", []byte(`{"did":"did:plc:x","time_us":1,"kind":"commit","extra":{"nested":[true,false,null]},"more":-1.5e3}`), false},
+		// The scanner doesn't handle escaped keys, so these go through the encoding/json fallback
+		{"escaped key fallback", []byte(`{"\u0064id":"did:plc:x","time_us":1,"kind":"commit"}`), true},
+		{"escaped commit key fallback", []byte(`{"did":"did:plc:x","time_us":1,"kind":"commit","commit":{"r\u006bey":"3k","record":{"text":"hi"}}}`), true},
+		{"negative time", []byte(`{"did":"did:plc:x","time_us":-1,"kind":"commit"}`), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var want models.Event
+			if err := json.Unmarshal(test.data, &want); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+			var scanned models.Event
+			if err := scanJetstreamEvent(test.data, &scanned); (err != nil) != test.fallback {
+				t.Errorf("scanJetstreamEvent error = %v, want fallback %v", err, test.fallback)
+			}
+			var got models.Event
+			if err := decodeJetstreamEvent(test.data, &got); err != nil {
+				t.Fatalf("decodeJetstreamEvent: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decodeJetstreamEvent = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// A null record is left nil, unlike json.Unmarshal's "null", since the event converters check for nil
+func TestDecodeJetstreamEventNullRecord(t *testing.T) {
+	var event models.Event
+	data := []byte(`{"did":"did:plc:x","time_us":1,"kind":"commit","commit":{"operation":"delete","record":null}}`)
+	if err := decodeJetstreamEvent(data, &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Commit == nil || event.Commit.Operation != "delete" || event.Commit.Record != nil {
+		t.Errorf("decodeJetstreamEvent = %+v, want a delete commit with no record", event.Commit)
+	}
+}
+
+func TestDecodeJetstreamEventErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ``},
+		{"truncated", `{"did":"did:plc:x","time_us":1`},
+		{"trailing data", `{"did":"did:plc:x"} {}`},
+		{"unterminated string", `{"did":"did:plc:x`},
+		{"fractional time", `{"did":"did:plc:x","time_us":1.5}`},
+		{"wrong type", `{"did":1}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var want models.Event
+			wantErr := json.Unmarshal([]byte(test.data), &want)
+			var got models.Event
+			err := decodeJetstreamEvent([]byte(test.data), &got)
+			if (err == nil) != (wantErr == nil) {
+				t.Fatalf("decodeJetstreamEvent error = %v, json.Unmarshal error = %v", err, wantErr)
+			}
+			if err != nil && err.Error() != wantErr.Error() {
+				t.Errorf("decodeJetstreamEvent error = %q, want %q", err, wantErr)
+			}
+		})
+	}
+}
+
+// benchmarkEnvelopes is the mix the decoder is tuned for: mostly commits, with the occasional identity and
+// account event
+var benchmarkEnvelopes = [][]byte{jetstreamCommit, jetstreamDelete, jetstreamCommit, jetstreamIdentity, jetstreamCommit, jetstreamAccount}
+
+// envelopeBytes is the mean size of the envelopes, for throughput
+func envelopeBytes(envelopes [][]byte) int64 {
+	total := 0
+	for _, data := range envelopes {
+		total += len(data)
+	}
+	return int64(total / len(envelopes))
+}
+
+func BenchmarkDecodeJetstreamEvent(b *testing.B) {
+	b.SetBytes(envelopeBytes(benchmarkEnvelopes))
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		var event models.Event
+		if err := decodeJetstreamEvent(benchmarkEnvelopes[i%len(benchmarkEnvelopes)], &event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeJSONUnmarshal(b *testing.B) {
+	b.SetBytes(envelopeBytes(benchmarkEnvelopes))
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		var event models.Event
+		if err := json.Unmarshal(benchmarkEnvelopes[i%len(benchmarkEnvelopes)], &event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeJetstreamCommit(b *testing.B) {
+	b.SetBytes(int64(len(jetstreamCommit)))
+	b.ReportAllocs()
+	for b.Loop() {
+		var event models.Event
+		if err := decodeJetstreamEvent(jetstreamCommit, &event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeJSONUnmarshalCommit(b *testing.B) {
+	b.SetBytes(int64(len(jetstreamCommit)))
+	b.ReportAllocs()
+	for b.Loop() {
+		var event models.Event
+		if err := json.Unmarshal(jetstreamCommit, &event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// LinkSource identifies where in a post a link was found
+type LinkSource int
+
+const (
+	LinkFromFacet LinkSource = iota
+	LinkFromEmbed
+)
+
+func (ls LinkSource) String() string {
+	switch ls {
+	case LinkFromFacet:
+		return "Facet"
+	case LinkFromEmbed:
+		return "Embed"
+	default:
+		return "Unknown"
+	}
+}
+
+// LinkInfo is a link found in a post by ExtractLinks
+type LinkInfo struct {
+	URL        string     `json:"url"`            // the link as written in the post
+	Normalized string     `json:"normalized"`     // lower-case scheme and host, no fragment, default port or tracking parameters
+	Domain     string     `json:"domain"`         // host of Normalized without a leading "www."
+	Source     LinkSource `json:"source"`         // LinkFromFacet if the link is in the text, even when it is embedded too
+	Text       string     `json:"text,omitempty"` // the post text the link facet covers, or the embed card's title
+
+	// Expanded is where a link to a known URL shortener finally leads, and ExpandedDomain is its domain. Both
+	// are empty until ExpandLinks follows the link.
+	Expanded       string `json:"expanded,omitempty"`
+	ExpandedDomain string `json:"expandedDomain,omitempty"`
+}
+
+func (l LinkInfo) String() string {
+	return fmt.Sprintf("LinkInfo{URL: %s, Domain: %s}", l.URL, l.Destination())
+}
+
+// Destination returns the domain the link ends up at: ExpandedDomain if the link was expanded, otherwise Domain
+func (l LinkInfo) Destination() string {
+	if l.ExpandedDomain != "" {
+		return l.ExpandedDomain
+	}
+	return l.Domain
+}
+
+// KnownShorteners are the domains ExpandLinks follows. Add to it to expand other shorteners.
+var KnownShorteners = []string{
+	"bit.ly", "buff.ly", "cutt.ly", "dlvr.it", "goo.gl", "is.gd", "lnkd.in", "ow.ly", "rb.gy", "rebrand.ly",
+	"s.id", "shorturl.at", "t.co", "t.ly", "tiny.cc", "tinyurl.com", "trib.al",
+}
+
+// trackingParameters are query parameters dropped by normalization; keys ending in "*" are prefixes
+var trackingParameters = []string{"utm_*", "fbclid", "gclid", "dclid", "msclkid", "mc_cid", "mc_eid", "igshid"}
+
+// maxLinkRedirects is the most redirects ExpandLinks follows for one link
+const maxLinkRedirects = 10
+
+// ExtractLinks returns the links in a post's text facets and external embed, in that order, with normalized
+// forms and domains. A link that appears more than once is only returned the first time. It makes no network
+// requests; use ExpandLinks to resolve shortened links.
+//
+// Example:
+//
+//	for _, link := range firefly.ExtractLinks(post) {
+//	    if slices.Contains(blockedDomains, link.Domain) {
+//	        report(post, link)
+//	    }
+//	}
+func ExtractLinks(post *FeedPost) []LinkInfo {
+	if post == nil {
+		return nil
+	}
+	var links []LinkInfo
+	seen := make(map[string]bool)
+	add := func(raw string, source LinkSource, text string) {
+		link, ok := newLinkInfo(raw)
+		if !ok || seen[link.Normalized] {
+			return
+		}
+		seen[link.Normalized] = true
+		link.Source = source
+		link.Text = text
+		links = append(links, link)
+	}
+
+	for _, facet := range post.Facets {
+		if facet.Type != LinkFacet {
+			continue
+		}
+		text := ""
+		if facet.StartIndex >= 0 && facet.StartIndex < facet.EndIndex && facet.EndIndex <= len(post.Text) {
+			text = post.Text[facet.StartIndex:facet.EndIndex]
+		}
+		add(facet.Target, LinkFromFacet, text)
+	}
+	if post.Embed != nil && post.Embed.External != nil {
+		add(post.Embed.External.URL, LinkFromEmbed, post.Embed.External.Title)
+	}
+	return links
+}
+
+// newLinkInfo parses and normalizes a link, reporting false if it isn't an http(s) URL
+func newLinkInfo(raw string) (LinkInfo, bool) {
+	normalized, domain, ok := normalizeLink(raw)
+	if !ok {
+		return LinkInfo{}, false
+	}
+	return LinkInfo{URL: raw, Normalized: normalized, Domain: domain}, true
+}
+
+// normalizeLink returns the normalized form and domain of an http(s) URL
+func normalizeLink(raw string) (string, string, bool) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		u.Host = net.JoinHostPort(host, port)
+	} else {
+		u.Host = host
+	}
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "/" {
+		u.Path = ""
+		u.RawPath = ""
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for key := range query {
+			if isTrackingParameter(key) {
+				query.Del(key)
+			}
+		}
+		u.RawQuery = query.Encode() // Encode sorts by key
+	}
+	return u.String(), strings.TrimPrefix(host, "www."), true
+}
+
+// isTrackingParameter reports whether a query parameter only tracks where a click came from
+func isTrackingParameter(key string) bool {
+	key = strings.ToLower(key)
+	for _, tracking := range trackingParameters {
+		if prefix, ok := strings.CutSuffix(tracking, "*"); (ok && strings.HasPrefix(key, prefix)) || key == tracking {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandLinks follows the redirects of links whose domain is in KnownShorteners, filling in Expanded and
+// ExpandedDomain, so a bot can check where a shortened link really goes. Each link costs a HEAD request per
+// redirect, made with the client's HTTP client; links to other domains are left alone. Links that fail are
+// left unexpanded and their errors are returned together after the rest are done.
+//
+// Example:
+//
+//	links := firefly.ExtractLinks(post)
+//	if err := client.ExpandLinks(ctx, links); err != nil {
+//	    log.Println("some links could not be expanded:", err)
+//	}
+//	for _, link := range links {
+//	    fmt.Println(link.URL, "->", link.Destination())
+//	}
+func (f *Firefly) ExpandLinks(ctx context.Context, links []LinkInfo) error {
+	httpClient := http.Client{}
+	if base := f.currentClient().Client; base != nil {
+		httpClient = *base
+	}
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	var errs []error
+	for i := range links {
+		if !slices.Contains(KnownShorteners, links[i].Domain) {
+			continue
+		}
+		expanded, err := followRedirects(ctx, &httpClient, links[i].Normalized)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", links[i].URL, err))
+			continue
+		}
+		normalized, domain, ok := normalizeLink(expanded)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: redirects to unsupported URL %s", links[i].URL, expanded))
+			continue
+		}
+		links[i].Expanded = normalized
+		links[i].ExpandedDomain = domain
+	}
+	return errors.Join(errs...)
+}
+
+// followRedirects sends HEAD requests along a redirect chain and returns the first URL that doesn't redirect
+func followRedirects(ctx context.Context, client *http.Client, link string) (string, error) {
+	current := link
+	for range maxLinkRedirects {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, current, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			return current, nil
+		}
+		next, err := resp.Request.URL.Parse(location)
+		if err != nil {
+			return "", fmt.Errorf("bad redirect: %w", err)
+		}
+		current = next.String()
+	}
+	return "", fmt.Errorf("more than %d redirects", maxLinkRedirects)
+}
@@ -0,0 +1,337 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/atproto/syntax"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+)
+
+var (
+	ErrNotOwnList = errors.New("list does not belong to the authenticated user")
+	ErrListSync   = errors.New("failed to sync list")
+)
+
+// ListPurpose identifies what a list is used for
+type ListPurpose string
+
+const (
+	ListPurposeCurate    ListPurpose = "app.bsky.graph.defs#curatelist"    // Feeds and reply gates
+	ListPurposeModerate  ListPurpose = "app.bsky.graph.defs#modlist"       // Muting or blocking in bulk
+	ListPurposeReference ListPurpose = "app.bsky.graph.defs#referencelist" // Starter packs
+)
+
+// List represents a BlueSky user list
+type List struct {
+	URI         string      `json:"uri" cborgen:"uri"`
+	CID         string      `json:"cid" cborgen:"cid"`
+	Name        string      `json:"name" cborgen:"name"`
+	Purpose     ListPurpose `json:"purpose" cborgen:"purpose"`
+	Description *string     `json:"description,omitempty" cborgen:"description,omitempty"`
+	Avatar      *string     `json:"avatar,omitempty" cborgen:"avatar,omitempty"`
+	Creator     *User       `json:"creator,omitempty" cborgen:"creator,omitempty"`
+	ItemCount   *int        `json:"itemCount,omitempty" cborgen:"itemCount,omitempty"`
+	IndexedAt   *time.Time  `json:"indexedAt,omitempty" cborgen:"indexedAt,omitempty"`
+	Raw         *bsky.GraphDefs_ListView
+}
+
+func (l *List) String() string {
+	return fmt.Sprintf("List{Name: %s, URI: %s}", l.Name, l.URI)
+}
+
+// OldToNewList converts bsky list views to Firefly lists
+func OldToNewList(oldList *bsky.GraphDefs_ListView) (*List, error) {
+	if oldList == nil {
+		return nil, fmt.Errorf("%w: nil list", ErrBadResponse)
+	}
+	list := &List{
+		URI:         oldList.Uri,
+		CID:         oldList.Cid,
+		Name:        oldList.Name,
+		Description: oldList.Description,
+		Avatar:      oldList.Avatar,
+		Raw:         oldList,
+	}
+	if oldList.Purpose != nil {
+		list.Purpose = ListPurpose(*oldList.Purpose)
+	}
+	if oldList.ListItemCount != nil {
+		count := int(*oldList.ListItemCount)
+		list.ItemCount = &count
+	}
+	if indexedAt, err := time.Parse(time.RFC3339, oldList.IndexedAt); err == nil {
+		list.IndexedAt = &indexedAt
+	}
+	if oldList.Creator != nil {
+		creator, err := OldToNewUser(oldList.Creator)
+		if err != nil {
+			return nil, err
+		}
+		list.Creator = creator
+	}
+	return list, nil
+}
+
+// GetLists returns one page of the lists created by actor, along with the cursor for the next page.
+// The returned cursor is empty when there are no more pages.
+func (f *Firefly) GetLists(ctx context.Context, actor string, cursor string, limit int) ([]*List, string, error) {
+	result, err := bsky.GraphGetLists(ctx, f.api, actor, cursor, int64(limit))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+
+	lists := make([]*List, 0, len(result.Lists))
+	for _, oldList := range result.Lists {
+		list, err := OldToNewList(oldList)
+		if err != nil {
+			return nil, "", err
+		}
+		lists = append(lists, list)
+	}
+
+	nextCursor := ""
+	if result.Cursor != nil {
+		nextCursor = *result.Cursor
+	}
+	return lists, nextCursor, nil
+}
+
+// GetListMembers returns one page of the accounts on the list at listURI, along with the cursor for the next page.
+// The returned cursor is empty when there are no more pages.
+func (f *Firefly) GetListMembers(ctx context.Context, listURI string, cursor string, limit int) ([]*User, string, error) {
+	result, err := bsky.GraphGetList(ctx, f.api, cursor, int64(limit), listURI)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+
+	users := make([]*User, 0, len(result.Items))
+	for _, item := range result.Items {
+		if item == nil {
+			continue
+		}
+		user, err := OldToNewUser(item.Subject)
+		if err != nil {
+			return nil, "", err
+		}
+		users = append(users, user)
+	}
+
+	nextCursor := ""
+	if result.Cursor != nil {
+		nextCursor = *result.Cursor
+	}
+	return users, nextCursor, nil
+}
+
+// IsInList reports whether actor is on the list at listURI. actor is a DID or anything ParseActor accepts.
+// Accounts hidden from list views, such as suspended accounts, are reported as not on the list.
+func (f *Firefly) IsInList(ctx context.Context, listURI string, actor string) (bool, error) {
+	did, err := f.resolveActor(ctx, actor)
+	if err != nil {
+		return false, err
+	}
+	cursor := ""
+	for {
+		members, next, err := f.GetListMembers(ctx, listURI, cursor, 100)
+		if err != nil {
+			return false, err
+		}
+		for _, member := range members {
+			if member.Did == did {
+				return true, nil
+			}
+		}
+		if next == "" || len(members) == 0 {
+			return false, nil
+		}
+		cursor = next
+	}
+}
+
+// SyncListOptions holds the settings for SyncList; set them with SyncListOption functions
+type SyncListOptions struct {
+	DryRun    bool // Compute the changes without applying them
+	BatchSize int  // Writes per applyWrites call (default and max 200)
+}
+
+// SyncListOption configures SyncList
+type SyncListOption func(*SyncListOptions)
+
+// SyncListDryRun reports which accounts would be added and removed without changing the list
+func SyncListDryRun() SyncListOption {
+	return func(o *SyncListOptions) { o.DryRun = true }
+}
+
+// SyncListBatchSize sets how many list items are written per applyWrites call (1-200)
+func SyncListBatchSize(size int) SyncListOption {
+	return func(o *SyncListOptions) { o.BatchSize = size }
+}
+
+// SyncListResult summarizes a SyncList run
+type SyncListResult struct {
+	DryRun    bool     `json:"dryRun"`
+	Added     []string `json:"added"`     // DIDs added to the list
+	Removed   []string `json:"removed"`   // DIDs removed from the list
+	Unchanged int      `json:"unchanged"` // desired members that were already on the list
+}
+
+func (r SyncListResult) String() string {
+	return fmt.Sprintf("SyncListResult{Added: %d, Removed: %d, Unchanged: %d, DryRun: %t}", len(r.Added), len(r.Removed), r.Unchanged, r.DryRun)
+}
+
+// SyncList makes the membership of one of the authenticated user's lists match desiredMembers, adding missing
+// accounts and removing everyone else, so a moderation or curation list can be driven from an external
+// source of truth. Members are DIDs or anything ParseActor accepts. Changes are applied in batches with
+// applyWrites; if a batch fails, the result covers the batches that succeeded.
+//
+// The current members are read from the list's records rather than the list view, so accounts hidden from
+// list views, and duplicate entries for the same account, are cleaned up too.
+//
+// Example:
+//
+//	result, err := client.SyncList(ctx, listURI, []string{"spammer1.bsky.social", "did:plc:abc123"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("added %d, removed %d\n", len(result.Added), len(result.Removed))
+func (f *Firefly) SyncList(ctx context.Context, listURI string, desiredMembers []string, options ...SyncListOption) (*SyncListResult, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	opts := SyncListOptions{BatchSize: maxApplyWrites}
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.BatchSize <= 0 || opts.BatchSize > maxApplyWrites {
+		opts.BatchSize = maxApplyWrites
+	}
+
+	if err := f.checkOwnList(listURI); err != nil {
+		return nil, err
+	}
+
+	desired := make(map[string]struct{}, len(desiredMembers))
+	for _, member := range desiredMembers {
+		did, err := f.resolveActor(ctx, member)
+		if err != nil {
+			return nil, err
+		}
+		desired[did] = struct{}{}
+	}
+
+	// Record keys of the current items, per member DID
+	current := make(map[string][]string)
+	for record, err := range f.ListCollection(ctx, f.Self.Did, CollectionListItem) {
+		if err != nil {
+			return nil, err
+		}
+		item, ok := record.Value.(*bsky.GraphListitem)
+		if !ok || item.List != listURI {
+			continue
+		}
+		current[item.Subject] = append(current[item.Subject], record.RKey)
+	}
+
+	// Each write carries the DID to report once it's applied; duplicate cleanups report nothing
+	type listChange struct {
+		write   *atproto.RepoApplyWrites_Input_Writes_Elem
+		added   string
+		removed string
+	}
+	result := &SyncListResult{DryRun: opts.DryRun}
+	var changes []listChange
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, did := range slices.Sorted(maps.Keys(desired)) {
+		if rkeys, ok := current[did]; ok {
+			result.Unchanged++
+			// Keep the first entry and drop any duplicates
+			for _, rkey := range rkeys[1:] {
+				changes = append(changes, listChange{write: listItemDelete(rkey)})
+			}
+			continue
+		}
+		changes = append(changes, listChange{
+			write: &atproto.RepoApplyWrites_Input_Writes_Elem{
+				RepoApplyWrites_Create: &atproto.RepoApplyWrites_Create{
+					Collection: CollectionListItem,
+					Value: &lexutil.LexiconTypeDecoder{Val: &bsky.GraphListitem{
+						List:      listURI,
+						Subject:   did,
+						CreatedAt: now,
+					}},
+				},
+			},
+			added: did,
+		})
+	}
+	for _, did := range slices.Sorted(maps.Keys(current)) {
+		if _, ok := desired[did]; ok {
+			continue
+		}
+		for i, rkey := range current[did] {
+			change := listChange{write: listItemDelete(rkey)}
+			if i == 0 {
+				change.removed = did
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	record := func(batch []listChange) {
+		for _, change := range batch {
+			if change.added != "" {
+				result.Added = append(result.Added, change.added)
+			}
+			if change.removed != "" {
+				result.Removed = append(result.Removed, change.removed)
+			}
+		}
+	}
+	if opts.DryRun {
+		record(changes)
+		return result, nil
+	}
+	for batch := range slices.Chunk(changes, opts.BatchSize) {
+		writes := make([]*atproto.RepoApplyWrites_Input_Writes_Elem, len(batch))
+		for i, change := range batch {
+			writes[i] = change.write
+		}
+		if _, err := atproto.RepoApplyWrites(ctx, f.api, &atproto.RepoApplyWrites_Input{
+			Repo:   f.Self.Did,
+			Writes: writes,
+		}); err != nil {
+			return result, fmt.Errorf("%w: %w", ErrListSync, err)
+		}
+		record(batch)
+	}
+	return result, nil
+}
+
+// checkOwnList returns ErrNotOwnList unless listURI is a list in the authenticated user's repo
+func (f *Firefly) checkOwnList(listURI string) error {
+	uri, err := syntax.ParseATURI(listURI)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidUri, err)
+	}
+	if uri.Authority().String() != f.Self.Did || uri.Collection().String() != CollectionList {
+		return fmt.Errorf("%w: %s", ErrNotOwnList, listURI)
+	}
+	return nil
+}
+
+// listItemDelete is an applyWrites delete of one of the authenticated user's list items
+func listItemDelete(rkey string) *atproto.RepoApplyWrites_Input_Writes_Elem {
+	return &atproto.RepoApplyWrites_Input_Writes_Elem{
+		RepoApplyWrites_Delete: &atproto.RepoApplyWrites_Delete{
+			Collection: CollectionListItem,
+			Rkey:       rkey,
+		},
+	}
+}
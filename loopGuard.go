@@ -0,0 +1,172 @@
+package firefly
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	"github.com/bluesky-social/indigo/atproto/syntax"
+)
+
+// SelfRelation describes how a post relates to the authenticated user. Bots use it to avoid replying to
+// themselves or being pulled into loops with other bots.
+type SelfRelation struct {
+	Authored   bool `json:"authored"`   // Self wrote the post
+	Descendant bool `json:"descendant"` // the post replies to Self, or sits in a thread Self started
+	Quotes     bool `json:"quotes"`     // the post quotes one of Self's posts
+	Mentions   bool `json:"mentions"`   // the post mentions Self
+}
+
+func (r SelfRelation) String() string {
+	return fmt.Sprintf("SelfRelation{Authored: %t, Descendant: %t, Quotes: %t, Mentions: %t}",
+		r.Authored, r.Descendant, r.Quotes, r.Mentions)
+}
+
+// RelationToSelf reports how post relates to the authenticated user. It only looks at the post itself, so
+// Descendant covers direct replies and threads rooted at Self's posts, not replies further down a thread
+// started by someone else. Returns the zero value when not logged in.
+func (f *Firefly) RelationToSelf(post *FeedPost) SelfRelation {
+	var relation SelfRelation
+	if f.Self == nil || post == nil {
+		return relation
+	}
+	relation.Authored = (post.Author != nil && post.Author.Did == f.Self.Did) || f.isSelfURI(post.URI)
+	if post.ReplyInfo != nil {
+		relation.Descendant = (post.ReplyInfo.ReplyTarget != nil && f.isSelfURI(post.ReplyInfo.ReplyTarget.URI)) ||
+			(post.ReplyInfo.ReplyRoot != nil && f.isSelfURI(post.ReplyInfo.ReplyRoot.URI))
+	}
+	if post.Embed != nil && post.Embed.Record != nil {
+		relation.Quotes = f.isSelfURI(post.Embed.Record.URI)
+	}
+	for _, facet := range post.Facets {
+		if facet.Type == MentionFacet && facet.Target == f.Self.Did {
+			relation.Mentions = true
+			break
+		}
+	}
+	return relation
+}
+
+// EventRelationToSelf is RelationToSelf for a firehose event. Events without a post only report Authored.
+func (f *Firefly) EventRelationToSelf(event *FirehoseEvent) SelfRelation {
+	if f.Self == nil || event == nil {
+		return SelfRelation{}
+	}
+	relation := f.RelationToSelf(event.Post)
+	relation.Authored = relation.Authored || event.Repo == f.Self.Did
+	return relation
+}
+
+// NotificationRelationToSelf is RelationToSelf for a notification's post. Notifications without a post only
+// report Authored.
+func (f *Firefly) NotificationRelationToSelf(notif *Notification) SelfRelation {
+	if f.Self == nil || notif == nil {
+		return SelfRelation{}
+	}
+	relation := f.RelationToSelf(notif.LinkedPost)
+	relation.Authored = relation.Authored || (notif.LinkedUser != nil && notif.LinkedUser.Did == f.Self.Did)
+	return relation
+}
+
+// isSelfURI reports whether an AT URI points into the authenticated user's repo
+func (f *Firefly) isSelfURI(uri string) bool {
+	if uri == "" {
+		return false
+	}
+	aturi, err := syntax.ParseATURI(uri)
+	if err != nil {
+		return false
+	}
+	authority := aturi.Authority().String()
+	return authority == f.Self.Did || (f.Self.Handle != "" && authority == f.Self.Handle)
+}
+
+// LoopGuard decides whether a bot should act on a trigger. It rejects posts written by the authenticated
+// user and remembers the most recently handled post URIs, so the same post arriving from both the firehose
+// and notifications, or twice after a reconnect, is only handled once. It is safe for concurrent use.
+type LoopGuard struct {
+	f        *Firefly
+	capacity int
+
+	mu    sync.Mutex
+	order *list.List               // handled URIs, most recent first
+	seen  map[string]*list.Element // URI to its element in order
+}
+
+// NewLoopGuard creates a LoopGuard that remembers up to size handled URIs (default 10,000)
+//
+// Example:
+//
+//	guard := client.NewLoopGuard(0)
+//	for event := range events {
+//	    if !guard.ShouldHandleEvent(event) || !client.EventRelationToSelf(event).Mentions {
+//	        continue
+//	    }
+//	    reply(event)
+//	}
+func (f *Firefly) NewLoopGuard(size int) *LoopGuard {
+	if size <= 0 {
+		size = 10000
+	}
+	return &LoopGuard{
+		f:        f,
+		capacity: size,
+		order:    list.New(),
+		seen:     make(map[string]*list.Element),
+	}
+}
+
+// ShouldHandle reports whether post is worth acting on: it must have a URI, must not be written by the
+// authenticated user, and must not have been handled already. A true result marks the post as handled.
+func (g *LoopGuard) ShouldHandle(post *FeedPost) bool {
+	if post == nil || post.URI == "" || g.f.RelationToSelf(post).Authored {
+		return false
+	}
+	return g.claim(post.URI)
+}
+
+// ShouldHandleEvent is ShouldHandle for a firehose event's post. Events without a post are rejected.
+func (g *LoopGuard) ShouldHandleEvent(event *FirehoseEvent) bool {
+	if event == nil || event.Post == nil || g.f.EventRelationToSelf(event).Authored {
+		return false
+	}
+	return g.ShouldHandle(event.Post)
+}
+
+// ShouldHandleNotification is ShouldHandle for a notification's post. Notifications without a post are rejected.
+func (g *LoopGuard) ShouldHandleNotification(notif *Notification) bool {
+	if notif == nil || notif.LinkedPost == nil || g.f.NotificationRelationToSelf(notif).Authored {
+		return false
+	}
+	return g.ShouldHandle(notif.LinkedPost)
+}
+
+// Handled reports whether uri has been handled, without marking it
+func (g *LoopGuard) Handled(uri string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.seen[uri]
+	return ok
+}
+
+// MarkHandled records uri as handled, for triggers acted on outside the guard
+func (g *LoopGuard) MarkHandled(uri string) {
+	g.claim(uri)
+}
+
+// claim marks uri as handled, returning false if it already was
+func (g *LoopGuard) claim(uri string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if element, ok := g.seen[uri]; ok {
+		g.order.MoveToFront(element)
+		return false
+	}
+	g.seen[uri] = g.order.PushFront(uri)
+	if g.order.Len() > g.capacity {
+		oldest := g.order.Back()
+		g.order.Remove(oldest)
+		delete(g.seen, oldest.Value.(string))
+	}
+	return true
+}
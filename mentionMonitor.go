@@ -0,0 +1,300 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MentionSource identifies how a mention was discovered
+type MentionSource int
+
+const (
+	MentionFromNotification MentionSource = iota
+	MentionFromSearch
+)
+
+func (ms MentionSource) String() string {
+	switch ms {
+	case MentionFromNotification:
+		return "Notification"
+	case MentionFromSearch:
+		return "Search"
+	default:
+		return "Unknown"
+	}
+}
+
+// Mention is a post that mentions or quotes the authenticated user
+type Mention struct {
+	Post       *FeedPost          `json:"post"`
+	Source     MentionSource      `json:"source"`
+	Reason     NotificationReason `json:"reason"` // NewMention, NewQuote or NewReply for notifications; UnknownReason for search results
+	DetectedAt time.Time          `json:"detectedAt"`
+}
+
+func (m Mention) String() string {
+	return fmt.Sprintf("Mention{Source: %s, Post: %s}", m.Source, m.Post.URI)
+}
+
+// MentionMonitorOptions configures a MentionMonitor
+type MentionMonitorOptions struct {
+	NotificationInterval time.Duration  // Time between notification polls (default 1 minute)
+	SearchInterval       time.Duration  // Time between searches (default 10 minutes)
+	IncludeReplies       bool           // Also report replies to the authenticated user's posts
+	SearchHandleText     bool           // Also search for the handle as plain text, catching untagged mentions
+	BufferSize           int            // Channel buffer size (default 100)
+	OnMention            func(*Mention) // Optional callback invoked for every mention before it is sent on the channel
+}
+
+// MentionMonitor reports posts that mention the authenticated user. It combines notification polling with
+// periodic post searches, which also catch mentions from accounts whose notifications are hidden
+// (blocked, muted or filtered by moderation). Mentions are de-duplicated by post URI across both sources.
+//
+// Only posts indexed after the monitor is created are reported.
+type MentionMonitor struct {
+	f       *Firefly
+	options MentionMonitorOptions
+	since   time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+
+	// notifSince is the IndexedAt of the newest notification handled, and notifAtSince the posts indexed at
+	// that instant. Unlike seen, they are never pruned, since the latest page repeats until new ones arrive.
+	notifSince   time.Time
+	notifAtSince map[string]struct{}
+}
+
+// NewMentionMonitor creates a MentionMonitor for the authenticated user.
+// Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	monitor := client.NewMentionMonitor(&firefly.MentionMonitorOptions{SearchHandleText: true})
+//	mentions, err := monitor.Start(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for mention := range mentions {
+//	    fmt.Printf("%s: %s\n", mention.Post.Author.Handle, mention.Post.Text)
+//	}
+func (f *Firefly) NewMentionMonitor(options *MentionMonitorOptions) *MentionMonitor {
+	if options == nil {
+		options = &MentionMonitorOptions{}
+	}
+	opts := *options
+	if opts.NotificationInterval <= 0 {
+		opts.NotificationInterval = time.Minute
+	}
+	if opts.SearchInterval <= 0 {
+		opts.SearchInterval = 10 * time.Minute
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	now := time.Now()
+	return &MentionMonitor{
+		f:            f,
+		options:      opts,
+		since:        now,
+		seen:         make(map[string]time.Time),
+		notifSince:   now,
+		notifAtSince: make(map[string]struct{}),
+	}
+}
+
+// CheckNotifications polls recent notifications and returns mentions that haven't been seen before, oldest
+// first. It reads back page by page until it reaches notifications it has already seen, so a burst of
+// mentions between polls isn't cut off at the first page.
+func (m *MentionMonitor) CheckNotifications(ctx context.Context) ([]*Mention, error) {
+	if m.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+
+	reasons := []NotificationReason{NewMention, NewQuote}
+	if m.options.IncludeReplies {
+		reasons = append(reasons, NewReply)
+	}
+	m.mu.Lock()
+	since := m.notifSince
+	m.mu.Unlock()
+
+	var notifications []*Notification
+	cursor := ""
+	for {
+		page, err := m.f.GetNotifications(ctx, NotifLimit(50), NotifReasons(reasons...), NotifCursor(cursor))
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, page.Notifications...)
+		// Pages are newest first, so stop once this one reaches back to what was seen last time
+		if page.Cursor == "" || len(page.Notifications) == 0 || page.Notifications[len(page.Notifications)-1].IndexedAt.Before(since) {
+			break
+		}
+		cursor = page.Cursor
+	}
+
+	var found []*Mention
+	for _, notif := range slices.Backward(notifications) {
+		if notif.LinkedPost == nil || !m.newNotification(notif.LinkedPost.URI, notif.IndexedAt) {
+			continue
+		}
+		if mention := m.record(notif.LinkedPost, MentionFromNotification, notif.Reason); mention != nil {
+			found = append(found, mention)
+		}
+	}
+	return found, nil
+}
+
+// CheckSearch searches for recent posts mentioning the authenticated user and returns those that haven't
+// been seen before
+func (m *MentionMonitor) CheckSearch(ctx context.Context) ([]*Mention, error) {
+	if m.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+
+	from := time.Now().Add(-2 * m.options.SearchInterval)
+	if from.Before(m.since) {
+		from = m.since
+	}
+	type mentionSearch struct {
+		query   string
+		options PostSearch
+	}
+	searches := []mentionSearch{
+		{"*", PostSearch{Mentions: m.f.Self.Did, SortBy: SortByLatest, From: &from}},
+	}
+	if m.options.SearchHandleText && m.f.Self.Handle != "" {
+		searches = append(searches, mentionSearch{m.f.Self.Handle, PostSearch{SortBy: SortByLatest, From: &from}})
+	}
+
+	var found []*Mention
+	for _, search := range searches {
+		posts, err := m.f.SearchPosts(ctx, search.query, 100, &search.options)
+		if err != nil {
+			return found, err
+		}
+		for _, post := range posts {
+			if post.Author != nil && post.Author.Did == m.f.Self.Did {
+				continue
+			}
+			if mention := m.record(post, MentionFromSearch, UnknownReason); mention != nil {
+				found = append(found, mention)
+			}
+		}
+	}
+	return found, nil
+}
+
+// Start runs the monitor in the background until ctx is cancelled. Mentions are passed to OnMention (if set)
+// and sent on the returned channel, which is closed when the monitor stops or the client is closed.
+// Errors from background polls are sent to Events.
+func (m *MentionMonitor) Start(ctx context.Context) (chan *Mention, error) {
+	if m.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	if m.f.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	mentions := make(chan *Mention, m.options.BufferSize)
+
+	ctx, cancel := m.f.bindLifetime(ctx)
+	m.f.background.Add(1)
+	go func() {
+		defer m.f.background.Done()
+		defer cancel()
+		defer close(mentions)
+
+		notificationTicker := time.NewTicker(m.options.NotificationInterval)
+		defer notificationTicker.Stop()
+		searchTicker := time.NewTicker(m.options.SearchInterval)
+		defer searchTicker.Stop()
+
+		m.run(ctx, mentions, m.CheckNotifications)
+		m.run(ctx, mentions, m.CheckSearch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-notificationTicker.C:
+				m.run(ctx, mentions, m.CheckNotifications)
+			case <-searchTicker.C:
+				m.run(ctx, mentions, m.CheckSearch)
+				m.prune()
+			}
+		}
+	}()
+
+	return mentions, nil
+}
+
+// run performs a single check and delivers the results
+func (m *MentionMonitor) run(ctx context.Context, mentions chan<- *Mention, check func(context.Context) ([]*Mention, error)) {
+	found, err := check(ctx)
+	if err != nil && ctx.Err() == nil {
+		m.f.emit(SourceScheduler, SeverityError, err)
+	}
+	for _, mention := range found {
+		if m.options.OnMention != nil {
+			m.options.OnMention(mention)
+		}
+		select {
+		case mentions <- mention:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// record marks a post as seen, returning a Mention if it is new
+func (m *MentionMonitor) record(post *FeedPost, source MentionSource, reason NotificationReason) *Mention {
+	if post == nil || post.URI == "" {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.seen[post.URI]; ok {
+		return nil
+	}
+	now := time.Now()
+	m.seen[post.URI] = now
+	return &Mention{Post: post, Source: source, Reason: reason, DetectedAt: now}
+}
+
+// newNotification reports whether a notification is newer than the last one handled, and if so makes it the
+// last one. Notifications must be passed oldest first.
+func (m *MentionMonitor) newNotification(uri string, indexedAt time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case indexedAt.Before(m.notifSince):
+		return false
+	case indexedAt.Equal(m.notifSince):
+		if _, ok := m.notifAtSince[uri]; ok {
+			return false
+		}
+	default:
+		m.notifSince = indexedAt
+		clear(m.notifAtSince)
+	}
+	m.notifAtSince[uri] = struct{}{}
+	return true
+}
+
+// prune forgets posts that are too old to be returned by a search again
+func (m *MentionMonitor) prune() {
+	retention := max(24*time.Hour, 4*m.options.SearchInterval)
+	cutoff := time.Now().Add(-retention)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for uri, seenAt := range m.seen {
+		if seenAt.Before(cutoff) {
+			delete(m.seen, uri)
+		}
+	}
+}
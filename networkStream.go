@@ -0,0 +1,115 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// NetworkStreamOptions configures StreamMyNetwork
+type NetworkStreamOptions struct {
+	Firehose        *FirehoseOptions // Base firehose options; Authors is replaced by the follow list (nil for defaults)
+	RefreshInterval time.Duration    // Time between follow list refreshes (default 30 minutes)
+}
+
+// StreamMyNetwork streams firehose events from every account the authenticated user follows.
+// The follow list is fetched before connecting and refreshed every RefreshInterval; changes are applied
+// to the open connection with a Jetstream options update, so no reconnect is needed.
+//
+// The authenticated user's own DID is always included, so the stream never widens to the whole network
+// when the follow list is empty. Jetstream accepts at most 10,000 DIDs; larger follow lists are truncated
+// and a warning is sent to Events.
+//
+// Example:
+//
+//	events, err := client.StreamMyNetwork(ctx, &firefly.NetworkStreamOptions{
+//	    Firehose: &firefly.FirehoseOptions{Collections: []string{"app.bsky.feed.post"}},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for event := range events {
+//	    fmt.Printf("%s posted: %s\n", event.Repo, event.Post.Text)
+//	}
+func (f *Firefly) StreamMyNetwork(ctx context.Context, options *NetworkStreamOptions) (chan *FirehoseEvent, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	if options == nil {
+		options = &NetworkStreamOptions{}
+	}
+	refreshInterval := options.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = 30 * time.Minute
+	}
+
+	var firehose FirehoseOptions
+	if options.Firehose != nil {
+		firehose = *options.Firehose
+	}
+	firehose.Authors = nil
+
+	dids, err := f.networkDids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	firehose.live = newLiveFilter(dids)
+
+	ctx, cancel := f.bindLifetime(ctx)
+	events, err := f.StreamEvents(ctx, &firehose)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		defer cancel()
+
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				updated, err := f.networkDids(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("failed to refresh follow list: %w", err))
+					}
+					continue
+				}
+				if !slices.Equal(updated, firehose.live.get()) {
+					firehose.live.set(updated)
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// networkDids returns Self's DID followed by the sorted DIDs of every account Self follows
+func (f *Firefly) networkDids(ctx context.Context) ([]string, error) {
+	var dids []string
+	pager := f.FollowsPager(f.Self.Did)
+	pager.PageSize = 100
+	for user, err := range pager.All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		if user.Did != f.Self.Did {
+			dids = append(dids, user.Did)
+		}
+	}
+
+	slices.Sort(dids)
+	dids = append([]string{f.Self.Did}, slices.Compact(dids)...)
+	if len(dids) > maxWantedDids {
+		f.emit(SourceFirehose, SeverityWarning, fmt.Errorf("following %d accounts; only the first %d are streamed", len(dids), maxWantedDids))
+	}
+	return dids, nil
+}
@@ -0,0 +1,195 @@
+package firefly
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// NotificationGroupOptions configures GroupNotifications
+type NotificationGroupOptions struct {
+	Window       time.Duration        // Maximum span of a single group (default 24 hours)
+	MinGroupSize int                  // Smallest burst that is collapsed; smaller bursts stay separate (default 2)
+	Reasons      []NotificationReason // Reasons that can be grouped (default likes, reposts and follows, including via-repost)
+}
+
+// NotificationGroup is a burst of similar notifications, such as several likes of the same post.
+// Notifications that weren't grouped are returned as groups of one.
+type NotificationGroup struct {
+	Reason        NotificationReason `json:"reason"`
+	Subject       string             `json:"subject,omitempty"` // URI of the post the group is about; empty for follows
+	Actors        []*User            `json:"actors"`            // Distinct accounts involved, most recent first
+	Notifications []*Notification    `json:"notifications"`     // The underlying notifications, most recent first
+	Latest        time.Time          `json:"latest"`
+	Earliest      time.Time          `json:"earliest"`
+}
+
+// Count returns the number of distinct accounts in the group
+func (g *NotificationGroup) Count() int {
+	return len(g.Actors)
+}
+
+// IsGrouped reports whether the group holds more than one notification
+func (g *NotificationGroup) IsGrouped() bool {
+	return len(g.Notifications) > 1
+}
+
+// Summary describes the group in a sentence, e.g. "alice.bsky.social and 4 others liked your post"
+func (g *NotificationGroup) Summary() string {
+	who := "Someone"
+	if len(g.Actors) > 0 && g.Actors[0] != nil {
+		who = g.Actors[0].Handle
+		if g.Actors[0].DisplayName != nil && *g.Actors[0].DisplayName != "" {
+			who = *g.Actors[0].DisplayName
+		}
+	}
+	switch others := len(g.Actors) - 1; {
+	case others == 1:
+		who += " and 1 other"
+	case others > 1:
+		who += fmt.Sprintf(" and %d others", others)
+	}
+	return who + " " + reasonPhrase(g.Reason)
+}
+
+func (g NotificationGroup) String() string {
+	return fmt.Sprintf("NotificationGroup{Reason: %s, Count: %d, Subject: %s}", g.Reason, len(g.Actors), g.Subject)
+}
+
+// reasonPhrase completes a summary sentence for a notification reason
+func reasonPhrase(reason NotificationReason) string {
+	switch reason {
+	case NewLike:
+		return "liked your post"
+	case NewRepost:
+		return "reposted your post"
+	case NewFollow:
+		return "followed you"
+	case NewMention:
+		return "mentioned you"
+	case NewReply:
+		return "replied to your post"
+	case NewQuote:
+		return "quoted your post"
+	case StarterPackJoined:
+		return "joined via your starter pack"
+	case NewLikeViaRepost:
+		return "liked your repost"
+	case NewRepostViaRepost:
+		return "reposted your repost"
+	case NewSubscribedPost:
+		return "posted"
+	default:
+		return "interacted with you"
+	}
+}
+
+// GroupNotifications collapses bursts of similar notifications, e.g. "5 people liked your post" or
+// "3 new followers". Notifications with the same reason and subject are grouped when they fall within
+// Window of the group's most recent notification. Groups are returned most recent first.
+// Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	page, _ := client.GetNotifications(ctx, firefly.NotifLimit(100))
+//	for _, group := range firefly.GroupNotifications(page.Notifications, nil) {
+//	    fmt.Println(group.Summary())
+//	}
+func GroupNotifications(notifications []*Notification, options *NotificationGroupOptions) []*NotificationGroup {
+	opts := NotificationGroupOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Window <= 0 {
+		opts.Window = 24 * time.Hour
+	}
+	if opts.MinGroupSize <= 0 {
+		opts.MinGroupSize = 2
+	}
+	if len(opts.Reasons) == 0 {
+		opts.Reasons = []NotificationReason{NewLike, NewRepost, NewFollow, NewLikeViaRepost, NewRepostViaRepost}
+	}
+
+	sorted := make([]*Notification, 0, len(notifications))
+	for _, notif := range notifications {
+		if notif != nil {
+			sorted = append(sorted, notif)
+		}
+	}
+	slices.SortStableFunc(sorted, func(a, b *Notification) int {
+		return b.IndexedAt.Compare(a.IndexedAt)
+	})
+
+	type groupKey struct {
+		reason  NotificationReason
+		subject string
+	}
+	var groups []*NotificationGroup
+	open := make(map[groupKey]*NotificationGroup)
+	for _, notif := range sorted {
+		key := groupKey{reason: notif.Reason, subject: notificationSubject(notif)}
+		if !slices.Contains(opts.Reasons, notif.Reason) {
+			groups = append(groups, newNotificationGroup(key.reason, key.subject, notif))
+			continue
+		}
+		if group, ok := open[key]; ok && group.Latest.Sub(notif.IndexedAt) <= opts.Window {
+			group.add(notif)
+			continue
+		}
+		group := newNotificationGroup(key.reason, key.subject, notif)
+		open[key] = group
+		groups = append(groups, group)
+	}
+
+	// Split bursts that didn't reach the threshold back into single notifications
+	result := make([]*NotificationGroup, 0, len(groups))
+	for _, group := range groups {
+		if len(group.Notifications) > 1 && len(group.Notifications) < opts.MinGroupSize {
+			for _, notif := range group.Notifications {
+				result = append(result, newNotificationGroup(group.Reason, group.Subject, notif))
+			}
+			continue
+		}
+		result = append(result, group)
+	}
+	slices.SortStableFunc(result, func(a, b *NotificationGroup) int {
+		return b.Latest.Compare(a.Latest)
+	})
+	return result
+}
+
+func newNotificationGroup(reason NotificationReason, subject string, notif *Notification) *NotificationGroup {
+	group := &NotificationGroup{
+		Reason:   reason,
+		Subject:  subject,
+		Latest:   notif.IndexedAt,
+		Earliest: notif.IndexedAt,
+	}
+	group.add(notif)
+	return group
+}
+
+// add appends a notification that is no newer than those already in the group
+func (g *NotificationGroup) add(notif *Notification) {
+	g.Notifications = append(g.Notifications, notif)
+	if notif.IndexedAt.Before(g.Earliest) {
+		g.Earliest = notif.IndexedAt
+	}
+	if notif.LinkedUser == nil {
+		return
+	}
+	for _, actor := range g.Actors {
+		if actor.Did == notif.LinkedUser.Did {
+			return
+		}
+	}
+	g.Actors = append(g.Actors, notif.LinkedUser)
+}
+
+// notificationSubject returns the URI of the record a notification is about
+func notificationSubject(notif *Notification) string {
+	if notif.Raw != nil && notif.Raw.ReasonSubject != nil {
+		return *notif.Raw.ReasonSubject
+	}
+	return ""
+}
@@ -0,0 +1,347 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// NewFollowerEvent is dispatched when someone follows the authenticated user
+type NewFollowerEvent struct {
+	Follower     *User         `json:"follower"` // full profile, including counts and description
+	Notification *Notification `json:"notification"`
+}
+
+// MentionEvent is dispatched when a post mentions the authenticated user
+type MentionEvent struct {
+	Post         *FeedPost     `json:"post"`
+	Thread       *Thread       `json:"thread"` // the post with its ancestors; nil if the thread couldn't be loaded
+	Notification *Notification `json:"notification"`
+}
+
+// ReplyEvent is dispatched when someone replies to one of the authenticated user's posts
+type ReplyEvent struct {
+	Reply        *FeedPost     `json:"reply"`
+	Parent       *FeedPost     `json:"parent"` // the post that was replied to; nil if it couldn't be loaded
+	Thread       *Thread       `json:"thread"` // the reply with its ancestors; nil if the thread couldn't be loaded
+	Notification *Notification `json:"notification"`
+}
+
+// NotificationRouterOptions configures a NotificationRouter
+type NotificationRouterOptions struct {
+	PollInterval time.Duration // Time between notification polls (default 1 minute)
+	ParentHeight int           // Ancestors loaded for mention and reply threads (default 10)
+
+	// Governor, if set, must allow a mention or reply before its handlers run, and counts each one it allows
+	// as a reply. Skipped posts are reported to Events as SourceScheduler info. More ancestors are loaded if
+	// the governor's MaxThreadDepth needs them.
+	Governor *ReplyGovernor
+}
+
+// NotificationRouter turns notifications into higher-level events and passes them to registered handlers:
+// new follows become NewFollowerEvents with the follower's full profile, mentions and replies come with their
+// thread. Other reasons can be handled as plain notifications with OnNotification.
+//
+// Handlers run one notification at a time, oldest first. Notifications caused by the authenticated user
+// are skipped, and each notification is dispatched at most once per router.
+type NotificationRouter struct {
+	f       *Firefly
+	options NotificationRouterOptions
+	guard   *LoopGuard
+
+	mu        sync.Mutex
+	since     time.Time
+	followers []func(context.Context, *NewFollowerEvent) error
+	mentions  []func(context.Context, *MentionEvent) error
+	replies   []func(context.Context, *ReplyEvent) error
+	other     map[NotificationReason][]func(context.Context, *Notification) error
+}
+
+// NewNotificationRouter creates a router for the authenticated user. Only notifications indexed after it is
+// created are dispatched by Start. Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	router := client.NewNotificationRouter(nil)
+//	router.OnNewFollower(func(ctx context.Context, event *firefly.NewFollowerEvent) error {
+//	    _, err := client.Follow(ctx, event.Follower.Did)
+//	    return err
+//	})
+//	router.OnMention(func(ctx context.Context, event *firefly.MentionEvent) error {
+//	    return answer(ctx, event.Thread)
+//	})
+//	if err := router.Start(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (f *Firefly) NewNotificationRouter(options *NotificationRouterOptions) *NotificationRouter {
+	if options == nil {
+		options = &NotificationRouterOptions{}
+	}
+	opts := *options
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Minute
+	}
+	if opts.ParentHeight <= 0 {
+		opts.ParentHeight = 10
+	}
+	if opts.Governor != nil {
+		opts.ParentHeight = max(opts.ParentHeight, opts.Governor.parentHeight())
+	}
+	return &NotificationRouter{
+		f:       f,
+		options: opts,
+		guard:   f.NewLoopGuard(0),
+		since:   time.Now(),
+		other:   make(map[NotificationReason][]func(context.Context, *Notification) error),
+	}
+}
+
+// OnNewFollower registers a handler for new followers
+func (r *NotificationRouter) OnNewFollower(handler func(ctx context.Context, event *NewFollowerEvent) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.followers = append(r.followers, handler)
+}
+
+// OnMention registers a handler for mentions
+func (r *NotificationRouter) OnMention(handler func(ctx context.Context, event *MentionEvent) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mentions = append(r.mentions, handler)
+}
+
+// OnReply registers a handler for replies
+func (r *NotificationRouter) OnReply(handler func(ctx context.Context, event *ReplyEvent) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replies = append(r.replies, handler)
+}
+
+// OnNotification registers a handler for the raw notifications of one reason, such as NewLike or NewQuote.
+// It runs in addition to any higher-level handler for the same reason.
+func (r *NotificationRouter) OnNotification(reason NotificationReason, handler func(ctx context.Context, notif *Notification) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.other[reason] = append(r.other[reason], handler)
+}
+
+// Dispatch builds the events for one notification and runs the matching handlers. Errors from the handlers
+// and from loading profiles or threads are joined and returned; a failed lookup still runs the handlers with
+// what is known. Notifications the router has already dispatched, or that were caused by the authenticated
+// user, are skipped.
+func (r *NotificationRouter) Dispatch(ctx context.Context, notif *Notification) error {
+	if notif == nil || (notif.LinkedUser != nil && r.f.Self != nil && notif.LinkedUser.Did == r.f.Self.Did) {
+		return nil
+	}
+	if key := notificationKey(notif); key == "" || !r.guard.claim(key) {
+		return nil
+	}
+
+	r.mu.Lock()
+	followers := slices.Clone(r.followers)
+	mentions := slices.Clone(r.mentions)
+	replies := slices.Clone(r.replies)
+	other := slices.Clone(r.other[notif.Reason])
+	r.mu.Unlock()
+
+	var errs []error
+	switch notif.Reason {
+	case NewFollow:
+		if len(followers) > 0 && notif.LinkedUser != nil {
+			event := &NewFollowerEvent{Follower: notif.LinkedUser, Notification: notif}
+			if profile, err := r.f.GetProfile(ctx, notif.LinkedUser.Did); err == nil {
+				event.Follower = profile
+			} else {
+				errs = append(errs, err)
+			}
+			for _, handler := range followers {
+				errs = append(errs, handler(ctx, event))
+			}
+		}
+	case NewMention:
+		if len(mentions) > 0 && notif.LinkedPost != nil {
+			event := &MentionEvent{Post: notif.LinkedPost, Notification: notif}
+			thread, err := r.f.GetPostThread(ctx, notif.LinkedPost.URI, 0, r.options.ParentHeight)
+			if err == nil {
+				event.Thread = thread
+			} else {
+				errs = append(errs, err)
+			}
+			if r.governs(event.Post, event.Thread) {
+				for _, handler := range mentions {
+					errs = append(errs, handler(ctx, event))
+				}
+			}
+		}
+	case NewReply:
+		if len(replies) > 0 && notif.LinkedPost != nil {
+			event := &ReplyEvent{Reply: notif.LinkedPost, Notification: notif}
+			thread, err := r.f.GetPostThread(ctx, notif.LinkedPost.URI, 0, r.options.ParentHeight)
+			if err == nil {
+				event.Thread = thread
+				if thread.Parent != nil {
+					event.Parent = thread.Parent.Post
+				}
+			} else {
+				errs = append(errs, err)
+			}
+			if r.governs(event.Reply, event.Thread) {
+				for _, handler := range replies {
+					errs = append(errs, handler(ctx, event))
+				}
+			}
+		}
+	}
+	for _, handler := range other {
+		errs = append(errs, handler(ctx, notif))
+	}
+	return errors.Join(errs...)
+}
+
+// governs reports whether the router's governor, if any, allows a reply to post, reporting it to Events if not
+func (r *NotificationRouter) governs(post *FeedPost, thread *Thread) bool {
+	if r.options.Governor == nil {
+		return true
+	}
+	if err := r.options.Governor.Allow(post, thread); err != nil {
+		r.f.emit(SourceScheduler, SeverityInfo, fmt.Errorf("notification router: skipped %s: %w", post.URI, err))
+		return false
+	}
+	return true
+}
+
+// Poll fetches notifications indexed since the last poll (or since the router was created) and dispatches
+// them oldest first
+func (r *NotificationRouter) Poll(ctx context.Context) error {
+	_, err := r.poll(ctx)
+	return err
+}
+
+// poll is Poll, also reporting whether the notifications could be fetched, so Start only backs off when the
+// server is failing rather than a handler
+func (r *NotificationRouter) poll(ctx context.Context) (bool, error) {
+	if r.f.Self == nil {
+		return false, ErrNotLoggedIn
+	}
+	r.mu.Lock()
+	since := r.since
+	r.mu.Unlock()
+
+	var pending []*Notification
+	cursor := ""
+	for {
+		page, err := r.f.GetNotifications(ctx, NotifLimit(50), NotifCursor(cursor))
+		if err != nil {
+			return false, err
+		}
+		done := page.Cursor == "" || len(page.Notifications) == 0
+		for _, notif := range page.Notifications {
+			if notif.IndexedAt.Before(since) {
+				done = true
+				break
+			}
+			pending = append(pending, notif)
+		}
+		if done {
+			break
+		}
+		cursor = page.Cursor
+	}
+
+	var errs []error
+	for _, notif := range slices.Backward(pending) {
+		if err := r.Dispatch(ctx, notif); err != nil {
+			errs = append(errs, err)
+		}
+		r.mu.Lock()
+		if notif.IndexedAt.After(r.since) {
+			r.since = notif.IndexedAt
+		}
+		r.mu.Unlock()
+	}
+	return true, errors.Join(errs...)
+}
+
+// Start polls in the background until ctx is cancelled or the client is closed. Errors from polls and
+// handlers are sent to Events. After a failed poll, the next one waits for the poll interval or the client's
+// BackoffPolicy delay, whichever is longer; the router stops if the policy runs out of retries. Handler errors
+// don't count as failed polls.
+func (r *NotificationRouter) Start(ctx context.Context) error {
+	if r.f.Self == nil {
+		return ErrNotLoggedIn
+	}
+	if r.f.isClosed() {
+		return ErrClientClosed
+	}
+
+	ctx, cancel := r.f.bindLifetime(ctx)
+	r.f.background.Add(1)
+	go func() {
+		defer r.f.background.Done()
+		defer cancel()
+		if err := r.run(ctx); err != nil {
+			r.f.emit(SourceScheduler, SeverityError, err)
+		}
+	}()
+	return nil
+}
+
+// Run polls like Start, but blocks until the router stops. It returns nil once ctx is cancelled or the client
+// is closed, and an error if the BackoffPolicy runs out of retries, so a Watchdog can restart it.
+func (r *NotificationRouter) Run(ctx context.Context) error {
+	if r.f.Self == nil {
+		return ErrNotLoggedIn
+	}
+	if r.f.isClosed() {
+		return ErrClientClosed
+	}
+
+	ctx, cancel := r.f.bindLifetime(ctx)
+	defer cancel()
+	r.f.background.Add(1)
+	defer r.f.background.Done()
+	return r.run(ctx)
+}
+
+// run is the poll loop behind Start and Run
+func (r *NotificationRouter) run(ctx context.Context) error {
+	policy := r.f.backoffPolicy()
+	failures := 0
+	for {
+		wait := r.options.PollInterval
+		fetched, err := r.poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.f.emit(SourceScheduler, SeverityError, fmt.Errorf("notification router: %w", err))
+		}
+		if !fetched && ctx.Err() == nil {
+			if policy.Exhausted(failures) {
+				return fmt.Errorf("notification router: giving up after %d failed polls", failures+1)
+			}
+			wait = max(wait, policy.Delay(failures))
+			failures++
+		} else {
+			failures = 0
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// notificationKey identifies a notification by the record that caused it
+func notificationKey(notif *Notification) string {
+	if notif.Raw != nil && notif.Raw.Uri != "" {
+		return notif.Raw.Uri
+	}
+	if notif.LinkedPost != nil && notif.LinkedPost.URI != "" {
+		return notif.Reason.String() + " " + notif.LinkedPost.URI
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/bluesky-social/indigo/api/bsky"
@@ -67,6 +68,55 @@ func (r NotificationReason) String() string {
 	}
 }
 
+// notificationReasonNames are the stable names used when serializing a NotificationReason.
+// They match the reason strings returned by app.bsky.notification.listNotifications.
+var notificationReasonNames = enumNames[NotificationReason]{
+	UnknownReason:      "unknown",
+	NewLike:            "like",
+	NewRepost:          "repost",
+	NewFollow:          "follow",
+	NewMention:         "mention",
+	NewReply:           "reply",
+	NewQuote:           "quote",
+	StarterPackJoined:  "starterpack-joined",
+	AccountVerified:    "verified",
+	AccountUnverified:  "unverified",
+	NewLikeViaRepost:   "like-via-repost",
+	NewRepostViaRepost: "repost-via-repost",
+	NewSubscribedPost:  "subscribed-post",
+	NewContactMatch:    "contact-match",
+}
+
+// MarshalText encodes the notification reason as its stable name
+func (r NotificationReason) MarshalText() ([]byte, error) {
+	return notificationReasonNames.text(r), nil
+}
+
+// UnmarshalText decodes a notification reason from its name or integer value
+func (r *NotificationReason) UnmarshalText(data []byte) error {
+	value, err := notificationReasonNames.parseText(data, "notification reason")
+	if err != nil {
+		return err
+	}
+	*r = value
+	return nil
+}
+
+// MarshalJSON encodes the notification reason as a JSON string
+func (r NotificationReason) MarshalJSON() ([]byte, error) {
+	return notificationReasonNames.json(r)
+}
+
+// UnmarshalJSON decodes a notification reason from its name, or from the integer value used by older versions
+func (r *NotificationReason) UnmarshalJSON(data []byte) error {
+	value, err := notificationReasonNames.parseJSON(data, "notification reason")
+	if err != nil {
+		return err
+	}
+	*r = value
+	return nil
+}
+
 // Notification represents a BlueSky notification with information about who performed what action.
 // It includes the notification reason, the user who triggered it, and any associated post.
 type Notification struct {
@@ -149,7 +199,9 @@ func (f *Firefly) OldToNewNotification(oldNotif *bsky.NotificationListNotificati
 		newNotif.Reason == NewRepost ||
 		newNotif.Reason == NewReply ||
 		newNotif.Reason == NewQuote {
-		newPost, err := f.OldToNewPost(oldNotif.Record.Val.(*bsky.FeedPost), oldNotif.Uri)
+		// Like and repost notifications carry the like/repost record, not a post
+		record, _ := oldNotif.Record.Val.(*bsky.FeedPost)
+		newPost, err := f.OldToNewPost(record, oldNotif.Uri)
 		if err == nil {
 			if newNotif.Reason != NewLike {
 				newPost.Author = newNotif.LinkedUser
@@ -171,19 +223,104 @@ func (notif Notification) String() string {
 	return fmt.Sprintf("Notification{User: %s, Reason: %s}", notif.LinkedUser.Handle, notif.Reason)
 }
 
-// GetNotifications fetches notifications from BlueSky with optional filtering.
+// NotificationQuery holds the filters for GetNotifications; set them with NotifOption functions
+type NotificationQuery struct {
+	Limit        int                  // Maximum notifications to return (1-100, default 50)
+	Before       *time.Time           // Only return notifications indexed before this time
+	PriorityOnly bool                 // Only return notifications the server marks as priority
+	Reasons      []NotificationReason // Only return these notification types; empty for all
+	Cursor       string               // Pagination cursor from a previous page; takes precedence over Before
+}
+
+// NotifOption configures a GetNotifications call
+type NotifOption func(*NotificationQuery)
+
+// NotifLimit sets the maximum number of notifications to return (1-100)
+func NotifLimit(limit int) NotifOption {
+	return func(q *NotificationQuery) { q.Limit = limit }
+}
+
+// NotifBefore only returns notifications indexed before t
+func NotifBefore(t time.Time) NotifOption {
+	return func(q *NotificationQuery) { q.Before = &t }
+}
+
+// NotifPriorityOnly only returns notifications the server marks as priority
+func NotifPriorityOnly() NotifOption {
+	return func(q *NotificationQuery) { q.PriorityOnly = true }
+}
+
+// NotifReasons only returns notifications of the given types
+func NotifReasons(reasons ...NotificationReason) NotifOption {
+	return func(q *NotificationQuery) { q.Reasons = append(q.Reasons, reasons...) }
+}
+
+// NotifCursor continues from the cursor of a previous page
+func NotifCursor(cursor string) NotifOption {
+	return func(q *NotificationQuery) { q.Cursor = cursor }
+}
+
+// NotificationPage is a page of notifications along with the read marker
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	Cursor        string          `json:"cursor,omitempty"` // Pass to NotifCursor for the next page; empty when there are no more
+	SeenAt        *time.Time      `json:"seenAt,omitempty"` // When the user last marked notifications as seen; nil if never
+}
+
+// Unread returns the notifications indexed after SeenAt. If SeenAt is unknown, all notifications are unread.
+func (p *NotificationPage) Unread() []*Notification {
+	if p.SeenAt == nil {
+		return p.Notifications
+	}
+	var unread []*Notification
+	for _, notif := range p.Notifications {
+		if notif.IndexedAt.After(*p.SeenAt) {
+			unread = append(unread, notif)
+		}
+	}
+	return unread
+}
+
+// GetNotifications fetches a page of notifications, filtered by the given options.
 //
-// Parameters:
-//   - fromBefore: Only return notifications created before this time
-//   - count: Maximum number of notifications to return (1-100)
-//   - priority: If true, only return notifications marked as priority by the server
-//   - reasons: Filter by notification types (e.g., ["like", "follow"]). Pass nil for all types.
-func (f *Firefly) GetNotifications(ctx context.Context, fromBefore time.Time, count int, priority bool, reasons []string) ([]*Notification, error) {
-	notifications, err := bsky.NotificationListNotifications(ctx, f.client, fromBefore.Format(time.RFC3339), int64(count), priority, reasons, "")
+// Example:
+//
+//	page, err := client.GetNotifications(ctx,
+//	    firefly.NotifLimit(25),
+//	    firefly.NotifReasons(firefly.NewMention, firefly.NewReply),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%d unread\n", len(page.Unread()))
+func (f *Firefly) GetNotifications(ctx context.Context, options ...NotifOption) (*NotificationPage, error) {
+	query := NotificationQuery{Limit: 50}
+	for _, option := range options {
+		option(&query)
+	}
+
+	cursor := query.Cursor
+	if cursor == "" && query.Before != nil {
+		cursor = query.Before.UTC().Format(time.RFC3339)
+	}
+	var reasons []string
+	for _, reason := range query.Reasons {
+		reasons = append(reasons, string(notificationReasonNames.text(reason)))
+	}
+
+	notifications, err := bsky.NotificationListNotifications(ctx, f.api, cursor, int64(query.Limit), query.PriorityOnly, reasons, "")
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
 	}
-	var newNotifications []*Notification
+	page := &NotificationPage{}
+	if notifications.Cursor != nil {
+		page.Cursor = *notifications.Cursor
+	}
+	if notifications.SeenAt != nil {
+		if seenAt, err := time.Parse(time.RFC3339, *notifications.SeenAt); err == nil {
+			page.SeenAt = &seenAt
+		}
+	}
 	for _, notif := range notifications.Notifications {
 		newNotif, err := f.OldToNewNotification(notif)
 		if err != nil {
@@ -195,17 +332,107 @@ func (f *Firefly) GetNotifications(ctx context.Context, fromBefore time.Time, co
 				newNotif.LinkedPost.Author = strippedUser
 			}
 		}
-		newNotifications = append(newNotifications, newNotif)
+		page.Notifications = append(page.Notifications, newNotif)
 	}
-	return newNotifications, nil
+	return page, nil
 }
 
 // GetLatestNotifications is a convenience method that returns the most recent notifications.
-// This is equivalent to calling GetNotifications with time.Now() and no filters.
+// This is equivalent to calling GetNotifications with only a limit.
 func (f *Firefly) GetLatestNotifications(ctx context.Context, count int) ([]*Notification, error) {
-	notifications, err := f.GetNotifications(ctx, time.Now(), count, false, nil)
+	page, err := f.GetNotifications(ctx, NotifLimit(count))
 	if err != nil {
 		return nil, err
 	}
+	return page.Notifications, nil
+}
+
+// GetNotificationsSince returns every notification indexed after since, oldest first, fetching as many
+// full pages as it takes. Options filter the notifications as with GetNotifications; any limit or cursor
+// they set is ignored. Use it to catch up on what arrived while a bot was offline.
+//
+// Example:
+//
+//	missed, err := client.GetNotificationsSince(ctx, lastSeen, firefly.NotifReasons(firefly.NewMention))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, notif := range missed {
+//	    handle(notif)
+//	    lastSeen = notif.IndexedAt
+//	}
+func (f *Firefly) GetNotificationsSince(ctx context.Context, since time.Time, options ...NotifOption) ([]*Notification, error) {
+	var notifications []*Notification
+	cursor := ""
+	for {
+		page, err := f.GetNotifications(ctx, append(slices.Clone(options), NotifLimit(100), NotifCursor(cursor))...)
+		if err != nil {
+			return nil, err
+		}
+		done := page.Cursor == "" || len(page.Notifications) == 0
+		for _, notif := range page.Notifications {
+			if !notif.IndexedAt.After(since) {
+				done = true
+				break
+			}
+			notifications = append(notifications, notif)
+		}
+		if done {
+			break
+		}
+		cursor = page.Cursor
+	}
+	slices.Reverse(notifications)
 	return notifications, nil
 }
+
+// SubscribeToUser turns on activity notifications for actor (a handle or DID), so the authenticated user
+// receives NewSubscribedPost notifications when they post, and optionally when they reply.
+func (f *Firefly) SubscribeToUser(ctx context.Context, actor string, includeReplies bool) error {
+	return f.putActivitySubscription(ctx, actor, true, includeReplies)
+}
+
+// UnsubscribeFromUser turns off activity notifications for actor (a handle or DID)
+func (f *Firefly) UnsubscribeFromUser(ctx context.Context, actor string) error {
+	return f.putActivitySubscription(ctx, actor, false, false)
+}
+
+func (f *Firefly) putActivitySubscription(ctx context.Context, actor string, posts bool, replies bool) error {
+	did, err := f.resolveActor(ctx, actor)
+	if err != nil {
+		return err
+	}
+	_, err = bsky.NotificationPutActivitySubscription(ctx, f.api, &bsky.NotificationPutActivitySubscription_Input{
+		Subject: did,
+		ActivitySubscription: &bsky.NotificationDefs_ActivitySubscription{
+			Post:  posts,
+			Reply: replies,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update activity subscription: %w", err)
+	}
+	return nil
+}
+
+// GetActivitySubscriptions returns one page of the accounts the authenticated user has subscribed to with
+// SubscribeToUser, along with the cursor for the next page. The returned cursor is empty when there are no more pages.
+func (f *Firefly) GetActivitySubscriptions(ctx context.Context, cursor string, limit int) ([]*User, string, error) {
+	result, err := bsky.NotificationListActivitySubscriptions(ctx, f.api, cursor, int64(limit))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	users := make([]*User, 0, len(result.Subscriptions))
+	for _, profile := range result.Subscriptions {
+		user, err := OldToNewUser(profile)
+		if err != nil {
+			return nil, "", err
+		}
+		users = append(users, user)
+	}
+	next := ""
+	if result.Cursor != nil {
+		next = *result.Cursor
+	}
+	return users, next, nil
+}
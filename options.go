@@ -0,0 +1,69 @@
+package firefly
+
+import (
+	"time"
+
+	"github.com/bluesky-social/indigo/xrpc"
+)
+
+// Option configures optional client behavior when passed to NewDefaultInstance or NewCustomInstance
+type Option func(*Firefly)
+
+// WithRefreshMargin sets how long before the access token expires the session is refreshed (default 60 seconds).
+// Tokens that live for less than twice the margin are refreshed halfway through their lifetime instead.
+func WithRefreshMargin(margin time.Duration) Option {
+	return func(f *Firefly) {
+		if margin > 0 {
+			f.refreshMargin = margin
+		}
+	}
+}
+
+// WithClockSkewTolerance sets how far the local clock may differ from the server's before token expiry times
+// are taken at face value (default 60 seconds). Within the tolerance, expiry is computed from the token's own
+// lifetime, so a slightly wrong local clock doesn't cause premature expiry errors. Pass 0 to always trust
+// the local clock.
+func WithClockSkewTolerance(tolerance time.Duration) Option {
+	return func(f *Firefly) {
+		if tolerance >= 0 {
+			f.clockSkew = tolerance
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request. PDS operators may require bots to
+// identify themselves, e.g. "my-bot/1.0 (+https://example.com/bot)".
+func WithUserAgent(userAgent string) Option {
+	return func(f *Firefly) {
+		f.client.UserAgent = &userAgent
+	}
+}
+
+// WithHeaders adds static headers to every request. Calling it more than once merges the headers.
+func WithHeaders(headers map[string]string) Option {
+	return func(f *Firefly) {
+		if f.client.Headers == nil {
+			f.client.Headers = make(map[string]string, len(headers))
+		}
+		for key, value := range headers {
+			f.client.Headers[key] = value
+		}
+	}
+}
+
+// WithRequestTimeout sets a default timeout for API calls whose context has no deadline of its own
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(f *Firefly) {
+		if timeout > 0 {
+			f.requestTimeout = timeout
+		}
+	}
+}
+
+// WithXRPCConfig gives direct access to the underlying xrpc.Client for settings Firefly doesn't wrap,
+// such as an admin token. Session credentials set here are overwritten by Login.
+func WithXRPCConfig(configure func(*xrpc.Client)) Option {
+	return func(f *Firefly) {
+		configure(f.client)
+	}
+}
@@ -0,0 +1,315 @@
+// Package ozone wraps the tools.ozone.moderation endpoints so moderation teams can script triage against a
+// labeler's Ozone service from Go. Requests go through a logged-in firefly client and are forwarded by the
+// PDS to the labeler, so the account only needs moderator access on that labeler.
+//
+// Example:
+//
+//	mod := ozone.New(client, "did:plc:labeler123")
+//	statuses, _, err := mod.QueryStatuses(ctx, &ozone.StatusQuery{ReviewState: ozone.ReviewOpen}, "", 50)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, status := range statuses {
+//	    subject, _ := ozone.StatusSubject(status)
+//	    _, err := mod.Acknowledge(ctx, subject, "triaged by script")
+//	}
+package ozone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TheAlyxGreen/firefly"
+	"github.com/bluesky-social/indigo/api/atproto"
+	toolsozone "github.com/bluesky-social/indigo/api/ozone"
+	"github.com/bluesky-social/indigo/lex/util"
+)
+
+var (
+	ErrFailedQuery    = errors.New("failed to query moderation service")
+	ErrFailedEmit     = errors.New("failed to emit moderation event")
+	ErrInvalidSubject = errors.New("invalid moderation subject")
+)
+
+// Review states used in StatusQuery.ReviewState and returned in SubjectStatusView.ReviewState
+const (
+	ReviewOpen      = "tools.ozone.moderation.defs#reviewOpen"
+	ReviewEscalated = "tools.ozone.moderation.defs#reviewEscalated"
+	ReviewClosed    = "tools.ozone.moderation.defs#reviewClosed"
+	ReviewNone      = "tools.ozone.moderation.defs#reviewNone"
+)
+
+// Event types used in EventQuery.Types
+const (
+	EventTakedown        = "tools.ozone.moderation.defs#modEventTakedown"
+	EventReverseTakedown = "tools.ozone.moderation.defs#modEventReverseTakedown"
+	EventAcknowledge     = "tools.ozone.moderation.defs#modEventAcknowledge"
+	EventEscalate        = "tools.ozone.moderation.defs#modEventEscalate"
+	EventComment         = "tools.ozone.moderation.defs#modEventComment"
+	EventLabel           = "tools.ozone.moderation.defs#modEventLabel"
+	EventReport          = "tools.ozone.moderation.defs#modEventReport"
+)
+
+// Client sends moderation requests to a single labeler's Ozone service
+type Client struct {
+	Labeler string // DID of the labeler the requests are forwarded to
+
+	f   *firefly.Firefly
+	lex util.LexClient
+}
+
+// New creates a moderation client for the labeler with the given DID, using f's session
+func New(f *firefly.Firefly, labelerDID string) *Client {
+	return &Client{
+		Labeler: labelerDID,
+		f:       f,
+		lex:     f.ProxiedLexClient(labelerDID + "#atproto_labeler"),
+	}
+}
+
+// Subject is the account or record a moderation event applies to. Set DID for an account, or URI and CID
+// for a single record.
+type Subject struct {
+	DID string
+	URI string
+	CID string
+}
+
+// RepoSubject returns a Subject for a whole account
+func RepoSubject(did string) Subject {
+	return Subject{DID: did}
+}
+
+// RecordSubject returns a Subject for a single record, such as a post
+func RecordSubject(uri string, cid string) Subject {
+	return Subject{URI: uri, CID: cid}
+}
+
+// StatusSubject returns the Subject a status from QueryStatuses refers to. Chat message subjects aren't supported.
+func StatusSubject(status *toolsozone.ModerationDefs_SubjectStatusView) (Subject, error) {
+	if status == nil || status.Subject == nil {
+		return Subject{}, ErrInvalidSubject
+	}
+	switch {
+	case status.Subject.AdminDefs_RepoRef != nil:
+		return RepoSubject(status.Subject.AdminDefs_RepoRef.Did), nil
+	case status.Subject.RepoStrongRef != nil:
+		return RecordSubject(status.Subject.RepoStrongRef.Uri, status.Subject.RepoStrongRef.Cid), nil
+	default:
+		return Subject{}, fmt.Errorf("%w: unsupported subject type", ErrInvalidSubject)
+	}
+}
+
+func (s Subject) String() string {
+	if s.URI != "" {
+		return s.URI
+	}
+	return s.DID
+}
+
+// input converts the subject to the form emitEvent expects
+func (s Subject) input() (*toolsozone.ModerationEmitEvent_Input_Subject, error) {
+	switch {
+	case s.URI != "" && s.CID != "":
+		return &toolsozone.ModerationEmitEvent_Input_Subject{
+			RepoStrongRef: &atproto.RepoStrongRef{Uri: s.URI, Cid: s.CID},
+		}, nil
+	case s.URI != "":
+		return nil, fmt.Errorf("%w: record subjects need a CID", ErrInvalidSubject)
+	case s.DID != "":
+		return &toolsozone.ModerationEmitEvent_Input_Subject{
+			AdminDefs_RepoRef: &atproto.AdminDefs_RepoRef{Did: s.DID},
+		}, nil
+	default:
+		return nil, ErrInvalidSubject
+	}
+}
+
+// EventQuery filters QueryEvents. The zero value matches every event, newest first.
+type EventQuery struct {
+	Subject               string    // DID or record URI; empty for all subjects
+	IncludeAllUserRecords bool      // With a DID subject, also include events on the account's records
+	Types                 []string  // Event types such as EventTakedown; empty for all
+	CreatedBy             string    // DID of the moderator who emitted the events
+	CreatedAfter          time.Time // Zero for no lower bound
+	CreatedBefore         time.Time // Zero for no upper bound
+	AddedLabels           []string  // Only label events that added one of these values
+	RemovedLabels         []string  // Only label events that removed one of these values
+	HasComment            bool      // Only events with a comment
+	Comment               string    // Only events whose comment contains this text
+	Ascending             bool      // Oldest first instead of newest first
+}
+
+// QueryEvents returns one page of moderation events matching query, along with the cursor for the next page.
+// Pass nil for query to list every event.
+func (c *Client) QueryEvents(ctx context.Context, query *EventQuery, cursor string, limit int) ([]*toolsozone.ModerationDefs_ModEventView, string, error) {
+	if query == nil {
+		query = &EventQuery{}
+	}
+	result, err := toolsozone.ModerationQueryEvents(ctx, c.lex, query.AddedLabels, nil, "", nil, query.Comment,
+		formatTime(query.CreatedAfter), formatTime(query.CreatedBefore), query.CreatedBy, cursor, query.HasComment,
+		query.IncludeAllUserRecords, int64(limit), nil, nil, query.RemovedLabels, nil, nil,
+		sortDirection(query.Ascending), query.Subject, "", query.Types)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedQuery, err)
+	}
+	nextCursor := ""
+	if result.Cursor != nil {
+		nextCursor = *result.Cursor
+	}
+	return result.Events, nextCursor, nil
+}
+
+// StatusQuery filters QueryStatuses. The zero value matches every subject, most recently reported first.
+type StatusQuery struct {
+	Subject        string    // DID or record URI; empty for all subjects
+	ReviewState    string    // One of the Review constants; empty for any
+	Tags           []string  // Only subjects with one of these tags
+	ExcludeTags    []string  // Skip subjects with any of these tags
+	Takendown      bool      // Only subjects that are taken down
+	Appealed       bool      // Only subjects with an open appeal
+	IncludeMuted   bool      // Include subjects whose reports are muted
+	ReportedAfter  time.Time // Zero for no lower bound
+	ReportedBefore time.Time // Zero for no upper bound
+	SortField      string    // Field to sort by, such as "lastReportedAt" (the default) or "priorityScore"
+	Ascending      bool      // Sort ascending instead of descending
+}
+
+// QueryStatuses returns one page of subject review statuses matching query, along with the cursor for the
+// next page. Pass nil for query to list every subject.
+func (c *Client) QueryStatuses(ctx context.Context, query *StatusQuery, cursor string, limit int) ([]*toolsozone.ModerationDefs_SubjectStatusView, string, error) {
+	if query == nil {
+		query = &StatusQuery{}
+	}
+	result, err := toolsozone.ModerationQueryStatuses(ctx, c.lex, "", query.Appealed, nil, "", cursor,
+		query.ExcludeTags, "", "", nil, "", "", nil, false, query.IncludeMuted, "", int64(limit), 0, 0, 0, 0,
+		false, 0, 0, "", formatTime(query.ReportedAfter), formatTime(query.ReportedBefore), query.ReviewState,
+		"", "", sortDirection(query.Ascending), query.SortField, query.Subject, "", query.Tags, query.Takendown)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedQuery, err)
+	}
+	nextCursor := ""
+	if result.Cursor != nil {
+		nextCursor = *result.Cursor
+	}
+	return result.SubjectStatuses, nextCursor, nil
+}
+
+// EventsPager pages through the moderation events matching query
+func (c *Client) EventsPager(query *EventQuery) *firefly.Pager[*toolsozone.ModerationDefs_ModEventView] {
+	return firefly.NewPager(func(ctx context.Context, cursor string, limit int) ([]*toolsozone.ModerationDefs_ModEventView, string, error) {
+		return c.QueryEvents(ctx, query, cursor, limit)
+	})
+}
+
+// StatusesPager pages through the subject statuses matching query
+func (c *Client) StatusesPager(query *StatusQuery) *firefly.Pager[*toolsozone.ModerationDefs_SubjectStatusView] {
+	return firefly.NewPager(func(ctx context.Context, cursor string, limit int) ([]*toolsozone.ModerationDefs_SubjectStatusView, string, error) {
+		return c.QueryStatuses(ctx, query, cursor, limit)
+	})
+}
+
+// EmitEvent records a moderation event against subject as the logged-in moderator. The helpers below cover
+// the common events; use this directly for the rest.
+func (c *Client) EmitEvent(ctx context.Context, subject Subject, event *toolsozone.ModerationEmitEvent_Input_Event) (*toolsozone.ModerationDefs_ModEventView, error) {
+	if c.f.Self == nil {
+		return nil, firefly.ErrNotLoggedIn
+	}
+	input, err := subject.input()
+	if err != nil {
+		return nil, err
+	}
+	view, err := toolsozone.ModerationEmitEvent(ctx, c.lex, &toolsozone.ModerationEmitEvent_Input{
+		CreatedBy: c.f.Self.Did,
+		Event:     event,
+		Subject:   input,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedEmit, err)
+	}
+	return view, nil
+}
+
+// Acknowledge closes the subject's open reports without taking action
+func (c *Client) Acknowledge(ctx context.Context, subject Subject, comment string) (*toolsozone.ModerationDefs_ModEventView, error) {
+	return c.EmitEvent(ctx, subject, &toolsozone.ModerationEmitEvent_Input_Event{
+		ModerationDefs_ModEventAcknowledge: &toolsozone.ModerationDefs_ModEventAcknowledge{Comment: optional(comment)},
+	})
+}
+
+// Escalate moves the subject to the escalated review queue
+func (c *Client) Escalate(ctx context.Context, subject Subject, comment string) (*toolsozone.ModerationDefs_ModEventView, error) {
+	return c.EmitEvent(ctx, subject, &toolsozone.ModerationEmitEvent_Input_Event{
+		ModerationDefs_ModEventEscalate: &toolsozone.ModerationDefs_ModEventEscalate{Comment: optional(comment)},
+	})
+}
+
+// Comment adds a note to the subject without changing its review state
+func (c *Client) Comment(ctx context.Context, subject Subject, comment string) (*toolsozone.ModerationDefs_ModEventView, error) {
+	return c.EmitEvent(ctx, subject, &toolsozone.ModerationEmitEvent_Input_Event{
+		ModerationDefs_ModEventComment: &toolsozone.ModerationDefs_ModEventComment{Comment: optional(comment)},
+	})
+}
+
+// Label applies the add label values to the subject and removes the remove values
+func (c *Client) Label(ctx context.Context, subject Subject, add []string, remove []string, comment string) (*toolsozone.ModerationDefs_ModEventView, error) {
+	// Both lists are required by the lexicon, even when empty
+	if add == nil {
+		add = []string{}
+	}
+	if remove == nil {
+		remove = []string{}
+	}
+	return c.EmitEvent(ctx, subject, &toolsozone.ModerationEmitEvent_Input_Event{
+		ModerationDefs_ModEventLabel: &toolsozone.ModerationDefs_ModEventLabel{
+			Comment:         optional(comment),
+			CreateLabelVals: add,
+			NegateLabelVals: remove,
+		},
+	})
+}
+
+// Takedown takes the subject down. A positive duration makes the takedown temporary; it is rounded up to
+// whole hours.
+func (c *Client) Takedown(ctx context.Context, subject Subject, comment string, duration time.Duration) (*toolsozone.ModerationDefs_ModEventView, error) {
+	takedown := &toolsozone.ModerationDefs_ModEventTakedown{Comment: optional(comment)}
+	if duration > 0 {
+		hours := int64((duration + time.Hour - 1) / time.Hour)
+		takedown.DurationInHours = &hours
+	}
+	return c.EmitEvent(ctx, subject, &toolsozone.ModerationEmitEvent_Input_Event{
+		ModerationDefs_ModEventTakedown: takedown,
+	})
+}
+
+// ReverseTakedown restores a subject that was taken down
+func (c *Client) ReverseTakedown(ctx context.Context, subject Subject, comment string) (*toolsozone.ModerationDefs_ModEventView, error) {
+	return c.EmitEvent(ctx, subject, &toolsozone.ModerationEmitEvent_Input_Event{
+		ModerationDefs_ModEventReverseTakedown: &toolsozone.ModerationDefs_ModEventReverseTakedown{Comment: optional(comment)},
+	})
+}
+
+// optional returns nil for an empty string so it is left out of the request
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// formatTime formats t for a query parameter, or returns an empty string to omit it
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func sortDirection(ascending bool) string {
+	if ascending {
+		return "asc"
+	}
+	return ""
+}
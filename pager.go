@@ -0,0 +1,226 @@
+package firefly
+
+import (
+	"context"
+	"iter"
+	"slices"
+)
+
+// PageFetcher fetches one page of results starting at cursor, returning the cursor for the next page
+// (empty when there are no more)
+type PageFetcher[T any] func(ctx context.Context, cursor string, limit int) ([]T, string, error)
+
+// Pager walks a cursor-paginated endpoint one page at a time. The zero cursor starts from the beginning;
+// set Cursor to resume from a saved position.
+//
+// Example:
+//
+//	for follower, err := range client.FollowersPager("alice.bsky.social").All(ctx) {
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    fmt.Println(follower.Handle)
+//	}
+type Pager[T any] struct {
+	Cursor   string // Cursor for the next page
+	PageSize int    // Results requested per page (default 50)
+
+	fetch PageFetcher[T]
+	done  bool
+}
+
+// NewPager creates a Pager around any fetch function, such as one of the Get* methods wrapped in a closure
+func NewPager[T any](fetch PageFetcher[T]) *Pager[T] {
+	return &Pager[T]{PageSize: 50, fetch: fetch}
+}
+
+// Done reports whether the last page has been fetched
+func (p *Pager[T]) Done() bool {
+	return p.done
+}
+
+// Reset rewinds the pager to the first page, for polling an endpoint for new results
+func (p *Pager[T]) Reset() {
+	p.Cursor = ""
+	p.done = false
+}
+
+// Next fetches the next page. It returns nil without an error once the pager is done.
+func (p *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	if p.done {
+		return nil, nil
+	}
+	if p.PageSize <= 0 {
+		p.PageSize = 50
+	}
+	page, next, err := p.fetch(ctx, p.Cursor, p.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	// Some endpoints repeat the cursor on their last page instead of omitting it
+	p.done = next == "" || next == p.Cursor || len(page) == 0
+	p.Cursor = next
+	return page, nil
+}
+
+// All iterates over every remaining result, fetching pages as needed. Iteration stops after yielding the
+// first error; breaking out of the loop leaves Cursor at the page after the last one fetched.
+func (p *Pager[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for !p.done {
+			page, err := p.Next(ctx)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// FollowersPager pages through the accounts following actor
+func (f *Firefly) FollowersPager(actor string) *Pager[*User] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*User, string, error) {
+		return f.GetFollowers(ctx, actor, cursor, limit)
+	})
+}
+
+// FollowsPager pages through the accounts actor follows
+func (f *Firefly) FollowsPager(actor string) *Pager[*User] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*User, string, error) {
+		return f.GetFollows(ctx, actor, cursor, limit)
+	})
+}
+
+// NotificationsPager pages through the authenticated user's notifications, filtered by the given options.
+// NotifCursor and NotifLimit are managed by the pager.
+func (f *Firefly) NotificationsPager(options ...NotifOption) *Pager[*Notification] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*Notification, string, error) {
+		page, err := f.GetNotifications(ctx, append(slices.Clip(options), NotifCursor(cursor), NotifLimit(limit))...)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Notifications, page.Cursor, nil
+	})
+}
+
+// SearchPostsPager pages through post search results. Pass nil for options to search without filters;
+// the Cursor field is managed by the pager.
+func (f *Firefly) SearchPostsPager(query string, options *PostSearch) *Pager[*FeedPost] {
+	filters := PostSearch{}
+	if options != nil {
+		filters = *options
+	}
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*FeedPost, string, error) {
+		page := filters
+		page.Cursor = cursor
+		return f.searchPage(ctx, query, limit, &page)
+	})
+}
+
+// SearchFeedPager pages through post search results as feed items, so a search can be used wherever a feed
+// is expected, such as a FeedSource. Pass nil for options to search without filters.
+func (f *Firefly) SearchFeedPager(query string, options *PostSearch) *Pager[*FeedItem] {
+	filters := PostSearch{}
+	if options != nil {
+		filters = *options
+	}
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*FeedItem, string, error) {
+		page := filters
+		page.Cursor = cursor
+		posts, next, err := f.searchPage(ctx, query, limit, &page)
+		if err != nil {
+			return nil, "", err
+		}
+		items := make([]*FeedItem, 0, len(posts))
+		for _, post := range posts {
+			items = append(items, &FeedItem{Post: post})
+		}
+		return items, next, nil
+	})
+}
+
+// SearchUsersPager pages through user search results
+func (f *Firefly) SearchUsersPager(query string) *Pager[*User] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*User, string, error) {
+		return f.searchUsersPage(ctx, query, cursor, limit)
+	})
+}
+
+// ListsPager pages through the lists created by actor
+func (f *Firefly) ListsPager(actor string) *Pager[*List] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*List, string, error) {
+		return f.GetLists(ctx, actor, cursor, limit)
+	})
+}
+
+// ListMembersPager pages through the accounts on a list
+func (f *Firefly) ListMembersPager(listURI string) *Pager[*User] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*User, string, error) {
+		return f.GetListMembers(ctx, listURI, cursor, limit)
+	})
+}
+
+// LikesPager pages through the accounts that liked a post
+func (f *Firefly) LikesPager(uri string) *Pager[*User] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*User, string, error) {
+		return f.GetLikes(ctx, uri, cursor, limit)
+	})
+}
+
+// RepostedByPager pages through the accounts that reposted a post
+func (f *Firefly) RepostedByPager(uri string) *Pager[*User] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*User, string, error) {
+		return f.GetRepostedBy(ctx, uri, cursor, limit)
+	})
+}
+
+// QuotesPager pages through the posts quoting a post
+func (f *Firefly) QuotesPager(uri string) *Pager[*FeedPost] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*FeedPost, string, error) {
+		return f.GetQuotes(ctx, uri, cursor, limit)
+	})
+}
+
+// ActorLikesPager pages through the posts liked by the authenticated user; pass an empty actor to use Self
+func (f *Firefly) ActorLikesPager(actor string) *Pager[*FeedPost] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*FeedPost, string, error) {
+		return f.GetActorLikes(ctx, actor, cursor, limit)
+	})
+}
+
+// ActivitySubscriptionsPager pages through the accounts the authenticated user has subscribed to
+func (f *Firefly) ActivitySubscriptionsPager() *Pager[*User] {
+	return NewPager(f.GetActivitySubscriptions)
+}
+
+// TimelinePager pages through the authenticated user's home timeline
+func (f *Firefly) TimelinePager() *Pager[*FeedItem] {
+	return NewPager(f.GetTimeline)
+}
+
+// AuthorFeedPager pages through actor's posts and reposts; pass an empty actor to use Self
+func (f *Firefly) AuthorFeedPager(actor string, filter AuthorFeedFilter, includePins bool) *Pager[*FeedItem] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*FeedItem, string, error) {
+		return f.GetAuthorFeed(ctx, actor, filter, includePins, cursor, limit)
+	})
+}
+
+// FeedPager pages through the custom feed at feedURI
+func (f *Firefly) FeedPager(feedURI string) *Pager[*FeedItem] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*FeedItem, string, error) {
+		return f.GetFeed(ctx, feedURI, cursor, limit)
+	})
+}
+
+// ListFeedPager pages through posts by the members of the list at listURI
+func (f *Firefly) ListFeedPager(listURI string) *Pager[*FeedItem] {
+	return NewPager(func(ctx context.Context, cursor string, limit int) ([]*FeedItem, string, error) {
+		return f.GetListFeed(ctx, listURI, cursor, limit)
+	})
+}
@@ -32,6 +32,45 @@ func (et EmbedType) String() string {
 	}
 }
 
+// embedTypeNames are the stable names used when serializing a EmbedType
+var embedTypeNames = enumNames[EmbedType]{
+	EmbedTypeUnknown:  "unknown",
+	EmbedTypeImages:   "images",
+	EmbedTypeExternal: "external",
+	EmbedTypeRecord:   "record",
+	EmbedTypeVideo:    "video",
+}
+
+// MarshalText encodes the embed type as its stable name
+func (et EmbedType) MarshalText() ([]byte, error) {
+	return embedTypeNames.text(et), nil
+}
+
+// UnmarshalText decodes an embed type from its name or integer value
+func (et *EmbedType) UnmarshalText(data []byte) error {
+	value, err := embedTypeNames.parseText(data, "embed type")
+	if err != nil {
+		return err
+	}
+	*et = value
+	return nil
+}
+
+// MarshalJSON encodes the embed type as a JSON string
+func (et EmbedType) MarshalJSON() ([]byte, error) {
+	return embedTypeNames.json(et)
+}
+
+// UnmarshalJSON decodes an embed type from its name, or from the integer value used by older versions
+func (et *EmbedType) UnmarshalJSON(data []byte) error {
+	value, err := embedTypeNames.parseJSON(data, "embed type")
+	if err != nil {
+		return err
+	}
+	*et = value
+	return nil
+}
+
 // EmbedImage represents an image embedded in a post.
 type EmbedImage struct {
 	AltText string `json:"altText" cborgen:"altText"`
@@ -25,6 +25,44 @@ func (ft FacetType) String() string {
 	}
 }
 
+// facetTypeNames are the stable names used when serializing a FacetType
+var facetTypeNames = enumNames[FacetType]{
+	UnknownFacetType: "unknown",
+	LinkFacet:        "link",
+	MentionFacet:     "mention",
+	TagFacet:         "tag",
+}
+
+// MarshalText encodes the facet type as its stable name
+func (ft FacetType) MarshalText() ([]byte, error) {
+	return facetTypeNames.text(ft), nil
+}
+
+// UnmarshalText decodes a facet type from its name or integer value
+func (ft *FacetType) UnmarshalText(data []byte) error {
+	value, err := facetTypeNames.parseText(data, "facet type")
+	if err != nil {
+		return err
+	}
+	*ft = value
+	return nil
+}
+
+// MarshalJSON encodes the facet type as a JSON string
+func (ft FacetType) MarshalJSON() ([]byte, error) {
+	return facetTypeNames.json(ft)
+}
+
+// UnmarshalJSON decodes a facet type from its name, or from the integer value used by older versions
+func (ft *FacetType) UnmarshalJSON(data []byte) error {
+	value, err := facetTypeNames.parseJSON(data, "facet type")
+	if err != nil {
+		return err
+	}
+	*ft = value
+	return nil
+}
+
 // RichTextFacet represents formatted text elements within a post such as links, mentions, and hashtags.
 // It includes the type of element, its target (URL, user DID, hashtag), and position within the post text.
 type RichTextFacet struct {
@@ -0,0 +1,447 @@
+package firefly
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/atproto/syntax"
+)
+
+// RenderFormat selects the markup produced by RenderPost and RenderPostText
+type RenderFormat int
+
+const (
+	RenderHTML RenderFormat = iota
+	RenderMarkdown
+)
+
+func (rf RenderFormat) String() string {
+	switch rf {
+	case RenderHTML:
+		return "HTML"
+	case RenderMarkdown:
+		return "Markdown"
+	default:
+		return "Unknown"
+	}
+}
+
+// CDNPreset is a size of image served by the Bluesky image CDN
+type CDNPreset string
+
+const (
+	CDNFeedThumbnail   CDNPreset = "feed_thumbnail"   // images as shown in feeds
+	CDNFeedFullsize    CDNPreset = "feed_fullsize"    // images at full size
+	CDNAvatar          CDNPreset = "avatar"           // profile pictures
+	CDNAvatarThumbnail CDNPreset = "avatar_thumbnail" // small profile pictures
+	CDNBanner          CDNPreset = "banner"           // profile banners
+)
+
+// CDNImageURL returns the Bluesky CDN URL for an image blob uploaded by did, resized for preset. The CDN
+// serves public images without authentication, so the URL can be used directly in web pages.
+//
+// Example:
+//
+//	src := firefly.CDNImageURL(post.Author.Did, blobCID, firefly.CDNFeedThumbnail)
+func CDNImageURL(did string, cid string, preset CDNPreset) string {
+	return fmt.Sprintf("https://cdn.bsky.app/img/%s/plain/%s/%s@jpeg", preset, did, cid)
+}
+
+// cdnVideoThumbnail returns the CDN URL for the poster frame of a video blob
+func cdnVideoThumbnail(did string, cid string) string {
+	return fmt.Sprintf("https://video.bsky.app/watch/%s/%s/thumbnail.jpg", url.PathEscape(did), cid)
+}
+
+// PostWebURL returns the bsky.app link for a post's AT URI, or "" if uri isn't a post URI
+func PostWebURL(uri string) string {
+	parsed, err := syntax.ParseATURI(uri)
+	if err != nil || parsed.Collection().String() != CollectionPost || parsed.RecordKey() == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://bsky.app/profile/%s/post/%s", parsed.Authority(), parsed.RecordKey())
+}
+
+// profileWebURL returns the bsky.app link for an account, given its DID or handle
+func profileWebURL(actor string) string {
+	return "https://bsky.app/profile/" + actor
+}
+
+// safeWebURL returns raw if it is an http or https URL, its bsky.app link if it is an AT URI for a post or an
+// account, and "" otherwise. Link targets come from untrusted records, and schemes like javascript: or data:
+// would run in the page a rendered post is embedded in.
+func safeWebURL(raw string) string {
+	if strings.HasPrefix(raw, "at://") {
+		parsed, err := syntax.ParseATURI(raw)
+		if err != nil {
+			return ""
+		}
+		if parsed.Collection() == "" {
+			return profileWebURL(parsed.Authority().String())
+		}
+		return PostWebURL(raw)
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+	return raw
+}
+
+// RenderPostText renders a post's text as HTML or Markdown, turning its link, mention and hashtag facets
+// into links. Text is escaped; facets that overlap or don't fit the text, and links that aren't http, https
+// or AT URIs, are left as plain text.
+func RenderPostText(post *FeedPost, format RenderFormat) string {
+	text := post.Text
+	facets := slices.Clone(post.Facets)
+	slices.SortFunc(facets, func(a, b RichTextFacet) int { return a.StartIndex - b.StartIndex })
+
+	var b strings.Builder
+	at := 0
+	for _, facet := range facets {
+		if facet.StartIndex < at || facet.EndIndex <= facet.StartIndex || facet.EndIndex > len(text) ||
+			!utf8.RuneStart(text[facet.StartIndex]) || (facet.EndIndex < len(text) && !utf8.RuneStart(text[facet.EndIndex])) {
+			continue
+		}
+		href := ""
+		switch facet.Type {
+		case LinkFacet:
+			href = safeWebURL(facet.Target)
+		case MentionFacet:
+			href = profileWebURL(facet.Target)
+		case TagFacet:
+			href = "https://bsky.app/hashtag/" + url.PathEscape(facet.Target)
+		}
+		if href == "" {
+			continue
+		}
+		b.WriteString(escapeText(text[at:facet.StartIndex], format))
+		b.WriteString(renderLink(href, escapeText(text[facet.StartIndex:facet.EndIndex], format), format))
+		at = facet.EndIndex
+	}
+	b.WriteString(escapeText(text[at:], format))
+
+	if format == RenderHTML {
+		return strings.ReplaceAll(b.String(), "\n", "<br>\n")
+	}
+	// Markdown needs two trailing spaces for a line break inside a paragraph
+	return strings.ReplaceAll(b.String(), "\n", "  \n")
+}
+
+// RenderPost renders a post as a complete, self-contained HTML or Markdown snippet: the author, the text with
+// its facets as links, any embed (an image grid with alt text, a link card, a video poster or a quoted post),
+// and a timestamp linking to the post on bsky.app. Images are served from the Bluesky CDN. Quoted posts show
+// their author and text when the post was fetched from the AppView, and a link otherwise. HTML snippets use
+// the classes bluesky-post, bluesky-images, bluesky-card and bluesky-quote for styling.
+//
+// Example:
+//
+//	posts, err := client.GetPosts(ctx, []string{uri})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	snippet := firefly.RenderPost(posts[0], firefly.RenderHTML)
+func RenderPost(post *FeedPost, format RenderFormat) string {
+	var b strings.Builder
+	if format == RenderHTML {
+		b.WriteString("<blockquote class=\"bluesky-post\">\n")
+	}
+	if post.Author != nil {
+		writeAuthor(&b, post.Author.Did, post.Author.Handle, post.Author.DisplayName, format)
+	}
+	if post.Text != "" {
+		if format == RenderHTML {
+			fmt.Fprintf(&b, "<p>%s</p>\n", RenderPostText(post, format))
+		} else {
+			fmt.Fprintf(&b, "%s\n\n", RenderPostText(post, format))
+		}
+	}
+	writeEmbed(&b, post, format)
+
+	when := ""
+	if post.CreatedAt != nil {
+		when = post.CreatedAt.UTC().Format("02 Jan 2006 15:04 MST")
+	}
+	if link := PostWebURL(post.URI); link != "" {
+		if when == "" {
+			when = "View on Bluesky"
+		}
+		when = renderLink(link, escapeText(when, format), format)
+	} else {
+		when = escapeText(when, format)
+	}
+	if when != "" {
+		if format == RenderHTML {
+			fmt.Fprintf(&b, "<p>%s</p>\n", when)
+		} else {
+			fmt.Fprintf(&b, "%s\n", when)
+		}
+	}
+	if format == RenderHTML {
+		b.WriteString("</blockquote>\n")
+	}
+	return b.String()
+}
+
+// renderedImage is an image ready to be rendered
+type renderedImage struct {
+	thumb, full, alt string
+}
+
+// writeEmbed renders a post's embed. The raw record is preferred, since its blob CIDs give CDN URLs and it
+// keeps the media of quotes with media, which the simplified Embed drops.
+func writeEmbed(b *strings.Builder, post *FeedPost, format RenderFormat) {
+	did := ""
+	if post.Author != nil {
+		did = post.Author.Did
+	}
+	if did == "" {
+		if parsed, err := syntax.ParseATURI(post.URI); err == nil {
+			did = parsed.Authority().String()
+		}
+	}
+
+	var images []renderedImage
+	var external *EmbedLink
+	var video string
+	var quote *PostRef
+	if post.Raw != nil && post.Raw.Embed != nil {
+		raw := post.Raw.Embed
+		media := &bsky.EmbedRecordWithMedia_Media{EmbedImages: raw.EmbedImages, EmbedVideo: raw.EmbedVideo, EmbedExternal: raw.EmbedExternal}
+		switch {
+		case raw.EmbedRecord != nil && raw.EmbedRecord.Record != nil:
+			quote = &PostRef{URI: raw.EmbedRecord.Record.Uri, CID: raw.EmbedRecord.Record.Cid}
+		case raw.EmbedRecordWithMedia != nil:
+			if record := raw.EmbedRecordWithMedia.Record; record != nil && record.Record != nil {
+				quote = &PostRef{URI: record.Record.Uri, CID: record.Record.Cid}
+			}
+			if raw.EmbedRecordWithMedia.Media != nil {
+				media = raw.EmbedRecordWithMedia.Media
+			}
+		}
+		if media.EmbedImages != nil {
+			for _, image := range media.EmbedImages.Images {
+				if image == nil || image.Image == nil {
+					continue
+				}
+				cid := image.Image.Ref.String()
+				images = append(images, renderedImage{
+					thumb: CDNImageURL(did, cid, CDNFeedThumbnail),
+					full:  CDNImageURL(did, cid, CDNFeedFullsize),
+					alt:   image.Alt,
+				})
+			}
+		}
+		if media.EmbedVideo != nil && media.EmbedVideo.Video != nil {
+			video = cdnVideoThumbnail(did, media.EmbedVideo.Video.Ref.String())
+		}
+		if ext := media.EmbedExternal; ext != nil && ext.External != nil {
+			external = &EmbedLink{URL: ext.External.Uri, Title: ext.External.Title, Description: ext.External.Description}
+			if ext.External.Thumb != nil {
+				external.ThumbURL = CDNImageURL(did, ext.External.Thumb.Ref.String(), CDNFeedThumbnail)
+			}
+		}
+	} else if post.Embed != nil {
+		for _, image := range post.Embed.Images {
+			if link := safeWebURL(image.URL); link != "" {
+				images = append(images, renderedImage{thumb: link, full: link, alt: image.AltText})
+			}
+		}
+		if post.Embed.External != nil {
+			copied := *post.Embed.External
+			copied.ThumbURL = safeWebURL(copied.ThumbURL)
+			external = &copied
+		}
+		quote = post.Embed.Record
+	}
+	if external != nil {
+		external.URL = safeWebURL(external.URL)
+		if external.URL == "" && external.Title == "" {
+			external = nil
+		}
+	}
+
+	if len(images) > 0 {
+		if format == RenderHTML {
+			b.WriteString("<div class=\"bluesky-images\">\n")
+			for _, image := range images {
+				fmt.Fprintf(b, "<a href=\"%s\"><img src=\"%s\" alt=\"%s\" loading=\"lazy\"></a>\n",
+					html.EscapeString(image.full), html.EscapeString(image.thumb), html.EscapeString(image.alt))
+			}
+			b.WriteString("</div>\n")
+		} else {
+			for _, image := range images {
+				fmt.Fprintf(b, "[![%s](%s)](%s)\n", escapeMarkdown(image.alt), markdownURL(image.thumb), markdownURL(image.full))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if video != "" {
+		link := PostWebURL(post.URI)
+		if format == RenderHTML {
+			fmt.Fprintf(b, "<a href=\"%s\"><img src=\"%s\" alt=\"Video\" loading=\"lazy\"></a>\n",
+				html.EscapeString(link), html.EscapeString(video))
+		} else {
+			fmt.Fprintf(b, "[![Video](%s)](%s)\n\n", markdownURL(video), markdownURL(link))
+		}
+	}
+
+	if external != nil {
+		if format == RenderHTML {
+			// A card whose link was dropped as unsafe keeps its text, without the link
+			tag := "div"
+			if external.URL != "" {
+				tag = "a"
+				fmt.Fprintf(b, "<a class=\"bluesky-card\" href=\"%s\">\n", html.EscapeString(external.URL))
+			} else {
+				b.WriteString("<div class=\"bluesky-card\">\n")
+			}
+			if external.ThumbURL != "" {
+				fmt.Fprintf(b, "<img src=\"%s\" alt=\"\" loading=\"lazy\">\n", html.EscapeString(external.ThumbURL))
+			}
+			title := external.Title
+			if title == "" {
+				title = external.URL
+			}
+			fmt.Fprintf(b, "<strong>%s</strong>\n", html.EscapeString(title))
+			if external.Description != "" {
+				fmt.Fprintf(b, "<span>%s</span>\n", html.EscapeString(external.Description))
+			}
+			fmt.Fprintf(b, "</%s>\n", tag)
+		} else {
+			title := external.Title
+			if title == "" {
+				title = external.URL
+			}
+			if external.URL != "" {
+				fmt.Fprintf(b, "> **%s**\n", renderLink(external.URL, escapeMarkdown(title), format))
+			} else {
+				fmt.Fprintf(b, "> **%s**\n", escapeMarkdown(title))
+			}
+			if external.Description != "" {
+				fmt.Fprintf(b, "> %s\n", escapeMarkdown(strings.ReplaceAll(external.Description, "\n", " ")))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if quote != nil {
+		writeQuote(b, quote, quotedRecord(post), format)
+	}
+}
+
+// quotedRecord returns the AppView's view of the post quoted by post, if it was fetched with one
+func quotedRecord(post *FeedPost) *bsky.EmbedRecord_ViewRecord {
+	if post.RawDetailed == nil || post.RawDetailed.Embed == nil {
+		return nil
+	}
+	view := post.RawDetailed.Embed.EmbedRecord_View
+	if withMedia := post.RawDetailed.Embed.EmbedRecordWithMedia_View; withMedia != nil {
+		view = withMedia.Record
+	}
+	if view == nil || view.Record == nil {
+		return nil
+	}
+	return view.Record.EmbedRecord_ViewRecord
+}
+
+// writeQuote renders a quoted post as a nested block
+func writeQuote(b *strings.Builder, quote *PostRef, view *bsky.EmbedRecord_ViewRecord, format RenderFormat) {
+	link := PostWebURL(quote.URI)
+	text := ""
+	if view != nil && view.Value != nil {
+		if record, ok := view.Value.Val.(*bsky.FeedPost); ok {
+			text = record.Text
+		}
+	}
+
+	if format == RenderHTML {
+		fmt.Fprintf(b, "<blockquote class=\"bluesky-quote\" cite=\"%s\">\n", html.EscapeString(link))
+		if view != nil && view.Author != nil {
+			writeAuthor(b, view.Author.Did, view.Author.Handle, view.Author.DisplayName, format)
+		}
+		if text != "" {
+			fmt.Fprintf(b, "<p>%s</p>\n", strings.ReplaceAll(html.EscapeString(text), "\n", "<br>\n"))
+		}
+		if link != "" {
+			fmt.Fprintf(b, "<p><a href=\"%s\">Quoted post</a></p>\n", html.EscapeString(link))
+		}
+		b.WriteString("</blockquote>\n")
+		return
+	}
+
+	var inner strings.Builder
+	if view != nil && view.Author != nil {
+		writeAuthor(&inner, view.Author.Did, view.Author.Handle, view.Author.DisplayName, format)
+	}
+	if text != "" {
+		fmt.Fprintf(&inner, "%s\n\n", strings.ReplaceAll(escapeMarkdown(text), "\n", "  \n"))
+	}
+	if link != "" {
+		fmt.Fprintf(&inner, "[Quoted post](%s)\n", link)
+	}
+	for _, line := range strings.Split(strings.TrimRight(inner.String(), "\n"), "\n") {
+		if line == "" {
+			b.WriteString(">\n")
+		} else {
+			fmt.Fprintf(b, "> %s\n", line)
+		}
+	}
+	b.WriteString("\n")
+}
+
+// writeAuthor renders an author line: the display name, if any, and the handle, linked to the profile
+func writeAuthor(b *strings.Builder, did string, handle string, displayName *string, format RenderFormat) {
+	actor := did
+	if actor == "" {
+		actor = handle
+	}
+	name := ""
+	if displayName != nil {
+		name = *displayName
+	}
+	label := "@" + handle
+	if name != "" {
+		label = fmt.Sprintf("%s (@%s)", name, handle)
+	}
+	if format == RenderHTML {
+		fmt.Fprintf(b, "<p>%s</p>\n", renderLink(profileWebURL(actor), html.EscapeString(label), format))
+	} else {
+		fmt.Fprintf(b, "**%s**\n\n", renderLink(profileWebURL(actor), escapeMarkdown(label), format))
+	}
+}
+
+// renderLink wraps already-escaped text in a link to href
+func renderLink(href string, text string, format RenderFormat) string {
+	if format == RenderHTML {
+		return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(href), text)
+	}
+	return fmt.Sprintf("[%s](%s)", text, markdownURL(href))
+}
+
+// markdownURL wraps a link destination in angle brackets, so spaces and parentheses can't end it early
+func markdownURL(href string) string {
+	return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(href) + ">"
+}
+
+// escapeText escapes plain text for format
+func escapeText(text string, format RenderFormat) string {
+	if format == RenderHTML {
+		return html.EscapeString(text)
+	}
+	return escapeMarkdown(text)
+}
+
+// markdownEscaper backslash-escapes the characters Markdown would treat as formatting inside a paragraph
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`,
+)
+
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
@@ -0,0 +1,465 @@
+package firefly
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrScheduleConflict  = errors.New("too many posts scheduled within an hour")
+	ErrScheduledNotFound = errors.New("scheduled post not found")
+	ErrAlreadyPublished  = errors.New("scheduled post already published")
+	ErrNowPublishing     = errors.New("scheduled post is being published")
+)
+
+// ScheduledStatus is where a ScheduledPost is in its life
+type ScheduledStatus int
+
+const (
+	ScheduledPending ScheduledStatus = iota
+	ScheduledPublished
+	ScheduledFailed
+)
+
+func (s ScheduledStatus) String() string {
+	switch s {
+	case ScheduledPending:
+		return "Pending"
+	case ScheduledPublished:
+		return "Published"
+	case ScheduledFailed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// scheduledStatusNames are the stable names used when serializing a ScheduledStatus
+var scheduledStatusNames = enumNames[ScheduledStatus]{
+	ScheduledPending:   "pending",
+	ScheduledPublished: "published",
+	ScheduledFailed:    "failed",
+}
+
+// MarshalText encodes the status as its stable name
+func (s ScheduledStatus) MarshalText() ([]byte, error) {
+	return scheduledStatusNames.text(s), nil
+}
+
+// UnmarshalText decodes a status from its name or integer value
+func (s *ScheduledStatus) UnmarshalText(data []byte) error {
+	value, err := scheduledStatusNames.parseText(data, "scheduled status")
+	if err != nil {
+		return err
+	}
+	*s = value
+	return nil
+}
+
+// MarshalJSON encodes the status as a JSON string
+func (s ScheduledStatus) MarshalJSON() ([]byte, error) {
+	return scheduledStatusNames.json(s)
+}
+
+// UnmarshalJSON decodes a status from its name, or from the integer value used by older versions
+func (s *ScheduledStatus) UnmarshalJSON(data []byte) error {
+	value, err := scheduledStatusNames.parseJSON(data, "scheduled status")
+	if err != nil {
+		return err
+	}
+	*s = value
+	return nil
+}
+
+// ScheduledPost is a draft waiting in a PostScheduler, or one it has already tried to publish
+type ScheduledPost struct {
+	ID        string          `json:"id"`
+	Draft     *DraftPost      `json:"draft"`
+	At        time.Time       `json:"at"` // When the post goes out
+	Status    ScheduledStatus `json:"status"`
+	Ref       *PostRef        `json:"ref,omitempty"`   // The published post
+	Error     string          `json:"error,omitempty"` // Why publishing failed
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+func (p ScheduledPost) String() string {
+	return fmt.Sprintf("ScheduledPost{ID: %s, At: %s, Status: %s}", p.ID, p.At.Format(time.RFC3339), p.Status)
+}
+
+// ScheduleWindow is a span of time to list scheduled posts in. A zero Start or End leaves that side open.
+type ScheduleWindow struct {
+	Start       time.Time // Inclusive
+	End         time.Time // Exclusive
+	PendingOnly bool      // Leave out posts already published or failed
+}
+
+func (w ScheduleWindow) String() string {
+	return fmt.Sprintf("ScheduleWindow{Start: %s, End: %s, PendingOnly: %t}",
+		w.Start.Format(time.RFC3339), w.End.Format(time.RFC3339), w.PendingOnly)
+}
+
+// Contains reports whether t falls within the window
+func (w ScheduleWindow) Contains(t time.Time) bool {
+	return (w.Start.IsZero() || !t.Before(w.Start)) && (w.End.IsZero() || t.Before(w.End))
+}
+
+// Includes reports whether a post belongs in the window, by its time and status
+func (w ScheduleWindow) Includes(post *ScheduledPost) bool {
+	return w.Contains(post.At) && (!w.PendingOnly || post.Status == ScheduledPending)
+}
+
+// ScheduleStore keeps a PostScheduler's posts. Implement it over a database so scheduled posts survive
+// restarts and a scheduling UI can share them; the default MemoryScheduleStore forgets them when the process
+// exits. LoadScheduled returns nil, nil for an unknown ID, and ListScheduled returns the posts
+// ScheduleWindow.Includes accepts, so a database store can answer PendingOnly with an indexed query.
+// ScheduledPost marshals to JSON with its draft's embed media included, base64-encoded.
+type ScheduleStore interface {
+	LoadScheduled(ctx context.Context, id string) (*ScheduledPost, error)
+	SaveScheduled(ctx context.Context, post *ScheduledPost) error
+	DeleteScheduled(ctx context.Context, id string) error
+	ListScheduled(ctx context.Context, window ScheduleWindow) ([]*ScheduledPost, error)
+}
+
+// MemoryScheduleStore is a ScheduleStore that keeps posts in memory
+type MemoryScheduleStore struct {
+	mu    sync.Mutex
+	posts map[string]*ScheduledPost
+}
+
+// NewMemoryScheduleStore creates an empty MemoryScheduleStore
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{posts: make(map[string]*ScheduledPost)}
+}
+
+func (s *MemoryScheduleStore) LoadScheduled(ctx context.Context, id string) (*ScheduledPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if post, ok := s.posts[id]; ok {
+		loaded := *post
+		return &loaded, nil
+	}
+	return nil, nil
+}
+
+func (s *MemoryScheduleStore) SaveScheduled(ctx context.Context, post *ScheduledPost) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *post
+	s.posts[post.ID] = &saved
+	return nil
+}
+
+func (s *MemoryScheduleStore) DeleteScheduled(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.posts, id)
+	return nil
+}
+
+func (s *MemoryScheduleStore) ListScheduled(ctx context.Context, window ScheduleWindow) ([]*ScheduledPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var posts []*ScheduledPost
+	for _, post := range s.posts {
+		if window.Includes(post) {
+			listed := *post
+			posts = append(posts, &listed)
+		}
+	}
+	return posts, nil
+}
+
+// PostSchedulerOptions configures a PostScheduler
+type PostSchedulerOptions struct {
+	Store         ScheduleStore // Where scheduled posts are kept (default a new MemoryScheduleStore)
+	MaxPerHour    int           // Most pending posts in any 60 minutes; 0 for no limit
+	CheckInterval time.Duration // Time between checks for due posts in Start (default 30 seconds)
+}
+
+// PostScheduler publishes drafts at set times, and answers the calendar queries a scheduling UI needs, so the
+// store is the only record of what is planned. Published and failed posts stay in the store with their
+// status, so the calendar keeps its history.
+type PostScheduler struct {
+	f          *Firefly
+	options    PostSchedulerOptions
+	mu         sync.Mutex          // serializes conflict checks with the writes they guard
+	publishing map[string]struct{} // IDs PublishDue is publishing now; guarded by mu
+}
+
+// NewPostScheduler creates a PostScheduler. Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	scheduler := client.NewPostScheduler(&firefly.PostSchedulerOptions{MaxPerHour: 4})
+//	draft := firefly.NewDraftPost().AddText("Good morning!")
+//	scheduled, err := scheduler.Schedule(ctx, draft, tomorrowAt9)
+//	if errors.Is(err, firefly.ErrScheduleConflict) {
+//	    scheduled, err = scheduler.Schedule(ctx, draft, tomorrowAt9.Add(time.Hour))
+//	}
+//	if err := scheduler.Start(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (f *Firefly) NewPostScheduler(options *PostSchedulerOptions) *PostScheduler {
+	if options == nil {
+		options = &PostSchedulerOptions{}
+	}
+	opts := *options
+	if opts.Store == nil {
+		opts.Store = NewMemoryScheduleStore()
+	}
+	opts.MaxPerHour = max(opts.MaxPerHour, 0)
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = 30 * time.Second
+	}
+	scheduler := &PostScheduler{f: f, options: opts, publishing: make(map[string]struct{})}
+	f.schedulersMu.Lock()
+	f.schedulers = append(f.schedulers, scheduler)
+	f.schedulersMu.Unlock()
+	return scheduler
+}
+
+// Schedule saves draft to be published at t. It returns ErrScheduleConflict if that would put more than
+// MaxPerHour pending posts in any 60 minutes.
+func (s *PostScheduler) Schedule(ctx context.Context, draft *DraftPost, t time.Time) (*ScheduledPost, error) {
+	if draft == nil {
+		return nil, ErrNilPost
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkConflict(ctx, t, ""); err != nil {
+		return nil, err
+	}
+	post := &ScheduledPost{
+		ID:        recordKeyClock.Next().String(),
+		Draft:     draft,
+		At:        t,
+		Status:    ScheduledPending,
+		CreatedAt: time.Now(),
+	}
+	if err := s.options.Store.SaveScheduled(ctx, post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// ListScheduled returns the posts scheduled within window, in the order they go out, including ones already
+// published or failed
+func (s *PostScheduler) ListScheduled(ctx context.Context, window ScheduleWindow) ([]*ScheduledPost, error) {
+	posts, err := s.options.Store.ListScheduled(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(posts, compareScheduled)
+	return posts, nil
+}
+
+// Cancel removes a scheduled post. Published posts can't be cancelled; delete the post itself instead. A post
+// that is going out right now returns ErrNowPublishing.
+func (s *PostScheduler) Cancel(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post, err := s.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.publishing[id]; ok {
+		return ErrNowPublishing
+	}
+	if post.Status == ScheduledPublished {
+		return ErrAlreadyPublished
+	}
+	return s.options.Store.DeleteScheduled(ctx, id)
+}
+
+// Reschedule moves a scheduled post to t, checking for conflicts like Schedule. A failed post is pending again
+// afterwards, so Reschedule also retries it. A post that is going out right now returns ErrNowPublishing.
+func (s *PostScheduler) Reschedule(ctx context.Context, id string, t time.Time) (*ScheduledPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.publishing[id]; ok {
+		return nil, ErrNowPublishing
+	}
+	if post.Status == ScheduledPublished {
+		return nil, ErrAlreadyPublished
+	}
+	if err := s.checkConflict(ctx, t, id); err != nil {
+		return nil, err
+	}
+	post.At = t
+	post.Status = ScheduledPending
+	post.Error = ""
+	if err := s.options.Store.SaveScheduled(ctx, post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// CheckSlot reports whether a post could be scheduled at t, returning ErrScheduleConflict if not. Use it to
+// grey out full slots in a calendar.
+func (s *PostScheduler) CheckSlot(ctx context.Context, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkConflict(ctx, t, "")
+}
+
+// PublishDue publishes every pending post whose time has come, oldest first, and returns the ones it
+// published. Posts that fail are marked failed and their errors joined; the rest still go out. The scheduler
+// isn't locked while posts are sent, so Schedule and the calendar queries carry on meanwhile.
+func (s *PostScheduler) PublishDue(ctx context.Context) ([]*ScheduledPost, error) {
+	due, err := s.claimDue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.release(due)
+
+	var published []*ScheduledPost
+	var errs []error
+	for _, post := range due {
+		ref, err := s.f.PublishDraftPost(ctx, post.Draft)
+		if err != nil && ctx.Err() != nil {
+			// Cancelled before it went out, so leave it pending for the next run
+			return published, errors.Join(append(errs, ctx.Err())...)
+		}
+		if err != nil {
+			post.Status = ScheduledFailed
+			post.Error = err.Error()
+			errs = append(errs, fmt.Errorf("scheduled post %s: %w", post.ID, err))
+		} else {
+			post.Status = ScheduledPublished
+			post.Ref = ref
+			published = append(published, post)
+		}
+		// Record the outcome even if ctx was cancelled meanwhile, or a published post would go out again
+		if err := s.options.Store.SaveScheduled(context.WithoutCancel(ctx), post); err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			return published, errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+	return published, errors.Join(errs...)
+}
+
+// claimDue lists the pending posts that are due and marks them as publishing, skipping any another
+// PublishDue call already claimed
+func (s *PostScheduler) claimDue(ctx context.Context) ([]*ScheduledPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	due, err := s.ListScheduled(ctx, ScheduleWindow{End: time.Now().Add(time.Nanosecond), PendingOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	claimed := due[:0]
+	for _, post := range due {
+		if _, ok := s.publishing[post.ID]; !ok && post.Status == ScheduledPending {
+			s.publishing[post.ID] = struct{}{}
+			claimed = append(claimed, post)
+		}
+	}
+	return claimed, nil
+}
+
+// release clears the publishing marks set by claimDue
+func (s *PostScheduler) release(posts []*ScheduledPost) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, post := range posts {
+		delete(s.publishing, post.ID)
+	}
+}
+
+// Start publishes due posts every CheckInterval in the background until ctx is cancelled or the client is
+// closed. Failures are sent to Events.
+func (s *PostScheduler) Start(ctx context.Context) error {
+	if s.f.Self == nil {
+		return ErrNotLoggedIn
+	}
+	if s.f.isClosed() {
+		return ErrClientClosed
+	}
+
+	ctx, cancel := s.f.bindLifetime(ctx)
+	s.f.background.Add(1)
+	go func() {
+		defer s.f.background.Done()
+		defer cancel()
+
+		ticker := time.NewTicker(s.options.CheckInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.PublishDue(ctx); err != nil && ctx.Err() == nil {
+				s.f.emit(SourceScheduler, SeverityError, fmt.Errorf("post scheduler: %w", err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// load fetches a scheduled post, returning ErrScheduledNotFound if there is none
+func (s *PostScheduler) load(ctx context.Context, id string) (*ScheduledPost, error) {
+	post, err := s.options.Store.LoadScheduled(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, fmt.Errorf("%w: %s", ErrScheduledNotFound, id)
+	}
+	return post, nil
+}
+
+// checkConflict returns ErrScheduleConflict if a post at t would put more than MaxPerHour pending posts in
+// some 60 minute span. The post with ID skip, the one being moved, isn't counted. Callers must hold mu.
+func (s *PostScheduler) checkConflict(ctx context.Context, t time.Time, skip string) error {
+	if s.options.MaxPerHour == 0 {
+		return nil
+	}
+	nearby, err := s.options.Store.ListScheduled(ctx, ScheduleWindow{Start: t.Add(-time.Hour + 1), End: t.Add(time.Hour), PendingOnly: true})
+	if err != nil {
+		return err
+	}
+	times := []time.Time{t}
+	for _, post := range nearby {
+		if post.ID != skip && post.Status == ScheduledPending {
+			times = append(times, post.At)
+		}
+	}
+	// The busiest span containing t starts at t or at one of the posts before it
+	for _, start := range times {
+		if start.After(t) {
+			continue
+		}
+		span := ScheduleWindow{Start: start, End: start.Add(time.Hour)}
+		count := 0
+		for _, at := range times {
+			if span.Contains(at) {
+				count++
+			}
+		}
+		if count > s.options.MaxPerHour {
+			return fmt.Errorf("%w: %d posts between %s and %s, limit %d", ErrScheduleConflict, count,
+				span.Start.Format(time.Kitchen), span.End.Format(time.Kitchen), s.options.MaxPerHour)
+		}
+	}
+	return nil
+}
+
+func compareScheduled(a, b *ScheduledPost) int {
+	if c := a.At.Compare(b.At); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
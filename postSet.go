@@ -0,0 +1,388 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/atproto/syntax"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+)
+
+var (
+	ErrPostUnavailable = errors.New("post is deleted or hidden")
+)
+
+// PostBatchItem is the outcome of a bulk action on one post of a PostSet
+type PostBatchItem struct {
+	Post    *PostRef `json:"post"`
+	Record  *PostRef `json:"record,omitempty"` // the like, repost or list item created for the post
+	Skipped bool     `json:"skipped"`          // nothing needed doing, e.g. the post was already liked
+	Err     error    `json:"-"`
+}
+
+func (i PostBatchItem) String() string {
+	if i.Err != nil {
+		return fmt.Sprintf("PostBatchItem{Post: %s, Err: %v}", i.Post.URI, i.Err)
+	}
+	return fmt.Sprintf("PostBatchItem{Post: %s, Skipped: %t}", i.Post.URI, i.Skipped)
+}
+
+// PostBatch reports a bulk action on a PostSet, with one item per post in the set's order
+type PostBatch struct {
+	Items []*PostBatchItem `json:"items"`
+}
+
+func (b PostBatch) String() string {
+	return fmt.Sprintf("PostBatch{Items: %d, Failed: %d}", len(b.Items), len(b.Failed()))
+}
+
+// Failed returns the items that failed
+func (b *PostBatch) Failed() []*PostBatchItem {
+	var failed []*PostBatchItem
+	for _, item := range b.Items {
+		if item.Err != nil {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of the failed items, or returns nil if none failed
+func (b *PostBatch) Err() error {
+	var errs []error
+	for _, item := range b.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", item.Post.URI, item.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// PostBatchOptions holds the settings for PostSet actions; set them with PostBatchOption functions
+type PostBatchOptions struct {
+	Interval time.Duration        // Minimum time between records (default paces to the repo-write rate limit)
+	DryRun   bool                 // Report what would be done without creating any records
+	OnItem   func(*PostBatchItem) // Called as each post is done, for progress reporting
+}
+
+// PostBatchOption configures a PostSet action
+type PostBatchOption func(*PostBatchOptions)
+
+// BatchInterval sets the minimum time between records. Shorter intervals risk rate limiting on large sets.
+func BatchInterval(interval time.Duration) PostBatchOption {
+	return func(o *PostBatchOptions) { o.Interval = interval }
+}
+
+// BatchDryRun reports which posts would be acted on without creating any records
+func BatchDryRun() PostBatchOption {
+	return func(o *PostBatchOptions) { o.DryRun = true }
+}
+
+// BatchProgress sets a function called as each post is done
+func BatchProgress(onItem func(*PostBatchItem)) PostBatchOption {
+	return func(o *PostBatchOptions) { o.OnItem = onItem }
+}
+
+// PostSet is a set of posts, by reference, for study and moderation tools that hydrate, like, repost or list
+// many posts at once. Posts are kept in the order they were added, once each, and their URIs should use DIDs
+// rather than handles, as hydrated posts are matched up by URI. Actions go through the posts one at a time,
+// spaced out to stay within the PDS write budget, and report the outcome for each post instead of stopping at
+// the first failure.
+//
+// A PostSet is not safe for concurrent use.
+type PostSet struct {
+	f         *Firefly
+	refs      []*PostRef
+	positions map[string]int       // index in refs by URI
+	posts     map[string]*FeedPost // hydrated posts by URI
+}
+
+// NewPostSet creates a PostSet holding refs. CIDs may be left empty; actions that need them hydrate the posts
+// first.
+//
+// Example:
+//
+//	set := client.NewPostSet(refs...)
+//	batch, err := set.LikeAll(ctx, firefly.BatchProgress(func(item *firefly.PostBatchItem) {
+//	    fmt.Println(item)
+//	}))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := batch.Err(); err != nil {
+//	    log.Println("some posts were not liked:", err)
+//	}
+func (f *Firefly) NewPostSet(refs ...*PostRef) *PostSet {
+	s := &PostSet{f: f, positions: make(map[string]int), posts: make(map[string]*FeedPost)}
+	s.Add(refs...)
+	return s
+}
+
+// Add adds posts to the set, skipping any it already holds
+func (s *PostSet) Add(refs ...*PostRef) {
+	for _, ref := range refs {
+		if ref == nil || ref.URI == "" {
+			continue
+		}
+		if _, ok := s.positions[ref.URI]; ok {
+			continue
+		}
+		copied := *ref
+		s.positions[ref.URI] = len(s.refs)
+		s.refs = append(s.refs, &copied)
+	}
+}
+
+// AddURIs adds posts to the set by AT URI
+func (s *PostSet) AddURIs(uris ...string) {
+	for _, uri := range uris {
+		s.Add(&PostRef{URI: uri})
+	}
+}
+
+// Len returns the number of posts in the set
+func (s *PostSet) Len() int {
+	return len(s.refs)
+}
+
+// Refs returns the posts in the set, with the CIDs of any that have been hydrated
+func (s *PostSet) Refs() []*PostRef {
+	refs := make([]*PostRef, len(s.refs))
+	for i, ref := range s.refs {
+		copied := *ref
+		refs[i] = &copied
+	}
+	return refs
+}
+
+// Posts returns the hydrated posts in the set's order, leaving out posts that haven't been hydrated or were
+// unavailable
+func (s *PostSet) Posts() []*FeedPost {
+	posts := make([]*FeedPost, 0, len(s.posts))
+	for _, ref := range s.refs {
+		if post, ok := s.posts[ref.URI]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
+
+// Post returns the hydrated post with the given URI, or nil if it isn't in the set or hasn't been hydrated
+func (s *PostSet) Post(uri string) *FeedPost {
+	return s.posts[uri]
+}
+
+// Hydrate loads every post in the set with GetPosts, refreshing any loaded before, and fills in their CIDs.
+// Posts that are deleted or hidden fail with ErrPostUnavailable. The error is only set if fetching failed.
+func (s *PostSet) Hydrate(ctx context.Context) (*PostBatch, error) {
+	uris := make([]string, len(s.refs))
+	for i, ref := range s.refs {
+		uris[i] = ref.URI
+	}
+	clear(s.posts)
+	if err := s.load(ctx, uris); err != nil {
+		return nil, err
+	}
+
+	batch := &PostBatch{}
+	for _, ref := range s.refs {
+		item := &PostBatchItem{Post: ref}
+		if _, ok := s.posts[ref.URI]; !ok {
+			item.Err = ErrPostUnavailable
+		}
+		batch.Items = append(batch.Items, item)
+	}
+	return batch, nil
+}
+
+// LikeAll likes every post in the set. Posts are hydrated first if they haven't been, and posts the
+// authenticated user already likes are skipped. The error is only set if the action couldn't start or ctx was
+// cancelled, in which case the batch covers the posts done so far.
+func (s *PostSet) LikeAll(ctx context.Context, options ...PostBatchOption) (*PostBatch, error) {
+	return s.interact(ctx, CollectionLike, options, (*FeedPost).LikedByMe, func(subject *atproto.RepoStrongRef, now string) lexutil.CBOR {
+		return &bsky.FeedLike{Subject: subject, CreatedAt: now}
+	})
+}
+
+// RepostAll reposts every post in the set. Posts are hydrated first if they haven't been, and posts the
+// authenticated user already reposted are skipped. The error is only set if the action couldn't start or ctx
+// was cancelled, in which case the batch covers the posts done so far.
+func (s *PostSet) RepostAll(ctx context.Context, options ...PostBatchOption) (*PostBatch, error) {
+	return s.interact(ctx, CollectionRepost, options, (*FeedPost).RepostedByMe, func(subject *atproto.RepoStrongRef, now string) lexutil.CBOR {
+		return &bsky.FeedRepost{Subject: subject, CreatedAt: now}
+	})
+}
+
+// interact creates a record pointing at each post in the set, skipping posts for which done reports true
+func (s *PostSet) interact(ctx context.Context, collection string, options []PostBatchOption, done func(*FeedPost) bool, newRecord func(*atproto.RepoStrongRef, string) lexutil.CBOR) (*PostBatch, error) {
+	if s.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	opts := s.batchOptions(options)
+	if err := s.hydrateMissing(ctx); err != nil {
+		return nil, err
+	}
+
+	run := s.newBatchRun(opts)
+	for _, ref := range s.refs {
+		item := &PostBatchItem{Post: ref}
+		post, ok := s.posts[ref.URI]
+		switch {
+		case !ok:
+			item.Err = ErrPostUnavailable
+		case done(post):
+			item.Skipped = true
+		default:
+			subject := &atproto.RepoStrongRef{Uri: ref.URI, Cid: ref.CID}
+			if err := run.write(ctx, item, collection, newRecord(subject, time.Now().UTC().Format(time.RFC3339))); err != nil {
+				return run.batch, err
+			}
+		}
+		run.finish(item)
+	}
+	return run.batch, nil
+}
+
+// AddAuthorsToList adds the author of every post in the set to one of the authenticated user's lists, since
+// lists hold accounts rather than posts. Authors are taken from the post URIs, so posts don't need to be
+// hydrated. Authors already on the list, or already added for an earlier post, are skipped. The error is only
+// set if the action couldn't start or ctx was cancelled, in which case the batch covers the posts done so far.
+//
+// Example:
+//
+//	set := client.NewPostSet()
+//	set.AddURIs(reportedURIs...)
+//	batch, err := set.AddAuthorsToList(ctx, modListURI)
+func (s *PostSet) AddAuthorsToList(ctx context.Context, listURI string, options ...PostBatchOption) (*PostBatch, error) {
+	if s.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	opts := s.batchOptions(options)
+	if err := s.f.checkOwnList(listURI); err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]struct{})
+	for record, err := range s.f.ListCollection(ctx, s.f.Self.Did, CollectionListItem) {
+		if err != nil {
+			return nil, err
+		}
+		if item, ok := record.Value.(*bsky.GraphListitem); ok && item.List == listURI {
+			members[item.Subject] = struct{}{}
+		}
+	}
+
+	run := s.newBatchRun(opts)
+	for _, ref := range s.refs {
+		item := &PostBatchItem{Post: ref}
+		did, err := s.author(ctx, ref.URI)
+		if err != nil {
+			item.Err = err
+		} else if _, ok := members[did]; ok {
+			item.Skipped = true
+		} else {
+			record := &bsky.GraphListitem{List: listURI, Subject: did, CreatedAt: time.Now().UTC().Format(time.RFC3339)}
+			if err := run.write(ctx, item, CollectionListItem, record); err != nil {
+				return run.batch, err
+			}
+			if item.Err == nil {
+				members[did] = struct{}{}
+			}
+		}
+		run.finish(item)
+	}
+	return run.batch, nil
+}
+
+// batchOptions applies options over the defaults
+func (s *PostSet) batchOptions(options []PostBatchOption) PostBatchOptions {
+	opts := PostBatchOptions{Interval: createRecordInterval}
+	for _, option := range options {
+		option(&opts)
+	}
+	return opts
+}
+
+// hydrateMissing hydrates the posts that haven't been hydrated yet
+func (s *PostSet) hydrateMissing(ctx context.Context) error {
+	var uris []string
+	for _, ref := range s.refs {
+		if _, ok := s.posts[ref.URI]; !ok {
+			uris = append(uris, ref.URI)
+		}
+	}
+	if len(uris) == 0 {
+		return nil
+	}
+	return s.load(ctx, uris)
+}
+
+// load hydrates the posts at uris, storing them and their CIDs
+func (s *PostSet) load(ctx context.Context, uris []string) error {
+	posts, err := s.f.GetPosts(ctx, uris)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		if i, ok := s.positions[post.URI]; ok {
+			s.posts[post.URI] = post
+			s.refs[i].CID = post.CID
+		}
+	}
+	return nil
+}
+
+// author returns the DID of the author of the post at uri
+func (s *PostSet) author(ctx context.Context, uri string) (string, error) {
+	aturi, err := syntax.ParseATURI(uri)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidUri, err)
+	}
+	return s.f.resolveActor(ctx, aturi.Authority().String())
+}
+
+// batchRun paces the record writes of one PostSet action and collects its items
+type batchRun struct {
+	f         *Firefly
+	opts      PostBatchOptions
+	batch     *PostBatch
+	lastWrite time.Time
+}
+
+func (s *PostSet) newBatchRun(opts PostBatchOptions) *batchRun {
+	return &batchRun{f: s.f, opts: opts, batch: &PostBatch{}}
+}
+
+// write creates a record in the authenticated user's repo for item, after waiting for the interval since the
+// previous one. Failures are recorded on the item; the error is only set if ctx is cancelled while waiting.
+func (r *batchRun) write(ctx context.Context, item *PostBatchItem, collection string, record lexutil.CBOR) error {
+	if r.opts.DryRun {
+		return nil
+	}
+	if !sleepUntil(ctx, r.lastWrite.Add(r.opts.Interval)) {
+		return ctx.Err()
+	}
+	item.Record, item.Err = r.f.withRateLimitRetry(ctx, func() (*PostRef, error) {
+		resp, err := atproto.RepoCreateRecord(ctx, r.f.api, &atproto.RepoCreateRecord_Input{
+			Collection: collection,
+			Repo:       r.f.Self.Did,
+			Record:     &lexutil.LexiconTypeDecoder{Val: record},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &PostRef{URI: resp.Uri, CID: resp.Cid}, nil
+	})
+	r.lastWrite = time.Now()
+	return nil
+}
+
+// finish adds a finished item to the batch and reports it to OnItem
+func (r *batchRun) finish(item *PostBatchItem) {
+	r.batch.Items = append(r.batch.Items, item)
+	if r.opts.OnItem != nil {
+		r.opts.OnItem(item)
+	}
+}
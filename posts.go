@@ -1,8 +1,10 @@
 package firefly
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -39,6 +41,45 @@ func (ref *PostRef) IsValid() bool {
 	return true
 }
 
+// NewPostRefFromURI builds a PostRef for an existing record by fetching its current CID with
+// com.atproto.repo.getRecord. Replying, quoting, and liking require a strong reference (URI + CID),
+// but usually only the URI is at hand. Handle-based URIs are accepted; the returned URI is the one
+// reported by the server.
+//
+// Example:
+//
+//	ref, err := client.NewPostRefFromURI(ctx, "at://alice.bsky.social/app.bsky.feed.post/3k2a...")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	reply := firefly.NewDraftPost().AddText("Agreed!").SetReplyInfo(ref, ref)
+func (f *Firefly) NewPostRefFromURI(ctx context.Context, uri string) (*PostRef, error) {
+	if uri == "" {
+		return nil, ErrEmptyUri
+	}
+	aturi, err := syntax.ParseATURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidUri, err)
+	}
+	if aturi.Collection() == "" || aturi.RecordKey() == "" {
+		return nil, fmt.Errorf("%w: URI does not point to a record", ErrInvalidUri)
+	}
+
+	record, err := atproto.RepoGetRecord(ctx, f.api, "", aturi.Collection().String(),
+		aturi.Authority().String(), aturi.RecordKey().String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	if record.Cid == nil || *record.Cid == "" {
+		return nil, fmt.Errorf("%w: record has no CID", ErrBadResponse)
+	}
+
+	return &PostRef{
+		CID: *record.Cid,
+		URI: record.Uri,
+	}, nil
+}
+
 // OldToNewRefPointer converts a pointer to the old reference to a pointer to the new reference or nil
 func OldToNewRefPointer(oldRef *atproto.RepoStrongRef) *PostRef {
 	if oldRef == nil {
@@ -57,6 +98,42 @@ type ReplyInfo struct { // nil if not a reply
 	ReplyRoot   *PostRef `json:"replyRoot" cborgen:"replyRoot"`     // top-level post the ReplyTarget is under
 }
 
+// PostViewer is the authenticated user's relationship to a post. Record fields hold the URI of the like or
+// repost record, and are empty when there is none.
+type PostViewer struct {
+	Like              string `json:"like,omitempty"`   // Self's like record for the post
+	Repost            string `json:"repost,omitempty"` // Self's repost record for the post
+	ThreadMuted       bool   `json:"threadMuted"`
+	ReplyDisabled     bool   `json:"replyDisabled"`     // a thread gate stops Self from replying
+	EmbeddingDisabled bool   `json:"embeddingDisabled"` // a post gate stops Self from quoting
+	Pinned            bool   `json:"pinned"`            // the post is pinned to Self's profile
+}
+
+func (v PostViewer) String() string {
+	return fmt.Sprintf("PostViewer{Liked: %t, Reposted: %t, ThreadMuted: %t, ReplyDisabled: %t}",
+		v.Like != "", v.Repost != "", v.ThreadMuted, v.ReplyDisabled)
+}
+
+// OldToNewPostViewer converts a post's viewer state; returns nil if there is none
+func OldToNewPostViewer(oldViewer *bsky.FeedDefs_ViewerState) *PostViewer {
+	if oldViewer == nil {
+		return nil
+	}
+	viewer := &PostViewer{
+		ThreadMuted:       oldViewer.ThreadMuted != nil && *oldViewer.ThreadMuted,
+		ReplyDisabled:     oldViewer.ReplyDisabled != nil && *oldViewer.ReplyDisabled,
+		EmbeddingDisabled: oldViewer.EmbeddingDisabled != nil && *oldViewer.EmbeddingDisabled,
+		Pinned:            oldViewer.Pinned != nil && *oldViewer.Pinned,
+	}
+	if oldViewer.Like != nil {
+		viewer.Like = *oldViewer.Like
+	}
+	if oldViewer.Repost != nil {
+		viewer.Repost = *oldViewer.Repost
+	}
+	return viewer
+}
+
 // FeedPost represents a BlueSky post with all its content and metadata.
 // This includes the post text, rich text formatting, creation time, language, and thread information.
 // Some fields like URI, CID, and Author may be populated depending on the context where the post was retrieved.
@@ -77,10 +154,10 @@ type FeedPost struct {
 	RepostCount *int            `json:"repostCount" cborgen:"repostCount"`
 	Labels      []string        `json:"labels,omitempty" cborgen:"labels,omitempty"`
 	Embed       *Embed          `json:"embed,omitempty" cborgen:"embed,omitempty"`
+	Viewer      *PostViewer     `json:"viewer,omitempty" cborgen:"viewer,omitempty"` // the authenticated user's relationship to the post
 	Raw         *bsky.FeedPost
 	RawDetailed *bsky.FeedDefs_PostView
 	//Threadgate    *FeedDefs_ThreadgateView           `json:"threadgate,omitempty" cborgen:"threadgate,omitempty"`
-	//Viewer        *FeedDefs_ViewerState              `json:"viewer,omitempty" cborgen:"viewer,omitempty"`
 }
 
 func (p FeedPost) String() string {
@@ -184,6 +261,7 @@ func (f *Firefly) OldToNewPostView(oldPostView *bsky.FeedDefs_PostView) (*FeedPo
 	newPost.RawDetailed = oldPostView
 	newPost.URI = oldPostView.Uri
 	newPost.CID = oldPostView.Cid
+	newPost.Viewer = OldToNewPostViewer(oldPostView.Viewer)
 
 	var likes int
 	if oldPostView.LikeCount != nil {
@@ -218,3 +296,130 @@ func (f *Firefly) OldToNewPostView(oldPostView *bsky.FeedDefs_PostView) (*FeedPo
 
 	return newPost, err
 }
+
+// GetQuotes returns a page of posts that quote the post at uri, along with the cursor for the next page
+// (empty when there are no more results)
+func (f *Firefly) GetQuotes(ctx context.Context, uri string, cursor string, limit int) ([]*FeedPost, string, error) {
+	result, err := bsky.FeedGetQuotes(ctx, f.api, "", cursor, int64(limit), uri)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	quotes := make([]*FeedPost, 0, len(result.Posts))
+	for _, postView := range result.Posts {
+		post, err := f.OldToNewPostView(postView)
+		if err != nil {
+			continue
+		}
+		quotes = append(quotes, post)
+	}
+	next := ""
+	if result.Cursor != nil {
+		next = *result.Cursor
+	}
+	return quotes, next, nil
+}
+
+// GetLikes returns one page of the accounts that liked the post at uri, along with the cursor for the next page.
+// The returned cursor is empty when there are no more pages.
+func (f *Firefly) GetLikes(ctx context.Context, uri string, cursor string, limit int) ([]*User, string, error) {
+	result, err := bsky.FeedGetLikes(ctx, f.api, "", cursor, int64(limit), uri)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	users := make([]*User, 0, len(result.Likes))
+	for _, like := range result.Likes {
+		if like == nil {
+			continue
+		}
+		user, err := OldToNewUser(like.Actor)
+		if err != nil {
+			return nil, "", err
+		}
+		users = append(users, user)
+	}
+	next := ""
+	if result.Cursor != nil {
+		next = *result.Cursor
+	}
+	return users, next, nil
+}
+
+// GetRepostedBy returns one page of the accounts that reposted the post at uri, along with the cursor for the
+// next page. The returned cursor is empty when there are no more pages.
+func (f *Firefly) GetRepostedBy(ctx context.Context, uri string, cursor string, limit int) ([]*User, string, error) {
+	result, err := bsky.FeedGetRepostedBy(ctx, f.api, "", cursor, int64(limit), uri)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	users := make([]*User, 0, len(result.RepostedBy))
+	for _, actor := range result.RepostedBy {
+		user, err := OldToNewUser(actor)
+		if err != nil {
+			return nil, "", err
+		}
+		users = append(users, user)
+	}
+	next := ""
+	if result.Cursor != nil {
+		next = *result.Cursor
+	}
+	return users, next, nil
+}
+
+// GetPosts hydrates posts by URI, 25 per request. Posts that are deleted or hidden from the viewer are left
+// out, so the result can be shorter than uris and isn't guaranteed to be in the same order.
+//
+// Example:
+//
+//	posts, err := client.GetPosts(ctx, []string{event.LikeEvent.Subject.URI})
+func (f *Firefly) GetPosts(ctx context.Context, uris []string) ([]*FeedPost, error) {
+	posts := make([]*FeedPost, 0, len(uris))
+	for batch := range slices.Chunk(uris, 25) {
+		result, err := f.flights.posts.do(ctx, strings.Join(batch, " "), func(ctx context.Context) (*bsky.FeedGetPosts_Output, error) {
+			return bsky.FeedGetPosts(ctx, f.api, batch)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+		}
+		for _, view := range result.Posts {
+			post, err := f.OldToNewPostView(view)
+			if err != nil {
+				continue
+			}
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+// GetActorLikes returns one page of the posts liked by actor, along with the cursor for the next page.
+// The server only allows this for the authenticated user's own likes; pass an empty actor to use Self.
+// The returned cursor is empty when there are no more pages.
+func (f *Firefly) GetActorLikes(ctx context.Context, actor string, cursor string, limit int) ([]*FeedPost, string, error) {
+	if actor == "" {
+		if f.Self == nil {
+			return nil, "", ErrNotLoggedIn
+		}
+		actor = f.Self.Did
+	}
+	result, err := bsky.FeedGetActorLikes(ctx, f.api, actor, cursor, int64(limit))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	posts := make([]*FeedPost, 0, len(result.Feed))
+	for _, item := range result.Feed {
+		if item == nil {
+			continue
+		}
+		post, err := f.OldToNewPostView(item.Post)
+		if err != nil {
+			continue
+		}
+		posts = append(posts, post)
+	}
+	next := ""
+	if result.Cursor != nil {
+		next = *result.Cursor
+	}
+	return posts, next, nil
+}
@@ -0,0 +1,156 @@
+package firefly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+)
+
+// ThreadSort is the order in which replies are shown in a thread
+type ThreadSort string
+
+const (
+	ThreadSortOldest    ThreadSort = "oldest"
+	ThreadSortNewest    ThreadSort = "newest"
+	ThreadSortMostLikes ThreadSort = "most-likes"
+	ThreadSortRandom    ThreadSort = "random"
+	ThreadSortHotness   ThreadSort = "hotness"
+)
+
+// ThreadViewPreferences controls how the authenticated user's clients display threads
+type ThreadViewPreferences struct {
+	Sort                    ThreadSort `json:"sort,omitempty"`                    // empty if unset
+	PrioritizeFollowedUsers bool       `json:"prioritizeFollowedUsers,omitempty"` // show followed users' replies first
+}
+
+// rawPreferences is the preferences list kept as raw JSON, so preference types this version of
+// Firefly doesn't know about survive a read-modify-write cycle unchanged
+type rawPreferences struct {
+	Preferences []json.RawMessage `json:"preferences"`
+}
+
+// GetInterests returns the interest tags stored in the authenticated user's preferences, usually
+// gathered during onboarding. Returns nil if none are set.
+func (f *Firefly) GetInterests(ctx context.Context) ([]string, error) {
+	var pref bsky.ActorDefs_InterestsPref
+	found, err := f.getPreference(ctx, "app.bsky.actor.defs#interestsPref", &pref)
+	if err != nil || !found {
+		return nil, err
+	}
+	return pref.Tags, nil
+}
+
+// SetInterests replaces the interest tags in the authenticated user's preferences
+func (f *Firefly) SetInterests(ctx context.Context, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	return f.putPreference(ctx, "app.bsky.actor.defs#interestsPref", &bsky.ActorDefs_InterestsPref{
+		LexiconTypeID: "app.bsky.actor.defs#interestsPref",
+		Tags:          tags,
+	})
+}
+
+// GetThreadViewPreferences returns the authenticated user's thread display preferences.
+// Returns the zero value if none are set.
+func (f *Firefly) GetThreadViewPreferences(ctx context.Context) (*ThreadViewPreferences, error) {
+	var pref bsky.ActorDefs_ThreadViewPref
+	found, err := f.getPreference(ctx, "app.bsky.actor.defs#threadViewPref", &pref)
+	if err != nil {
+		return nil, err
+	}
+	result := &ThreadViewPreferences{}
+	if !found {
+		return result, nil
+	}
+	if pref.Sort != nil {
+		result.Sort = ThreadSort(*pref.Sort)
+	}
+	if pref.PrioritizeFollowedUsers != nil {
+		result.PrioritizeFollowedUsers = *pref.PrioritizeFollowedUsers
+	}
+	return result, nil
+}
+
+// SetThreadViewPreferences replaces the authenticated user's thread display preferences
+func (f *Firefly) SetThreadViewPreferences(ctx context.Context, prefs *ThreadViewPreferences) error {
+	if prefs == nil {
+		prefs = &ThreadViewPreferences{}
+	}
+	pref := &bsky.ActorDefs_ThreadViewPref{
+		LexiconTypeID:           "app.bsky.actor.defs#threadViewPref",
+		PrioritizeFollowedUsers: &prefs.PrioritizeFollowedUsers,
+	}
+	if prefs.Sort != "" {
+		sort := string(prefs.Sort)
+		pref.Sort = &sort
+	}
+	return f.putPreference(ctx, "app.bsky.actor.defs#threadViewPref", pref)
+}
+
+// getRawPreferences fetches the authenticated user's preferences without decoding them
+func (f *Firefly) getRawPreferences(ctx context.Context) ([]json.RawMessage, error) {
+	var out rawPreferences
+	if err := f.api.LexDo(ctx, lexutil.Query, "", "app.bsky.actor.getPreferences", nil, nil, &out); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	return out.Preferences, nil
+}
+
+// getPreference decodes the first preference of the given type into out and reports whether it was found
+func (f *Firefly) getPreference(ctx context.Context, typeID string, out any) (bool, error) {
+	prefs, err := f.getRawPreferences(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, raw := range prefs {
+		if preferenceType(raw) == typeID {
+			if err := json.Unmarshal(raw, out); err != nil {
+				return false, fmt.Errorf("%w: %w", ErrBadResponse, err)
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// putPreference replaces every preference of the given type with value, leaving all other preferences untouched
+func (f *Firefly) putPreference(ctx context.Context, typeID string, value any) error {
+	prefs, err := f.getRawPreferences(ctx)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode preference: %w", err)
+	}
+
+	updated := make([]json.RawMessage, 0, len(prefs)+1)
+	for _, raw := range prefs {
+		if preferenceType(raw) != typeID {
+			updated = append(updated, raw)
+		}
+	}
+	updated = append(updated, encoded)
+
+	err = f.api.LexDo(ctx, lexutil.Procedure, "application/json", "app.bsky.actor.putPreferences", nil,
+		&rawPreferences{Preferences: updated}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update preferences: %w", err)
+	}
+	return nil
+}
+
+// preferenceType returns the $type of a raw preference, or an empty string if it can't be read
+func preferenceType(raw json.RawMessage) string {
+	var typed struct {
+		Type string `json:"$type"`
+	}
+	if err := json.Unmarshal(raw, &typed); err != nil {
+		return ""
+	}
+	return typed.Type
+}
@@ -0,0 +1,287 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/lex/util"
+)
+
+// maxProfilesPerRequest is the most actors app.bsky.actor.getProfiles accepts in one call
+const maxProfilesPerRequest = 25
+
+// AppliedLabel is a moderation label attached to an account or record
+type AppliedLabel struct {
+	Value   string `json:"value"`
+	Source  string `json:"source"`            // DID of the labeler, or of the account itself for self-labels
+	Negated bool   `json:"negated,omitempty"` // the label removes an earlier label with the same value
+}
+
+// OldToNewLabels converts the labels attached to a view
+func OldToNewLabels(oldLabels []*atproto.LabelDefs_Label) []AppliedLabel {
+	if len(oldLabels) == 0 {
+		return nil
+	}
+	labels := make([]AppliedLabel, 0, len(oldLabels))
+	for _, label := range oldLabels {
+		if label == nil {
+			continue
+		}
+		labels = append(labels, AppliedLabel{
+			Value:   label.Val,
+			Source:  label.Src,
+			Negated: label.Neg != nil && *label.Neg,
+		})
+	}
+	return labels
+}
+
+// ProfileViewer is the authenticated user's relationship to an account. Record fields hold the URI of the
+// follow or block record, and are empty when there is none.
+type ProfileViewer struct {
+	Following      string `json:"following,omitempty"`      // Self's follow record for the account
+	FollowedBy     string `json:"followedBy,omitempty"`     // the account's follow record for Self
+	Blocking       string `json:"blocking,omitempty"`       // Self's block record for the account
+	BlockingByList string `json:"blockingByList,omitempty"` // URI of a moderation list of Self's that blocks the account
+	BlockedBy      bool   `json:"blockedBy"`
+	Muted          bool   `json:"muted"`
+	MutedByList    string `json:"mutedByList,omitempty"` // URI of a moderation list of Self's that mutes the account
+}
+
+func (v ProfileViewer) String() string {
+	return fmt.Sprintf("ProfileViewer{Following: %t, FollowedBy: %t, Blocking: %t, BlockedBy: %t, Muted: %t}",
+		v.Following != "", v.FollowedBy != "", v.Blocking != "" || v.BlockingByList != "", v.BlockedBy, v.Muted)
+}
+
+// OldToNewProfileViewer converts a profile's viewer state; returns nil if there is none
+func OldToNewProfileViewer(oldViewer *bsky.ActorDefs_ViewerState) *ProfileViewer {
+	if oldViewer == nil {
+		return nil
+	}
+	viewer := &ProfileViewer{
+		BlockedBy: oldViewer.BlockedBy != nil && *oldViewer.BlockedBy,
+		Muted:     oldViewer.Muted != nil && *oldViewer.Muted,
+	}
+	if oldViewer.Following != nil {
+		viewer.Following = *oldViewer.Following
+	}
+	if oldViewer.FollowedBy != nil {
+		viewer.FollowedBy = *oldViewer.FollowedBy
+	}
+	if oldViewer.Blocking != nil {
+		viewer.Blocking = *oldViewer.Blocking
+	}
+	if oldViewer.BlockingByList != nil {
+		viewer.BlockingByList = oldViewer.BlockingByList.Uri
+	}
+	if oldViewer.MutedByList != nil {
+		viewer.MutedByList = oldViewer.MutedByList.Uri
+	}
+	return viewer
+}
+
+// ProfileQuery holds the settings for GetProfile and GetProfiles; set them with ProfileOption functions
+type ProfileQuery struct {
+	BypassCache bool     // Fetch from the server even if the profile cache has a fresh copy
+	Labelers    []string // Labeler DIDs sent as atproto-accept-labelers; empty for the server's defaults
+	WithViewer  bool     // Require a session and always fill in User.Viewer
+}
+
+// ProfileOption configures a GetProfile or GetProfiles call
+type ProfileOption func(*ProfileQuery)
+
+// ProfileBypassCache fetches fresh profiles and updates the profile cache with them
+func ProfileBypassCache() ProfileOption {
+	return func(q *ProfileQuery) { q.BypassCache = true }
+}
+
+// ProfileLabelers asks for labels from these labelers, instead of the ones the server applies by default.
+// Each entry is a labeler DID, optionally followed by ";redact". Profiles fetched with different labelers
+// are cached separately.
+func ProfileLabelers(labelers ...string) ProfileOption {
+	return func(q *ProfileQuery) { q.Labelers = append(q.Labelers, labelers...) }
+}
+
+// ProfileWithViewer makes the call fail with ErrNotLoggedIn when there is no session, and guarantees that
+// User.Viewer is set, even for accounts Self has no relationship with
+func ProfileWithViewer() ProfileOption {
+	return func(q *ProfileQuery) { q.WithViewer = true }
+}
+
+// WithProfileCache keeps profiles returned by GetProfile and GetProfiles for ttl, so repeated lookups of the
+// same account don't hit the server. Cached profiles can lag behind follows, blocks and label changes; pass
+// ProfileBypassCache for lookups that must be current.
+func WithProfileCache(ttl time.Duration) Option {
+	return func(f *Firefly) {
+		if ttl > 0 {
+			f.profiles = newProfileCache(ttl)
+		}
+	}
+}
+
+// profileCache holds recently fetched profiles, keyed by host, labeler set and by both DID and handle
+type profileCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedProfile
+	ttl     time.Duration
+}
+
+// cachedProfile is a single cached profile
+type cachedProfile struct {
+	user    *User
+	expires time.Time
+}
+
+func newProfileCache(ttl time.Duration) *profileCache {
+	return &profileCache{entries: make(map[string]cachedProfile), ttl: ttl}
+}
+
+// profileCacheKey identifies an actor's profile as seen through a set of labelers. Profiles read from a
+// WithHost server come back without viewer state, so they are kept apart from the client's own.
+func profileCacheKey(host string, labelers []string, actor string) string {
+	key := strings.Join(labelers, ",") + "|" + strings.ToLower(actor)
+	if host != "" {
+		key = host + " " + key
+	}
+	return key
+}
+
+// lookup returns a copy of a cached profile if it hasn't expired. Safe to call on a nil cache.
+func (c *profileCache) lookup(host string, labelers []string, actor string) (*User, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[profileCacheKey(host, labelers, actor)]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	user := *entry.user
+	return &user, true
+}
+
+// store caches a profile under its DID and handle. Safe to call on a nil cache.
+func (c *profileCache) store(host string, labelers []string, user *User) {
+	if c == nil || user == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	copied := *user
+	entry := cachedProfile{user: &copied, expires: now.Add(c.ttl)}
+	c.entries[profileCacheKey(host, labelers, user.Did)] = entry
+	if user.Handle != "" {
+		c.entries[profileCacheKey(host, labelers, user.Handle)] = entry
+	}
+}
+
+// newProfileQuery applies options and checks the session if the viewer is required
+func (f *Firefly) newProfileQuery(options []ProfileOption) (ProfileQuery, error) {
+	var query ProfileQuery
+	for _, option := range options {
+		option(&query)
+	}
+	if query.WithViewer && f.Self == nil {
+		return query, ErrNotLoggedIn
+	}
+	return query, nil
+}
+
+// profileClient returns the client to fetch profiles with, sending the query's labelers if there are any
+func (f *Firefly) profileClient(query ProfileQuery) *apiClient {
+	if len(query.Labelers) == 0 {
+		return f.api
+	}
+	return &apiClient{f: f, headers: map[string]string{
+		"atproto-accept-labelers": strings.Join(query.Labelers, ", "),
+	}}
+}
+
+// finishProfile applies the query's guarantees to a fetched or cached profile
+func finishProfile(query ProfileQuery, user *User) *User {
+	if query.WithViewer && user.Viewer == nil {
+		user.Viewer = &ProfileViewer{}
+	}
+	return user
+}
+
+// GetProfiles retrieves detailed profiles for several accounts, in the order given, using as few requests as
+// possible. Actors can be handles, DIDs, or any other form ParseActor accepts. Accounts the server can't find
+// are left out of the result.
+//
+// Example:
+//
+//	users, err := client.GetProfiles(ctx, []string{"alice.bsky.social", "did:plc:xyz789"},
+//	    firefly.ProfileWithViewer(), firefly.ProfileLabelers("did:plc:ar7c4by46qjdydhdevvrndac"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, user := range users {
+//	    fmt.Println(user.Handle, user.Labels, user.Viewer)
+//	}
+func (f *Firefly) GetProfiles(ctx context.Context, actors []string, options ...ProfileOption) ([]*User, error) {
+	query, err := f.newProfileQuery(options)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _ := hostOverride(ctx, util.Query)
+	found := make(map[string]*User, len(actors))
+	var missing []string
+	for _, actor := range actors {
+		actor = normalizeActor(actor)
+		if _, ok := found[strings.ToLower(actor)]; ok || actor == "" {
+			continue
+		}
+		if !query.BypassCache {
+			if user, ok := f.profiles.lookup(host, query.Labelers, actor); ok {
+				found[strings.ToLower(actor)] = user
+				continue
+			}
+		}
+		found[strings.ToLower(actor)] = nil
+		missing = append(missing, actor)
+	}
+
+	client := f.profileClient(query)
+	for batch := range slices.Chunk(missing, maxProfilesPerRequest) {
+		result, err := bsky.ActorGetProfiles(ctx, client, batch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+		}
+		for _, profile := range result.Profiles {
+			user, err := OldToNewDetailedUser(profile)
+			if err != nil {
+				return nil, err
+			}
+			f.profiles.store(host, query.Labelers, user)
+			found[strings.ToLower(user.Did)] = user
+			found[strings.ToLower(user.Handle)] = user
+		}
+	}
+
+	users := make([]*User, 0, len(actors))
+	seen := make(map[string]bool, len(actors))
+	for _, actor := range actors {
+		user := found[strings.ToLower(normalizeActor(actor))]
+		if user == nil || seen[user.Did] {
+			continue
+		}
+		seen[user.Did] = true
+		users = append(users, finishProfile(query, user))
+	}
+	return users, nil
+}
@@ -0,0 +1,234 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+)
+
+// QuoteDiscovery reports a newly found post quoting one of the authenticated user's posts
+type QuoteDiscovery struct {
+	Quote      *FeedPost `json:"quote"`  // The post doing the quoting
+	Quoted     *FeedPost `json:"quoted"` // The authenticated user's post that was quoted
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+func (q QuoteDiscovery) String() string {
+	return fmt.Sprintf("QuoteDiscovery{Quote: %s, Quoted: %s}", q.Quote.URI, q.Quoted.URI)
+}
+
+// QuoteTrackerOptions configures a QuoteTracker
+type QuoteTrackerOptions struct {
+	PollInterval time.Duration         // Time between checks (default 10 minutes)
+	RecentPosts  int                   // Number of the user's most recent posts to watch (default 25, max 100)
+	BufferSize   int                   // Channel buffer size (default 100)
+	OnQuote      func(*QuoteDiscovery) // Optional callback invoked for every quote before it is sent on the channel
+}
+
+// QuoteTracker watches the authenticated user's recent posts for new quote posts using app.bsky.feed.getQuotes.
+// Posts are only re-fetched when their quote count changes, so idle polls cost a single author feed request.
+type QuoteTracker struct {
+	f       *Firefly
+	options QuoteTrackerOptions
+
+	mu       sync.Mutex
+	counts   map[string]int                 // Last seen quote count per post URI
+	known    map[string]map[string]struct{} // Quote URIs seen per post URI
+	baseline bool
+}
+
+// NewQuoteTracker creates a QuoteTracker for the authenticated user.
+// Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	tracker := client.NewQuoteTracker(nil)
+//	quotes, err := tracker.Start(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for quote := range quotes {
+//	    fmt.Printf("%s quoted you: %s\n", quote.Quote.Author.Handle, quote.Quote.Text)
+//	}
+func (f *Firefly) NewQuoteTracker(options *QuoteTrackerOptions) *QuoteTracker {
+	if options == nil {
+		options = &QuoteTrackerOptions{}
+	}
+	opts := *options
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 10 * time.Minute
+	}
+	if opts.RecentPosts <= 0 {
+		opts.RecentPosts = 25
+	}
+	if opts.RecentPosts > 100 {
+		opts.RecentPosts = 100
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	return &QuoteTracker{
+		f:       f,
+		options: opts,
+		counts:  make(map[string]int),
+		known:   make(map[string]map[string]struct{}),
+	}
+}
+
+// Check looks for quotes of the user's recent posts that haven't been seen before. The first check records
+// existing quotes as a baseline and returns nothing; quotes of posts published afterwards are always reported.
+func (t *QuoteTracker) Check(ctx context.Context) ([]*QuoteDiscovery, error) {
+	if t.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+
+	posts, err := t.recentPosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var found []*QuoteDiscovery
+	now := time.Now()
+	watched := make(map[string]struct{}, len(posts))
+	for _, post := range posts {
+		watched[post.URI] = struct{}{}
+		count := 0
+		if post.QuoteCount != nil {
+			count = *post.QuoteCount
+		}
+		previous, tracked := t.counts[post.URI]
+		if (tracked && count == previous) || (!tracked && count == 0) {
+			t.counts[post.URI] = count
+			continue
+		}
+
+		quotes, err := t.allQuotes(ctx, post.URI)
+		if err != nil {
+			return found, err
+		}
+		seen := t.known[post.URI]
+		if seen == nil {
+			seen = make(map[string]struct{})
+			t.known[post.URI] = seen
+		}
+		for _, quote := range quotes {
+			if _, ok := seen[quote.URI]; ok {
+				continue
+			}
+			seen[quote.URI] = struct{}{}
+			// Quotes already present on the first check are the baseline
+			if t.baseline {
+				found = append(found, &QuoteDiscovery{Quote: quote, Quoted: post, DetectedAt: now})
+			}
+		}
+		t.counts[post.URI] = count
+	}
+
+	// Forget posts that have fallen out of the watch window
+	for uri := range t.counts {
+		if _, ok := watched[uri]; !ok {
+			delete(t.counts, uri)
+			delete(t.known, uri)
+		}
+	}
+	t.baseline = true
+	return found, nil
+}
+
+// Start runs the tracker in the background until ctx is cancelled, checking immediately and then every
+// PollInterval. Quotes are passed to OnQuote (if set) and sent on the returned channel, which is closed
+// when the tracker stops or the client is closed. Errors from background checks are sent to Events.
+func (t *QuoteTracker) Start(ctx context.Context) (chan *QuoteDiscovery, error) {
+	if t.f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	if t.f.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	quotes := make(chan *QuoteDiscovery, t.options.BufferSize)
+
+	ctx, cancel := t.f.bindLifetime(ctx)
+	t.f.background.Add(1)
+	go func() {
+		defer t.f.background.Done()
+		defer cancel()
+		defer close(quotes)
+
+		ticker := time.NewTicker(t.options.PollInterval)
+		defer ticker.Stop()
+
+		t.runCheck(ctx, quotes)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.runCheck(ctx, quotes)
+			}
+		}
+	}()
+
+	return quotes, nil
+}
+
+// runCheck performs a single check and delivers the results
+func (t *QuoteTracker) runCheck(ctx context.Context, quotes chan<- *QuoteDiscovery) {
+	found, err := t.Check(ctx)
+	if err != nil && ctx.Err() == nil {
+		t.f.emit(SourceScheduler, SeverityError, err)
+	}
+	for _, quote := range found {
+		if t.options.OnQuote != nil {
+			t.options.OnQuote(quote)
+		}
+		select {
+		case quotes <- quote:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// recentPosts fetches the user's most recent original posts and replies, skipping reposts
+func (t *QuoteTracker) recentPosts(ctx context.Context) ([]*FeedPost, error) {
+	feed, err := bsky.FeedGetAuthorFeed(ctx, t.f.api, t.f.Self.Did, "", "posts_with_replies", false, int64(t.options.RecentPosts))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	posts := make([]*FeedPost, 0, len(feed.Feed))
+	for _, item := range feed.Feed {
+		if item.Reason != nil || item.Post == nil || item.Post.Author == nil || item.Post.Author.Did != t.f.Self.Did {
+			continue
+		}
+		post, err := t.f.OldToNewPostView(item.Post)
+		if err != nil {
+			continue
+		}
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
+
+// allQuotes walks every page of quotes for a post
+func (t *QuoteTracker) allQuotes(ctx context.Context, uri string) ([]*FeedPost, error) {
+	var quotes []*FeedPost
+	cursor := ""
+	for {
+		page, next, err := t.f.GetQuotes(ctx, uri, cursor, 100)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, page...)
+		if next == "" || len(page) == 0 {
+			return quotes, nil
+		}
+		cursor = next
+	}
+}
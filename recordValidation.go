@@ -0,0 +1,547 @@
+package firefly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/atproto/syntax"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+)
+
+var (
+	ErrInvalidRecord = errors.New("record does not match its lexicon")
+)
+
+// knownEmbedTypes are the embed types the AppView renders in a post
+var knownEmbedTypes = map[string]bool{
+	"app.bsky.embed.images":          true,
+	"app.bsky.embed.video":           true,
+	"app.bsky.embed.external":        true,
+	"app.bsky.embed.record":          true,
+	"app.bsky.embed.recordWithMedia": true,
+}
+
+// ValidationIssue is one way a record breaks its lexicon
+type ValidationIssue struct {
+	Path    string `json:"path"`    // where in the record, such as "embed.images[2].aspectRatio"
+	Message string `json:"message"` // what is wrong there
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("ValidationIssue{Path: %s, Message: %s}", i.Path, i.Message)
+}
+
+// WithRecordValidation makes every record write, including PublishDraftPost and writes made through
+// LexClient, check its record with ValidateRecord first. A record with issues is not sent; the write fails with
+// an error wrapping ErrInvalidRecord that lists them.
+//
+// Example:
+//
+//	client, err := firefly.NewDefaultInstance(ctx, firefly.WithRecordValidation())
+func WithRecordValidation() Option {
+	return func(f *Firefly) {
+		f.validateRecords = true
+	}
+}
+
+// ValidateRecord checks a record against the lexicon of collection, returning every issue found, or nil if
+// there are none. It catches what the PDS would otherwise reject with an opaque error, or accept and the
+// AppView silently ignore: text over its length limits, facets pointing outside the text, malformed DIDs,
+// AT-URIs and strong refs, too many images, languages or labels, embeds of a type the AppView doesn't
+// know, and threadgates whose allow list would be left out, letting anyone reply. record may be a generated indigo struct such as *bsky.FeedPost, a *lexutil.LexiconTypeDecoder, or
+// anything that marshals to the record's JSON, such as a map. The rules for the app.bsky records are built in,
+// so records in any other collection are not checked.
+//
+// Example:
+//
+//	post, err := client.DraftToBskyPost(ctx, draft)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, issue := range firefly.ValidateRecord(firefly.CollectionPost, post) {
+//	    log.Printf("%s: %s", issue.Path, issue.Message)
+//	}
+func ValidateRecord(collection string, record any) []ValidationIssue {
+	if decoder, ok := record.(*lexutil.LexiconTypeDecoder); ok {
+		if decoder == nil || decoder.Val == nil {
+			return []ValidationIssue{{Message: "record is empty"}}
+		}
+		record = decoder.Val
+	}
+
+	v := &recordValidator{}
+	if !v.record(record) {
+		return validateRecordJSON(collection, record)
+	}
+	if want, err := lexutil.NewFromType(collection); err == nil && reflect.TypeOf(want) != reflect.TypeOf(record) {
+		v.add("$type", "a %T record does not belong in collection %s", record, collection)
+	}
+	// The generated struct omits an empty allow list from its JSON, which turns "no one can reply" into
+	// "anyone can reply"
+	if gate, ok := record.(*bsky.FeedThreadgate); ok && len(gate.Allow) == 0 && len(gate.HiddenReplies) == 0 {
+		v.add("allow", "empty, so it is left out of the record and anyone can reply; use a ReplyGate to disable replies")
+	}
+	return v.issues
+}
+
+// validateRecordJSON validates a record given in some other form by decoding its JSON into the generated
+// struct for collection. Unknown embed types are caught here, before decoding drops them. Generated structs
+// without rules of their own have nothing to check.
+func validateRecordJSON(collection string, record any) []ValidationIssue {
+	typed, err := lexutil.NewFromType(collection)
+	if err != nil || reflect.TypeOf(typed) == reflect.TypeOf(record) {
+		return nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return []ValidationIssue{{Message: fmt.Sprintf("record can't be encoded: %s", err)}}
+	}
+
+	var shape struct {
+		Type          *string          `json:"$type"`
+		Allow         *json.RawMessage `json:"allow"`
+		HiddenReplies []string         `json:"hiddenReplies"`
+		Embed         *struct {
+			Type  string `json:"$type"`
+			Media *struct {
+				Type string `json:"$type"`
+			} `json:"media"`
+		} `json:"embed"`
+	}
+	if err := json.Unmarshal(data, &shape); err != nil {
+		return []ValidationIssue{{Message: fmt.Sprintf("record is not a JSON object: %s", err)}}
+	}
+	v := &recordValidator{}
+	switch {
+	case shape.Type == nil:
+		v.add("$type", "required")
+	case *shape.Type != collection:
+		v.add("$type", "record type %s does not match collection %s", *shape.Type, collection)
+	}
+	if collection == CollectionPost && shape.Embed != nil {
+		if !knownEmbedTypes[shape.Embed.Type] {
+			v.add("embed.$type", "unknown embed type %q, which the AppView will not show", shape.Embed.Type)
+		}
+		if media := shape.Embed.Media; media != nil && !knownEmbedTypes[media.Type] {
+			v.add("embed.media.$type", "unknown embed type %q, which the AppView will not show", media.Type)
+		}
+	}
+	// A threadgate without allow lets anyone reply, which is rarely what a record with nothing else in it means
+	if collection == CollectionThreadgate && shape.Allow == nil && len(shape.HiddenReplies) == 0 {
+		v.add("allow", "missing, so anyone can reply; an empty list disables replies")
+	}
+	if len(v.issues) > 0 {
+		return v.issues
+	}
+
+	if err := json.Unmarshal(data, typed); err != nil {
+		return []ValidationIssue{{Message: fmt.Sprintf("record doesn't match %s: %s", collection, err)}}
+	}
+	v.record(typed)
+	return v.issues
+}
+
+// validateWrites checks the records in a createRecord, putRecord or applyWrites request body, returning an
+// error wrapping ErrInvalidRecord if any has issues
+func validateWrites(endpoint string, bodyData any) error {
+	type write struct {
+		collection string
+		record     *lexutil.LexiconTypeDecoder
+	}
+	var writes []write
+	switch input := bodyData.(type) {
+	case *atproto.RepoCreateRecord_Input:
+		writes = append(writes, write{input.Collection, input.Record})
+	case *atproto.RepoPutRecord_Input:
+		writes = append(writes, write{input.Collection, input.Record})
+	case *atproto.RepoApplyWrites_Input:
+		for _, w := range input.Writes {
+			switch {
+			case w == nil:
+			case w.RepoApplyWrites_Create != nil:
+				writes = append(writes, write{w.RepoApplyWrites_Create.Collection, w.RepoApplyWrites_Create.Value})
+			case w.RepoApplyWrites_Update != nil:
+				writes = append(writes, write{w.RepoApplyWrites_Update.Collection, w.RepoApplyWrites_Update.Value})
+			}
+		}
+	}
+
+	var problems []string
+	for i, w := range writes {
+		for _, issue := range ValidateRecord(w.collection, w.record) {
+			path := issue.Path
+			if len(writes) > 1 {
+				path = strings.TrimSuffix(fmt.Sprintf("writes[%d].%s", i, path), ".")
+			}
+			if path == "" {
+				problems = append(problems, issue.Message)
+			} else {
+				problems = append(problems, fmt.Sprintf("%s: %s", path, issue.Message))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s %s", ErrInvalidRecord, endpoint, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// recordValidator collects the issues found in one record
+type recordValidator struct {
+	issues []ValidationIssue
+}
+
+// record checks a generated record struct, reporting false if there are no rules for its type
+func (v *recordValidator) record(record any) bool {
+	switch r := record.(type) {
+	case *bsky.FeedPost:
+		v.post(r)
+	case *bsky.FeedLike:
+		v.subject(r.Subject, r.CreatedAt)
+	case *bsky.FeedRepost:
+		v.subject(r.Subject, r.CreatedAt)
+	case *bsky.GraphFollow:
+		v.did("subject", r.Subject)
+		v.datetime("createdAt", r.CreatedAt)
+	case *bsky.GraphBlock:
+		v.did("subject", r.Subject)
+		v.datetime("createdAt", r.CreatedAt)
+	case *bsky.GraphListitem:
+		v.atURI("list", r.List)
+		v.did("subject", r.Subject)
+		v.datetime("createdAt", r.CreatedAt)
+	case *bsky.GraphList:
+		v.list(r)
+	case *bsky.ActorProfile:
+		v.profile(r)
+	case *bsky.FeedThreadgate:
+		v.atURI("post", r.Post)
+		v.datetime("createdAt", r.CreatedAt)
+		v.count("allow", len(r.Allow), 5)
+		v.count("hiddenReplies", len(r.HiddenReplies), 300)
+		for i, uri := range r.HiddenReplies {
+			v.atURI(fmt.Sprintf("hiddenReplies[%d]", i), uri)
+		}
+	case *bsky.FeedPostgate:
+		v.atURI("post", r.Post)
+		v.datetime("createdAt", r.CreatedAt)
+		v.count("detachedEmbeddingUris", len(r.DetachedEmbeddingUris), 50)
+		for i, uri := range r.DetachedEmbeddingUris {
+			v.atURI(fmt.Sprintf("detachedEmbeddingUris[%d]", i), uri)
+		}
+		v.count("embeddingRules", len(r.EmbeddingRules), 5)
+	default:
+		return false
+	}
+	return true
+}
+
+func (v *recordValidator) add(path string, format string, args ...any) {
+	v.issues = append(v.issues, ValidationIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// text checks a string against a lexicon's maxLength (in bytes) and maxGraphemes
+func (v *recordValidator) text(path string, s string, maxBytes int, maxChars int) {
+	if len(s) > maxBytes {
+		v.add(path, "%d bytes is over the limit of %d", len(s), maxBytes)
+	}
+	if chars := graphemeCount(s); chars > maxChars {
+		v.add(path, "%d characters is over the limit of %d", chars, maxChars)
+	}
+}
+
+// graphemeCount counts the user-visible characters in s, as the PDS does for maxGraphemes. It follows the
+// common cases of Unicode's grapheme cluster rules: combining marks, variation selectors, emoji skin tones
+// and tags join the character before them, a zero width joiner joins the characters either side of it, a
+// pair of regional indicators is one flag, and CR LF is one line break.
+func graphemeCount(s string) int {
+	count := 0
+	prev := rune(-1)
+	flagHalf := false // prev is the first regional indicator of a flag
+	for _, r := range s {
+		switch {
+		case prev == -1:
+		case prev == '\r' && r == '\n':
+			prev = r
+			continue
+		case prev == '\u200d' || r == '\u200d' || extendsGrapheme(r):
+			prev = r
+			continue
+		case flagHalf && isRegionalIndicator(r):
+			flagHalf = false
+			prev = r
+			continue
+		}
+		count++
+		flagHalf = isRegionalIndicator(r)
+		prev = r
+	}
+	return count
+}
+
+// extendsGrapheme reports whether r attaches to the character before it
+func extendsGrapheme(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) || // emoji skin tone modifiers
+		(r >= 0xE0020 && r <= 0xE007F) // tags, as in subdivision flags
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// count checks an array against a lexicon's maxLength
+func (v *recordValidator) count(path string, n int, limit int) {
+	if n > limit {
+		v.add(path, "%d items is over the limit of %d", n, limit)
+	}
+}
+
+func (v *recordValidator) datetime(path string, s string) {
+	if s == "" {
+		v.add(path, "required")
+	} else if _, err := syntax.ParseDatetime(s); err != nil {
+		v.add(path, "%s", err)
+	}
+}
+
+func (v *recordValidator) did(path string, s string) {
+	if s == "" {
+		v.add(path, "required")
+	} else if _, err := syntax.ParseDID(s); err != nil {
+		v.add(path, "%s", err)
+	}
+}
+
+func (v *recordValidator) atURI(path string, s string) {
+	if s == "" {
+		v.add(path, "required")
+	} else if _, err := syntax.ParseATURI(s); err != nil {
+		v.add(path, "%s", err)
+	}
+}
+
+func (v *recordValidator) strongRef(path string, ref *atproto.RepoStrongRef) {
+	if ref == nil {
+		v.add(path, "required")
+		return
+	}
+	v.atURI(path+".uri", ref.Uri)
+	if ref.Cid == "" {
+		v.add(path+".cid", "required")
+	} else if _, err := syntax.ParseCID(ref.Cid); err != nil {
+		v.add(path+".cid", "%s", err)
+	}
+}
+
+func (v *recordValidator) blob(path string, blob *lexutil.LexBlob) {
+	if blob == nil {
+		v.add(path, "required")
+		return
+	}
+	if blob.MimeType == "" {
+		v.add(path+".mimeType", "required")
+	}
+	if blob.Size <= 0 {
+		v.add(path+".size", "must be positive")
+	}
+}
+
+func (v *recordValidator) aspectRatio(path string, ratio *bsky.EmbedDefs_AspectRatio) {
+	if ratio != nil && (ratio.Width < 1 || ratio.Height < 1) {
+		v.add(path, "width and height must be at least 1, got %dx%d", ratio.Width, ratio.Height)
+	}
+}
+
+// subject checks the fields shared by likes and reposts
+func (v *recordValidator) subject(subject *atproto.RepoStrongRef, createdAt string) {
+	v.strongRef("subject", subject)
+	v.datetime("createdAt", createdAt)
+}
+
+func (v *recordValidator) post(post *bsky.FeedPost) {
+	v.text("text", post.Text, 3000, 300)
+	v.datetime("createdAt", post.CreatedAt)
+
+	v.count("langs", len(post.Langs), 3)
+	for i, lang := range post.Langs {
+		if _, err := syntax.ParseLanguage(lang); err != nil {
+			v.add(fmt.Sprintf("langs[%d]", i), "%s", err)
+		}
+	}
+	v.count("tags", len(post.Tags), 8)
+	for i, tag := range post.Tags {
+		v.text(fmt.Sprintf("tags[%d]", i), tag, 640, 64)
+	}
+	v.facets("facets", post.Facets, post.Text)
+
+	if post.Reply != nil {
+		v.strongRef("reply.root", post.Reply.Root)
+		v.strongRef("reply.parent", post.Reply.Parent)
+	}
+	if post.Embed != nil {
+		v.embed("embed", post.Embed)
+	}
+	if post.Labels != nil {
+		v.selfLabels("labels", post.Labels.LabelDefs_SelfLabels)
+	}
+}
+
+func (v *recordValidator) facets(path string, facets []*bsky.RichtextFacet, text string) {
+	for i, facet := range facets {
+		at := fmt.Sprintf("%s[%d]", path, i)
+		if facet == nil {
+			v.add(at, "required")
+			continue
+		}
+		if index := facet.Index; index == nil {
+			v.add(at+".index", "required")
+		} else if index.ByteStart < 0 || index.ByteEnd < index.ByteStart || index.ByteEnd > int64(len(text)) {
+			v.add(at+".index", "byte range %d-%d is outside the %d-byte text", index.ByteStart, index.ByteEnd, len(text))
+		}
+		if len(facet.Features) == 0 {
+			v.add(at+".features", "at least one feature is required")
+		}
+		for j, feature := range facet.Features {
+			featureAt := fmt.Sprintf("%s.features[%d]", at, j)
+			switch {
+			case feature == nil:
+				v.add(featureAt, "required")
+			case feature.RichtextFacet_Mention != nil:
+				v.did(featureAt+".did", feature.RichtextFacet_Mention.Did)
+			case feature.RichtextFacet_Link != nil:
+				if _, err := syntax.ParseURI(feature.RichtextFacet_Link.Uri); err != nil {
+					v.add(featureAt+".uri", "%s", err)
+				}
+			case feature.RichtextFacet_Tag != nil:
+				if feature.RichtextFacet_Tag.Tag == "" {
+					v.add(featureAt+".tag", "required")
+				}
+				v.text(featureAt+".tag", feature.RichtextFacet_Tag.Tag, 640, 64)
+			default:
+				v.add(featureAt, "unknown feature type")
+			}
+		}
+	}
+}
+
+func (v *recordValidator) embed(path string, embed *bsky.FeedPost_Embed) {
+	switch {
+	case embed.EmbedImages != nil:
+		v.images(path, embed.EmbedImages)
+	case embed.EmbedVideo != nil:
+		v.video(path, embed.EmbedVideo)
+	case embed.EmbedExternal != nil:
+		v.external(path, embed.EmbedExternal)
+	case embed.EmbedRecord != nil:
+		v.strongRef(path+".record", embed.EmbedRecord.Record)
+	case embed.EmbedRecordWithMedia != nil:
+		withMedia := embed.EmbedRecordWithMedia
+		if withMedia.Record == nil {
+			v.add(path+".record", "required")
+		} else {
+			v.strongRef(path+".record.record", withMedia.Record.Record)
+		}
+		switch media := withMedia.Media; {
+		case media == nil:
+			v.add(path+".media", "required")
+		case media.EmbedImages != nil:
+			v.images(path+".media", media.EmbedImages)
+		case media.EmbedVideo != nil:
+			v.video(path+".media", media.EmbedVideo)
+		case media.EmbedExternal != nil:
+			v.external(path+".media", media.EmbedExternal)
+		default:
+			v.add(path+".media", "unknown or empty media type")
+		}
+	default:
+		v.add(path, "unknown or empty embed type")
+	}
+}
+
+func (v *recordValidator) images(path string, images *bsky.EmbedImages) {
+	if len(images.Images) == 0 {
+		v.add(path+".images", "at least one image is required")
+	}
+	v.count(path+".images", len(images.Images), 4)
+	for i, image := range images.Images {
+		at := fmt.Sprintf("%s.images[%d]", path, i)
+		if image == nil {
+			v.add(at, "required")
+			continue
+		}
+		v.blob(at+".image", image.Image)
+		v.aspectRatio(at+".aspectRatio", image.AspectRatio)
+	}
+}
+
+func (v *recordValidator) video(path string, video *bsky.EmbedVideo) {
+	v.blob(path+".video", video.Video)
+	v.aspectRatio(path+".aspectRatio", video.AspectRatio)
+	if video.Alt != nil {
+		v.text(path+".alt", *video.Alt, 10000, 1000)
+	}
+	v.count(path+".captions", len(video.Captions), 20)
+}
+
+func (v *recordValidator) external(path string, external *bsky.EmbedExternal) {
+	if external.External == nil {
+		v.add(path+".external", "required")
+		return
+	}
+	if _, err := syntax.ParseURI(external.External.Uri); err != nil {
+		v.add(path+".external.uri", "%s", err)
+	}
+}
+
+func (v *recordValidator) selfLabels(path string, labels *atproto.LabelDefs_SelfLabels) {
+	if labels == nil {
+		v.add(path, "unknown or empty label type")
+		return
+	}
+	v.count(path+".values", len(labels.Values), 10)
+	for i, label := range labels.Values {
+		if label != nil {
+			v.text(fmt.Sprintf("%s.values[%d].val", path, i), label.Val, 128, 128)
+		}
+	}
+}
+
+func (v *recordValidator) list(list *bsky.GraphList) {
+	if list.Name == "" {
+		v.add("name", "required")
+	}
+	v.text("name", list.Name, 64, 64)
+	if list.Purpose == nil || *list.Purpose == "" {
+		v.add("purpose", "required")
+	}
+	if list.Description != nil {
+		v.text("description", *list.Description, 3000, 300)
+		v.facets("descriptionFacets", list.DescriptionFacets, *list.Description)
+	}
+	v.datetime("createdAt", list.CreatedAt)
+}
+
+func (v *recordValidator) profile(profile *bsky.ActorProfile) {
+	if profile.DisplayName != nil {
+		v.text("displayName", *profile.DisplayName, 640, 64)
+	}
+	if profile.Description != nil {
+		v.text("description", *profile.Description, 2560, 256)
+	}
+	if profile.CreatedAt != nil {
+		v.datetime("createdAt", *profile.CreatedAt)
+	}
+	if profile.PinnedPost != nil {
+		v.strongRef("pinnedPost", profile.PinnedPost)
+	}
+	if profile.Labels != nil {
+		v.selfLabels("labels", profile.Labels.LabelDefs_SelfLabels)
+	}
+}
@@ -0,0 +1,134 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+)
+
+// Viewer predicates read the viewer state the server attaches to profiles and posts fetched with a session.
+// They report false when there is none, such as for accounts and posts from the firehose or fetched while
+// logged out; use RelationshipWith or GetProfile with ProfileWithViewer when the answer must be known.
+
+// IsFollowedByMe reports whether the authenticated user follows the account
+func (u *User) IsFollowedByMe() bool {
+	return u.Viewer != nil && u.Viewer.Following != ""
+}
+
+// FollowsMe reports whether the account follows the authenticated user
+func (u *User) FollowsMe() bool {
+	return u.Viewer != nil && u.Viewer.FollowedBy != ""
+}
+
+// IsMutual reports whether the account and the authenticated user follow each other
+func (u *User) IsMutual() bool {
+	return u.IsFollowedByMe() && u.FollowsMe()
+}
+
+// IsBlocked reports whether the authenticated user blocks the account, directly or through a moderation list
+func (u *User) IsBlocked() bool {
+	return u.Viewer != nil && (u.Viewer.Blocking != "" || u.Viewer.BlockingByList != "")
+}
+
+// BlocksMe reports whether the account blocks the authenticated user
+func (u *User) BlocksMe() bool {
+	return u.Viewer != nil && u.Viewer.BlockedBy
+}
+
+// IsMuted reports whether the authenticated user mutes the account, directly or through a moderation list
+func (u *User) IsMuted() bool {
+	return u.Viewer != nil && (u.Viewer.Muted || u.Viewer.MutedByList != "")
+}
+
+// LikedByMe reports whether the authenticated user has liked the post
+func (p *FeedPost) LikedByMe() bool {
+	return p.Viewer != nil && p.Viewer.Like != ""
+}
+
+// RepostedByMe reports whether the authenticated user has reposted the post
+func (p *FeedPost) RepostedByMe() bool {
+	return p.Viewer != nil && p.Viewer.Repost != ""
+}
+
+// IsThreadMutedByMe reports whether the authenticated user has muted the post's thread
+func (p *FeedPost) IsThreadMutedByMe() bool {
+	return p.Viewer != nil && p.Viewer.ThreadMuted
+}
+
+// IsPinnedByMe reports whether the post is pinned to the authenticated user's profile
+func (p *FeedPost) IsPinnedByMe() bool {
+	return p.Viewer != nil && p.Viewer.Pinned
+}
+
+// CanReply reports whether the post's thread gate lets the authenticated user reply. It reports true when
+// there is no viewer state, as most posts can be replied to.
+func (p *FeedPost) CanReply() bool {
+	return p.Viewer == nil || !p.Viewer.ReplyDisabled
+}
+
+// CanQuote reports whether the post's post gate lets the authenticated user quote it. It reports true when
+// there is no viewer state, as most posts can be quoted.
+func (p *FeedPost) CanQuote() bool {
+	return p.Viewer == nil || !p.Viewer.EmbeddingDisabled
+}
+
+// Relationship summarizes how the authenticated user and another account are connected
+type Relationship struct {
+	Actor      *User `json:"actor"`      // the account's current profile
+	IsSelf     bool  `json:"isSelf"`     // the account is the authenticated user
+	Following  bool  `json:"following"`  // Self follows the account
+	FollowedBy bool  `json:"followedBy"` // the account follows Self
+	Blocking   bool  `json:"blocking"`   // Self blocks the account, directly or through a list
+	BlockedBy  bool  `json:"blockedBy"`  // the account blocks Self
+	Muted      bool  `json:"muted"`      // Self mutes the account, directly or through a list
+}
+
+func (r Relationship) String() string {
+	return fmt.Sprintf("Relationship{Actor: %s, Following: %t, FollowedBy: %t, Blocking: %t, BlockedBy: %t, Muted: %t}",
+		r.Actor.Handle, r.Following, r.FollowedBy, r.Blocking, r.BlockedBy, r.Muted)
+}
+
+// Mutual reports whether the account and Self follow each other
+func (r Relationship) Mutual() bool {
+	return r.Following && r.FollowedBy
+}
+
+// Blocked reports whether a block in either direction stops Self and the account from interacting
+func (r Relationship) Blocked() bool {
+	return r.Blocking || r.BlockedBy
+}
+
+// RelationshipWith fetches the authenticated user's current relationship with actor, bypassing the profile
+// cache so follows and blocks made moments ago are reflected. actor may be a DID, a handle, or any other form
+// ParseActor accepts.
+//
+// Example:
+//
+//	rel, err := client.RelationshipWith(ctx, "alice.bsky.social")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if rel.FollowedBy && !rel.Following && !rel.Blocked() {
+//	    _, err = client.Follow(ctx, rel.Actor.Did)
+//	}
+func (f *Firefly) RelationshipWith(ctx context.Context, actor string) (*Relationship, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	did, err := f.resolveActor(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := f.GetProfile(ctx, did, ProfileBypassCache(), ProfileWithViewer())
+	if err != nil {
+		return nil, err
+	}
+	return &Relationship{
+		Actor:      profile,
+		IsSelf:     profile.Did == f.Self.Did,
+		Following:  profile.IsFollowedByMe(),
+		FollowedBy: profile.FollowsMe(),
+		Blocking:   profile.IsBlocked(),
+		BlockedBy:  profile.BlocksMe(),
+		Muted:      profile.IsMuted(),
+	}, nil
+}
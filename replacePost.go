@@ -0,0 +1,158 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/atproto/syntax"
+)
+
+var (
+	ErrNotOwnPost = errors.New("post does not belong to the authenticated user")
+)
+
+// DeletePost deletes one of the authenticated user's posts. If ref has a CID, the post is only deleted if it
+// still matches that version.
+func (f *Firefly) DeletePost(ctx context.Context, ref *PostRef) error {
+	if f.Self == nil {
+		return ErrNotLoggedIn
+	}
+	uri, err := f.ownPostURI(ref)
+	if err != nil {
+		return err
+	}
+	input := &atproto.RepoDeleteRecord_Input{
+		Collection: uri.Collection().String(),
+		Repo:       f.Self.Did,
+		Rkey:       uri.RecordKey().String(),
+	}
+	if ref.CID != "" {
+		input.SwapRecord = &ref.CID
+	}
+	if _, err := atproto.RepoDeleteRecord(ctx, f.api, input); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedDelete, err)
+	}
+	return nil
+}
+
+// ownPostURI parses ref's URI and checks that it is a post in the authenticated user's repo
+func (f *Firefly) ownPostURI(ref *PostRef) (syntax.ATURI, error) {
+	if ref == nil || ref.URI == "" {
+		return "", ErrEmptyUri
+	}
+	uri, err := syntax.ParseATURI(ref.URI)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidUri, err)
+	}
+	if uri.Collection() != CollectionPost || uri.RecordKey() == "" {
+		return "", fmt.Errorf("%w: not a post", ErrInvalidUri)
+	}
+	if !f.isSelfURI(ref.URI) {
+		return "", ErrNotOwnPost
+	}
+	return uri, nil
+}
+
+// ReplaceOptions holds the settings for ReplacePost; set them with ReplaceOption functions
+type ReplaceOptions struct {
+	Correction bool // Publish the new post as a reply to the original and keep the original
+}
+
+// ReplaceOption configures a ReplacePost call
+type ReplaceOption func(*ReplaceOptions)
+
+// ReplaceAsCorrection publishes the new post as a reply to the original instead of deleting it, so the
+// original's likes, reposts, quotes and replies stay attached to a post that still exists
+func ReplaceAsCorrection() ReplaceOption {
+	return func(o *ReplaceOptions) { o.Correction = true }
+}
+
+// ReplacedPost is the result of ReplacePost
+type ReplacedPost struct {
+	Original    *PostRef `json:"original"`
+	Replacement *PostRef `json:"replacement"`
+	Deleted     bool     `json:"deleted"` // whether the original was deleted
+}
+
+func (r ReplacedPost) String() string {
+	return fmt.Sprintf("ReplacedPost{Original: %s, Replacement: %s, Deleted: %t}", r.Original.URI, r.Replacement.URI, r.Deleted)
+}
+
+// ReplacePost emulates editing a post. atproto has no edits: a post's URI and CID name its exact content, and
+// every like, repost, quote and reply points at them. ReplacePost therefore publishes newDraft as a new post
+// and then deletes the original, so the replacement starts with no engagement and existing quotes and replies
+// point at a deleted post. If the original was a reply and newDraft has no ReplyInfo, the replacement is
+// posted into the same thread.
+//
+// With ReplaceAsCorrection, newDraft is instead published as a reply to the original and the original is
+// kept, which leaves all existing references intact.
+//
+// The replacement is published before anything is deleted. If the deletion fails, the result is returned
+// with Deleted false together with an error wrapping ErrFailedDelete, and both posts exist.
+//
+// Example:
+//
+//	fixed := firefly.NewDraftPost().AddText("Meetup is on Thursday, not Tuesday")
+//	result, err := client.ReplacePost(ctx, typoRef, fixed)
+func (f *Firefly) ReplacePost(ctx context.Context, old *PostRef, newDraft *DraftPost, options ...ReplaceOption) (*ReplacedPost, error) {
+	if newDraft == nil {
+		return nil, ErrNilPost
+	}
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	var opts ReplaceOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	uri, err := f.ownPostURI(old)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := atproto.RepoGetRecord(ctx, f.api, "", uri.Collection().String(), f.Self.Did, uri.RecordKey().String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	original := &PostRef{URI: record.Uri, CID: old.CID}
+	if record.Cid != nil {
+		original.CID = *record.Cid
+	}
+
+	var post *bsky.FeedPost
+	if record.Value != nil {
+		post, _ = record.Value.Val.(*bsky.FeedPost)
+	}
+
+	// Work on a copy so the caller's draft isn't rethreaded
+	draft := *newDraft
+	if opts.Correction {
+		root := original
+		if post != nil && post.Reply != nil && post.Reply.Root != nil {
+			root = OldToNewRefPointer(post.Reply.Root)
+		}
+		draft.ReplyInfo = &ReplyInfo{ReplyTarget: original, ReplyRoot: root}
+	} else if draft.ReplyInfo == nil && post != nil && post.Reply != nil {
+		draft.ReplyInfo = &ReplyInfo{
+			ReplyTarget: OldToNewRefPointer(post.Reply.Parent),
+			ReplyRoot:   OldToNewRefPointer(post.Reply.Root),
+		}
+	}
+
+	replacement, err := f.PublishDraftPost(ctx, &draft)
+	if err != nil {
+		return nil, err
+	}
+	result := &ReplacedPost{Original: original, Replacement: replacement}
+	if opts.Correction {
+		return result, nil
+	}
+	if err := f.DeletePost(ctx, original); err != nil {
+		return result, err
+	}
+	result.Deleted = true
+	return result, nil
+}
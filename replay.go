@@ -0,0 +1,97 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidReplayWindow = errors.New("invalid replay window")
+)
+
+// replayIdleTimeout is how long a replay whose end time has passed waits for another event before closing.
+// Narrow filters may never produce an event past the end of the window.
+const replayIdleTimeout = 5 * time.Second
+
+// ReplayEvents streams the events Jetstream recorded between from and to, then closes the channel. It is meant
+// for backfilling after downtime: the cursor is computed from from, nothing is dropped when the consumer falls
+// behind, and a reconnect resumes after the last delivered event. Jetstream only keeps a limited history
+// (about a day on the public instances), so older windows start at the oldest event available.
+// A to in the future streams live events until that time. Pass nil for options to use the defaults;
+// the options' Cursor is ignored.
+//
+// Example:
+//
+//	// Catch up on the last three hours
+//	events, err := client.ReplayEvents(ctx, time.Now().Add(-3*time.Hour), time.Now(), &firefly.FirehoseOptions{
+//	    Collections: []string{"app.bsky.feed.post"},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for event := range events {
+//	    handle(event)
+//	}
+func (f *Firefly) ReplayEvents(ctx context.Context, from, to time.Time, options *FirehoseOptions) (chan *FirehoseEvent, error) {
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidReplayWindow)
+	}
+	if f.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	// Work on a copy so the advancing cursor doesn't leak into the caller's options
+	opts := FirehoseOptions{}
+	if options != nil {
+		opts = *options
+	}
+	cursor := from.UnixMicro()
+	opts.Cursor = &cursor
+	opts.replay = true
+
+	ctx, cancel := f.bindLifetime(ctx)
+	inner, err := f.StreamEvents(ctx, &opts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	events := make(chan *FirehoseEvent, opts.BufferSize)
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		defer cancel()
+		defer close(events)
+
+		idle := time.NewTicker(time.Second)
+		defer idle.Stop()
+		lastReceived := time.Now()
+		for {
+			select {
+			case event, ok := <-inner:
+				if !ok {
+					return
+				}
+				lastReceived = time.Now()
+				if event.Timestamp.After(to) {
+					return
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			case now := <-idle.C:
+				if now.After(to) && now.Sub(lastReceived) > replayIdleTimeout {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
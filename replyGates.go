@@ -0,0 +1,159 @@
+package firefly
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/atproto/syntax"
+	"github.com/bluesky-social/indigo/util"
+	cbg "github.com/whyrusleeping/cbor-gen"
+)
+
+// recordKeyClock generates monotonic record keys for records that must share an rkey, like a post and its threadgate
+var recordKeyClock = syntax.NewTIDClock(0)
+
+// ReplyGate restricts who may reply to a post. Each enabled rule adds to the set of allowed repliers;
+// a ReplyGate with no rules enabled disables replies entirely. A nil ReplyGate allows everyone to reply.
+type ReplyGate struct {
+	Followers bool     `json:"followers,omitempty"` // Accounts following the author
+	Following bool     `json:"following,omitempty"` // Accounts the author follows
+	Mentioned bool     `json:"mentioned,omitempty"` // Accounts mentioned in the post
+	Lists     []string `json:"lists,omitempty"`     // Members of these list URIs
+}
+
+// replyGate returns the draft's reply gate, creating an empty one if needed
+func (d *DraftPost) replyGate() *ReplyGate {
+	if d.ReplyGate == nil {
+		d.ReplyGate = &ReplyGate{}
+	}
+	return d.ReplyGate
+}
+
+// RepliesFollowersOnly allows accounts that follow the author to reply (chainable).
+// Can be combined with the other reply presets; each one adds to the allowed repliers.
+func (d *DraftPost) RepliesFollowersOnly() *DraftPost {
+	d.replyGate().Followers = true
+	return d
+}
+
+// RepliesFollowingOnly allows accounts the author follows to reply (chainable).
+// Can be combined with the other reply presets; each one adds to the allowed repliers.
+func (d *DraftPost) RepliesFollowingOnly() *DraftPost {
+	d.replyGate().Following = true
+	return d
+}
+
+// RepliesMentionedOnly allows accounts mentioned in the post to reply (chainable).
+// Can be combined with the other reply presets; each one adds to the allowed repliers.
+func (d *DraftPost) RepliesMentionedOnly() *DraftPost {
+	d.replyGate().Mentioned = true
+	return d
+}
+
+// RepliesFromList allows members of the given list to reply (chainable).
+// Can be combined with the other reply presets; each one adds to the allowed repliers.
+func (d *DraftPost) RepliesFromList(listURI string) *DraftPost {
+	gate := d.replyGate()
+	gate.Lists = append(gate.Lists, listURI)
+	return d
+}
+
+// RepliesDisabled prevents anyone from replying, replacing any previously set reply presets (chainable)
+func (d *DraftPost) RepliesDisabled() *DraftPost {
+	d.ReplyGate = &ReplyGate{}
+	return d
+}
+
+// RepliesFromEveryone removes any reply restrictions (chainable)
+func (d *DraftPost) RepliesFromEveryone() *DraftPost {
+	d.ReplyGate = nil
+	return d
+}
+
+// threadgateRecord is an app.bsky.feed.threadgate record that always writes its allow list. The generated
+// bsky.FeedThreadgate omits an empty list, and a threadgate without one lets everyone reply, so a gate that
+// disables replies would do the opposite.
+type threadgateRecord struct {
+	LexiconTypeID string                            `json:"$type,const=app.bsky.feed.threadgate" cborgen:"$type,const=app.bsky.feed.threadgate"`
+	Allow         []*bsky.FeedThreadgate_Allow_Elem `json:"allow"`
+	CreatedAt     string                            `json:"createdAt"`
+	Post          string                            `json:"post"`
+}
+
+// MarshalCBOR writes the record as DAG-CBOR, keeping an empty allow list
+func (r *threadgateRecord) MarshalCBOR(w io.Writer) error {
+	var e cborEncoder
+	e.header(cbg.MajMap, 4)
+	e.text("post")
+	e.text(r.Post)
+	e.text("$type")
+	e.text(CollectionThreadgate)
+	e.text("allow")
+	e.header(cbg.MajArray, uint64(len(r.Allow)))
+	buf := bytes.NewBuffer(e.out)
+	for _, rule := range r.Allow {
+		if err := rule.MarshalCBOR(buf); err != nil {
+			return err
+		}
+	}
+	e.out = buf.Bytes()
+	e.text("createdAt")
+	e.text(r.CreatedAt)
+	_, err := w.Write(e.out)
+	return err
+}
+
+// toThreadgate builds the threadgate record for the post at postURI
+func (g *ReplyGate) toThreadgate(postURI string) *threadgateRecord {
+	allow := make([]*bsky.FeedThreadgate_Allow_Elem, 0)
+	if g.Mentioned {
+		allow = append(allow, &bsky.FeedThreadgate_Allow_Elem{
+			FeedThreadgate_MentionRule: &bsky.FeedThreadgate_MentionRule{
+				LexiconTypeID: "app.bsky.feed.threadgate#mentionRule",
+			},
+		})
+	}
+	if g.Followers {
+		allow = append(allow, &bsky.FeedThreadgate_Allow_Elem{
+			FeedThreadgate_FollowerRule: &bsky.FeedThreadgate_FollowerRule{
+				LexiconTypeID: "app.bsky.feed.threadgate#followerRule",
+			},
+		})
+	}
+	if g.Following {
+		allow = append(allow, &bsky.FeedThreadgate_Allow_Elem{
+			FeedThreadgate_FollowingRule: &bsky.FeedThreadgate_FollowingRule{
+				LexiconTypeID: "app.bsky.feed.threadgate#followingRule",
+			},
+		})
+	}
+	for _, list := range g.Lists {
+		allow = append(allow, &bsky.FeedThreadgate_Allow_Elem{
+			FeedThreadgate_ListRule: &bsky.FeedThreadgate_ListRule{
+				LexiconTypeID: "app.bsky.feed.threadgate#listRule",
+				List:          list,
+			},
+		})
+	}
+
+	return &threadgateRecord{
+		LexiconTypeID: CollectionThreadgate,
+		Allow:         allow,
+		CreatedAt:     time.Now().Format(util.ISO8601),
+		Post:          postURI,
+	}
+}
+
+// validate checks that list rules point at list records
+func (g *ReplyGate) validate() error {
+	for _, list := range g.Lists {
+		aturi, err := syntax.ParseATURI(list)
+		if err != nil || aturi.Collection() != CollectionList {
+			return fmt.Errorf("%w: not a list URI: %s", ErrInvalidUri, list)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package firefly
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+	cbor "github.com/ipfs/go-ipld-cbor"
+)
+
+const gatedPostURI = "at://did:plc:x/app.bsky.feed.post/3k"
+
+func TestRepliesDisabledWritesEmptyAllow(t *testing.T) {
+	draft := NewDraftPost().AddText("No replies").RepliesDisabled()
+	record := &lexutil.LexiconTypeDecoder{Val: draft.ReplyGate.toThreadgate(gatedPostURI)}
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if string(fields["allow"]) != "[]" {
+		t.Errorf("allow = %s, want [] in %s", fields["allow"], data)
+	}
+	if string(fields["$type"]) != `"app.bsky.feed.threadgate"` {
+		t.Errorf("$type = %s, want app.bsky.feed.threadgate", fields["$type"])
+	}
+}
+
+func TestThreadgateCBORKeepsAllow(t *testing.T) {
+	tests := []struct {
+		name string
+		gate *ReplyGate
+		want int
+	}{
+		{"disabled", &ReplyGate{}, 0},
+		{"followers and list", &ReplyGate{Followers: true, Lists: []string{"at://did:plc:x/app.bsky.graph.list/1"}}, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := test.gate.toThreadgate(gatedPostURI).MarshalCBOR(&buf); err != nil {
+				t.Fatal(err)
+			}
+			// The generated decoder reads an empty list as nil, so check the map itself
+			var fields map[string]any
+			if err := cbor.DecodeInto(buf.Bytes(), &fields); err != nil {
+				t.Fatal(err)
+			}
+			allow, ok := fields["allow"].([]any)
+			if !ok || len(allow) != test.want {
+				t.Errorf("allow = %#v, want %d rules", fields["allow"], test.want)
+			}
+			var decoded bsky.FeedThreadgate
+			if err := decoded.UnmarshalCBOR(bytes.NewReader(buf.Bytes())); err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Post != gatedPostURI || len(decoded.Allow) != test.want {
+				t.Errorf("decoded = %+v, want post %q with %d rules", decoded, gatedPostURI, test.want)
+			}
+		})
+	}
+}
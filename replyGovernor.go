@@ -0,0 +1,230 @@
+package firefly
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrReplyLimited = errors.New("reply limited by governor")
+)
+
+// ReplyGovernorOptions configures a ReplyGovernor. Limits left at zero use the default; set them negative to
+// turn them off.
+type ReplyGovernorOptions struct {
+	AuthorCooldown time.Duration // Minimum time between replies to the same author (default 1 minute)
+	AuthorLimit    int           // Most replies to the same author per AuthorWindow (default 5)
+	AuthorWindow   time.Duration // Period AuthorLimit applies to (default 1 hour)
+	GlobalLimit    int           // Most replies to anyone per GlobalWindow (default 60)
+	GlobalWindow   time.Duration // Period GlobalLimit applies to (default 1 hour)
+
+	// MaxThreadDepth is how far below its thread's root a post can be and still get a reply, counting direct
+	// replies to the root as depth 1 (default 20). It stops two bots from replying to each other forever.
+	MaxThreadDepth int
+
+	// QuietStart and QuietEnd are the daily quiet hours, as times of day in Location, during which no replies
+	// are sent. QuietEnd before QuietStart spans midnight. Quiet hours are off when they are equal.
+	QuietStart time.Duration
+	QuietEnd   time.Duration
+	Location   *time.Location // Time zone of the quiet hours (default time.Local)
+}
+
+// ReplyGovernor keeps a bot's replies within per-author and overall rate limits, out of quiet hours, and out
+// of runaway threads, so a bug or another bot can't make it behave like spam. Ask it before replying, or set
+// it as NotificationRouterOptions.Governor to gate mention and reply handlers. It is safe for concurrent use.
+type ReplyGovernor struct {
+	options ReplyGovernorOptions
+
+	mu      sync.Mutex
+	authors map[string][]time.Time // reply times per author DID, oldest first
+	global  []time.Time            // all reply times, oldest first
+}
+
+// NewReplyGovernor creates a ReplyGovernor. Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	governor := firefly.NewReplyGovernor(&firefly.ReplyGovernorOptions{
+//	    GlobalLimit: 30,
+//	    QuietStart:  23 * time.Hour,
+//	    QuietEnd:    7 * time.Hour,
+//	})
+//	for event := range events {
+//	    if err := governor.Allow(event.Post, nil); err != nil {
+//	        log.Println("not replying:", err)
+//	        continue
+//	    }
+//	    reply(event)
+//	}
+func NewReplyGovernor(options *ReplyGovernorOptions) *ReplyGovernor {
+	if options == nil {
+		options = &ReplyGovernorOptions{}
+	}
+	opts := *options
+	if opts.AuthorCooldown == 0 {
+		opts.AuthorCooldown = time.Minute
+	}
+	if opts.AuthorLimit == 0 {
+		opts.AuthorLimit = 5
+	}
+	if opts.AuthorWindow <= 0 {
+		opts.AuthorWindow = time.Hour
+	}
+	if opts.GlobalLimit == 0 {
+		opts.GlobalLimit = 60
+	}
+	if opts.GlobalWindow <= 0 {
+		opts.GlobalWindow = time.Hour
+	}
+	if opts.MaxThreadDepth == 0 {
+		opts.MaxThreadDepth = 20
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	return &ReplyGovernor{options: opts, authors: make(map[string][]time.Time)}
+}
+
+// Check reports whether a reply to post is allowed now, without counting one. The error wraps
+// ErrReplyLimited and says which limit was hit. Pass the post's thread, such as from GetPostThread, to check
+// its depth; without one, any reply is taken to be at depth 1.
+func (g *ReplyGovernor) Check(post *FeedPost, thread *Thread) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.checkLocked(post, thread, time.Now())
+}
+
+// Record counts a reply sent to post, for replies the governor wasn't asked about first
+func (g *ReplyGovernor) Record(post *FeedPost) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.recordLocked(post, time.Now())
+}
+
+// Allow checks whether a reply to post is allowed and, if it is, counts it, in one step so concurrent
+// handlers can't both take the last slot
+func (g *ReplyGovernor) Allow(post *FeedPost, thread *Thread) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	if err := g.checkLocked(post, thread, now); err != nil {
+		return err
+	}
+	g.recordLocked(post, now)
+	return nil
+}
+
+// checkLocked is Check at a given time. Callers must hold g.mu.
+func (g *ReplyGovernor) checkLocked(post *FeedPost, thread *Thread, now time.Time) error {
+	if post == nil {
+		return fmt.Errorf("%w: no post", ErrReplyLimited)
+	}
+	if g.quiet(now) {
+		return fmt.Errorf("%w: quiet hours", ErrReplyLimited)
+	}
+	if limit := g.options.MaxThreadDepth; limit > 0 {
+		if depth := threadDepth(post, thread); depth > limit {
+			return fmt.Errorf("%w: thread depth %d is over %d", ErrReplyLimited, depth, limit)
+		}
+	}
+
+	g.pruneLocked(now)
+	if limit := g.options.GlobalLimit; limit > 0 && len(g.global) >= limit {
+		return fmt.Errorf("%w: %d replies in the last %s", ErrReplyLimited, len(g.global), g.options.GlobalWindow)
+	}
+	author := postAuthor(post)
+	if author == "" {
+		return nil
+	}
+	replies := g.authors[author]
+	if len(replies) == 0 {
+		return nil
+	}
+	if cooldown := g.options.AuthorCooldown; cooldown > 0 && now.Sub(replies[len(replies)-1]) < cooldown {
+		return fmt.Errorf("%w: replied to %s less than %s ago", ErrReplyLimited, author, cooldown)
+	}
+	if limit := g.options.AuthorLimit; limit > 0 {
+		recent := 0
+		for _, at := range replies {
+			if now.Sub(at) < g.options.AuthorWindow {
+				recent++
+			}
+		}
+		if recent >= limit {
+			return fmt.Errorf("%w: %d replies to %s in the last %s", ErrReplyLimited, recent, author, g.options.AuthorWindow)
+		}
+	}
+	return nil
+}
+
+// recordLocked counts a reply at a given time. Callers must hold g.mu.
+func (g *ReplyGovernor) recordLocked(post *FeedPost, now time.Time) {
+	g.pruneLocked(now)
+	g.global = append(g.global, now)
+	if author := postAuthor(post); author != "" {
+		g.authors[author] = append(g.authors[author], now)
+	}
+}
+
+// pruneLocked forgets replies too old for any limit. Callers must hold g.mu.
+func (g *ReplyGovernor) pruneLocked(now time.Time) {
+	cutoff := now.Add(-g.options.GlobalWindow)
+	g.global = slices.DeleteFunc(g.global, func(at time.Time) bool { return !at.After(cutoff) })
+
+	cutoff = now.Add(-max(g.options.AuthorWindow, g.options.AuthorCooldown))
+	for author, replies := range g.authors {
+		replies = slices.DeleteFunc(replies, func(at time.Time) bool { return !at.After(cutoff) })
+		if len(replies) == 0 {
+			delete(g.authors, author)
+		} else {
+			g.authors[author] = replies
+		}
+	}
+}
+
+// quiet reports whether t falls inside the quiet hours
+func (g *ReplyGovernor) quiet(t time.Time) bool {
+	start, end := g.options.QuietStart, g.options.QuietEnd
+	if start == end {
+		return false
+	}
+	t = t.In(g.options.Location)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	clock := t.Sub(midnight)
+	if start < end {
+		return clock >= start && clock < end
+	}
+	return clock >= start || clock < end
+}
+
+// parentHeight returns how many ancestors must be loaded to check a post's thread depth
+func (g *ReplyGovernor) parentHeight() int {
+	return max(g.options.MaxThreadDepth+1, 0)
+}
+
+// threadDepth returns how far below its thread's root post is, counting the ancestors in thread. When they
+// don't reach the root, the result is a lower bound.
+func threadDepth(post *FeedPost, thread *Thread) int {
+	depth := 0
+	top := post
+	for node := thread; node != nil && node.Parent != nil; node = node.Parent {
+		depth++
+		top = node.Parent.Post
+	}
+	// Ancestors that are missing, blocked or weren't loaded still count as one more level
+	if top == nil || top.ReplyInfo != nil {
+		depth++
+	}
+	return depth
+}
+
+// postAuthor returns the DID of a post's author, or "" if it isn't known
+func postAuthor(post *FeedPost) string {
+	if post.Author == nil {
+		return ""
+	}
+	return post.Author.Did
+}
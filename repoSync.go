@@ -0,0 +1,195 @@
+package firefly
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+)
+
+// RepoCommit identifies the latest revision of a repository
+type RepoCommit struct {
+	CID string `json:"cid" cborgen:"cid"` // hash of the commit object
+	Rev string `json:"rev" cborgen:"rev"` // revision TID, increases with every commit
+}
+
+func (c RepoCommit) String() string {
+	return fmt.Sprintf("RepoCommit{Rev: %s, CID: %s}", c.Rev, c.CID)
+}
+
+// GetLatestCommit returns the current commit CID and revision of a repository using com.atproto.sync.getLatestCommit.
+// Backup tools can compare it with the revision they captured to check that they are up to date.
+//
+// Note: sync endpoints are served by the PDS that hosts the repository, so the client may need to point at
+// that PDS rather than an entryway.
+func (f *Firefly) GetLatestCommit(ctx context.Context, did string) (*RepoCommit, error) {
+	output, err := atproto.SyncGetLatestCommit(ctx, f.api, did)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	return &RepoCommit{
+		CID: output.Cid,
+		Rev: output.Rev,
+	}, nil
+}
+
+// ListBlobs returns one page of blob CIDs stored in a repository using com.atproto.sync.listBlobs, along with
+// the cursor for the next page. If since is non-empty, only blobs added after that revision are listed.
+// The returned cursor is empty when there are no more pages.
+func (f *Firefly) ListBlobs(ctx context.Context, did string, since string, cursor string, limit int) ([]string, string, error) {
+	output, err := atproto.SyncListBlobs(ctx, f.api, cursor, did, int64(limit), since)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	nextCursor := ""
+	if output.Cursor != nil {
+		nextCursor = *output.Cursor
+	}
+	return output.Cids, nextCursor, nil
+}
+
+// ListAllBlobs walks every page of ListBlobs and returns all blob CIDs in the repository
+func (f *Firefly) ListAllBlobs(ctx context.Context, did string, since string) ([]string, error) {
+	var cids []string
+	cursor := ""
+	for {
+		page, next, err := f.ListBlobs(ctx, did, since, cursor, 1000)
+		if err != nil {
+			return nil, err
+		}
+		cids = append(cids, page...)
+		if next == "" || len(page) == 0 {
+			return cids, nil
+		}
+		cursor = next
+	}
+}
+
+// GetRecordProof fetches a single record together with the signed commit and the merkle tree nodes that
+// prove its inclusion, using com.atproto.sync.getRecord. The result is a CAR file as raw bytes.
+func (f *Firefly) GetRecordProof(ctx context.Context, did string, collection string, rkey string) ([]byte, error) {
+	car, err := atproto.SyncGetRecord(ctx, f.api, collection, did, rkey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	return car, nil
+}
+
+// GetRepo downloads an account's entire repository as a CAR file using com.atproto.sync.getRepo.
+// Use ReadRepoCAR to list the records it contains.
+func (f *Firefly) GetRepo(ctx context.Context, did string) ([]byte, error) {
+	car, err := atproto.SyncGetRepo(ctx, f.api, did, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	return car, nil
+}
+
+// RepoRecord is a single record read from a repository CAR file
+type RepoRecord struct {
+	Collection string `json:"collection"`
+	RKey       string `json:"rkey"`
+	CID        string `json:"cid"`
+	Data       []byte `json:"data"` // DAG-CBOR encoded record
+}
+
+// URI returns the record's AT URI within the given repo
+func (r *RepoRecord) URI(did string) string {
+	return fmt.Sprintf("at://%s/%s/%s", did, r.Collection, r.RKey)
+}
+
+// ReadRepoCAR lists the records in a repository CAR file, such as one returned by GetRepo, sorted by
+// collection and record key. Record paths are recovered from the repository's merkle search tree nodes,
+// so blocks that aren't reachable as records (commits, tree nodes, orphans) are skipped.
+func ReadRepoCAR(r io.Reader) ([]*RepoRecord, error) {
+	blocks, err := readCARBlocks(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadResponse, err)
+	}
+
+	// Each tree node holds entries whose keys are prefix-compressed against the previous entry in that node
+	paths := make(map[string]string)
+	for _, data := range blocks {
+		var node map[string]any
+		if err := cbor.DecodeInto(data, &node); err != nil {
+			continue
+		}
+		entries, ok := node["e"].([]any)
+		if !ok {
+			continue
+		}
+		var key []byte
+		for _, item := range entries {
+			entry, ok := item.(map[string]any)
+			if !ok {
+				break
+			}
+			prefixLen, _ := entry["p"].(int)
+			suffix, _ := entry["k"].([]byte)
+			value, ok := entry["v"].(cid.Cid)
+			if !ok || prefixLen > len(key) {
+				break
+			}
+			key = append(key[:prefixLen:prefixLen], suffix...)
+			paths[value.String()] = string(key)
+		}
+	}
+
+	var records []*RepoRecord
+	for id, path := range paths {
+		data, ok := blocks[id]
+		if !ok {
+			continue
+		}
+		collection, rkey, found := strings.Cut(path, "/")
+		if !found {
+			continue
+		}
+		records = append(records, &RepoRecord{Collection: collection, RKey: rkey, CID: id, Data: data})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Collection != records[j].Collection {
+			return records[i].Collection < records[j].Collection
+		}
+		return records[i].RKey < records[j].RKey
+	})
+	return records, nil
+}
+
+// readCARBlocks reads every block of a CARv1 file, keyed by CID string
+func readCARBlocks(r *bufio.Reader) (map[string][]byte, error) {
+	headerLength, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading CAR header: %w", err)
+	}
+	if _, err := io.CopyN(io.Discard, r, int64(headerLength)); err != nil {
+		return nil, fmt.Errorf("reading CAR header: %w", err)
+	}
+
+	blocks := make(map[string][]byte)
+	for {
+		length, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			return blocks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CAR block: %w", err)
+		}
+		section := make([]byte, length)
+		if _, err := io.ReadFull(r, section); err != nil {
+			return nil, fmt.Errorf("reading CAR block: %w", err)
+		}
+		n, id, err := cid.CidFromBytes(section)
+		if err != nil {
+			return nil, fmt.Errorf("reading CAR block CID: %w", err)
+		}
+		blocks[id.String()] = section[n:]
+	}
+}
@@ -0,0 +1,663 @@
+// Package rsspost publishes the entries of an RSS or Atom feed to Bluesky, the classic news and blog bot.
+// A Poster polls the feed on a schedule, skips entries it has already posted (tracked by GUID in a pluggable
+// Store), renders new ones through a PostTemplate with a link card, and publishes them oldest first.
+//
+// Example:
+//
+//	store, err := rsspost.NewFileStore("seen.txt")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	template, err := rsspost.NewPostTemplate("New on the blog: {{.Title}}")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	poster := rsspost.New(client, "https://blog.example.com/feed.xml", &rsspost.Options{
+//	    Store:    store,
+//	    Template: template,
+//	})
+//	log.Fatal(poster.Run(ctx))
+package rsspost
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TheAlyxGreen/firefly"
+)
+
+var (
+	ErrNotAFeed    = errors.New("not an RSS or Atom feed")
+	ErrFailedFetch = errors.New("failed to fetch feed")
+)
+
+// Entry is one item of an RSS feed or entry of an Atom feed
+type Entry struct {
+	GUID      string    `json:"guid"` // The entry's guid or id, or its link if it has neither
+	Title     string    `json:"title"`
+	Link      string    `json:"link"`
+	Summary   string    `json:"summary"`             // Description or summary as plain text, with markup removed
+	Image     string    `json:"image,omitempty"`     // URL of an image enclosure or thumbnail, if any
+	Published time.Time `json:"published,omitempty"` // Zero if the feed doesn't date its entries
+	FeedTitle string    `json:"feedTitle"`
+}
+
+func (e Entry) String() string {
+	return fmt.Sprintf("Entry{GUID: %s, Title: %s}", e.GUID, e.Title)
+}
+
+// feedDocument covers RSS 2.0, RSS 1.0 (RDF) and Atom. Element names are matched without their namespaces, so
+// one struct reads all three.
+type feedDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Title string      `xml:"title"`
+		Items []feedEntry `xml:"item"`
+	} `xml:"channel"`
+	Title   string      `xml:"title"`
+	Items   []feedEntry `xml:"item"`  // RSS 1.0 items sit beside the channel
+	Entries []feedEntry `xml:"entry"` // Atom
+}
+
+type feedEntry struct {
+	Title       string `xml:"title"`
+	GUID        string `xml:"guid"`
+	ID          string `xml:"id"`
+	Description string `xml:"description"`
+	Summary     string `xml:"summary"`
+	Content     string `xml:"content"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"date"` // Dublin Core, used by RSS 1.0
+	Published   string `xml:"published"`
+	Updated     string `xml:"updated"`
+	Links       []struct {
+		Href  string `xml:"href,attr"`
+		Rel   string `xml:"rel,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"link"`
+	Enclosures []struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+	Thumbnails []struct {
+		URL string `xml:"url,attr"`
+	} `xml:"thumbnail"`
+}
+
+// Parse reads an RSS 2.0, RSS 1.0 or Atom document and returns its entries in document order
+func Parse(r io.Reader) ([]*Entry, error) {
+	var doc feedDocument
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotAFeed, err)
+	}
+
+	var raw []feedEntry
+	title := ""
+	switch doc.XMLName.Local {
+	case "rss":
+		raw, title = doc.Channel.Items, doc.Channel.Title
+	case "RDF":
+		raw, title = doc.Items, doc.Channel.Title
+	case "feed":
+		raw, title = doc.Entries, doc.Title
+	default:
+		return nil, fmt.Errorf("%w: root element <%s>", ErrNotAFeed, doc.XMLName.Local)
+	}
+
+	entries := make([]*Entry, 0, len(raw))
+	for _, item := range raw {
+		entry := &Entry{
+			Title:     strings.TrimSpace(plainText(item.Title)),
+			Link:      entryLink(&item),
+			Summary:   plainText(firstNonEmpty(item.Description, item.Summary, item.Content)),
+			FeedTitle: strings.TrimSpace(title),
+		}
+		entry.GUID = firstNonEmpty(strings.TrimSpace(item.GUID), strings.TrimSpace(item.ID), entry.Link)
+		if entry.GUID == "" {
+			entry.GUID = entry.Title + " " + firstNonEmpty(item.PubDate, item.Published, item.Updated, item.Date)
+		}
+		for _, value := range []string{item.PubDate, item.Published, item.Date, item.Updated} {
+			if published, ok := parseDate(value); ok {
+				entry.Published = published
+				break
+			}
+		}
+		for _, enclosure := range item.Enclosures {
+			if strings.HasPrefix(enclosure.Type, "image/") {
+				entry.Image = enclosure.URL
+				break
+			}
+		}
+		if entry.Image == "" && len(item.Thumbnails) > 0 {
+			entry.Image = item.Thumbnails[0].URL
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// entryLink returns an RSS item's link, or an Atom entry's alternate link
+func entryLink(item *feedEntry) string {
+	for _, link := range item.Links {
+		if value := strings.TrimSpace(link.Value); value != "" {
+			return value
+		}
+		if link.Href != "" && (link.Rel == "" || link.Rel == "alternate") {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// dateLayouts are the date formats seen in the wild, RFC 822 variants for RSS and RFC 3339 for Atom
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+var (
+	markupPattern     = regexp.MustCompile(`<[^>]*>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// plainText removes markup from a feed's text and collapses its whitespace
+func plainText(text string) string {
+	text = html.UnescapeString(markupPattern.ReplaceAllString(text, " "))
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Store remembers the GUIDs of entries that have been posted. Implementations must be safe for concurrent
+// use.
+type Store interface {
+	Seen(ctx context.Context, guid string) (bool, error)
+	MarkSeen(ctx context.Context, guid string) error
+}
+
+// MemoryStore is a Store that keeps GUIDs in memory, so a restarted Poster starts over from the feed's
+// current state
+type MemoryStore struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{seen: make(map[string]struct{})}
+}
+
+func (s *MemoryStore) Seen(ctx context.Context, guid string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.seen[guid]
+	return ok, nil
+}
+
+func (s *MemoryStore) MarkSeen(ctx context.Context, guid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen[guid] = struct{}{}
+	return nil
+}
+
+// FileStore is a Store that keeps GUIDs in a text file, one per line, so they survive restarts
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	seen map[string]struct{}
+}
+
+// NewFileStore opens the store at path, creating the file if it doesn't exist
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, seen: make(map[string]struct{})}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if guid := scanner.Text(); guid != "" {
+			s.seen[guid] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileStore) Seen(ctx context.Context, guid string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.seen[guid]
+	return ok, nil
+}
+
+func (s *FileStore) MarkSeen(ctx context.Context, guid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.seen[guid]; ok {
+		return nil
+	}
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	// GUIDs are single tokens in practice; line breaks would split one into two entries
+	line := strings.NewReplacer("\r", " ", "\n", " ").Replace(guid) + "\n"
+	if _, err := file.WriteString(line); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	s.seen[guid] = struct{}{}
+	return nil
+}
+
+// PostTemplate turns feed entries into posts. The text is a text/template executed with the Entry, with a
+// truncate function for cutting fields to a number of characters. URLs in the text become links, and by
+// default the entry's link is attached as a link card.
+type PostTemplate struct {
+	NoLinkCard bool     // Don't attach the entry's link as a link card
+	Languages  []string // Languages of the posts, if known
+	Labels     []firefly.SelfLabel
+
+	text *template.Template
+}
+
+// DefaultTemplate posts the entry's title with a link card
+const DefaultTemplate = "{{.Title}}"
+
+// NewPostTemplate parses a post template.
+//
+// Example:
+//
+//	template, err := rsspost.NewPostTemplate("{{.Title}}\n\n{{truncate 200 .Summary}}")
+func NewPostTemplate(text string) (*PostTemplate, error) {
+	parsed, err := template.New("post").Funcs(template.FuncMap{"truncate": truncate}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &PostTemplate{text: parsed}, nil
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]+[^\s<>".,;:!?)\]'"]`)
+
+// Render builds the post for an entry. If the text is over the post length limit, the entry's summary and
+// then its title are shortened to fit; an entry that still doesn't fit returns firefly.ErrPostTooLong.
+func (t *PostTemplate) Render(entry *Entry) (*firefly.DraftPost, error) {
+	fitted := *entry
+	text, err := t.execute(&fitted)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range []*string{&fitted.Summary, &fitted.Title} {
+		over := utf8.RuneCountInString(text) - 300
+		if over <= 0 {
+			break
+		}
+		*field = truncate(max(utf8.RuneCountInString(*field)-over, 0), *field)
+		if text, err = t.execute(&fitted); err != nil {
+			return nil, err
+		}
+	}
+	if utf8.RuneCountInString(text) > 300 {
+		return nil, fmt.Errorf("%w: %s", firefly.ErrPostTooLong, entry.GUID)
+	}
+
+	draft := firefly.NewDraftPost()
+	at := 0
+	for _, match := range urlPattern.FindAllStringIndex(text, -1) {
+		if match[0] > at {
+			draft.AddText(text[at:match[0]])
+		}
+		draft.AddLink(text[match[0]:match[1]], text[match[0]:match[1]])
+		at = match[1]
+	}
+	if at < len(text) {
+		draft.AddText(text[at:])
+	}
+	if len(t.Languages) > 0 {
+		draft.SetLanguages(t.Languages...)
+	}
+	if len(t.Labels) > 0 {
+		draft.SetLabels(t.Labels...)
+	}
+	if !t.NoLinkCard && entry.Link != "" {
+		draft.SetEmbed(firefly.NewEmbedBuilder().SetExternal(entry.Link, entry.Title, truncate(300, entry.Summary), nil))
+	}
+	return draft, nil
+}
+
+func (t *PostTemplate) execute(entry *Entry) (string, error) {
+	var b strings.Builder
+	if err := t.text.Execute(&b, entry); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", entry.GUID, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// truncate cuts text to at most n characters, ending it with an ellipsis if anything was cut
+func truncate(n int, text string) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	if n <= 1 {
+		return ""
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
+// Options configures a Poster
+type Options struct {
+	Store        Store         // Where posted GUIDs are kept (default a new MemoryStore)
+	Template     *PostTemplate // How entries become posts (default DefaultTemplate)
+	PollInterval time.Duration // Time between polls of the feed with Run (default 15 minutes)
+	PostInterval time.Duration // Time between posts within a poll (default 10 seconds)
+	MaxPerPoll   int           // Most entries posted per poll; the rest wait for the next (default 5)
+	HTTPClient   *http.Client  // Client for fetching the feed and link card images (default http.DefaultClient)
+
+	// Since, if set, skips entries published before it, marking them seen without posting them. By default a
+	// Poster's first poll posts nothing if the store has seen none of the feed's entries, so a new bot doesn't
+	// repost the feed's history, and otherwise catches up on the entries published while it was stopped.
+	Since time.Time
+
+	// OnError, if set, is called with every error from Run's polls
+	OnError func(err error)
+}
+
+// Poster publishes a feed's new entries
+type Poster struct {
+	f       *firefly.Firefly
+	feedURL string
+	options Options
+
+	mu      sync.Mutex
+	polled  bool
+	unsaved map[string]bool // GUIDs that were handled but that the store failed to mark seen
+}
+
+// New creates a Poster that publishes feedURL's entries with f. Pass nil for options to use the defaults.
+func New(f *firefly.Firefly, feedURL string, options *Options) *Poster {
+	if options == nil {
+		options = &Options{}
+	}
+	opts := *options
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore()
+	}
+	if opts.Template == nil {
+		opts.Template, _ = NewPostTemplate(DefaultTemplate)
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 15 * time.Minute
+	}
+	if opts.PostInterval <= 0 {
+		opts.PostInterval = 10 * time.Second
+	}
+	if opts.MaxPerPoll <= 0 {
+		opts.MaxPerPoll = 5
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &Poster{f: f, feedURL: feedURL, options: opts}
+}
+
+// Fetch downloads and parses the feed
+func (p *Poster) Fetch(ctx context.Context) ([]*Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	resp, err := p.options.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrFailedFetch, resp.Status)
+	}
+	return Parse(io.LimitReader(resp.Body, 10<<20))
+}
+
+// Poll fetches the feed and publishes up to MaxPerPoll new entries, oldest first, returning the posts
+// created. Entries are marked seen once they are published. An entry that can never be posted, such as one
+// too long to fit or with an image over the size limit, is marked seen and skipped, and its error returned
+// with the others once the poll finishes. Any other failed publish stops the poll, and that entry is tried
+// again next time. If the store can't mark a published entry seen, the Poster remembers it in memory so it
+// isn't posted twice, and tries to save it again on the next poll.
+func (p *Poster) Poll(ctx context.Context) ([]*firefly.PostRef, error) {
+	entries, err := p.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	first := !p.polled
+	p.polled = true
+	unsaved := make([]string, 0, len(p.unsaved))
+	for guid := range p.unsaved {
+		unsaved = append(unsaved, guid)
+	}
+	p.mu.Unlock()
+
+	var errs []error
+	for _, guid := range unsaved {
+		if err := p.markSeen(ctx, guid); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Feeds list entries newest first; sort by date when every entry has one
+	slices.Reverse(entries)
+	if !slices.ContainsFunc(entries, func(entry *Entry) bool { return entry.Published.IsZero() }) {
+		slices.SortStableFunc(entries, func(a, b *Entry) int { return a.Published.Compare(b.Published) })
+	}
+
+	seen := make(map[string]bool, len(entries))
+	known := false
+	for _, entry := range entries {
+		if seen[entry.GUID], err = p.seen(ctx, entry.GUID); err != nil {
+			return nil, errors.Join(append(errs, err)...)
+		}
+		known = known || seen[entry.GUID]
+	}
+	// A store that knows none of the entries belongs to a new bot, which starts from the feed's current state
+	baseline := first && p.options.Since.IsZero() && !known
+
+	var posted []*firefly.PostRef
+	for _, entry := range entries {
+		if seen[entry.GUID] {
+			continue
+		}
+		if baseline || (!entry.Published.IsZero() && entry.Published.Before(p.options.Since)) {
+			if err := p.markSeen(ctx, entry.GUID); err != nil {
+				errs = append(errs, err)
+			}
+			seen[entry.GUID] = true
+			continue
+		}
+		if len(posted) == p.options.MaxPerPoll {
+			break
+		}
+
+		if len(posted) > 0 {
+			select {
+			case <-ctx.Done():
+				return posted, errors.Join(append(errs, ctx.Err())...)
+			case <-time.After(p.options.PostInterval):
+			}
+		}
+		ref, err := p.publish(ctx, entry)
+		if err != nil && (ctx.Err() != nil || !permanent(err)) {
+			return posted, errors.Join(append(errs, err)...)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			posted = append(posted, ref)
+		}
+		if err := p.markSeen(ctx, entry.GUID); err != nil {
+			errs = append(errs, err)
+		}
+		seen[entry.GUID] = true
+	}
+	return posted, errors.Join(errs...)
+}
+
+// permanent reports whether publishing an entry failed in a way that trying again won't fix
+func permanent(err error) bool {
+	var execErr template.ExecError
+	return errors.As(err, &execErr) ||
+		errors.Is(err, firefly.ErrPostTooLong) ||
+		errors.Is(err, firefly.ErrInvalidMention) ||
+		errors.Is(err, firefly.ErrInvalidLink) ||
+		errors.Is(err, firefly.ErrInvalidPost) ||
+		errors.Is(err, firefly.ErrMediaTooLarge) ||
+		errors.Is(err, firefly.ErrUnsupportedImage) ||
+		errors.Is(err, firefly.ErrDuplicatePost)
+}
+
+// seen reports whether an entry was handled, counting ones the store failed to save
+func (p *Poster) seen(ctx context.Context, guid string) (bool, error) {
+	p.mu.Lock()
+	unsaved := p.unsaved[guid]
+	p.mu.Unlock()
+	if unsaved {
+		return true, nil
+	}
+	return p.options.Store.Seen(ctx, guid)
+}
+
+// markSeen saves an entry as handled, even if ctx was cancelled after it was posted. If the store fails, the
+// GUID is kept in memory until a later poll saves it.
+func (p *Poster) markSeen(ctx context.Context, guid string) error {
+	err := p.options.Store.MarkSeen(context.WithoutCancel(ctx), guid)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		if p.unsaved == nil {
+			p.unsaved = make(map[string]bool)
+		}
+		p.unsaved[guid] = true
+		return err
+	}
+	delete(p.unsaved, guid)
+	return nil
+}
+
+// publish renders and posts one entry, with the entry's image as the link card thumbnail when it can be
+// downloaded
+func (p *Poster) publish(ctx context.Context, entry *Entry) (*firefly.PostRef, error) {
+	draft, err := p.options.Template.Render(entry)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Embed != nil && entry.Image != "" {
+		if thumb := p.download(ctx, entry.Image); thumb != nil {
+			draft.Embed.SetExternal(entry.Link, entry.Title, truncate(300, entry.Summary), thumb)
+		}
+	}
+	ref, err := p.f.PublishDraftPost(ctx, draft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post %s: %w", entry.GUID, err)
+	}
+	return ref, nil
+}
+
+// download fetches a link card image, shrinking it with firefly.PrepareImage if it's over
+// firefly.MaxImageBytes, and gives up quietly on any failure
+func (p *Poster) download(ctx context.Context, url string) []byte {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := p.options.HTTPClient.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes+1))
+	if err != nil || len(data) > maxDownloadBytes || !bytes.HasPrefix([]byte(http.DetectContentType(data)), []byte("image/")) {
+		return nil
+	}
+	if len(data) > firefly.MaxImageBytes {
+		if data, err = firefly.PrepareImage(data, &firefly.ImageOptions{Resize: true}); err != nil {
+			return nil
+		}
+	}
+	return data
+}
+
+// Link card images larger than this aren't downloaded, even to be shrunk
+const maxDownloadBytes = 20 << 20
+
+// Run polls the feed immediately and then every PollInterval until ctx is cancelled, which is the only
+// error it returns. Poll errors are passed to OnError and don't stop it.
+func (p *Poster) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.Poll(ctx); err != nil && ctx.Err() == nil && p.options.OnError != nil {
+			p.options.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -25,11 +25,11 @@ const (
 
 // PostSearch holds all search filter options for post searching
 type PostSearch struct {
-	Author   string     // Filter by author handle or DID
+	Author   string     // Filter by author handle or DID (any form ParseActor accepts)
 	Cursor   string     // Pagination cursor
 	Domain   string     // Filter by domain
 	Language string     // Filter by language code (e.g., "en", "es")
-	Mentions string     // Filter posts mentioning a specific user
+	Mentions string     // Filter posts mentioning a specific user (any form ParseActor accepts)
 	SortBy   SortOrder  // Sort order ("top" or "latest")
 	URL      string     // Filter posts containing a specific URL
 	Tags     []string   // Filter by hashtags
@@ -40,6 +40,12 @@ type PostSearch struct {
 // SearchPosts searches for posts with optional filters.
 // Pass nil for options to search without filters.
 func (f *Firefly) SearchPosts(ctx context.Context, query string, limit int, options *PostSearch) ([]*FeedPost, error) {
+	posts, _, err := f.searchPage(ctx, query, limit, options)
+	return posts, err
+}
+
+// searchPage runs a post search and also returns the cursor for the next page
+func (f *Firefly) searchPage(ctx context.Context, query string, limit int, options *PostSearch) ([]*FeedPost, string, error) {
 	if options == nil {
 		options = &PostSearch{}
 	}
@@ -54,26 +60,225 @@ func (f *Firefly) SearchPosts(ctx context.Context, query string, limit int, opti
 	if options.Until != nil {
 		toTime = options.Until.Format(time.RFC3339)
 	}
+	author, mentions := options.Author, options.Mentions
+	if author != "" {
+		author = normalizeActor(author)
+	}
+	if mentions != "" {
+		mentions = normalizeActor(mentions)
+	}
 	results, err := bsky.FeedSearchPosts(
-		ctx, f.client, options.Author, options.Cursor,
+		ctx, f.api, author, options.Cursor,
 		options.Domain, options.Language, int64(limit),
-		options.Mentions, query, fromTime, string(options.SortBy),
+		mentions, query, fromTime, string(options.SortBy),
 		options.Tags, toTime, options.URL)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrSearchFailed, err)
+		return nil, "", fmt.Errorf("%w: %w", ErrSearchFailed, err)
 	}
 	if results == nil {
-		return nil, fmt.Errorf("%w: %w", ErrSearchFailed, errors.New("nil results returned"))
+		return nil, "", fmt.Errorf("%w: %w", ErrSearchFailed, errors.New("nil results returned"))
 	}
 	posts = make([]*FeedPost, len(results.Posts))
 	for i, postView := range results.Posts {
 		newPost, err := f.OldToNewPostView(postView)
 		if err != nil {
-			return nil, fmt.Errorf("%w: %w", ErrSearchFailed, err)
+			return nil, "", fmt.Errorf("%w: %w", ErrSearchFailed, err)
 		} else {
 			posts[i] = newPost
 		}
 	}
 
+	cursor := ""
+	if results.Cursor != nil {
+		cursor = *results.Cursor
+	}
+	return posts, cursor, nil
+}
+
+// maxStreamSearchPages caps how many pages a single StreamSearch poll walks back when catching up on a burst
+const maxStreamSearchPages = 5
+
+// StreamSearchOptions holds the settings for StreamSearch; set them with StreamSearchOption functions
+type StreamSearchOptions struct {
+	Filters    *PostSearch // Extra search filters; Cursor and SortBy are managed by the stream
+	Limit      int         // Results fetched per request (default 25, max 100)
+	BufferSize int         // Channel buffer size (default 100)
+	Backfill   bool        // Emit the posts found by the first poll instead of treating them as already seen
+}
+
+// StreamSearchOption configures a StreamSearch call
+type StreamSearchOption func(*StreamSearchOptions)
+
+// StreamSearchFilters narrows the stream with the usual post search filters
+func StreamSearchFilters(filters *PostSearch) StreamSearchOption {
+	return func(o *StreamSearchOptions) { o.Filters = filters }
+}
+
+// StreamSearchLimit sets how many results are fetched per request
+func StreamSearchLimit(limit int) StreamSearchOption {
+	return func(o *StreamSearchOptions) { o.Limit = limit }
+}
+
+// StreamSearchBufferSize sets the channel's buffer size
+func StreamSearchBufferSize(size int) StreamSearchOption {
+	return func(o *StreamSearchOptions) { o.BufferSize = size }
+}
+
+// StreamSearchBackfill emits the current results on the first poll rather than only posts that appear later
+func StreamSearchBackfill() StreamSearchOption {
+	return func(o *StreamSearchOptions) { o.Backfill = true }
+}
+
+// StreamSearch polls SearchPosts sorted by latest every interval and sends posts that haven't been seen before,
+// oldest first, on the returned channel. It is a lightweight alternative to the firehose for monitoring a narrow
+// query. The channel is closed when ctx is cancelled or the client is closed; failed polls are sent to Events.
+//
+// Example:
+//
+//	posts, err := client.StreamSearch(ctx, "golang", time.Minute,
+//	    firefly.StreamSearchFilters(&firefly.PostSearch{Language: "en"}),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for post := range posts {
+//	    fmt.Printf("%s: %s\n", post.Author.Handle, post.Text)
+//	}
+func (f *Firefly) StreamSearch(ctx context.Context, query string, interval time.Duration, options ...StreamSearchOption) (chan *FeedPost, error) {
+	if f.isClosed() {
+		return nil, ErrClientClosed
+	}
+	opts := StreamSearchOptions{Limit: 25, BufferSize: 100}
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.Limit <= 0 || opts.Limit > 100 {
+		opts.Limit = 25
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	filters := PostSearch{}
+	if opts.Filters != nil {
+		filters = *opts.Filters
+	}
+	filters.SortBy = SortByLatest
+	filters.Cursor = ""
+
+	stream := &searchStream{f: f, query: query, filters: filters, limit: opts.Limit, baseline: !opts.Backfill}
+	posts := make(chan *FeedPost, opts.BufferSize)
+	ctx, cancel := f.bindLifetime(ctx)
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		defer cancel()
+		defer close(posts)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			found, err := stream.poll(ctx)
+			if err != nil && ctx.Err() == nil {
+				f.emit(SourceScheduler, SeverityError, err)
+			}
+			for _, post := range found {
+				select {
+				case posts <- post:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
 	return posts, nil
 }
+
+// searchStream tracks the newest post StreamSearch has seen. Posts sharing the newest timestamp are remembered
+// by URI so they aren't repeated.
+type searchStream struct {
+	f        *Firefly
+	query    string
+	filters  PostSearch
+	limit    int
+	baseline bool // the next poll only records what is already there
+
+	newest   time.Time
+	atNewest map[string]struct{}
+}
+
+// poll fetches results newer than the watermark, walking back a few pages if a burst filled the first one
+func (s *searchStream) poll(ctx context.Context) ([]*FeedPost, error) {
+	var fresh []*FeedPost
+	filters := s.filters
+	for page := 0; page < maxStreamSearchPages; page++ {
+		results, cursor, err := s.f.searchPage(ctx, s.query, s.limit, &filters)
+		if err != nil {
+			return nil, err
+		}
+		reachedSeen := false
+		for _, post := range results {
+			if s.seen(post) {
+				reachedSeen = true
+				continue
+			}
+			fresh = append(fresh, post)
+		}
+		if reachedSeen || cursor == "" || len(results) < s.limit || s.baseline {
+			break
+		}
+		filters.Cursor = cursor
+	}
+
+	// Results arrive newest first; deliver them oldest first
+	for i, j := 0, len(fresh)-1; i < j; i, j = i+1, j-1 {
+		fresh[i], fresh[j] = fresh[j], fresh[i]
+	}
+	for _, post := range fresh {
+		s.record(post)
+	}
+	if s.baseline {
+		s.baseline = false
+		return nil, nil
+	}
+	return fresh, nil
+}
+
+func (s *searchStream) seen(post *FeedPost) bool {
+	at := searchTimestamp(post)
+	if at.Before(s.newest) {
+		return true
+	}
+	_, ok := s.atNewest[post.URI]
+	return at.Equal(s.newest) && ok
+}
+
+func (s *searchStream) record(post *FeedPost) {
+	at := searchTimestamp(post)
+	if at.After(s.newest) {
+		s.newest = at
+		s.atNewest = make(map[string]struct{})
+	}
+	if at.Equal(s.newest) {
+		s.atNewest[post.URI] = struct{}{}
+	}
+}
+
+// searchTimestamp is the time a post sorts by in latest-first search results
+func searchTimestamp(post *FeedPost) time.Time {
+	if post.IndexedAt != nil {
+		return *post.IndexedAt
+	}
+	if post.CreatedAt != nil {
+		return *post.CreatedAt
+	}
+	return time.Time{}
+}
@@ -0,0 +1,148 @@
+package firefly
+
+import (
+	"strings"
+	"time"
+)
+
+// SearchQuery builds a post search query string from Bluesky's search operators. It covers operators that
+// PostSearch has no field for, like to:, exact phrases and negation, and can be combined with PostSearch
+// filters in the same search. Methods return the query so calls can be chained.
+//
+// Example:
+//
+//	query := firefly.NewSearchQuery().
+//	    Phrase("release notes").
+//	    From("alice.bsky.social").
+//	    Language("en").
+//	    Since(time.Now().AddDate(0, 0, -7)).
+//	    Exclude("beta")
+//	posts, err := client.SearchPosts(ctx, query.String(), 25, nil)
+type SearchQuery struct {
+	terms []string
+}
+
+// NewSearchQuery creates an empty search query
+func NewSearchQuery() *SearchQuery {
+	return &SearchQuery{}
+}
+
+// Words adds words that must appear in the post, in any order
+func (q *SearchQuery) Words(words ...string) *SearchQuery {
+	for _, word := range words {
+		q.terms = append(q.terms, strings.Fields(word)...)
+	}
+	return q
+}
+
+// Phrase adds an exact phrase that must appear in the post
+func (q *SearchQuery) Phrase(phrase string) *SearchQuery {
+	if quoted := quotePhrase(phrase); quoted != "" {
+		q.terms = append(q.terms, quoted)
+	}
+	return q
+}
+
+// Exclude drops posts containing word
+func (q *SearchQuery) Exclude(word string) *SearchQuery {
+	for _, field := range strings.Fields(word) {
+		q.terms = append(q.terms, "-"+field)
+	}
+	return q
+}
+
+// ExcludePhrase drops posts containing the exact phrase
+func (q *SearchQuery) ExcludePhrase(phrase string) *SearchQuery {
+	if quoted := quotePhrase(phrase); quoted != "" {
+		q.terms = append(q.terms, "-"+quoted)
+	}
+	return q
+}
+
+// Hashtag only matches posts tagged with tag (with or without the leading #)
+func (q *SearchQuery) Hashtag(tag string) *SearchQuery {
+	return q.operator("#", strings.TrimPrefix(tag, "#"))
+}
+
+// From only matches posts written by actor, a handle, DID or profile link. Use "me" for the authenticated user.
+func (q *SearchQuery) From(actor string) *SearchQuery {
+	return q.operator("from:", normalizeActor(actor))
+}
+
+// ExcludeFrom drops posts written by actor
+func (q *SearchQuery) ExcludeFrom(actor string) *SearchQuery {
+	return q.operator("-from:", normalizeActor(actor))
+}
+
+// To only matches replies to actor
+func (q *SearchQuery) To(actor string) *SearchQuery {
+	return q.operator("to:", normalizeActor(actor))
+}
+
+// Mentions only matches posts that mention actor
+func (q *SearchQuery) Mentions(actor string) *SearchQuery {
+	return q.operator("mentions:", normalizeActor(actor))
+}
+
+// Domain only matches posts linking to domain
+func (q *SearchQuery) Domain(domain string) *SearchQuery {
+	return q.operator("domain:", domain)
+}
+
+// Language only matches posts in the given language code, e.g. "en"
+func (q *SearchQuery) Language(language string) *SearchQuery {
+	return q.operator("lang:", language)
+}
+
+// Since only matches posts created at or after t. Midnight UTC is written as a plain date.
+func (q *SearchQuery) Since(t time.Time) *SearchQuery {
+	return q.operator("since:", searchTime(t))
+}
+
+// Until only matches posts created before t. Midnight UTC is written as a plain date.
+func (q *SearchQuery) Until(t time.Time) *SearchQuery {
+	return q.operator("until:", searchTime(t))
+}
+
+// Raw adds a term exactly as given, for operators the builder doesn't know about
+func (q *SearchQuery) Raw(term string) *SearchQuery {
+	if term = strings.TrimSpace(term); term != "" {
+		q.terms = append(q.terms, term)
+	}
+	return q
+}
+
+// String returns the query string to pass to SearchPosts
+func (q *SearchQuery) String() string {
+	return strings.Join(q.terms, " ")
+}
+
+// operator adds prefix+value, skipping empty values. Spaces would end the operator early, so they are removed.
+func (q *SearchQuery) operator(prefix string, value string) *SearchQuery {
+	value = strings.Join(strings.Fields(value), "")
+	if value != "" {
+		q.terms = append(q.terms, prefix+value)
+	}
+	return q
+}
+
+// quotePhrase wraps phrase in double quotes. The search syntax has no escapes, so quotes inside are dropped.
+func quotePhrase(phrase string) string {
+	phrase = strings.Join(strings.Fields(strings.ReplaceAll(phrase, `"`, "")), " ")
+	if phrase == "" {
+		return ""
+	}
+	return `"` + phrase + `"`
+}
+
+// searchTime formats t for since: and until:
+func searchTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	t = t.UTC()
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,67 @@
+package firefly
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidLabel  = errors.New("invalid self-label")
+	ErrTooManyLabels = errors.New("too many self-labels")
+)
+
+// Limits from com.atproto.label.defs
+const (
+	maxSelfLabels     = 10
+	maxSelfLabelBytes = 128
+)
+
+// SelfLabel is a content label an author applies to their own post
+type SelfLabel string
+
+const (
+	LabelPorn         SelfLabel = "porn"          // Sexually explicit content
+	LabelSexual       SelfLabel = "sexual"        // Sexually suggestive content
+	LabelNudity       SelfLabel = "nudity"        // Non-sexual nudity, e.g. artistic
+	LabelGraphicMedia SelfLabel = "graphic-media" // Violence, gore or other disturbing media
+
+	// LabelNoUnauthenticated asks apps not to show the content to logged-out viewers
+	LabelNoUnauthenticated SelfLabel = "!no-unauthenticated"
+)
+
+// knownSelfLabels are the labels Bluesky's app understands
+var knownSelfLabels = map[SelfLabel]bool{
+	LabelPorn:              true,
+	LabelSexual:            true,
+	LabelNudity:            true,
+	LabelGraphicMedia:      true,
+	LabelNoUnauthenticated: true,
+}
+
+// IsKnown reports whether the label is one of the predefined constants
+func (l SelfLabel) IsKnown() bool {
+	return knownSelfLabels[l]
+}
+
+func (l SelfLabel) String() string {
+	return string(l)
+}
+
+// validateSelfLabels checks the draft's labels. Predefined labels must be one of the constants;
+// custom labels only need to be well-formed.
+func validateSelfLabels(labels []SelfLabel, custom []string) error {
+	if len(labels)+len(custom) > maxSelfLabels {
+		return fmt.Errorf("%w: %d given, maximum is %d", ErrTooManyLabels, len(labels)+len(custom), maxSelfLabels)
+	}
+	for _, label := range labels {
+		if !label.IsKnown() {
+			return fmt.Errorf("%w: %q is not a predefined label; use AddCustomLabel for labeler-defined values", ErrInvalidLabel, label)
+		}
+	}
+	for _, label := range custom {
+		if label == "" || len(label) > maxSelfLabelBytes {
+			return fmt.Errorf("%w: custom label %q must be 1-%d bytes", ErrInvalidLabel, label, maxSelfLabelBytes)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+)
+
+var (
+	ErrServiceAuth = errors.New("failed to get service auth token")
+)
+
+// GetServiceAuthToken asks the PDS to sign a short-lived service-auth JWT for calling another atproto service
+// directly, such as the video service, a feed generator, or a labeler. audienceDID is the service's DID, and
+// lxm is the XRPC method the token may be used for (e.g. "app.bsky.video.getUploadLimits"), or empty for a
+// token not bound to one method. The server decides the lifetime, usually a minute, so request a fresh token
+// for each call.
+//
+// Example:
+//
+//	token, err := client.GetServiceAuthToken(ctx, "did:web:video.bsky.app", "app.bsky.video.getUploadLimits")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	req.Header.Set("Authorization", "Bearer "+token)
+func (f *Firefly) GetServiceAuthToken(ctx context.Context, audienceDID string, lxm string) (string, error) {
+	return f.GetServiceAuthTokenUntil(ctx, audienceDID, lxm, time.Time{})
+}
+
+// GetServiceAuthTokenUntil is GetServiceAuthToken with an explicit expiry, for tokens that must outlive a
+// single request like a long upload. The PDS caps how far ahead expires may be; a zero time uses its default.
+func (f *Firefly) GetServiceAuthTokenUntil(ctx context.Context, audienceDID string, lxm string, expires time.Time) (string, error) {
+	if f.Self == nil {
+		return "", ErrNotLoggedIn
+	}
+	var exp int64
+	if !expires.IsZero() {
+		exp = expires.Unix()
+	}
+	out, err := atproto.ServerGetServiceAuth(ctx, f.api, audienceDID, exp, lxm)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrServiceAuth, err)
+	}
+	return out.Token, nil
+}
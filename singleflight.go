@@ -0,0 +1,85 @@
+package firefly
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/lex/util"
+)
+
+// readFlights collapses concurrent identical reads of the hottest endpoints into one request each. Hydrating
+// firehose events tends to look up the same accounts and posts many times at once.
+type readFlights struct {
+	profiles flightGroup[*bsky.ActorDefs_ProfileViewDetailed]
+	handles  flightGroup[*atproto.IdentityResolveHandle_Output]
+	posts    flightGroup[*bsky.FeedGetPosts_Output]
+	didDocs  flightGroup[*DIDDocument]
+}
+
+// flightGroup runs at most one call per key at a time; callers that arrive while a call is running share its
+// result. The zero value is ready to use.
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+// flightCall is a call in progress; done is closed once val and err are set
+type flightCall[T any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	val     T
+	err     error
+}
+
+// do returns the result of fn, or of the call already running for key. fn gets a context that outlives any
+// single caller, since others may be waiting on it. A caller whose ctx ends stops waiting without affecting
+// the others; when the last one gives up, the call is cancelled. Reads sent to a WithHost server never share
+// a call with reads sent elsewhere.
+func (g *flightGroup[T]) do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	if host, ok := hostOverride(ctx, util.Query); ok {
+		key = host + " " + key
+	}
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall[T])
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		shared, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall[T]{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+		go func() {
+			defer cancel()
+			val, err := fn(shared)
+			g.mu.Lock()
+			call.val, call.err = val, err
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		var zero T
+		return zero, ctx.Err()
+	}
+}
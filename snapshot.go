@@ -0,0 +1,330 @@
+package firefly
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/bluesky-social/indigo/xrpc"
+)
+
+var (
+	ErrInvalidSnapshot = errors.New("invalid client snapshot")
+)
+
+// snapshotVersion is the ClientSnapshot format written by Snapshot
+const snapshotVersion = 1
+
+// ClientSnapshot is the state of a client, captured by Snapshot and loaded by Restore. It marshals to JSON,
+// so it can be written to a file and used to start a CLI without logging in again, or checked into a test
+// fixture for deterministic integration tests.
+//
+// A snapshot holds the session's tokens, so store it as carefully as a password.
+type ClientSnapshot struct {
+	Version int              `json:"version"`
+	Server  string           `json:"server"`
+	TakenAt time.Time        `json:"takenAt"`
+	Session *SessionSnapshot `json:"session,omitempty"`
+	Self    *User            `json:"self,omitempty"`
+
+	Identities     []IdentitySnapshot  `json:"identities,omitempty"`     // cached handle resolutions
+	Profiles       []ProfileSnapshot   `json:"profiles,omitempty"`       // cached profiles, if WithProfileCache is used
+	WriteBudget    []WriteSnapshot     `json:"writeBudget,omitempty"`    // points spent in the last day, if WithWriteQueue is used
+	PublishedPosts []*PublishedPost    `json:"publishedPosts,omitempty"` // duplicate guard history, if it's kept in memory
+	Scheduled      []ScheduledSnapshot `json:"scheduled,omitempty"`      // pending posts of schedulers kept in memory
+
+	// FirehoseCursor is the time_us of the last firehose event the client read, or 0 if it hasn't streamed.
+	// Pass FirehoseCursor+1 as FirehoseOptions.Cursor to pick up where the stream left off.
+	FirehoseCursor int64 `json:"firehoseCursor,omitempty"`
+
+	// Cursors carries the caller's own cursors, such as a Pager's Cursor, under names of their choosing. The
+	// client doesn't use them.
+	Cursors map[string]string `json:"cursors,omitempty"`
+}
+
+func (s ClientSnapshot) String() string {
+	did := ""
+	if s.Session != nil {
+		did = s.Session.Did
+	}
+	return fmt.Sprintf("ClientSnapshot{Server: %s, DID: %s, TakenAt: %s}", s.Server, did, s.TakenAt.Format(time.RFC3339))
+}
+
+// SessionSnapshot is the session part of a ClientSnapshot
+type SessionSnapshot struct {
+	AccessJwt  string `json:"accessJwt"`
+	RefreshJwt string `json:"refreshJwt"`
+	Handle     string `json:"handle"`
+	Did        string `json:"did"`
+}
+
+// IdentitySnapshot is a cached handle resolution
+type IdentitySnapshot struct {
+	Handle  string    `json:"handle"`
+	Did     string    `json:"did"`
+	Expires time.Time `json:"expires"`
+}
+
+// ProfileSnapshot is a cached profile
+type ProfileSnapshot struct {
+	Key     string    `json:"key"`
+	User    *User     `json:"user"`
+	Expires time.Time `json:"expires"`
+}
+
+// ScheduledSnapshot is a pending post of a PostScheduler. Scheduler is the scheduler's position among the
+// client's schedulers, in the order NewPostScheduler created them.
+type ScheduledSnapshot struct {
+	Scheduler int            `json:"scheduler"`
+	Post      *ScheduledPost `json:"post"`
+}
+
+// WriteSnapshot is a write counted against the write queue budget
+type WriteSnapshot struct {
+	At     time.Time `json:"at"`
+	Points int       `json:"points"`
+}
+
+// Snapshot captures the client's session, caches, write budget, duplicate guard history, firehose cursor and
+// the pending posts of schedulers that keep them in memory. Schedulers with their own ScheduleStore already
+// persist their posts and are skipped. Writes waiting in the write queue are calls still in progress, so
+// they aren't captured, and neither are running firehose streams; restart them after restoring.
+//
+// Example:
+//
+//	snapshot := client.Snapshot()
+//	snapshot.Cursors = map[string]string{"timeline": pager.Cursor}
+//	data, _ := json.Marshal(snapshot)
+//	os.WriteFile("state.json", data, 0o600)
+func (f *Firefly) Snapshot() *ClientSnapshot {
+	now := time.Now()
+	client := f.currentClient()
+	snapshot := &ClientSnapshot{
+		Version: snapshotVersion,
+		Server:  client.Host,
+		TakenAt: now,
+	}
+	if client.Auth != nil {
+		snapshot.Session = &SessionSnapshot{
+			AccessJwt:  client.Auth.AccessJwt,
+			RefreshJwt: client.Auth.RefreshJwt,
+			Handle:     client.Auth.Handle,
+			Did:        client.Auth.Did,
+		}
+		if f.Self != nil {
+			self := *f.Self
+			snapshot.Self = &self
+		}
+	}
+
+	f.identities.mu.Lock()
+	for handle, entry := range f.identities.handleToDid {
+		if now.Before(entry.expires) {
+			snapshot.Identities = append(snapshot.Identities, IdentitySnapshot{Handle: handle, Did: entry.value, Expires: entry.expires})
+		}
+	}
+	f.identities.mu.Unlock()
+	slices.SortFunc(snapshot.Identities, func(a, b IdentitySnapshot) int { return cmp.Compare(a.Handle, b.Handle) })
+
+	if f.profiles != nil {
+		f.profiles.mu.Lock()
+		for _, key := range slices.Sorted(maps.Keys(f.profiles.entries)) {
+			entry := f.profiles.entries[key]
+			if now.Before(entry.expires) {
+				snapshot.Profiles = append(snapshot.Profiles, ProfileSnapshot{Key: key, User: entry.user, Expires: entry.expires})
+			}
+		}
+		f.profiles.mu.Unlock()
+	}
+
+	if f.writes != nil {
+		f.writes.mu.Lock()
+		for _, spent := range f.writes.spent {
+			snapshot.WriteBudget = append(snapshot.WriteBudget, WriteSnapshot{At: spent.at, Points: spent.points})
+		}
+		f.writes.mu.Unlock()
+	}
+
+	if f.duplicates != nil {
+		if store, ok := f.duplicates.options.Store.(*MemoryDuplicateStore); ok {
+			store.mu.Lock()
+			for _, hash := range slices.Sorted(maps.Keys(store.posts)) {
+				copied := *store.posts[hash]
+				snapshot.PublishedPosts = append(snapshot.PublishedPosts, &copied)
+			}
+			store.mu.Unlock()
+		}
+	}
+
+	snapshot.FirehoseCursor = f.firehoseCursor.Load()
+	f.schedulersMu.Lock()
+	for i, scheduler := range f.schedulers {
+		store, ok := scheduler.options.Store.(*MemoryScheduleStore)
+		if !ok {
+			continue
+		}
+		store.mu.Lock()
+		var pending []*ScheduledPost
+		for _, post := range store.posts {
+			if post.Status == ScheduledPending {
+				copied := *post
+				copied.Draft = post.Draft.Clone()
+				pending = append(pending, &copied)
+			}
+		}
+		store.mu.Unlock()
+		slices.SortFunc(pending, compareScheduled)
+		for _, post := range pending {
+			snapshot.Scheduled = append(snapshot.Scheduled, ScheduledSnapshot{Scheduler: i, Post: post})
+		}
+	}
+	f.schedulersMu.Unlock()
+	return snapshot
+}
+
+// Restore loads a snapshot taken by Snapshot into the client, resuming its session without a network request
+// unless the access token has expired, in which case it is refreshed. Cached entries that have expired since
+// the snapshot are dropped. Profiles, write budget and duplicate history are only restored if the client was
+// created with the matching option, and scheduled posts only into schedulers already made with
+// NewPostScheduler, matched by the order they were made in. The snapshot must come from the same server as
+// the client.
+//
+// Example:
+//
+//	data, err := os.ReadFile("state.json")
+//	if err == nil {
+//	    var snapshot firefly.ClientSnapshot
+//	    if err = json.Unmarshal(data, &snapshot); err == nil {
+//	        err = client.Restore(ctx, &snapshot)
+//	    }
+//	}
+//	if err != nil {
+//	    err = client.Login(ctx, handle, password)
+//	}
+func (f *Firefly) Restore(ctx context.Context, snapshot *ClientSnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("%w: nil snapshot", ErrInvalidSnapshot)
+	}
+	if snapshot.Version != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, snapshot.Version)
+	}
+	if host := f.currentClient().Host; snapshot.Server != host {
+		return fmt.Errorf("%w: taken on %s, not %s", ErrInvalidSnapshot, snapshot.Server, host)
+	}
+	now := time.Now()
+
+	// Only handle lookups are restored, for no longer than the cache would keep them; a DID's handle is
+	// trusted only once ResolveDIDToHandle has verified it
+	for _, identity := range snapshot.Identities {
+		if now.Before(identity.Expires) {
+			expires := identity.Expires
+			if limit := now.Add(f.identities.ttl); expires.After(limit) {
+				expires = limit
+			}
+			f.identities.storeHandle(identity.Handle, identity.Did, expires)
+		}
+	}
+
+	if f.profiles != nil {
+		f.profiles.mu.Lock()
+		for _, profile := range snapshot.Profiles {
+			if profile.User != nil && now.Before(profile.Expires) {
+				f.profiles.entries[profile.Key] = cachedProfile{user: profile.User, expires: profile.Expires}
+			}
+		}
+		f.profiles.mu.Unlock()
+	}
+
+	if f.writes != nil && len(snapshot.WriteBudget) > 0 {
+		f.writes.dispatch(func() {
+			for _, write := range snapshot.WriteBudget {
+				if now.Sub(write.At) < 24*time.Hour {
+					f.writes.spent = append(f.writes.spent, spentPoints{at: write.At, points: write.Points})
+				}
+			}
+			slices.SortStableFunc(f.writes.spent, func(a, b spentPoints) int { return a.at.Compare(b.at) })
+		})
+	}
+
+	if f.duplicates != nil {
+		for _, post := range snapshot.PublishedPosts {
+			if post != nil && post.Post != nil && now.Sub(post.PublishedAt) < f.duplicates.options.Window {
+				if err := f.duplicates.options.Store.RememberPost(ctx, post); err != nil {
+					return fmt.Errorf("failed to restore published posts: %w", err)
+				}
+			}
+		}
+	}
+
+	if snapshot.FirehoseCursor > f.firehoseCursor.Load() {
+		f.firehoseCursor.Store(snapshot.FirehoseCursor)
+	}
+
+	f.schedulersMu.Lock()
+	schedulers := slices.Clone(f.schedulers)
+	f.schedulersMu.Unlock()
+	for _, scheduled := range snapshot.Scheduled {
+		if scheduled.Post == nil || scheduled.Scheduler < 0 || scheduled.Scheduler >= len(schedulers) {
+			continue
+		}
+		if err := schedulers[scheduled.Scheduler].options.Store.SaveScheduled(ctx, scheduled.Post); err != nil {
+			return fmt.Errorf("failed to restore scheduled posts: %w", err)
+		}
+	}
+
+	if snapshot.Session != nil {
+		return f.resumeSession(ctx, snapshot.Session, snapshot.Self)
+	}
+	return nil
+}
+
+// resumeSession installs saved session tokens, refreshing them first if the access token has expired, and
+// schedules the next refresh
+func (f *Firefly) resumeSession(ctx context.Context, session *SessionSnapshot, self *User) error {
+	if session.AccessJwt == "" || session.RefreshJwt == "" || session.Did == "" {
+		return fmt.Errorf("%w: incomplete session", ErrInvalidSnapshot)
+	}
+	f.setAuth(&xrpc.AuthInfo{
+		AccessJwt:  session.AccessJwt,
+		RefreshJwt: session.RefreshJwt,
+		Handle:     session.Handle,
+		Did:        session.Did,
+	})
+
+	expiration, err := f.sessionExpiryFromToken(session.AccessJwt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+	}
+	f.refreshMu.Lock()
+	f.sessionExpiration = expiration
+	f.refreshMu.Unlock()
+	if !time.Now().Before(expiration) {
+		// syncRefresh schedules the next refresh itself
+		if err := f.syncRefresh(ctx, ""); err != nil {
+			f.setAuth(nil)
+			return err
+		}
+	} else {
+		f.refreshMu.Lock()
+		if f.cancelRefresh != nil {
+			f.cancelRefresh()
+		}
+		f.scheduleSessionRefresh()
+		f.refreshMu.Unlock()
+	}
+
+	if self != nil && self.Did == session.Did {
+		restored := *self
+		f.Self = &restored
+		return nil
+	}
+	// As with Login, a profile that fails to load leaves Self unset without failing the session
+	if profile, err := f.GetProfile(ctx, session.Did); err == nil {
+		f.Self = profile
+	}
+	return nil
+}
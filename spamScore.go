@@ -0,0 +1,329 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpamWeights sets how much each signal counts toward a SpamReport's score. Zero turns a signal off.
+type SpamWeights struct {
+	AccountAge    float64 // young accounts
+	FollowRatio   float64 // following far more accounts than follow back
+	PostingRate   float64 // posting faster than people usually do
+	LinkDensity   float64 // posts that are mostly links
+	DefaultAvatar float64 // no profile picture
+}
+
+// DefaultSpamWeights are the weights used when SpamScorerOptions leaves them unset
+var DefaultSpamWeights = SpamWeights{
+	AccountAge:    3,
+	FollowRatio:   2,
+	PostingRate:   2,
+	LinkDensity:   2,
+	DefaultAvatar: 1,
+}
+
+// SpamSignal is one heuristic's contribution to a SpamReport
+type SpamSignal struct {
+	Name     string  `json:"name"`
+	Strength float64 `json:"strength"` // 0 when the account looks normal, 1 when it looks most like spam
+	Weight   float64 `json:"weight"`
+	Detail   string  `json:"detail"` // what was measured, e.g. "account is 2 days old"
+}
+
+func (s SpamSignal) String() string {
+	return fmt.Sprintf("SpamSignal{Name: %s, Strength: %.2f, Detail: %s}", s.Name, s.Strength, s.Detail)
+}
+
+// SpamReport is the result of SpamScore: the weighted average of its signals' strengths, from 0 to 1
+type SpamReport struct {
+	DID     string       `json:"did"`
+	Score   float64      `json:"score"`
+	Signals []SpamSignal `json:"signals"`
+}
+
+func (r SpamReport) String() string {
+	return fmt.Sprintf("SpamReport{DID: %s, Score: %.2f}", r.DID, r.Score)
+}
+
+// SpamScorerOptions configures a SpamScorer
+type SpamScorerOptions struct {
+	Weights        SpamWeights   // Signal weights (default DefaultSpamWeights)
+	NewAccountAge  time.Duration // Accounts younger than this count as new, the younger the stronger (default 30 days)
+	MaxPostsPerDay float64       // Posting rate at which the signal starts; it is strongest at twice the rate (default 50)
+	RecentPosts    int           // Recent posts read from the author's feed for the rate and link signals (default 50)
+	CacheTTL       time.Duration // How long an account's profile and recent posts are reused between scores (default 10 minutes)
+}
+
+// SpamScorer rates how much an account and its posts look like spam, using only the profile and the author's
+// recent posts. The score is a starting signal for moderation bots, not a verdict: tune the weights against
+// accounts you have already judged. A SpamScorer is also a Classifier, adding a "spam" score to post events.
+//
+// Scoring an account the scorer hasn't seen within CacheTTL costs two requests, a profile and an author feed,
+// made on the firehose read loop when used as a Classifier. That is far too slow for every post on the
+// network; narrow the stream first, such as to replies to the bot or to mentions, or score posts in a handler.
+type SpamScorer struct {
+	f       *Firefly
+	options SpamScorerOptions
+
+	mu       sync.Mutex
+	accounts map[string]spamAccount // by DID
+}
+
+// spamAccount is what a SpamScorer fetched about an account, kept for CacheTTL
+type spamAccount struct {
+	user    *User
+	recent  []*FeedPost
+	expires time.Time
+}
+
+// NewSpamScorer creates a SpamScorer. Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	scorer := client.NewSpamScorer(&firefly.SpamScorerOptions{
+//	    Weights: firefly.SpamWeights{AccountAge: 1, PostingRate: 3, LinkDensity: 3},
+//	})
+//	events, err := client.StreamEvents(ctx, &firefly.FirehoseOptions{
+//	    Collections: []string{firefly.CollectionPost},
+//	    Authors:     newcomers,
+//	    Classifiers: []firefly.Classifier{scorer},
+//	})
+//	for event := range firefly.GateEvents(ctx, events, firefly.ScoreGate("spam", 0.6)) {
+//	    handle(event)
+//	}
+func (f *Firefly) NewSpamScorer(options *SpamScorerOptions) *SpamScorer {
+	if options == nil {
+		options = &SpamScorerOptions{}
+	}
+	opts := *options
+	if opts.Weights == (SpamWeights{}) {
+		opts.Weights = DefaultSpamWeights
+	}
+	if opts.NewAccountAge <= 0 {
+		opts.NewAccountAge = 30 * 24 * time.Hour
+	}
+	if opts.MaxPostsPerDay <= 0 {
+		opts.MaxPostsPerDay = 50
+	}
+	if opts.RecentPosts <= 0 {
+		opts.RecentPosts = 50
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = 10 * time.Minute
+	}
+	return &SpamScorer{f: f, options: opts, accounts: make(map[string]spamAccount)}
+}
+
+// SpamScore rates an account, and optionally one of its posts, with the default SpamScorer. See
+// SpamScorer.Score.
+//
+// Example:
+//
+//	report, err := client.SpamScore(ctx, nil, post)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if report.Score > 0.7 {
+//	    fmt.Println("likely spam:", report.Signals)
+//	}
+func (f *Firefly) SpamScore(ctx context.Context, user *User, post *FeedPost) (*SpamReport, error) {
+	return f.NewSpamScorer(nil).Score(ctx, user, post)
+}
+
+// Score rates an account, and optionally one of its posts. Pass nil for user to score the post's author;
+// firehose posts carry no author, so it is taken from the post's URI. Profiles without counts are fetched
+// first. The profile and recent posts are cached for CacheTTL, so repeat scores of an account are cheap.
+func (s *SpamScorer) Score(ctx context.Context, user *User, post *FeedPost) (*SpamReport, error) {
+	if user == nil && post != nil {
+		user = post.Author
+		if user == nil {
+			if did, err := ExtractDidFromUri(post.URI); err == nil {
+				user = &User{Did: did}
+			}
+		}
+	}
+	if user == nil || user.Did == "" {
+		return nil, ErrNilUser
+	}
+	user, recent, err := s.account(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := s.options.Weights
+	signals := []SpamSignal{
+		s.accountAgeSignal(user, weights.AccountAge),
+		followRatioSignal(user, weights.FollowRatio),
+		s.postingRateSignal(recent, weights.PostingRate),
+		linkDensitySignal(recent, post, weights.LinkDensity),
+		defaultAvatarSignal(user, weights.DefaultAvatar),
+	}
+	report := &SpamReport{DID: user.Did}
+	total := 0.0
+	for _, signal := range signals {
+		if signal.Weight <= 0 {
+			continue
+		}
+		report.Signals = append(report.Signals, signal)
+		report.Score += signal.Strength * signal.Weight
+		total += signal.Weight
+	}
+	if total > 0 {
+		report.Score /= total
+	}
+	return report, nil
+}
+
+// account returns the full profile and recent posts for user, from the cache if they are fresh
+func (s *SpamScorer) account(ctx context.Context, user *User) (*User, []*FeedPost, error) {
+	s.mu.Lock()
+	cached, ok := s.accounts[user.Did]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.user, cached.recent, nil
+	}
+
+	if user.FollowersCount == nil || user.FollowsCount == nil || user.CreatedAt.IsZero() {
+		profile, err := s.f.GetProfile(ctx, user.Did)
+		if err != nil {
+			return nil, nil, err
+		}
+		user = profile
+	}
+	var recent []*FeedPost
+	if s.options.Weights.PostingRate > 0 || s.options.Weights.LinkDensity > 0 {
+		items, _, err := s.f.GetAuthorFeed(ctx, user.Did, AuthorFeedPostsWithReplies, false, "", s.options.RecentPosts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, item := range items {
+			if !item.IsRepost() && item.Post != nil {
+				recent = append(recent, item.Post)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for did, entry := range s.accounts {
+		if now.After(entry.expires) {
+			delete(s.accounts, did)
+		}
+	}
+	s.accounts[user.Did] = spamAccount{user: user, recent: recent, expires: now.Add(s.options.CacheTTL)}
+	return user, recent, nil
+}
+
+// Classify scores a post's author and the post, as the "spam" score
+func (s *SpamScorer) Classify(ctx context.Context, post *FeedPost) (Classification, error) {
+	report, err := s.Score(ctx, nil, post)
+	if err != nil {
+		return Classification{}, err
+	}
+	return Classification{Scores: map[string]float64{"spam": report.Score}}, nil
+}
+
+func (s *SpamScorer) accountAgeSignal(user *User, weight float64) SpamSignal {
+	signal := SpamSignal{Name: "account age", Weight: weight}
+	if user.CreatedAt.IsZero() {
+		signal.Strength = 0.5
+		signal.Detail = "account age unknown"
+		return signal
+	}
+	age := time.Since(user.CreatedAt)
+	signal.Strength = clampUnit(1 - float64(age)/float64(s.options.NewAccountAge))
+	signal.Detail = fmt.Sprintf("account is %d days old", int(age.Hours()/24))
+	return signal
+}
+
+// followRatioSignal starts at twice as many follows as followers and is strongest at twenty times. Accounts
+// following fewer than 20 are left alone, since everyone starts with no followers.
+func followRatioSignal(user *User, weight float64) SpamSignal {
+	followers, follows := 0, 0
+	if user.FollowersCount != nil {
+		followers = *user.FollowersCount
+	}
+	if user.FollowsCount != nil {
+		follows = *user.FollowsCount
+	}
+	signal := SpamSignal{Name: "follow ratio", Weight: weight, Detail: fmt.Sprintf("follows %d, followed by %d", follows, followers)}
+	if follows >= 20 {
+		ratio := float64(follows) / float64(max(followers, 1))
+		signal.Strength = clampUnit((ratio - 2) / 18)
+	}
+	return signal
+}
+
+// postingRateSignal measures posts per day across the recent posts, from the oldest until now
+func (s *SpamScorer) postingRateSignal(recent []*FeedPost, weight float64) SpamSignal {
+	signal := SpamSignal{Name: "posting rate", Weight: weight, Detail: "no recent posts"}
+	var oldest time.Time
+	count := 0
+	for _, post := range recent {
+		if post.CreatedAt == nil {
+			continue
+		}
+		count++
+		if oldest.IsZero() || post.CreatedAt.Before(oldest) {
+			oldest = *post.CreatedAt
+		}
+	}
+	if count < 2 {
+		return signal
+	}
+	days := max(time.Since(oldest).Hours()/24, 1.0/24)
+	rate := float64(count) / days
+	signal.Strength = clampUnit((rate - s.options.MaxPostsPerDay) / s.options.MaxPostsPerDay)
+	signal.Detail = fmt.Sprintf("%.0f posts a day", rate)
+	return signal
+}
+
+// linkDensitySignal is the share of posts carrying links, counting a post whose text is little more than
+// links, or that has three or more, as fully spammy. The scored post counts alongside the recent ones.
+func linkDensitySignal(recent []*FeedPost, post *FeedPost, weight float64) SpamSignal {
+	signal := SpamSignal{Name: "link density", Weight: weight, Detail: "no posts"}
+	posts := recent
+	if post != nil && !slices.ContainsFunc(recent, func(r *FeedPost) bool { return r.URI == post.URI }) {
+		posts = append([]*FeedPost{post}, recent...)
+	}
+	if len(posts) == 0 {
+		return signal
+	}
+	total, linked := 0.0, 0
+	for _, candidate := range posts {
+		links := ExtractLinks(candidate)
+		if len(links) == 0 {
+			continue
+		}
+		linked++
+		words := len(strings.Fields(candidate.Text)) - len(links)
+		if len(links) >= 3 || words < 4 {
+			total += 1
+		} else {
+			total += 0.5
+		}
+	}
+	signal.Strength = clampUnit(total / float64(len(posts)))
+	signal.Detail = fmt.Sprintf("%d of %d posts have links", linked, len(posts))
+	return signal
+}
+
+func defaultAvatarSignal(user *User, weight float64) SpamSignal {
+	signal := SpamSignal{Name: "default avatar", Weight: weight, Detail: "has an avatar"}
+	if user.Avatar == nil || *user.Avatar == "" {
+		signal.Strength = 1
+		signal.Detail = "no avatar"
+	}
+	return signal
+}
+
+// clampUnit limits v to the range 0 to 1
+func clampUnit(v float64) float64 {
+	return min(max(v, 0), 1)
+}
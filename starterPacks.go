@@ -0,0 +1,230 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+)
+
+var (
+	ErrInvalidStarterPack = errors.New("invalid starter pack")
+)
+
+// StarterPackItemKind identifies what a StarterPackResult refers to
+type StarterPackItemKind int
+
+const (
+	StarterPackMember StarterPackItemKind = iota
+	StarterPackFeed
+)
+
+func (k StarterPackItemKind) String() string {
+	switch k {
+	case StarterPackMember:
+		return "Member"
+	case StarterPackFeed:
+		return "Feed"
+	default:
+		return "Unknown"
+	}
+}
+
+// StarterPackResult reports what happened to one member or feed when joining a starter pack. For feeds,
+// FollowCreated means the feed was pinned and FollowSkipped means it was already pinned.
+type StarterPackResult struct {
+	Kind   StarterPackItemKind `json:"kind"`
+	Target string              `json:"target"` // member DID or feed URI
+	Status FollowStatus        `json:"status"`
+	Follow *PostRef            `json:"follow,omitempty"` // the new follow record for members
+	Err    error               `json:"-"`                // set when Status is FollowFailed
+}
+
+func (r StarterPackResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("StarterPackResult{Kind: %s, Target: %s, Status: %s, Err: %v}", r.Kind, r.Target, r.Status, r.Err)
+	}
+	return fmt.Sprintf("StarterPackResult{Kind: %s, Target: %s, Status: %s}", r.Kind, r.Target, r.Status)
+}
+
+// JoinStarterPack does what the app's "follow all" button on a starter pack does: it pins the pack's feeds
+// and follows every member. starterPack is an AT URI or a bsky.app starter pack link. Follows are paced with
+// FollowAll, so options such as FollowInterval and FollowDryRun apply; with FollowDryRun the feeds aren't
+// pinned either.
+//
+// Feeds are handled first. One result per feed and member is sent on the returned channel, which is closed
+// when everything has been processed or ctx is cancelled.
+//
+// Example:
+//
+//	results, err := client.JoinStarterPack(ctx, "https://bsky.app/starter-pack/alice.bsky.social/3kxyz")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for result := range results {
+//	    fmt.Println(result)
+//	}
+func (f *Firefly) JoinStarterPack(ctx context.Context, starterPack string, options ...FollowAllOption) (chan *StarterPackResult, error) {
+	if f.Self == nil {
+		return nil, ErrNotLoggedIn
+	}
+	if f.isClosed() {
+		return nil, ErrClientClosed
+	}
+	var opts FollowAllOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	uri, err := f.starterPackURI(ctx, starterPack)
+	if err != nil {
+		return nil, err
+	}
+	pack, err := bsky.GraphGetStarterPack(ctx, f.api, uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	if pack.StarterPack == nil {
+		return nil, fmt.Errorf("%w: missing starter pack", ErrBadResponse)
+	}
+
+	var members []string
+	if pack.StarterPack.List != nil {
+		pager := f.ListMembersPager(pack.StarterPack.List.Uri)
+		pager.PageSize = 100
+		for member, err := range pager.All(ctx) {
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member.Did)
+		}
+	}
+	follows, err := f.FollowAll(ctx, members, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan *StarterPackResult, 100)
+	ctx, cancel := f.bindLifetime(ctx)
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		defer cancel()
+		defer close(results)
+
+		send := func(result *StarterPackResult) bool {
+			select {
+			case results <- result:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, result := range f.pinFeeds(ctx, pack.StarterPack.Feeds, opts.DryRun) {
+			if !send(result) {
+				return
+			}
+		}
+		for follow := range follows {
+			target := follow.DID
+			if target == "" {
+				target = follow.Actor
+			}
+			if !send(&StarterPackResult{
+				Kind:   StarterPackMember,
+				Target: target,
+				Status: follow.Status,
+				Follow: follow.Follow,
+				Err:    follow.Err,
+			}) {
+				return
+			}
+		}
+	}()
+
+	return results, nil
+}
+
+// starterPackURI turns a starter pack link into an AT URI with a DID authority
+func (f *Firefly) starterPackURI(ctx context.Context, starterPack string) (string, error) {
+	var actor, rkey string
+	switch {
+	case strings.HasPrefix(starterPack, "at://"):
+		parts := strings.Split(strings.TrimPrefix(starterPack, "at://"), "/")
+		if len(parts) != 3 || parts[1] != CollectionStarterPack {
+			return "", fmt.Errorf("%w: %s", ErrInvalidStarterPack, starterPack)
+		}
+		actor, rkey = parts[0], parts[2]
+	case strings.HasPrefix(starterPack, "https://bsky.app/starter-pack/"):
+		parts := strings.Split(strings.TrimPrefix(starterPack, "https://bsky.app/starter-pack/"), "/")
+		if len(parts) != 2 {
+			return "", fmt.Errorf("%w: %s", ErrInvalidStarterPack, starterPack)
+		}
+		actor, rkey = parts[0], parts[1]
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidStarterPack, starterPack)
+	}
+	did, err := f.resolveActor(ctx, actor)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("at://%s/app.bsky.graph.starterpack/%s", did, rkey), nil
+}
+
+// pinFeeds adds the feeds to the authenticated user's saved feeds as pinned, in one preferences update
+func (f *Firefly) pinFeeds(ctx context.Context, feeds []*bsky.FeedDefs_GeneratorView, dryRun bool) []*StarterPackResult {
+	if len(feeds) == 0 {
+		return nil
+	}
+	results := make([]*StarterPackResult, 0, len(feeds))
+	fail := func(err error) []*StarterPackResult {
+		failed := make([]*StarterPackResult, 0, len(feeds))
+		for _, feed := range feeds {
+			failed = append(failed, &StarterPackResult{Kind: StarterPackFeed, Target: feed.Uri, Status: FollowFailed, Err: err})
+		}
+		return failed
+	}
+
+	var saved bsky.ActorDefs_SavedFeedsPrefV2
+	if _, err := f.getPreference(ctx, "app.bsky.actor.defs#savedFeedsPrefV2", &saved); err != nil {
+		return fail(err)
+	}
+	changed := false
+	for _, feed := range feeds {
+		result := &StarterPackResult{Kind: StarterPackFeed, Target: feed.Uri, Status: FollowCreated}
+		var existing *bsky.ActorDefs_SavedFeed
+		for _, item := range saved.Items {
+			if item.Type == "feed" && item.Value == feed.Uri {
+				existing = item
+				break
+			}
+		}
+		switch {
+		case existing != nil && existing.Pinned:
+			result.Status = FollowSkipped
+		case existing != nil:
+			existing.Pinned = true
+			changed = true
+		default:
+			saved.Items = append(saved.Items, &bsky.ActorDefs_SavedFeed{
+				Id:     recordKeyClock.Next().String(),
+				Pinned: true,
+				Type:   "feed",
+				Value:  feed.Uri,
+			})
+			changed = true
+		}
+		results = append(results, result)
+	}
+
+	if changed && !dryRun {
+		saved.LexiconTypeID = "app.bsky.actor.defs#savedFeedsPrefV2"
+		if err := f.putPreference(ctx, "app.bsky.actor.defs#savedFeedsPrefV2", &saved); err != nil {
+			return fail(err)
+		}
+	}
+	return results
+}
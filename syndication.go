@@ -0,0 +1,467 @@
+package firefly
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrNoFeedPager = errors.New("feed source has no pager")
+)
+
+// FeedChannel describes a syndication feed as a whole
+type FeedChannel struct {
+	Title       string `json:"title"`
+	Link        string `json:"link"` // Web page the feed mirrors, such as a profile on bsky.app
+	Description string `json:"description"`
+	FeedURL     string `json:"feedUrl,omitempty"` // Where the feed itself is served; the Atom feed's ID and self link
+}
+
+func (c FeedChannel) String() string {
+	return fmt.Sprintf("FeedChannel{Title: %s}", c.Title)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// WriteRSS writes posts as an RSS 2.0 feed, in the order given. Each item links to the post on bsky.app, is
+// identified by the post's AT URI, and carries the post rendered by RenderPost as HTML, images included.
+func WriteRSS(w io.Writer, channel FeedChannel, posts []*FeedPost) error {
+	doc := rssDocument{Version: "2.0", Channel: rssChannel{
+		Title:       channel.Title,
+		Link:        channel.Link,
+		Description: channel.Description,
+	}}
+	if latest := latestPostTime(posts); !latest.IsZero() {
+		doc.Channel.LastBuildDate = latest.Format(time.RFC1123Z)
+	}
+	for _, post := range posts {
+		item := rssItem{
+			Title:       postTitle(post),
+			Link:        PostWebURL(post.URI),
+			Description: RenderPost(post, RenderHTML),
+			GUID:        rssGUID{Value: post.URI},
+		}
+		if post.CreatedAt != nil {
+			item.PubDate = post.CreatedAt.Format(time.RFC1123Z)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write RSS: %w", err)
+	}
+	return nil
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published,omitempty"`
+	Links     []atomLink  `xml:"link"`
+	Author    atomAuthor  `xml:"author"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteAtom writes posts as an Atom feed, in the order given, with the same content as WriteRSS. The feed's
+// ID is the channel's FeedURL, or its Link if that is empty; entries are identified by the posts' AT URIs.
+func WriteAtom(w io.Writer, channel FeedChannel, posts []*FeedPost) error {
+	updated := latestPostTime(posts)
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	doc := atomFeed{
+		Title:    channel.Title,
+		Subtitle: channel.Description,
+		ID:       cmp.Or(channel.FeedURL, channel.Link),
+		Updated:  updated.UTC().Format(time.RFC3339),
+	}
+	if channel.Link != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "alternate", Href: channel.Link})
+	}
+	if channel.FeedURL != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "self", Href: channel.FeedURL})
+	}
+	for _, post := range posts {
+		entry := atomEntry{
+			Title:   postTitle(post),
+			ID:      post.URI,
+			Updated: updated.UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "html", Value: RenderPost(post, RenderHTML)},
+		}
+		if link := PostWebURL(post.URI); link != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Href: link})
+		}
+		if post.CreatedAt != nil {
+			entry.Published = post.CreatedAt.UTC().Format(time.RFC3339)
+			entry.Updated = entry.Published
+		}
+		if post.Author != nil {
+			entry.Author = atomAuthor{Name: cmp.Or(post.Author.Handle, post.Author.Did), URI: profileWebURL(post.Author.Did)}
+		} else {
+			entry.Author = atomAuthor{Name: "unknown"}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write Atom: %w", err)
+	}
+	return nil
+}
+
+// SyndicationFormat chooses between RSS and Atom
+type SyndicationFormat int
+
+const (
+	FormatRSS SyndicationFormat = iota
+	FormatAtom
+)
+
+func (sf SyndicationFormat) String() string {
+	switch sf {
+	case FormatRSS:
+		return "RSS"
+	case FormatAtom:
+		return "Atom"
+	default:
+		return "Unknown"
+	}
+}
+
+// SyndicationOptions holds the settings for ServeRSS; set them with SyndicationOption functions
+type SyndicationOptions struct {
+	Channel FeedChannel       // Feed title, link and description (the title defaults to the source's name)
+	Format  SyndicationFormat // Format served when the request doesn't ask for one (default FormatRSS)
+	Limit   int               // Posts in the feed (default 30, at most 100)
+	TTL     time.Duration     // How long a rendered feed is served before the source is fetched again (default 5 minutes)
+}
+
+// SyndicationOption configures ServeRSS
+type SyndicationOption func(*SyndicationOptions)
+
+// SyndicationChannel sets the feed's title, link and description
+func SyndicationChannel(channel FeedChannel) SyndicationOption {
+	return func(o *SyndicationOptions) { o.Channel = channel }
+}
+
+// SyndicationDefaultFormat sets the format served when the request doesn't ask for one
+func SyndicationDefaultFormat(format SyndicationFormat) SyndicationOption {
+	return func(o *SyndicationOptions) { o.Format = format }
+}
+
+// SyndicationLimit sets the number of posts in the feed (1-100)
+func SyndicationLimit(limit int) SyndicationOption {
+	return func(o *SyndicationOptions) { o.Limit = limit }
+}
+
+// SyndicationTTL sets how long a rendered feed is cached
+func SyndicationTTL(ttl time.Duration) SyndicationOption {
+	return func(o *SyndicationOptions) { o.TTL = ttl }
+}
+
+// syndicationHandler serves a FeedSource as RSS or Atom, caching the latest page of posts
+type syndicationHandler struct {
+	source  FeedSource
+	options SyndicationOptions
+
+	mu        sync.Mutex
+	posts     []*FeedPost
+	fetchedAt time.Time
+}
+
+// ServeRSS returns an http.Handler that serves the first page of a feed, such as an account's posts, a list
+// or a search, as an RSS 2.0 or Atom feed. Requests choose the format with ?format=rss or ?format=atom, and
+// get the default otherwise. Posts are fetched at most once per TTL and shared by both formats; responses carry
+// an ETag and a matching Cache-Control max-age. If a fetch fails, the last good feed is served until one
+// succeeds. The source's Pager is used only by the handler and is rewound on every fetch.
+//
+// Example:
+//
+//	http.Handle("/alice.xml", firefly.ServeRSS(firefly.FeedSource{
+//	    Name:  "Alice on Bluesky",
+//	    Pager: client.AuthorFeedPager("alice.bsky.social", firefly.AuthorFeedPostsNoReplies, false),
+//	}))
+//	http.Handle("/golang.xml", firefly.ServeRSS(
+//	    firefly.FeedSource{Name: "#golang", Pager: client.SearchFeedPager("#golang", nil)},
+//	    firefly.SyndicationDefaultFormat(firefly.FormatAtom),
+//	))
+//	log.Fatal(http.ListenAndServe(":8080", nil))
+func ServeRSS(source FeedSource, options ...SyndicationOption) http.Handler {
+	opts := SyndicationOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.Channel.Title == "" {
+		opts.Channel.Title = cmp.Or(source.Name, "Bluesky")
+	}
+	if opts.Limit <= 0 || opts.Limit > 100 {
+		opts.Limit = 30
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 5 * time.Minute
+	}
+	return &syndicationHandler{source: source, options: opts}
+}
+
+func (h *syndicationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	format := h.options.Format
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case "rss":
+		format = FormatRSS
+	case "atom":
+		format = FormatAtom
+	}
+
+	posts, err := h.latest(r.Context())
+	if err != nil {
+		http.Error(w, "failed to load feed", http.StatusBadGateway)
+		return
+	}
+
+	channel := h.options.Channel
+	if channel.FeedURL == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		channel.FeedURL = scheme + "://" + r.Host + r.URL.RequestURI()
+	}
+	var body bytes.Buffer
+	contentType := "application/rss+xml; charset=utf-8"
+	if format == FormatAtom {
+		contentType = "application/atom+xml; charset=utf-8"
+		err = WriteAtom(&body, channel, posts)
+	} else {
+		err = WriteRSS(&body, channel, posts)
+	}
+	if err != nil {
+		http.Error(w, "failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.options.TTL.Seconds())))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(body.Bytes())
+}
+
+// latest returns the cached posts, fetching the first page of the source again once they are older than the
+// TTL. A failed fetch falls back to the cached posts if there are any.
+func (h *syndicationHandler) latest(ctx context.Context) ([]*FeedPost, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.fetchedAt.IsZero() && time.Since(h.fetchedAt) < h.options.TTL {
+		return h.posts, nil
+	}
+	if h.source.Pager == nil {
+		return nil, ErrNoFeedPager
+	}
+
+	h.source.Pager.Reset()
+	h.source.Pager.PageSize = h.options.Limit
+	items, err := h.source.Pager.Next(ctx)
+	if err != nil {
+		if !h.fetchedAt.IsZero() {
+			return h.posts, nil
+		}
+		return nil, err
+	}
+	posts := make([]*FeedPost, 0, len(items))
+	for _, item := range items {
+		if item.Post != nil {
+			posts = append(posts, item.Post)
+		}
+	}
+	h.posts, h.fetchedAt = posts, time.Now()
+	return posts, nil
+}
+
+// postTitle is a short plain text title for a post: its first line, cut to 80 characters
+func postTitle(post *FeedPost) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(post.Text), "\n")
+	if utf8.RuneCountInString(title) > 80 {
+		runes := []rune(title)
+		title = strings.TrimSpace(string(runes[:79])) + "…"
+	}
+	if title != "" {
+		return title
+	}
+	if post.Author != nil && post.Author.Handle != "" {
+		return "Post by @" + post.Author.Handle
+	}
+	return "Post"
+}
+
+// latestPostTime returns when the newest of posts was created, or the zero time if none has a date
+func latestPostTime(posts []*FeedPost) time.Time {
+	var latest time.Time
+	for _, post := range posts {
+		if post.CreatedAt != nil && post.CreatedAt.After(latest) {
+			latest = *post.CreatedAt
+		}
+	}
+	return latest
+}
+
+// RSSCrossPoster is a CrossPoster that keeps an RSS file of the latest forwarded posts, newest first. The
+// file is rewritten on every Publish, so a web server can serve it as a static feed. It starts empty; earlier
+// contents of the file are replaced. It is safe for concurrent use.
+type RSSCrossPoster struct {
+	path     string
+	channel  FeedChannel
+	maxItems int
+
+	mu    sync.Mutex
+	posts []*FeedPost
+}
+
+// NewRSSCrossPoster creates an RSSCrossPoster that writes up to maxItems posts (default 50) to the file at path
+//
+// Example:
+//
+//	rss := firefly.NewRSSCrossPoster("public/feed.xml", firefly.FeedChannel{
+//	    Title: "Alice on Bluesky",
+//	    Link:  "https://bsky.app/profile/alice.bsky.social",
+//	}, 20)
+//	bridge := client.NewBridge(firefly.BridgeFromFeed(client.AuthorFeedPager("alice.bsky.social", firefly.AuthorFeedPostsNoReplies, false)), nil, rss)
+func NewRSSCrossPoster(path string, channel FeedChannel, maxItems int) *RSSCrossPoster {
+	if maxItems <= 0 {
+		maxItems = 50
+	}
+	return &RSSCrossPoster{path: path, channel: channel, maxItems: maxItems}
+}
+
+// Publish adds post to the top of the feed and rewrites the file. A post already in the feed is moved to
+// the top rather than repeated.
+func (r *RSSCrossPoster) Publish(ctx context.Context, post *FeedPost) error {
+	if post == nil {
+		return ErrNilPost
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.posts = slices.DeleteFunc(r.posts, func(existing *FeedPost) bool { return existing.URI == post.URI })
+	r.posts = slices.Insert(r.posts, 0, post)
+	if len(r.posts) > r.maxItems {
+		r.posts = r.posts[:r.maxItems]
+	}
+
+	// Write to a temporary file and rename it, so readers never see a half-written feed
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write RSS: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write RSS: %w", err)
+	}
+	if err := WriteRSS(tmp, r.channel, r.posts); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write RSS: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to write RSS: %w", err)
+	}
+	return nil
+}
+
+// Posts returns the posts currently in the feed, newest first
+func (r *RSSCrossPoster) Posts() []*FeedPost {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.posts)
+}
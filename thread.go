@@ -0,0 +1,199 @@
+package firefly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+)
+
+// Thread is one post in a conversation, linked to the post it replies to and the replies below it.
+// Posts that were deleted or hidden by a block appear as nodes with NotFound or Blocked set and a nil Post.
+type Thread struct {
+	URI      string    `json:"uri"`
+	Post     *FeedPost `json:"post,omitempty"`    // nil if NotFound or Blocked
+	NotFound bool      `json:"notFound"`          // the post was deleted or never existed
+	Blocked  bool      `json:"blocked"`           // the post is hidden by a block
+	Parent   *Thread   `json:"-"`                 // the post this one replies to; nil at the top of the fetched thread
+	Replies  []*Thread `json:"replies,omitempty"` // direct replies, in the order the server returned them
+}
+
+func (t Thread) String() string {
+	return fmt.Sprintf("Thread{URI: %s, Replies: %d}", t.URI, len(t.Replies))
+}
+
+// ThreadEntry is one line of a flattened thread
+type ThreadEntry struct {
+	URI      string    `json:"uri"`
+	Post     *FeedPost `json:"post,omitempty"`
+	NotFound bool      `json:"notFound"`
+	Blocked  bool      `json:"blocked"`
+	Depth    int       `json:"depth"` // 0 for the requested post, negative for its ancestors, positive for replies
+}
+
+// entry converts a thread node to a ThreadEntry at depth
+func (t *Thread) entry(depth int) *ThreadEntry {
+	return &ThreadEntry{URI: t.URI, Post: t.Post, NotFound: t.NotFound, Blocked: t.Blocked, Depth: depth}
+}
+
+// ThreadFormat selects the output of Thread.Render
+type ThreadFormat int
+
+const (
+	ThreadPlainText ThreadFormat = iota
+	ThreadMarkdown
+)
+
+func (tf ThreadFormat) String() string {
+	switch tf {
+	case ThreadPlainText:
+		return "Plain Text"
+	case ThreadMarkdown:
+		return "Markdown"
+	default:
+		return "Unknown"
+	}
+}
+
+// GetPostThread fetches the thread around the post at uri: up to parentHeight ancestors and depth levels of
+// replies (the server defaults are used when 0). The returned node is the requested post; walk Parent for
+// the ancestors and Replies for the conversation below it.
+//
+// Example:
+//
+//	thread, err := client.GetPostThread(ctx, "at://did:plc:abc123/app.bsky.feed.post/3k2a...", 6, 0)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Print(thread.Render(firefly.ThreadPlainText))
+func (f *Firefly) GetPostThread(ctx context.Context, uri string, depth int, parentHeight int) (*Thread, error) {
+	result, err := bsky.FeedGetPostThread(ctx, f.api, int64(depth), int64(parentHeight), uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	if result.Thread == nil {
+		return nil, fmt.Errorf("%w: missing thread", ErrBadResponse)
+	}
+	thread := f.threadNode(result.Thread.FeedDefs_ThreadViewPost, result.Thread.FeedDefs_NotFoundPost,
+		result.Thread.FeedDefs_BlockedPost)
+	if thread == nil {
+		return nil, fmt.Errorf("%w: unknown thread type", ErrBadResponse)
+	}
+	return thread, nil
+}
+
+// threadNode converts one member of the thread union types, following parents and replies
+func (f *Firefly) threadNode(view *bsky.FeedDefs_ThreadViewPost, notFound *bsky.FeedDefs_NotFoundPost, blocked *bsky.FeedDefs_BlockedPost) *Thread {
+	switch {
+	case view != nil && view.Post != nil:
+		node := &Thread{URI: view.Post.Uri}
+		post, err := f.OldToNewPostView(view.Post)
+		if err == nil {
+			node.Post = post
+		} else {
+			node.NotFound = true
+		}
+		if view.Parent != nil {
+			node.Parent = f.threadNode(view.Parent.FeedDefs_ThreadViewPost, view.Parent.FeedDefs_NotFoundPost,
+				view.Parent.FeedDefs_BlockedPost)
+		}
+		for _, reply := range view.Replies {
+			if reply == nil {
+				continue
+			}
+			child := f.threadNode(reply.FeedDefs_ThreadViewPost, reply.FeedDefs_NotFoundPost, reply.FeedDefs_BlockedPost)
+			if child != nil {
+				child.Parent = node
+				node.Replies = append(node.Replies, child)
+			}
+		}
+		return node
+	case notFound != nil:
+		return &Thread{URI: notFound.Uri, NotFound: true}
+	case blocked != nil:
+		return &Thread{URI: blocked.Uri, Blocked: true}
+	default:
+		return nil
+	}
+}
+
+// Flatten lists the thread in reading order: the ancestors from the top of the thread down, then this post,
+// then every reply depth-first. Depth is relative to this post, so ancestors are negative and replies positive.
+func (t *Thread) Flatten() []*ThreadEntry {
+	var ancestors []*Thread
+	for parent := t.Parent; parent != nil; parent = parent.Parent {
+		ancestors = append(ancestors, parent)
+	}
+	entries := make([]*ThreadEntry, 0, len(ancestors)+1)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		entries = append(entries, ancestors[i].entry(-(i + 1)))
+	}
+
+	var walk func(node *Thread, depth int)
+	walk = func(node *Thread, depth int) {
+		entries = append(entries, node.entry(depth))
+		for _, reply := range node.Replies {
+			walk(reply, depth+1)
+		}
+	}
+	walk(t, 0)
+	return entries
+}
+
+// Render formats the flattened thread as plain text or Markdown. Ancestors and this post are printed one
+// after another; replies are indented by depth.
+func (t *Thread) Render(format ThreadFormat) string {
+	var b strings.Builder
+	for _, entry := range t.Flatten() {
+		level := max(entry.Depth, 0)
+		author, text := "[deleted]", ""
+		switch {
+		case entry.Blocked:
+			author = "[blocked]"
+		case entry.Post != nil:
+			author = "@unknown"
+			if entry.Post.Author != nil {
+				author = "@" + entry.Post.Author.Handle
+			}
+			text = entry.Post.Text
+		}
+		when := ""
+		if entry.Post != nil && entry.Post.CreatedAt != nil {
+			when = entry.Post.CreatedAt.UTC().Format("02 Jan 2006 15:04")
+		}
+
+		if format == ThreadMarkdown {
+			indent := strings.Repeat("  ", level)
+			fmt.Fprintf(&b, "%s- ", indent)
+			if entry.Depth == 0 {
+				fmt.Fprintf(&b, "**%s**", author)
+			} else {
+				b.WriteString(author)
+			}
+			if when != "" {
+				fmt.Fprintf(&b, " · %s", when)
+			}
+			b.WriteString("\n")
+			for _, line := range strings.Split(text, "\n") {
+				if line != "" {
+					fmt.Fprintf(&b, "%s  > %s\n", indent, line)
+				}
+			}
+			continue
+		}
+
+		indent := strings.Repeat("    ", level)
+		fmt.Fprintf(&b, "%s%s", indent, author)
+		if when != "" {
+			fmt.Fprintf(&b, " (%s)", when)
+		}
+		b.WriteString("\n")
+		for _, line := range strings.Split(text, "\n") {
+			if line != "" {
+				fmt.Fprintf(&b, "%s  %s\n", indent, line)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,256 @@
+package firefly
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TrendKind selects what a TrendCounter query counts
+type TrendKind int
+
+const (
+	TrendHashtags TrendKind = iota
+	TrendDomains
+	TrendLanguages
+)
+
+func (tk TrendKind) String() string {
+	switch tk {
+	case TrendHashtags:
+		return "Hashtags"
+	case TrendDomains:
+		return "Domains"
+	case TrendLanguages:
+		return "Languages"
+	default:
+		return "Unknown"
+	}
+}
+
+// trendKinds is the number of TrendKind values
+const trendKinds = 3
+
+// TrendEntry is one row of a TrendCounter top-K query
+type TrendEntry struct {
+	Key   string `json:"key"` // lower-case hashtag without "#", link domain, or language code
+	Count int    `json:"count"`
+}
+
+func (e TrendEntry) String() string {
+	return fmt.Sprintf("TrendEntry{Key: %s, Count: %d}", e.Key, e.Count)
+}
+
+// TrendCounterOptions configures a TrendCounter
+type TrendCounterOptions struct {
+	Resolution time.Duration // Width of each time bucket, the precision of window queries (default 1 minute)
+	Retention  time.Duration // Longest window that can be queried; older buckets are dropped (default 1 hour)
+}
+
+// TrendCounter counts hashtags, link domains and languages in posts from the stream over sliding windows,
+// for trend dashboards. Each post counts at most once per key. Counts are kept in time buckets, so memory is
+// bounded by Retention rather than by stream volume, and windows are measured back from the newest post
+// seen, so replayed streams give the same results as live ones.
+//
+// TrendCounter is an EventSink: add it to FirehoseOptions.Sinks, or call Add yourself. It is safe for
+// concurrent use.
+type TrendCounter struct {
+	options TrendCounterOptions
+
+	mu      sync.Mutex
+	buckets map[int64]*trendBucket // keyed by bucket start divided by Resolution
+	latest  time.Time
+}
+
+// trendBucket holds the counts of one time bucket
+type trendBucket struct {
+	posts  int
+	counts [trendKinds]map[string]int
+}
+
+// NewTrendCounter creates a TrendCounter. Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	trends := firefly.NewTrendCounter(&firefly.TrendCounterOptions{Retention: 24 * time.Hour})
+//	_, err := client.StreamEvents(ctx, &firefly.FirehoseOptions{
+//	    Collections: []string{firefly.CollectionPost},
+//	    Sinks:       []firefly.EventSink{trends},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for range time.Tick(time.Minute) {
+//	    fmt.Println(trends.Top(firefly.TrendHashtags, 15*time.Minute, 10))
+//	}
+func NewTrendCounter(options *TrendCounterOptions) *TrendCounter {
+	if options == nil {
+		options = &TrendCounterOptions{}
+	}
+	opts := *options
+	if opts.Resolution <= 0 {
+		opts.Resolution = time.Minute
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = time.Hour
+	}
+	opts.Retention = max(opts.Retention, opts.Resolution)
+	return &TrendCounter{options: opts, buckets: make(map[int64]*trendBucket)}
+}
+
+// Write counts a post event, ignoring other events. It never fails.
+func (c *TrendCounter) Write(ctx context.Context, event *FirehoseEvent) error {
+	if event != nil && event.Type == EventTypePost && event.Post != nil {
+		c.Add(event.Post, event.Timestamp)
+	}
+	return nil
+}
+
+// Add counts a post created at the given time. Posts older than Retention before the newest post are
+// ignored.
+func (c *TrendCounter) Add(post *FeedPost, at time.Time) {
+	if post == nil {
+		return
+	}
+	var keys [trendKinds][]string
+	keys[TrendHashtags] = postHashtags(post)
+	for _, link := range ExtractLinks(post) {
+		if !slices.Contains(keys[TrendDomains], link.Domain) {
+			keys[TrendDomains] = append(keys[TrendDomains], link.Domain)
+		}
+	}
+	for _, language := range post.Languages {
+		language = strings.ToLower(language)
+		if language != "" && !slices.Contains(keys[TrendLanguages], language) {
+			keys[TrendLanguages] = append(keys[TrendLanguages], language)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if at.After(c.latest) {
+		c.latest = at
+		c.pruneLocked()
+	}
+	if c.latest.Sub(at) >= c.options.Retention {
+		return
+	}
+	index := at.UnixNano() / int64(c.options.Resolution)
+	bucket, ok := c.buckets[index]
+	if !ok {
+		bucket = &trendBucket{}
+		for kind := range bucket.counts {
+			bucket.counts[kind] = make(map[string]int)
+		}
+		c.buckets[index] = bucket
+	}
+	bucket.posts++
+	for kind, values := range keys {
+		for _, key := range values {
+			bucket.counts[kind][key]++
+		}
+	}
+}
+
+// Top returns the k most frequent keys of a kind in the last window, most frequent first. Ties are ordered
+// by key. The window is rounded up to whole buckets and capped at Retention.
+func (c *TrendCounter) Top(kind TrendKind, window time.Duration, k int) []TrendEntry {
+	if kind < 0 || kind >= trendKinds || k <= 0 {
+		return nil
+	}
+	totals := make(map[string]int)
+	c.mu.Lock()
+	for _, bucket := range c.windowLocked(window) {
+		for key, count := range bucket.counts[kind] {
+			totals[key] += count
+		}
+	}
+	c.mu.Unlock()
+
+	entries := make([]TrendEntry, 0, len(totals))
+	for key, count := range totals {
+		entries = append(entries, TrendEntry{Key: key, Count: count})
+	}
+	slices.SortFunc(entries, func(a, b TrendEntry) int {
+		if a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return entries[:min(k, len(entries))]
+}
+
+// Count returns how many posts in the last window had key. Hashtags are matched without "#" and
+// case-insensitively.
+func (c *TrendCounter) Count(kind TrendKind, key string, window time.Duration) int {
+	if kind < 0 || kind >= trendKinds {
+		return 0
+	}
+	key = strings.ToLower(strings.TrimPrefix(key, "#"))
+	total := 0
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, bucket := range c.windowLocked(window) {
+		total += bucket.counts[kind][key]
+	}
+	return total
+}
+
+// Posts returns how many posts were counted in the last window, for turning counts into shares
+func (c *TrendCounter) Posts(window time.Duration) int {
+	total := 0
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, bucket := range c.windowLocked(window) {
+		total += bucket.posts
+	}
+	return total
+}
+
+// windowLocked returns the buckets inside the last window. Callers must hold c.mu.
+func (c *TrendCounter) windowLocked(window time.Duration) []*trendBucket {
+	window = min(window, c.options.Retention)
+	newest := c.latest.UnixNano() / int64(c.options.Resolution)
+	oldest := newest - int64((window+c.options.Resolution-1)/c.options.Resolution) + 1
+	buckets := make([]*trendBucket, 0, newest-oldest+1)
+	for index, bucket := range c.buckets {
+		if index >= oldest && index <= newest {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets
+}
+
+// pruneLocked drops buckets older than Retention. Callers must hold c.mu.
+func (c *TrendCounter) pruneLocked() {
+	oldest := c.latest.Add(-c.options.Retention).UnixNano() / int64(c.options.Resolution)
+	for index := range c.buckets {
+		if index < oldest {
+			delete(c.buckets, index)
+		}
+	}
+}
+
+// postHashtags returns a post's distinct hashtags, from its tags and tag facets, lower-cased without "#"
+func postHashtags(post *FeedPost) []string {
+	var tags []string
+	add := func(tag string) {
+		tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range post.Tags {
+		add(tag)
+	}
+	for _, facet := range post.Facets {
+		if facet.Type == TagFacet {
+			add(facet.Target)
+		}
+	}
+	return tags
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/lex/util"
 )
 
 var (
@@ -17,26 +18,26 @@ var (
 // User represents a BlueSky user profile that can contain either basic or detailed information.
 // Optional fields use pointers for nil-safe handling. Detailed info (follower counts, etc.) may be nil for basic profiles.
 type User struct {
-	Avatar         *string    `json:"avatar,omitempty" cborgen:"avatar,omitempty"`
-	Banner         *string    `json:"banner,omitempty" cborgen:"banner,omitempty"`
-	CreatedAt      time.Time  `json:"createdAt,omitempty" cborgen:"createdAt,omitempty"`
-	Description    *string    `json:"description,omitempty" cborgen:"description,omitempty"`
-	Did            string     `json:"did" cborgen:"did"`
-	DisplayName    *string    `json:"displayName,omitempty" cborgen:"displayName,omitempty"`
-	Handle         string     `json:"handle" cborgen:"handle"`
-	IndexedAt      *time.Time `json:"indexedAt,omitempty" cborgen:"indexedAt,omitempty"`
-	FollowersCount *int       `json:"followersCount,omitempty" cborgen:"followersCount,omitempty"`
-	FollowsCount   *int       `json:"followsCount,omitempty" cborgen:"followsCount,omitempty"`
-	PinnedPost     *PostRef   `json:"pinnedPost,omitempty" cborgen:"pinnedPost,omitempty"`
-	PostsCount     *int       `json:"postsCount,omitempty" cborgen:"postsCount,omitempty"`
+	Avatar         *string        `json:"avatar,omitempty" cborgen:"avatar,omitempty"`
+	Banner         *string        `json:"banner,omitempty" cborgen:"banner,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt,omitempty" cborgen:"createdAt,omitempty"`
+	Description    *string        `json:"description,omitempty" cborgen:"description,omitempty"`
+	Did            string         `json:"did" cborgen:"did"`
+	DisplayName    *string        `json:"displayName,omitempty" cborgen:"displayName,omitempty"`
+	Handle         string         `json:"handle" cborgen:"handle"`
+	IndexedAt      *time.Time     `json:"indexedAt,omitempty" cborgen:"indexedAt,omitempty"`
+	FollowersCount *int           `json:"followersCount,omitempty" cborgen:"followersCount,omitempty"`
+	FollowsCount   *int           `json:"followsCount,omitempty" cborgen:"followsCount,omitempty"`
+	PinnedPost     *PostRef       `json:"pinnedPost,omitempty" cborgen:"pinnedPost,omitempty"`
+	PostsCount     *int           `json:"postsCount,omitempty" cborgen:"postsCount,omitempty"`
+	Labels         []AppliedLabel `json:"labels,omitempty" cborgen:"labels,omitempty"`
+	Viewer         *ProfileViewer `json:"viewer,omitempty" cborgen:"viewer,omitempty"` // the authenticated user's relationship to the account
 	RawBasic       *bsky.ActorDefs_ProfileViewBasic
 	Raw            *bsky.ActorDefs_ProfileView
 	RawDetailed    *bsky.ActorDefs_ProfileViewDetailed
 	//Associated   *ActorDefs_ProfileAssociated       `json:"associated,omitempty" cborgen:"associated,omitempty"`
-	//Labels       []*comatprototypes.LabelDefs_Label `json:"labels,omitempty" cborgen:"labels,omitempty"`
 	//Status       *ActorDefs_StatusView              `json:"status,omitempty" cborgen:"status,omitempty"`
 	//Verification *ActorDefs_VerificationState       `json:"verification,omitempty" cborgen:"verification,omitempty"`
-	//Viewer       *ActorDefs_ViewerState             `json:"viewer,omitempty" cborgen:"viewer,omitempty"`
 }
 
 func (u *User) String() string {
@@ -61,6 +62,8 @@ func OldToNewUserBasic(oldUser *bsky.ActorDefs_ProfileViewBasic) (*User, error)
 		Did:         oldUser.Did,
 		DisplayName: oldUser.DisplayName,
 		Handle:      oldUser.Handle,
+		Labels:      OldToNewLabels(oldUser.Labels),
+		Viewer:      OldToNewProfileViewer(oldUser.Viewer),
 		RawBasic:    oldUser,
 	}, nil
 }
@@ -92,6 +95,8 @@ func OldToNewUser(oldUser *bsky.ActorDefs_ProfileView) (*User, error) {
 		DisplayName: oldUser.DisplayName,
 		Handle:      oldUser.Handle,
 		IndexedAt:   &IndexedAt,
+		Labels:      OldToNewLabels(oldUser.Labels),
+		Viewer:      OldToNewProfileViewer(oldUser.Viewer),
 		Raw:         oldUser,
 		RawDetailed: nil,
 	}
@@ -144,13 +149,16 @@ func OldToNewDetailedUser(oldUser *bsky.ActorDefs_ProfileViewDetailed) (*User, e
 		IndexedAt:      &IndexedAt,
 		PinnedPost:     OldToNewRefPointer(oldUser.PinnedPost),
 		PostsCount:     &postsCount,
+		Labels:         OldToNewLabels(oldUser.Labels),
+		Viewer:         OldToNewProfileViewer(oldUser.Viewer),
 		RawDetailed:    oldUser,
 	}
 	return newUser, nil
 }
 
 // GetProfile retrieves detailed profile information for a specific user.
-// The actor parameter can be either a handle (e.g., "alice.bsky.social") or a DID.
+// The actor parameter can be a handle (e.g., "alice.bsky.social"), a DID, or any other form ParseActor accepts.
+// Concurrent lookups of the same actor share one request.
 //
 // Example:
 //
@@ -161,41 +169,74 @@ func OldToNewDetailedUser(oldUser *bsky.ActorDefs_ProfileViewDetailed) (*User, e
 //	if profile.FollowersCount != nil {
 //	    fmt.Printf("%s has %d followers\n", *profile.DisplayName, *profile.FollowersCount)
 //	}
-func (f *Firefly) GetProfile(ctx context.Context, actor string) (*User, error) {
-	profile, err := bsky.ActorGetProfile(ctx, f.client, actor)
+//
+// Options can bypass the profile cache, choose the labelers whose labels are returned, and require the viewer
+// relationship:
+//
+//	profile, err := client.GetProfile(ctx, "alice.bsky.social",
+//	    firefly.ProfileBypassCache(), firefly.ProfileWithViewer())
+func (f *Firefly) GetProfile(ctx context.Context, actor string, options ...ProfileOption) (*User, error) {
+	query, err := f.newProfileQuery(options)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+		return nil, err
+	}
+	actor = normalizeActor(actor)
+	host, _ := hostOverride(ctx, util.Query)
+	if !query.BypassCache {
+		if user, ok := f.profiles.lookup(host, query.Labelers, actor); ok {
+			return finishProfile(query, user), nil
+		}
 	}
 
-	return OldToNewDetailedUser(profile)
+	profile, err := f.flights.profiles.do(ctx, profileCacheKey(host, query.Labelers, actor), func(ctx context.Context) (*bsky.ActorDefs_ProfileViewDetailed, error) {
+		return bsky.ActorGetProfile(ctx, f.profileClient(query), actor)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+	user, err := OldToNewDetailedUser(profile)
+	if err != nil {
+		return nil, err
+	}
+	f.profiles.store(host, query.Labelers, user)
+	return finishProfile(query, user), nil
 }
 
 // SearchUsers searches for BlueSky users matching the query string.
 // Returns basic user profiles (detailed fields like follower counts may be nil).
 func (f *Firefly) SearchUsers(ctx context.Context, query string, cursor string, limit int) ([]*User, error) {
+	users, _, err := f.searchUsersPage(ctx, query, cursor, limit)
+	return users, err
+}
 
-	result, err := bsky.ActorSearchActors(ctx, f.client, cursor, int64(limit), query, "")
+// searchUsersPage runs a user search and also returns the cursor for the next page
+func (f *Firefly) searchUsersPage(ctx context.Context, query string, cursor string, limit int) ([]*User, string, error) {
+	result, err := bsky.ActorSearchActors(ctx, f.api, cursor, int64(limit), query, "")
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
 	}
 
 	users := make([]*User, len(result.Actors))
 	for i, actor := range result.Actors {
 		newUser, err := OldToNewUser(actor)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		users[i] = newUser
 	}
 
-	return users, nil
+	nextCursor := ""
+	if result.Cursor != nil {
+		nextCursor = *result.Cursor
+	}
+	return users, nextCursor, nil
 }
 
 // GetSuggestedUsers returns user suggestions from BlueSky's recommendation algorithm.
 // Returns basic user profiles (detailed fields like follower counts may be nil).
 func (f *Firefly) GetSuggestedUsers(ctx context.Context, cursor string, limit int) ([]*User, error) {
 
-	result, err := bsky.ActorGetSuggestions(ctx, f.client, cursor, int64(limit))
+	result, err := bsky.ActorGetSuggestions(ctx, f.api, cursor, int64(limit))
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrFailedFetch, err)
 	}
@@ -211,3 +252,51 @@ func (f *Firefly) GetSuggestedUsers(ctx context.Context, cursor string, limit in
 
 	return users, nil
 }
+
+// GetFollowers returns one page of accounts following the given actor, along with the cursor for the next page.
+// The returned cursor is empty when there are no more pages.
+func (f *Firefly) GetFollowers(ctx context.Context, actor string, cursor string, limit int) ([]*User, string, error) {
+	result, err := bsky.GraphGetFollowers(ctx, f.api, actor, cursor, int64(limit))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+
+	users := make([]*User, len(result.Followers))
+	for i, actor := range result.Followers {
+		newUser, err := OldToNewUser(actor)
+		if err != nil {
+			return nil, "", err
+		}
+		users[i] = newUser
+	}
+
+	nextCursor := ""
+	if result.Cursor != nil {
+		nextCursor = *result.Cursor
+	}
+	return users, nextCursor, nil
+}
+
+// GetFollows returns one page of accounts the given actor follows, along with the cursor for the next page.
+// The returned cursor is empty when there are no more pages.
+func (f *Firefly) GetFollows(ctx context.Context, actor string, cursor string, limit int) ([]*User, string, error) {
+	result, err := bsky.GraphGetFollows(ctx, f.api, actor, cursor, int64(limit))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrFailedFetch, err)
+	}
+
+	users := make([]*User, len(result.Follows))
+	for i, actor := range result.Follows {
+		newUser, err := OldToNewUser(actor)
+		if err != nil {
+			return nil, "", err
+		}
+		users[i] = newUser
+	}
+
+	nextCursor := ""
+	if result.Cursor != nil {
+		nextCursor = *result.Cursor
+	}
+	return users, nextCursor, nil
+}
@@ -0,0 +1,285 @@
+package firefly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTaskStopped     = errors.New("supervised task stopped")
+	ErrWatchdogStarted = errors.New("watchdog already started")
+)
+
+// sessionTask is the name the session refresh timer is reported under
+const sessionTask = "session refresh"
+
+// TaskRestart is the error a Watchdog sends to Events when a supervised task dies, as a warning when it is
+// restarted and as an error when the watchdog gives up on it. Use errors.As on BackgroundEvent.Err to read it.
+type TaskRestart struct {
+	Task     string        // Name the task was supervised under
+	Restarts int           // Restart attempts so far, counting this one
+	Delay    time.Duration // Wait before the restart; 0 when giving up
+	Err      error         // Why the task stopped
+}
+
+func (r *TaskRestart) Error() string {
+	if r.Delay == 0 {
+		return fmt.Sprintf("watchdog: %s stopped after %d restarts: %v", r.Task, r.Restarts, r.Err)
+	}
+	return fmt.Sprintf("watchdog: %s stopped, restarting in %s: %v", r.Task, r.Delay.Round(time.Millisecond), r.Err)
+}
+
+func (r *TaskRestart) Unwrap() error {
+	return r.Err
+}
+
+// WatchdogOptions configures a Watchdog
+type WatchdogOptions struct {
+	Backoff       BackoffPolicy // Delays between restarts of a failing task (default the client's BackoffPolicy)
+	HealthyAfter  time.Duration // A task that ran this long before stopping starts its backoff over (default 1 minute)
+	CheckInterval time.Duration // Time between checks of the session refresh timer (default 30 seconds)
+	IgnoreSession bool          // Don't supervise the session refresh timer
+}
+
+// supervisedTask is a blocking loop a Watchdog keeps alive
+type supervisedTask struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Watchdog keeps a long-running bot's background loops alive. It restarts supervised tasks that return or
+// panic before they are cancelled, waiting longer after each failure under a BackoffPolicy, and rearms the
+// session refresh timer after a failed refresh would otherwise leave the session to expire. Every restart is
+// reported to Events with SourceWatchdog.
+type Watchdog struct {
+	f       *Firefly
+	options WatchdogOptions
+
+	mu       sync.Mutex
+	tasks    []supervisedTask
+	ctx      context.Context // set by Start
+	restarts map[string]int
+}
+
+// NewWatchdog creates a Watchdog. Pass nil for options to use the defaults.
+//
+// Example:
+//
+//	watchdog := client.NewWatchdog(nil)
+//	watchdog.Supervise("mentions", router.Run)
+//	watchdog.Supervise("firehose", client.FirehoseTask(&firefly.FirehoseOptions{
+//	    Collections: []string{firefly.CollectionPost},
+//	}, func(ctx context.Context, event *firefly.FirehoseEvent) {
+//	    handle(event)
+//	}))
+//	if err := watchdog.Start(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (f *Firefly) NewWatchdog(options *WatchdogOptions) *Watchdog {
+	if options == nil {
+		options = &WatchdogOptions{}
+	}
+	opts := *options
+	if opts.Backoff == (BackoffPolicy{}) {
+		opts.Backoff = f.backoffPolicy()
+	}
+	opts.Backoff = opts.Backoff.withDefaults()
+	if opts.HealthyAfter <= 0 {
+		opts.HealthyAfter = time.Minute
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = 30 * time.Second
+	}
+	return &Watchdog{f: f, options: opts, restarts: make(map[string]int)}
+}
+
+// Supervise adds a task to the watchdog. The task should block while it works, like NotificationRouter.Run or
+// ConvoSession.Run, and return once ctx is cancelled; returning or panicking any earlier counts as dying.
+// Tasks added after Start begin at once.
+func (w *Watchdog) Supervise(name string, task func(ctx context.Context) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	supervised := supervisedTask{name: name, run: task}
+	w.tasks = append(w.tasks, supervised)
+	if w.ctx != nil && w.ctx.Err() == nil {
+		w.launch(w.ctx, supervised)
+	}
+}
+
+// Restarts returns how many times each task has been restarted, by name
+func (w *Watchdog) Restarts() map[string]int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return maps.Clone(w.restarts)
+}
+
+// Start runs the supervised tasks in the background until ctx is cancelled or the client is closed. A task
+// that keeps dying is given up on once the BackoffPolicy runs out of retries; the others carry on.
+func (w *Watchdog) Start(ctx context.Context) error {
+	if w.f.isClosed() {
+		return ErrClientClosed
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ctx != nil {
+		return ErrWatchdogStarted
+	}
+	ctx, cancel := w.f.bindLifetime(ctx)
+	w.ctx = ctx
+	for _, task := range w.tasks {
+		w.launch(ctx, task)
+	}
+	if !w.options.IgnoreSession {
+		w.f.background.Add(1)
+		go func() {
+			defer w.f.background.Done()
+			w.superviseSession(ctx)
+		}()
+	}
+	// Release the lifetime binding once the watchdog stops
+	context.AfterFunc(ctx, cancel)
+	return nil
+}
+
+// launch runs one task under supervision. Callers must hold mu.
+func (w *Watchdog) launch(ctx context.Context, task supervisedTask) {
+	w.f.background.Add(1)
+	go func() {
+		defer w.f.background.Done()
+		w.supervise(ctx, task)
+	}()
+}
+
+// supervise runs a task until ctx is cancelled, restarting it whenever it stops early
+func (w *Watchdog) supervise(ctx context.Context, task supervisedTask) {
+	policy := w.options.Backoff
+	failures := 0
+	for {
+		started := time.Now()
+		err := runTask(ctx, task.run)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = ErrTaskStopped
+		}
+		if time.Since(started) >= w.options.HealthyAfter {
+			failures = 0
+		}
+		if policy.Exhausted(failures) {
+			w.f.emit(SourceWatchdog, SeverityError, &TaskRestart{Task: task.name, Restarts: w.restartCount(task.name), Err: err})
+			return
+		}
+		delay := policy.Delay(failures)
+		failures++
+		w.f.emit(SourceWatchdog, SeverityWarning, &TaskRestart{Task: task.name, Restarts: w.countRestart(task.name), Delay: delay, Err: err})
+		if !sleepUntil(ctx, time.Now().Add(delay)) {
+			return
+		}
+	}
+}
+
+// superviseSession checks the session refresh timer every CheckInterval. A scheduled refresh that fails is
+// not rescheduled, so while the client is logged in without a timer, the watchdog refreshes the session
+// itself, which rearms the timer.
+func (w *Watchdog) superviseSession(ctx context.Context) {
+	policy := w.options.Backoff
+	failures := 0
+	for {
+		wait := w.options.CheckInterval
+		if w.f.refreshStopped() {
+			refreshCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			err := w.f.syncRefresh(refreshCtx, "")
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+			restarts := w.countRestart(sessionTask)
+			if err == nil {
+				failures = 0
+				w.f.emit(SourceWatchdog, SeverityInfo, fmt.Errorf("watchdog: %s rearmed", sessionTask))
+			} else if policy.Exhausted(failures) {
+				w.f.emit(SourceWatchdog, SeverityError, &TaskRestart{Task: sessionTask, Restarts: restarts, Err: err})
+				return
+			} else {
+				delay := policy.Delay(failures)
+				failures++
+				wait = max(wait, delay)
+				w.f.emit(SourceWatchdog, SeverityWarning, &TaskRestart{Task: sessionTask, Restarts: restarts, Delay: wait, Err: err})
+			}
+		}
+		if !sleepUntil(ctx, time.Now().Add(wait)) {
+			return
+		}
+	}
+}
+
+// countRestart records a restart attempt for a task and returns the total
+func (w *Watchdog) countRestart(name string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.restarts[name]++
+	return w.restarts[name]
+}
+
+func (w *Watchdog) restartCount(name string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.restarts[name]
+}
+
+// runTask runs a task once, turning a panic into an error
+func runTask(ctx context.Context, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: panic: %v", ErrTaskStopped, p)
+		}
+	}()
+	return run(ctx)
+}
+
+// refreshStopped reports whether the client is logged in with no session refresh scheduled, which happens
+// after a scheduled refresh fails
+func (f *Firefly) refreshStopped() bool {
+	f.refreshMu.Lock()
+	defer f.refreshMu.Unlock()
+	return f.cancelRefresh == nil && f.currentClient().Auth != nil
+}
+
+// FirehoseTask returns a task for Watchdog.Supervise that streams firehose events to handle until the stream
+// gives up reconnecting. Each restart resumes from just after the last event handled, so nothing is missed
+// within Jetstream's replay window. options is copied and may be nil.
+func (f *Firefly) FirehoseTask(options *FirehoseOptions, handle func(ctx context.Context, event *FirehoseEvent)) func(ctx context.Context) error {
+	stream := FirehoseOptions{}
+	if options != nil {
+		stream = *options
+	}
+	var resume *int64
+	return func(ctx context.Context) error {
+		// Stop the stream if handle panics, so a restart doesn't leave it running
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		current := stream
+		if resume != nil {
+			current.Cursor = resume
+		}
+		events, err := f.StreamEvents(ctx, &current)
+		if err != nil {
+			return err
+		}
+		for event := range events {
+			handle(ctx, event)
+			next := event.Sequence + 1
+			resume = &next
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("%w: firehose stream ended", ErrTaskStopped)
+	}
+}
@@ -0,0 +1,312 @@
+package firefly
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrWebhookFailed  = errors.New("webhook delivery failed")
+	ErrNoWebhookURL   = errors.New("webhook URL is required")
+	ErrWebhookBacklog = errors.New("webhook delivery queue is full")
+	ErrWebhookClosed  = errors.New("webhook sink closed")
+)
+
+// WebhookSinkOptions configures a WebhookSink
+type WebhookSinkOptions struct {
+	URL           string                        // Endpoint that receives POSTed batches (required)
+	Secret        []byte                        // HMAC-SHA256 signing key; nil disables signing
+	Filter        func(*FirehoseEvent) bool     // Only events for which Filter returns true are sent; nil sends all
+	BatchSize     int                           // Events per request (default 100)
+	FlushInterval time.Duration                 // Maximum time an event waits in a partial batch (default 5 seconds)
+	QueueSize     int                           // Full batches waiting for delivery before new ones are dead-lettered (default 10)
+	MaxRetries    int                           // Retries after the first failed attempt (default 3; negative for none, as 0 means the default)
+	RetryBackoff  time.Duration                 // Delay before the first retry, growing as in DefaultBackoffPolicy (default 1 second)
+	DeadLetter    func([]*FirehoseEvent, error) // Receives batches that could not be delivered; nil drops them
+	HTTPClient    *http.Client                  // Client used for requests (default 10 second timeout)
+	Headers       map[string]string             // Extra headers sent with every request
+}
+
+// WebhookBatch is the JSON body POSTed to the webhook
+type WebhookBatch struct {
+	SentAt time.Time        `json:"sentAt"`
+	Events []*FirehoseEvent `json:"events"`
+}
+
+// WebhookSink forwards firehose events to an HTTP endpoint as signed JSON batches, so systems that don't
+// speak WebSocket can consume a filtered stream.
+//
+// When a Secret is set, each request carries an X-Firefly-Timestamp header (Unix seconds) and an
+// X-Firefly-Signature header of the form "sha256=<hex>", which is the HMAC-SHA256 of the timestamp,
+// a ".", and the request body. Receivers should recompute it and reject stale timestamps.
+//
+// Batches are delivered by a goroutine the sink starts on its first Write, so a slow or failing endpoint never
+// holds up the firehose read loop; batches that can't be delivered go to DeadLetter. Call Close to send the
+// last partial batch and stop the goroutine.
+type WebhookSink struct {
+	options WebhookSinkOptions
+
+	mu      sync.Mutex
+	pending []*FirehoseEvent
+	closed  bool
+
+	batches   chan []*FirehoseEvent
+	start     sync.Once
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+	ctx       context.Context // cancels in-flight deliveries when Close gives up waiting
+	cancel    context.CancelFunc
+}
+
+// NewWebhookSink creates a WebhookSink. Only URL is required.
+//
+// Example:
+//
+//	sink, err := firefly.NewWebhookSink(&firefly.WebhookSinkOptions{
+//	    URL:    "https://example.com/hooks/bluesky",
+//	    Secret: []byte(os.Getenv("WEBHOOK_SECRET")),
+//	    Filter: func(e *firefly.FirehoseEvent) bool { return e.Type == firefly.EventTypePost },
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	events, _ := client.StreamEvents(ctx, &firefly.FirehoseOptions{Sinks: []firefly.EventSink{sink}})
+//	defer sink.Close(context.Background())
+func NewWebhookSink(options *WebhookSinkOptions) (*WebhookSink, error) {
+	if options == nil || options.URL == "" {
+		return nil, ErrNoWebhookURL
+	}
+	opts := *options
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	} else if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 10
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookSink{
+		options: opts,
+		batches: make(chan []*FirehoseEvent, opts.QueueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}, nil
+}
+
+// Write queues an event for delivery and returns without waiting for the network. A full batch is handed to
+// the delivery goroutine; if QueueSize batches are already waiting, the batch goes to DeadLetter and
+// ErrWebhookBacklog is returned. Events rejected by the Filter are ignored.
+func (s *WebhookSink) Write(ctx context.Context, event *FirehoseEvent) error {
+	if event == nil || (s.options.Filter != nil && !s.options.Filter(event)) {
+		return nil
+	}
+	s.start.Do(s.startDelivery)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrWebhookClosed
+	}
+	s.pending = append(s.pending, event)
+	if len(s.pending) < s.options.BatchSize {
+		return nil
+	}
+	batch := s.pending
+	s.pending = nil
+	select {
+	case s.batches <- batch:
+		return nil
+	default:
+		if s.options.DeadLetter != nil {
+			s.options.DeadLetter(batch, ErrWebhookBacklog)
+		}
+		return ErrWebhookBacklog
+	}
+}
+
+// startDelivery starts the goroutine that sends queued batches, and partial ones every FlushInterval
+func (s *WebhookSink) startDelivery() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.options.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case batch := <-s.batches:
+				s.send(s.ctx, batch)
+			case <-ticker.C:
+				s.Flush(s.ctx)
+			case <-s.stop:
+				// Writes are refused by now, so the queue can only shrink
+				for len(s.batches) > 0 {
+					s.send(s.ctx, <-s.batches)
+				}
+				s.Flush(s.ctx)
+				return
+			}
+		}
+	}()
+}
+
+// Close stops accepting events, delivers the queued batches and the last partial one, and stops the delivery
+// goroutine. If ctx ends first, deliveries still in progress are abandoned and ctx's error is returned.
+func (s *WebhookSink) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		s.start.Do(s.startDelivery)
+		close(s.stop)
+	})
+	select {
+	case <-s.done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
+// Flush sends any queued events immediately. Batches that still fail after all retries are passed
+// to DeadLetter and the delivery error is returned.
+func (s *WebhookSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.send(ctx, batch)
+}
+
+// send delivers a batch, passing it to DeadLetter if it fails
+func (s *WebhookSink) send(ctx context.Context, batch []*FirehoseEvent) error {
+	err := s.deliver(ctx, batch)
+	if err != nil && s.options.DeadLetter != nil {
+		s.options.DeadLetter(batch, err)
+	}
+	return err
+}
+
+// Run writes every event from events to the webhook until the channel is closed or ctx is cancelled, then
+// closes the sink, waiting up to the HTTP client's timeout for the last batches. Use it when consuming a
+// channel instead of setting the sink in FirehoseOptions.Sinks. Delivery errors don't stop Run; they are
+// handled by DeadLetter.
+func (s *WebhookSink) Run(ctx context.Context, events <-chan *FirehoseEvent) error {
+	defer func() {
+		// Use a fresh context so the final batches aren't lost to the cancellation
+		closeCtx, cancel := context.WithTimeout(context.Background(), s.options.HTTPClient.Timeout+time.Second)
+		defer cancel()
+		s.Close(closeCtx)
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			s.Write(ctx, event)
+		}
+	}
+}
+
+// deliver POSTs a batch, retrying with exponential backoff on network errors, 5xx responses, and rate limiting
+func (s *WebhookSink) deliver(ctx context.Context, batch []*FirehoseEvent) error {
+	body, err := json.Marshal(&WebhookBatch{SentAt: time.Now(), Events: batch})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWebhookFailed, err)
+	}
+
+	policy := BackoffPolicy{Initial: s.options.RetryBackoff}
+	var lastErr error
+	for attempt := 0; attempt <= s.options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %w", ErrWebhookFailed, ctx.Err())
+			case <-time.After(policy.Delay(attempt - 1)):
+			}
+		}
+
+		retry, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return fmt.Errorf("%w: %w", ErrWebhookFailed, lastErr)
+}
+
+// post sends a single request and reports whether a failure is worth retrying
+func (s *WebhookSink) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.options.Headers {
+		req.Header.Set(key, value)
+	}
+	if s.options.Secret != nil {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Firefly-Timestamp", timestamp)
+		req.Header.Set("X-Firefly-Signature", "sha256="+signWebhook(s.options.Secret, timestamp, body))
+	}
+
+	resp, err := s.options.HTTPClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
+
+// signWebhook computes the hex HMAC-SHA256 of "timestamp.body"
+func signWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,354 @@
+package firefly
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+)
+
+var (
+	ErrWriteTooLarge = errors.New("write exceeds the write queue budget")
+)
+
+// Point costs of repository writes, as counted by Bluesky's PDS rate limits
+const (
+	writeCostCreate = 3
+	writeCostUpdate = 2
+	writeCostDelete = 1
+)
+
+// Default write budgets, matching the limits Bluesky's PDS applies to each account
+const (
+	defaultHourlyWritePoints = 5000
+	defaultDailyWritePoints  = 35000
+)
+
+// WritePriority orders queued writes. Higher priorities run first; writes of equal priority run in the order
+// they were made.
+type WritePriority int
+
+const (
+	WritePriorityLow WritePriority = iota - 1
+	WritePriorityNormal
+	WritePriorityHigh
+)
+
+func (p WritePriority) String() string {
+	switch p {
+	case WritePriorityLow:
+		return "Low"
+	case WritePriorityNormal:
+		return "Normal"
+	case WritePriorityHigh:
+		return "High"
+	default:
+		return "Unknown"
+	}
+}
+
+// writePriorityKey is the context key for WithWritePriority
+type writePriorityKey struct{}
+
+// WithWritePriority returns a context whose record writes are queued at priority. Without it, writes use
+// WritePriorityNormal. It has no effect unless the write queue is enabled.
+//
+// Example:
+//
+//	// Replies to users go ahead of background likes
+//	_, err := client.PostReply(firefly.WithWritePriority(ctx, firefly.WritePriorityHigh), post, reply)
+func WithWritePriority(ctx context.Context, priority WritePriority) context.Context {
+	return context.WithValue(ctx, writePriorityKey{}, priority)
+}
+
+// WriteQueueOptions configures the write queue
+type WriteQueueOptions struct {
+	HourlyPoints int // Points that may be spent in any hour (default 5000)
+	DailyPoints  int // Points that may be spent in any 24 hours (default 35000)
+}
+
+// WriteQueueStats is a snapshot of the write queue
+type WriteQueueStats struct {
+	Pending      int                   `json:"pending"`             // writes waiting for their turn or for budget
+	ByPriority   map[WritePriority]int `json:"byPriority"`          // pending writes per priority
+	InFlight     bool                  `json:"inFlight"`            // a write is being sent
+	Deferred     uint64                `json:"deferred"`            // writes so far that had to wait for budget
+	HourlyUsed   int                   `json:"hourlyUsed"`          // points spent in the last hour
+	HourlyPoints int                   `json:"hourlyPoints"`        // hourly budget
+	DailyUsed    int                   `json:"dailyUsed"`           // points spent in the last 24 hours
+	DailyPoints  int                   `json:"dailyPoints"`         // daily budget
+	ResumesAt    *time.Time            `json:"resumesAt,omitempty"` // when the next pending write fits the budget; nil if it fits now
+}
+
+func (s WriteQueueStats) String() string {
+	return fmt.Sprintf("WriteQueueStats{Pending: %d, Hourly: %d/%d, Daily: %d/%d}",
+		s.Pending, s.HourlyUsed, s.HourlyPoints, s.DailyUsed, s.DailyPoints)
+}
+
+// WithWriteQueue sends every record write (posts, likes, follows, deletes and batched writes) through a queue
+// that runs them one at a time and keeps them within hourly and daily point budgets. When the budget is used
+// up, writes wait until enough of it frees up instead of failing, so a long-running bot doesn't trip its
+// account's rate limits. Pass nil for options to use Bluesky's default limits.
+//
+// Example:
+//
+//	client, err := firefly.NewDefaultInstance(ctx, firefly.WithWriteQueue(&firefly.WriteQueueOptions{
+//	    HourlyPoints: 1000,
+//	}))
+func WithWriteQueue(options *WriteQueueOptions) Option {
+	return func(f *Firefly) {
+		if options == nil {
+			options = &WriteQueueOptions{}
+		}
+		opts := *options
+		if opts.HourlyPoints <= 0 {
+			opts.HourlyPoints = defaultHourlyWritePoints
+		}
+		if opts.DailyPoints <= 0 {
+			opts.DailyPoints = defaultDailyWritePoints
+		}
+		f.writes = &writeQueue{f: f, hourly: opts.HourlyPoints, daily: opts.DailyPoints}
+	}
+}
+
+// WriteQueueStats returns a snapshot of the write queue. It returns the zero value if the write queue is not
+// enabled.
+func (f *Firefly) WriteQueueStats() WriteQueueStats {
+	if f.writes == nil {
+		return WriteQueueStats{}
+	}
+	return f.writes.stats()
+}
+
+// writeQueue grants record writes one at a time, highest priority first, once their cost fits the budget
+type writeQueue struct {
+	f      *Firefly
+	hourly int
+	daily  int
+
+	mu       sync.Mutex
+	spent    []spentPoints // oldest first; entries older than a day are pruned
+	waiting  []*writeTicket
+	inFlight bool
+	deferred uint64
+	seq      uint64
+	timer    *time.Timer
+	blocked  bool // the head of the queue is waiting for budget
+}
+
+// spentPoints records the cost of a granted write
+type spentPoints struct {
+	at     time.Time
+	points int
+	seq    uint64 // the granted ticket's seq, so a cancelled grant can be refunded
+}
+
+// writeTicket is a write waiting for its turn; ready is closed when it is granted
+type writeTicket struct {
+	priority WritePriority
+	cost     int
+	seq      uint64
+	deferred bool
+	ready    chan struct{}
+}
+
+// acquire waits until a write of cost points may be sent and returns the function to call once it is done
+func (q *writeQueue) acquire(ctx context.Context, cost int) (func(), error) {
+	if cost > q.hourly || cost > q.daily {
+		return nil, fmt.Errorf("%w: costs %d points", ErrWriteTooLarge, cost)
+	}
+	priority, _ := ctx.Value(writePriorityKey{}).(WritePriority)
+
+	ticket := &writeTicket{priority: priority, cost: cost, ready: make(chan struct{})}
+	q.dispatch(func() {
+		q.seq++
+		ticket.seq = q.seq
+		at, _ := slices.BinarySearchFunc(q.waiting, ticket, compareTickets)
+		q.waiting = slices.Insert(q.waiting, at, ticket)
+	})
+
+	select {
+	case <-ticket.ready:
+		return q.release, nil
+	case <-ctx.Done():
+		q.dispatch(func() {
+			select {
+			case <-ticket.ready:
+				// Granted just as the caller gave up; nothing was sent, so refund it and pass the turn on
+				q.inFlight = false
+				q.spent = slices.DeleteFunc(q.spent, func(entry spentPoints) bool { return entry.seq == ticket.seq })
+			default:
+				q.waiting = slices.DeleteFunc(q.waiting, func(t *writeTicket) bool { return t == ticket })
+			}
+		})
+		return nil, ctx.Err()
+	}
+}
+
+// release ends the write in flight and grants the next one
+func (q *writeQueue) release() {
+	q.dispatch(func() { q.inFlight = false })
+}
+
+// dispatch runs update, if any, under the lock, then grants the next write if it can. Deferral notices are
+// emitted after unlocking, since OnEvent callbacks may read the stats.
+func (q *writeQueue) dispatch(update func()) {
+	q.mu.Lock()
+	if update != nil {
+		update()
+	}
+	notice := q.dispatchLocked()
+	q.mu.Unlock()
+	if notice != nil {
+		q.f.emit(SourceScheduler, SeverityInfo, notice)
+	}
+}
+
+// dispatchLocked grants the head of the queue if nothing is in flight and its cost fits the budget, and
+// otherwise schedules a retry for when it will. It returns a notice when the queue starts waiting for budget.
+func (q *writeQueue) dispatchLocked() error {
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	if q.inFlight || len(q.waiting) == 0 {
+		return nil
+	}
+	head := q.waiting[0]
+	now := time.Now()
+	wait := q.waitLocked(head.cost, now)
+	if wait > 0 {
+		if !head.deferred {
+			head.deferred = true
+			q.deferred++
+		}
+		q.timer = time.AfterFunc(wait, func() { q.dispatch(nil) })
+		if q.blocked {
+			return nil
+		}
+		q.blocked = true
+		return fmt.Errorf("write budget used up, deferring %d writes until %s",
+			len(q.waiting), now.Add(wait).Format(time.RFC3339))
+	}
+
+	q.blocked = false
+	q.waiting = q.waiting[1:]
+	q.inFlight = true
+	q.spent = append(q.spent, spentPoints{at: now, points: head.cost, seq: head.seq})
+	close(head.ready)
+	return nil
+}
+
+// waitLocked prunes expired spending and returns how long until cost points fit both budgets
+func (q *writeQueue) waitLocked(cost int, now time.Time) time.Duration {
+	expired := 0
+	for expired < len(q.spent) && now.Sub(q.spent[expired].at) >= 24*time.Hour {
+		expired++
+	}
+	q.spent = q.spent[expired:]
+
+	wait := time.Duration(0)
+	for _, window := range []struct {
+		length time.Duration
+		budget int
+	}{{time.Hour, q.hourly}, {24 * time.Hour, q.daily}} {
+		used := 0
+		for _, entry := range q.spent {
+			if now.Sub(entry.at) < window.length {
+				used += entry.points
+			}
+		}
+		excess := used + cost - window.budget
+		for _, entry := range q.spent {
+			if excess <= 0 {
+				break
+			}
+			if now.Sub(entry.at) >= window.length {
+				continue
+			}
+			excess -= entry.points
+			if excess <= 0 {
+				wait = max(wait, entry.at.Add(window.length).Sub(now))
+			}
+		}
+	}
+	return wait
+}
+
+// stats returns a snapshot of the queue
+func (q *writeQueue) stats() WriteQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	stats := WriteQueueStats{
+		Pending:      len(q.waiting),
+		ByPriority:   make(map[WritePriority]int),
+		InFlight:     q.inFlight,
+		Deferred:     q.deferred,
+		HourlyPoints: q.hourly,
+		DailyPoints:  q.daily,
+	}
+	for _, ticket := range q.waiting {
+		stats.ByPriority[ticket.priority]++
+	}
+	for _, entry := range q.spent {
+		if now.Sub(entry.at) < time.Hour {
+			stats.HourlyUsed += entry.points
+		}
+		if now.Sub(entry.at) < 24*time.Hour {
+			stats.DailyUsed += entry.points
+		}
+	}
+	if len(q.waiting) > 0 {
+		if wait := q.waitLocked(q.waiting[0].cost, now); wait > 0 {
+			resumes := now.Add(wait)
+			stats.ResumesAt = &resumes
+		}
+	}
+	return stats
+}
+
+// compareTickets orders tickets by descending priority, then by arrival
+func compareTickets(a, b *writeTicket) int {
+	if a.priority != b.priority {
+		return cmp.Compare(b.priority, a.priority)
+	}
+	return cmp.Compare(a.seq, b.seq)
+}
+
+// writeCost returns the point cost of a request, or 0 if it isn't a record write
+func writeCost(endpoint string, bodyData any) int {
+	switch endpoint {
+	case "com.atproto.repo.createRecord":
+		return writeCostCreate
+	case "com.atproto.repo.putRecord":
+		return writeCostUpdate
+	case "com.atproto.repo.deleteRecord":
+		return writeCostDelete
+	case "com.atproto.repo.applyWrites":
+		input, ok := bodyData.(*atproto.RepoApplyWrites_Input)
+		if !ok {
+			return writeCostCreate
+		}
+		cost := 0
+		for _, write := range input.Writes {
+			switch {
+			case write == nil:
+			case write.RepoApplyWrites_Create != nil:
+				cost += writeCostCreate
+			case write.RepoApplyWrites_Update != nil:
+				cost += writeCostUpdate
+			case write.RepoApplyWrites_Delete != nil:
+				cost += writeCostDelete
+			}
+		}
+		return cost
+	default:
+		return 0
+	}
+}